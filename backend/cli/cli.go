@@ -0,0 +1,336 @@
+// Package cli 提供无界面的 agent-hub 命令行入口。
+// 命令直接构造与 GUI 相同的 services，不启动 Wails 窗口，便于在开发机和 CI 中脚本化操作。
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"agent-hub/backend/services"
+)
+
+// errUsage 表示参数错误，Run 会以退出码 2 结束
+var errUsage = errors.New("usage error")
+
+// reportedError 表示结果（含失败信息）已经输出，Run 只需以非零退出码结束
+type reportedError struct{ error }
+
+func reported(err error) error { return reportedError{err} }
+
+// command 单个顶层命令
+type command struct {
+	name    string
+	summary string
+	run     func(r *runner, args []string) error
+}
+
+// runner 命令执行期间共享的状态（服务实例与输出）
+type runner struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	json    bool
+	env     *services.Environment
+	skills  *services.SkillsService
+	agents  *services.AgentService
+	started bool
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"skills", "管理全局 skills（list / show / install / update / rollback / history / diff / restore / delete / link / verify / lint / scan / hash / sign）", runSkills},
+		{"agents", "列出支持的 agents", runAgents},
+		{"health", "检查 agent 软链接健康状态（--repair 自动修复）", runHealth},
+		{"config", "导出 / 导入配置（export / import）", runConfig},
+		{"cache", "管理仓库克隆缓存（list / prune）", runCache},
+		{"mirror", "管理离线镜像（sync / list / remove）", runMirror},
+		{"credentials", "管理私有仓库的 host 凭据（list / set / remove）", runCredentials},
+		{"registry", "管理远程 registry 并搜索 skills（list / add / remove / enable / disable / move / search / show）", runRegistry},
+		{"serve-registry", "以 HTTP/JSON registry 协议发布本地目录中的 skills", runServeRegistry},
+		{"policy", "查看与设置 skill 来源的信任策略（show / check / set）", runPolicy},
+		{"keys", "管理校验 skill 签名的受信任公钥（list / add / remove）", runKeys},
+		{"secrets", "查看密钥存储状态、设置 vault 口令（info / passphrase）", runSecrets},
+		{"activity", "分页查询活动日志", runActivity},
+		{"store", "管理元数据存储（info / import）", runStore},
+		{"help", "显示帮助", runHelp},
+	}
+}
+
+// globalFlags 可出现在任意位置的全局参数
+type globalFlags struct {
+	json      bool
+	offline   bool
+	homeDir   string
+	skillsDir string
+	configDir string
+}
+
+// dirFlags 覆盖目录的全局参数，对应 services.LoadEnvironment 的三个参数
+var dirFlags = []string{"home", "skills-dir", "config-dir"}
+
+// parseGlobalFlags 从参数中取出全局参数，返回剩余参数
+func parseGlobalFlags(args []string) (globalFlags, []string, error) {
+	var g globalFlags
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--json" || a == "-json" {
+			g.json = true
+			continue
+		}
+		if a == "--offline" || a == "-offline" {
+			g.offline = true
+			continue
+		}
+		name, value, matched := "", "", false
+		for _, f := range dirFlags {
+			for _, prefix := range []string{"--" + f, "-" + f} {
+				if a == prefix {
+					if i+1 >= len(args) {
+						return g, nil, fmt.Errorf("flag needs an argument: %s", a)
+					}
+					name, value, matched = f, args[i+1], true
+					i++
+				} else if strings.HasPrefix(a, prefix+"=") {
+					name, value, matched = f, strings.TrimPrefix(a, prefix+"="), true
+				}
+			}
+		}
+		if !matched {
+			rest = append(rest, a)
+			continue
+		}
+		switch name {
+		case "home":
+			g.homeDir = value
+		case "skills-dir":
+			g.skillsDir = value
+		case "config-dir":
+			g.configDir = value
+		}
+	}
+	return g, rest, nil
+}
+
+// IsCommand 判断命令行参数是否应以 CLI 模式运行
+func IsCommand(args []string) bool {
+	_, args, err := parseGlobalFlags(args)
+	if err != nil {
+		return true
+	}
+	if len(args) == 0 {
+		return false
+	}
+	name := args[0]
+	if name == "-h" || name == "--help" {
+		return true
+	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Run 执行命令并返回进程退出码
+func Run(args []string, stdout, stderr io.Writer) int {
+	r := &runner{stdout: stdout, stderr: stderr}
+
+	// 全局参数可以出现在任意位置
+	g, rest, err := parseGlobalFlags(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n\n", err)
+		printUsage(stderr)
+		return 2
+	}
+	r.json = g.json
+
+	if len(rest) == 0 || rest[0] == "-h" || rest[0] == "--help" {
+		printUsage(stdout)
+		return 0
+	}
+
+	env, err := services.LoadEnvironment(g.homeDir, g.skillsDir, g.configDir)
+	if err != nil {
+		r.fail(err)
+		return 1
+	}
+	if g.offline {
+		env.Offline = true
+	}
+	r.env = env
+
+	for _, cmd := range commands {
+		if cmd.name != rest[0] {
+			continue
+		}
+		err := cmd.run(r, rest[1:])
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			return 2
+		case errors.As(err, new(reportedError)):
+			if !r.json {
+				fmt.Fprintf(stderr, "Error: %v\n", err)
+			}
+			return 1
+		default:
+			r.fail(err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(stderr, "unknown command: %s\n\n", rest[0])
+	printUsage(stderr)
+	return 2
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: agent-hub <command> [arguments] [global flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-15s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Global flags:")
+	fmt.Fprintln(w, "  --json               以 JSON 输出结果")
+	fmt.Fprintf(w, "  --offline            离线模式，只使用本地镜像（环境变量 %s）\n", services.EnvOffline)
+	fmt.Fprintf(w, "  --home <dir>         替代 home 目录（环境变量 %s）\n", services.EnvHomeDir)
+	fmt.Fprintf(w, "  --skills-dir <dir>   中央 skills 目录（环境变量 %s）\n", services.EnvSkillsDir)
+	fmt.Fprintf(w, "  --config-dir <dir>   配置目录（环境变量 %s）\n", services.EnvConfigDir)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'agent-hub <command> --help' for details.")
+}
+
+func runHelp(r *runner, args []string) error {
+	printUsage(r.stdout)
+	return nil
+}
+
+// start 懒加载服务实例，模拟 GUI 的 OnStartup 流程
+func (r *runner) start() {
+	if r.started {
+		return
+	}
+	ctx := context.Background()
+	r.skills = services.NewSkillsService(r.env)
+	r.skills.Startup(ctx)
+	r.agents = services.NewAgentService(r.env)
+	r.agents.Startup(ctx)
+	r.started = true
+}
+
+// print 按输出模式打印结果：JSON 模式下输出 v，否则调用 text 输出可读文本
+func (r *runner) print(v interface{}, text func(w io.Writer)) error {
+	if r.json {
+		enc := json.NewEncoder(r.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	text(r.stdout)
+	return nil
+}
+
+// fail 输出错误信息，JSON 模式下输出 {"error": "..."}
+func (r *runner) fail(err error) {
+	if r.json {
+		enc := json.NewEncoder(r.stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	fmt.Fprintf(r.stderr, "Error: %v\n", err)
+}
+
+// newFlagSet 创建子命令的参数解析器，错误输出到 stderr
+func (r *runner) newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(r.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(r.stderr, "Usage: agent-hub %s\n", usage)
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(r.stderr, "\nFlags:")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
+
+// parseArgs 解析参数，允许 flag 与位置参数交替出现（例如 install a@b --agents X）
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// splitList 将逗号分隔的列表拆分为去空白后的切片
+func splitList(s string) []string {
+	var result []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// usageError 输出错误原因与用法，并返回 errUsage
+func usageError(fs *flag.FlagSet, format string, a ...interface{}) error {
+	fmt.Fprintf(fs.Output(), format+"\n\n", a...)
+	fs.Usage()
+	return errUsage
+}
+
+// newTable 创建对齐输出的表格 writer
+func newTable(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+}
+
+func runAgents(r *runner, args []string) error {
+	fs := r.newFlagSet("agents", "agents")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	r.start()
+	agents := r.agents.GetSupportedAgents()
+	sort.SliceStable(agents, func(i, j int) bool { return agents[i].Name < agents[j].Name })
+	return r.print(agents, func(w io.Writer) {
+		tw := newTable(w)
+		fmt.Fprintln(tw, "NAME\tGLOBAL PATHS\tCUSTOM")
+		for _, a := range agents {
+			custom := ""
+			if a.IsCustom {
+				custom = "yes"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", a.Name, strings.Join(a.GlobalPaths, ", "), custom)
+		}
+		tw.Flush()
+	})
+}
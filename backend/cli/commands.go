@@ -0,0 +1,1937 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"agent-hub/backend/services"
+)
+
+// itemResult 批量操作中单项的执行结果
+type itemResult struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// printItemResults 输出批量操作结果，存在失败项时返回错误
+func (r *runner) printItemResults(action string, results []itemResult) error {
+	failed := 0
+	for _, res := range results {
+		if !res.OK {
+			failed++
+		}
+	}
+	if err := r.print(results, func(w io.Writer) {
+		for _, res := range results {
+			if res.OK {
+				fmt.Fprintf(w, "%s %s\n", action, res.Name)
+			} else {
+				fmt.Fprintf(w, "failed %s: %s\n", res.Name, res.Error)
+			}
+		}
+	}); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return reported(fmt.Errorf("%d of %d failed", failed, len(results)))
+	}
+	return nil
+}
+
+// printBatchResult 输出批量操作的逐项结果，存在失败项时返回错误
+func (r *runner) printBatchResult(action string, result *services.BatchResult) error {
+	if err := r.print(result, func(w io.Writer) {
+		for _, item := range result.Items {
+			switch item.Status {
+			case services.BatchStatusSucceeded:
+				fmt.Fprintf(w, "%s %s\n", action, item.Name)
+			case services.BatchStatusRolledBack:
+				fmt.Fprintf(w, "rolled back %s\n", item.Name)
+			case services.BatchStatusSkipped:
+				fmt.Fprintf(w, "skipped %s\n", item.Name)
+			default:
+				fmt.Fprintf(w, "failed %s: %s\n", item.Name, item.Error)
+			}
+		}
+	}); err != nil {
+		return err
+	}
+	if result.Failed > 0 {
+		if result.RolledBack {
+			return reported(fmt.Errorf("%d of %d failed, all changes rolled back", result.Failed, len(result.Items)))
+		}
+		return reported(fmt.Errorf("%d of %d failed", result.Failed, len(result.Items)))
+	}
+	return nil
+}
+
+// planEach 为每一项生成变更计划并合并，无法生成计划的项记录为警告
+func planEach(operation string, names []string, fn func(name string) (*services.ChangePlan, error)) *services.ChangePlan {
+	plan := services.NewChangePlan(operation)
+	for _, name := range names {
+		p, err := fn(name)
+		if err != nil {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		plan.Merge(p)
+	}
+	return plan
+}
+
+// printPlan 输出 --dry-run 的变更计划
+func (r *runner) printPlan(plan *services.ChangePlan) error {
+	return r.print(plan, func(w io.Writer) {
+		fmt.Fprintf(w, "dry run (%s), nothing was changed\n", plan.Operation)
+		if !plan.HasChanges() {
+			fmt.Fprintln(w, "no changes")
+		}
+		for _, f := range plan.Files {
+			fmt.Fprintf(w, "  %-6s  %s\n", f.Action, f.Path)
+		}
+		for _, l := range plan.Links {
+			fmt.Fprintf(w, "  %-6s  link %s -> %s (%s)\n", l.Action, l.Path, l.Target, l.Agent)
+		}
+		for _, l := range plan.Lock {
+			detail := ""
+			if l.OldRef != l.NewRef {
+				detail += fmt.Sprintf(", ref %q -> %q", l.OldRef, l.NewRef)
+			}
+			if l.OldCommit != l.NewCommit {
+				detail += fmt.Sprintf(", commit %q -> %q", shortSHA(l.OldCommit), shortSHA(l.NewCommit))
+			}
+			fmt.Fprintf(w, "  %-6s  lock %s (%s)%s\n", l.Action, l.Skill, l.Source, detail)
+		}
+		for _, name := range plan.CustomAgents {
+			fmt.Fprintf(w, "  add     custom agent %s\n", name)
+		}
+		for _, warning := range plan.Warnings {
+			fmt.Fprintf(w, "warning: %s\n", warning)
+		}
+	})
+}
+
+// ---- skills ----
+
+const skillsUsage = `skills <subcommand> [arguments]
+
+Subcommands:
+  list                                     列出已安装的 skills
+  show <name>                              显示 skill 详情
+  install <source@skill[#ref]>...          安装 skills 并链接到 agents；source 为 owner/repo、自定义源、
+                                           git+https:// / git+ssh:// 仓库、file:// 目录或 .zip / .tar.gz 压缩包，
+                                           #ref 固定 git 来源的分支/tag/commit；安全扫描风险达到阈值时拒绝安装，
+                                           --allow-risk 放行；--atomic 任一项失败时回滚全部；--dry-run 只显示变更计划
+                                           （install / update / delete / link 均支持）
+  update <name>... | --outdated [--float]  更新 skills（固定了 ref 的 skill 除非 --float 否则保持固定）
+         [--force | --merge] [--allow-risk] 存在本地修改时覆盖或三方合并，默认拒绝更新
+  rollback <name>...                       恢复为上一次更新前的版本
+  history <name>                           列出 skill 的历史版本
+  diff <name> [--version <id>]             显示更新将带来的变更，或历史版本与当前内容的差异
+  restore <name> <version>                 恢复到指定的历史版本
+  delete <name>... [--atomic]              删除 skills（--atomic 任一项失败时恢复已删除的项）
+  link <name> --agents "A,B"               设置 skill 链接的 agents（空列表表示取消全部链接）
+  verify [name]... [--accept]              校验 skills 内容与 .skills-lock 记录的哈希是否一致
+                                           （--accept 将指定 skills 的当前内容记录为未修改，用于没有记录哈希的旧安装）
+  lint [name]... [--file SKILL.md]         检查 SKILL.md 格式（默认检查全部 skills）
+         [--strict]                        警告也视为失败
+  scan <name | source@skill>...            安全扫描已安装或远程的 skill，输出风险报告
+  hash <dir>                               输出 skill 目录的签名内容（不含 SKILL.sig 的树哈希，末尾不换行）
+  sign <dir> --ssh-key <path>              用 SSH 私钥签名 skill 目录，写入 SKILL.sig`
+
+func runSkills(r *runner, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintf(r.stderr, "Usage: agent-hub %s\n", skillsUsage)
+		if len(args) == 0 {
+			return errUsage
+		}
+		return nil
+	}
+	switch args[0] {
+	case "list", "ls":
+		return runSkillsList(r, args[1:])
+	case "show":
+		return runSkillsShow(r, args[1:])
+	case "install":
+		return runSkillsInstall(r, args[1:])
+	case "update":
+		return runSkillsUpdate(r, args[1:])
+	case "rollback":
+		return runSkillsRollback(r, args[1:])
+	case "history":
+		return runSkillsHistory(r, args[1:])
+	case "diff":
+		return runSkillsDiff(r, args[1:])
+	case "restore":
+		return runSkillsRestore(r, args[1:])
+	case "delete", "rm":
+		return runSkillsDelete(r, args[1:])
+	case "link":
+		return runSkillsLink(r, args[1:])
+	case "verify":
+		return runSkillsVerify(r, args[1:])
+	case "lint":
+		return runSkillsLint(r, args[1:])
+	case "scan":
+		return runSkillsScan(r, args[1:])
+	case "hash":
+		return runSkillsHash(r, args[1:])
+	case "sign":
+		return runSkillsSign(r, args[1:])
+	}
+	fmt.Fprintf(r.stderr, "unknown skills subcommand: %s\n\nUsage: agent-hub %s\n", args[0], skillsUsage)
+	return errUsage
+}
+
+func runSkillsList(r *runner, args []string) error {
+	fs := r.newFlagSet("skills list", "skills list")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	r.start()
+	skills, err := r.skills.GetAllAgentSkills()
+	if err != nil {
+		return err
+	}
+	if skills == nil {
+		skills = []services.Skills{}
+	}
+	return r.print(skills, func(w io.Writer) {
+		tw := newTable(w)
+		fmt.Fprintln(tw, "NAME\tSOURCE\tAGENTS")
+		for _, s := range skills {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Name, s.Source, strings.Join(s.Agents, ", "))
+		}
+		tw.Flush()
+	})
+}
+
+func runSkillsShow(r *runner, args []string) error {
+	fs := r.newFlagSet("skills show", "skills show <name>")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		return usageError(fs, "expected exactly one skill name")
+	}
+	r.start()
+	detail, err := r.skills.GetSkillDetail(names[0])
+	if err != nil {
+		return err
+	}
+	return r.print(detail, func(w io.Writer) {
+		tw := newTable(w)
+		fmt.Fprintf(tw, "Name:\t%s\n", detail.Name)
+		fmt.Fprintf(tw, "Description:\t%s\n", detail.Desc)
+		fmt.Fprintf(tw, "Path:\t%s\n", detail.Path)
+		fmt.Fprintf(tw, "Source:\t%s\n", detail.Source)
+		if detail.Ref != "" {
+			fmt.Fprintf(tw, "Pinned:\t%s\n", detail.Ref)
+		}
+		if detail.CommitSHA != "" {
+			fmt.Fprintf(tw, "Commit:\t%s\n", detail.CommitSHA)
+		}
+		if sig := detail.Signature; sig != nil {
+			status := sig.Status
+			switch {
+			case sig.Signer != "":
+				status += fmt.Sprintf(" (%s, signed by %s)", sig.Format, sig.Signer)
+			case sig.KeyID != "":
+				status += fmt.Sprintf(" (%s, key %s)", sig.Format, sig.KeyID)
+			}
+			if sig.Error != "" {
+				status += ": " + sig.Error
+			}
+			fmt.Fprintf(tw, "Signature:\t%s\n", status)
+		}
+		if m := detail.Manifest; m != nil {
+			if m.Version != "" {
+				fmt.Fprintf(tw, "Version:\t%s\n", m.Version)
+			}
+			if m.License != "" {
+				fmt.Fprintf(tw, "License:\t%s\n", m.License)
+			}
+			if len(m.Tags) > 0 {
+				fmt.Fprintf(tw, "Tags:\t%s\n", strings.Join(m.Tags, ", "))
+			}
+			if len(m.AllowedTools) > 0 {
+				fmt.Fprintf(tw, "Allowed tools:\t%s\n", strings.Join(m.AllowedTools, ", "))
+			}
+			if len(m.Dependencies) > 0 {
+				deps := make([]string, 0, len(m.Dependencies))
+				for _, d := range m.Dependencies {
+					dep := d.Name
+					if d.Version != "" {
+						dep += "@" + d.Version
+					}
+					if d.Optional {
+						dep += " (optional)"
+					}
+					deps = append(deps, dep)
+				}
+				fmt.Fprintf(tw, "Dependencies:\t%s\n", strings.Join(deps, ", "))
+			}
+		}
+		if detail.ManifestError != "" {
+			fmt.Fprintf(tw, "Manifest error:\t%s\n", detail.ManifestError)
+		}
+		fmt.Fprintf(tw, "Agents:\t%s\n", strings.Join(detail.Agents, ", "))
+		fmt.Fprintf(tw, "Installed:\t%s\n", detail.InstalledAt)
+		fmt.Fprintf(tw, "Updated:\t%s\n", detail.UpdatedAt)
+		tw.Flush()
+	})
+}
+
+func runSkillsInstall(r *runner, args []string) error {
+	fs := r.newFlagSet("skills install", "skills install <source@skill[#ref]>... [--agents \"Claude Code,Cursor\"] [--allow-risk] [--atomic] [--concurrency N] [--dry-run]")
+	agentsFlag := fs.String("agents", "", "要链接的 agents，逗号分隔（默认使用设置中的默认 agents）")
+	allowRisk := fs.Bool("allow-risk", false, "安全扫描风险达到阈值时仍然安装")
+	atomic := fs.Bool("atomic", false, "全部成功或全部回滚：任一项失败时撤销已安装的项")
+	concurrency := fs.Int("concurrency", 0, "同时安装的数量（默认 4）")
+	dryRun := fs.Bool("dry-run", false, "只显示将要发生的变更，不安装")
+	fullNames, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(fullNames) == 0 {
+		return usageError(fs, "expected at least one skill (source@skill)")
+	}
+	r.start()
+
+	agents := splitList(*agentsFlag)
+	if len(agents) == 0 {
+		if settings, err := r.skills.GetSettings(); err == nil {
+			agents = settings.DefaultAgents
+		}
+	}
+
+	if *dryRun {
+		opts := services.InstallOptions{AllowRisk: *allowRisk}
+		return r.printPlan(planEach("install", fullNames, func(fullName string) (*services.ChangePlan, error) {
+			return r.skills.PlanInstallRemoteSkill(fullName, agents, opts)
+		}))
+	}
+
+	result, err := r.skills.BatchInstallFromRepoWithOptions(fullNames, agents, services.BatchOptions{
+		Atomic:      *atomic,
+		Concurrency: *concurrency,
+		AllowRisk:   *allowRisk,
+	})
+	if err != nil {
+		return err
+	}
+	return r.printBatchResult("installed", result)
+}
+
+func runSkillsUpdate(r *runner, args []string) error {
+	fs := r.newFlagSet("skills update", "skills update <name>... | --outdated [--float] [--force | --merge] [--allow-risk] [--dry-run]")
+	outdated := fs.Bool("outdated", false, "更新所有检测到有新版本的 skills")
+	float := fs.Bool("float", false, "忽略固定的 ref，更新到默认分支最新版本并取消固定")
+	force := fs.Bool("force", false, "覆盖本地修改（旧内容保存在版本历史中）")
+	merge := fs.Bool("merge", false, "保留本地修改，SKILL.md 与上游三方合并")
+	allowRisk := fs.Bool("allow-risk", false, "新版本的安全扫描风险达到阈值时仍然更新")
+	dryRun := fs.Bool("dry-run", false, "只显示将要发生的变更，不更新")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 && !*outdated {
+		return usageError(fs, "expected skill names or --outdated")
+	}
+	if *force && *merge {
+		return usageError(fs, "--force and --merge are mutually exclusive")
+	}
+	r.start()
+
+	if *outdated {
+		updates, err := r.skills.CheckSkillUpdates()
+		if err != nil {
+			return err
+		}
+		for _, u := range updates {
+			if u.HasUpdate {
+				names = append(names, u.Name)
+			}
+		}
+	}
+
+	opts := services.UpdateOptions{Float: *float, Force: *force, Merge: *merge, AllowRisk: *allowRisk}
+	if *dryRun {
+		return r.printPlan(planEach("update", names, func(name string) (*services.ChangePlan, error) {
+			return r.skills.PlanUpdateSkill(name, opts)
+		}))
+	}
+	results := make([]updateItemResult, 0, len(names))
+	failed := 0
+	for _, name := range names {
+		res := updateItemResult{itemResult: itemResult{Name: name, OK: true}}
+		result, err := r.skills.UpdateSkillWithOptions(name, opts)
+		switch {
+		case err != nil:
+			res.OK = false
+			res.Error = err.Error()
+		case result.Status == services.UpdateStatusConflict:
+			res.OK = false
+			res.Error = "merged with conflicts in " + strings.Join(result.ConflictFiles, ", ")
+		}
+		if !res.OK {
+			failed++
+		}
+		res.Result = result
+		results = append(results, res)
+	}
+	if err := r.print(results, func(w io.Writer) {
+		for _, res := range results {
+			switch {
+			case !res.OK:
+				fmt.Fprintf(w, "failed %s: %s\n", res.Name, res.Error)
+			case res.Result.Status == services.UpdateStatusMerged:
+				fmt.Fprintf(w, "merged %s\n", res.Name)
+			default:
+				fmt.Fprintf(w, "updated %s\n", res.Name)
+			}
+			if res.Result != nil {
+				for _, f := range res.Result.KeptFiles {
+					fmt.Fprintf(w, "  kept local %s\n", f)
+				}
+				for _, f := range res.Result.OverwrittenFiles {
+					fmt.Fprintf(w, "  overwritten %s\n", f)
+				}
+				for _, d := range res.Result.LintDiagnostics {
+					fmt.Fprintf(w, "  lint %s SKILL.md:%d: %s\n", d.Severity, d.Line, d.Message)
+				}
+			}
+		}
+	}); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return reported(fmt.Errorf("%d of %d failed", failed, len(results)))
+	}
+	return nil
+}
+
+// updateItemResult skills update 单项结果，附带合并详情
+type updateItemResult struct {
+	itemResult
+	Result *services.SkillUpdateResult `json:"result,omitempty"`
+}
+
+func runSkillsRollback(r *runner, args []string) error {
+	fs := r.newFlagSet("skills rollback", "skills rollback <name>...")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return usageError(fs, "expected at least one skill name")
+	}
+	r.start()
+
+	results := make([]itemResult, 0, len(names))
+	for _, name := range names {
+		res := itemResult{Name: name, OK: true}
+		if err := r.skills.RollbackSkill(name); err != nil {
+			res.OK = false
+			res.Error = err.Error()
+		}
+		results = append(results, res)
+	}
+	return r.printItemResults("rolled back", results)
+}
+
+func runSkillsHistory(r *runner, args []string) error {
+	fs := r.newFlagSet("skills history", "skills history <name>")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		return usageError(fs, "expected exactly one skill name")
+	}
+	r.start()
+
+	versions, err := r.skills.ListSkillVersions(names[0])
+	if err != nil {
+		return err
+	}
+	return r.print(versions, func(w io.Writer) {
+		tw := newTable(w)
+		fmt.Fprintln(tw, "VERSION\tCREATED\tREASON\tFILES\tCOMMIT")
+		for _, v := range versions {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", v.ID, v.CreatedAt, v.Reason, v.FileCount, shortSHA(v.CommitSHA))
+		}
+		tw.Flush()
+	})
+}
+
+func runSkillsRestore(r *runner, args []string) error {
+	fs := r.newFlagSet("skills restore", "skills restore <name> <version>")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 2 {
+		return usageError(fs, "expected a skill name and a version")
+	}
+	r.start()
+
+	if err := r.skills.RestoreSkillVersion(names[0], names[1]); err != nil {
+		return err
+	}
+	return r.print(itemResult{Name: names[0], OK: true}, func(w io.Writer) {
+		fmt.Fprintf(w, "restored %s to %s\n", names[0], names[1])
+	})
+}
+
+func runSkillsDiff(r *runner, args []string) error {
+	fs := r.newFlagSet("skills diff", "skills diff <name> [--version <id>]")
+	version := fs.String("version", "", "对比指定历史版本与当前内容，默认对比本地与远程")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		return usageError(fs, "expected exactly one skill name")
+	}
+	r.start()
+
+	var diff *services.TreeDiff
+	if *version != "" {
+		if diff, err = r.skills.DiffSkillVersion(names[0], *version); err != nil {
+			return err
+		}
+	} else {
+		skillDiff, err := r.skills.GetSkillDiff(names[0])
+		if err != nil {
+			return err
+		}
+		diff = &services.TreeDiff{
+			Files:      skillDiff.Files,
+			Additions:  skillDiff.Additions,
+			Deletions:  skillDiff.Deletions,
+			HasChanges: skillDiff.HasChanges,
+		}
+	}
+	return r.print(diff, func(w io.Writer) {
+		if !diff.HasChanges {
+			fmt.Fprintln(w, "no changes")
+			return
+		}
+		fmt.Fprint(w, diff.UnifiedDiff())
+	})
+}
+
+// shortSHA 截取 commit SHA 前 7 位用于表格显示
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func runSkillsDelete(r *runner, args []string) error {
+	fs := r.newFlagSet("skills delete", "skills delete <name>... [--atomic] [--dry-run]")
+	atomic := fs.Bool("atomic", false, "全部成功或全部回滚：任一项失败时恢复已删除的项")
+	dryRun := fs.Bool("dry-run", false, "只显示将要删除的文件与软链接，不删除")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return usageError(fs, "expected at least one skill name")
+	}
+	r.start()
+
+	if *dryRun {
+		plan, err := r.skills.PlanBatchDeleteSkills(names)
+		if err != nil {
+			return err
+		}
+		return r.printPlan(plan)
+	}
+
+	result, err := r.skills.BatchDeleteSkillsWithOptions(names, services.BatchOptions{Atomic: *atomic})
+	if err != nil {
+		return err
+	}
+	return r.printBatchResult("deleted", result)
+}
+
+func runSkillsLink(r *runner, args []string) error {
+	fs := r.newFlagSet("skills link", "skills link <name> --agents \"Claude Code,Cursor\" [--dry-run]")
+	agentsFlag := fs.String("agents", "", "链接的 agents，逗号分隔；传空字符串取消全部链接")
+	dryRun := fs.Bool("dry-run", false, "只显示将要增删的软链接，不修改")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		return usageError(fs, "expected exactly one skill name")
+	}
+	agentsSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "agents" {
+			agentsSet = true
+		}
+	})
+	if !agentsSet {
+		return usageError(fs, "--agents is required")
+	}
+	r.start()
+
+	agents := splitList(*agentsFlag)
+	if *dryRun {
+		plan, err := r.skills.PlanBatchUpdateSkillAgentLinks(names, agents)
+		if err != nil {
+			return err
+		}
+		return r.printPlan(plan)
+	}
+	linked, err := r.skills.UpdateSkillAgentLinks(names[0], agents)
+	if err != nil {
+		return err
+	}
+	result := map[string]interface{}{"skill": names[0], "agents": agents, "linked": linked}
+	return r.print(result, func(w io.Writer) {
+		fmt.Fprintf(w, "%s linked to %d agents\n", names[0], linked)
+	})
+}
+
+func runSkillsVerify(r *runner, args []string) error {
+	fs := r.newFlagSet("skills verify", "skills verify [name]... [--accept]")
+	accept := fs.Bool("accept", false, "将指定 skills 的当前内容记录为未修改（写入 .skills-lock 哈希）")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if *accept && len(names) == 0 {
+		return usageError(fs, "--accept requires at least one skill name")
+	}
+	r.start()
+	if *accept {
+		for _, name := range names {
+			if _, err := r.skills.AcceptSkillContent(name); err != nil {
+				return err
+			}
+		}
+	}
+	results, err := r.skills.VerifySkills()
+	if err != nil {
+		return err
+	}
+	if len(names) > 0 {
+		wanted := make(map[string]bool, len(names))
+		for _, n := range names {
+			wanted[n] = true
+		}
+		filtered := results[:0]
+		for _, v := range results {
+			if wanted[v.Name] {
+				filtered = append(filtered, v)
+				delete(wanted, v.Name)
+			}
+		}
+		for n := range wanted {
+			return fmt.Errorf("skill not found in .skills-lock: %s", n)
+		}
+		results = filtered
+	}
+
+	bad := 0
+	for _, v := range results {
+		if v.Status == services.VerifyStatusModified || v.Status == services.VerifyStatusMissing {
+			bad++
+		}
+	}
+	if err := r.print(results, func(w io.Writer) {
+		for _, v := range results {
+			printVerifyResult(w, v)
+		}
+	}); err != nil {
+		return err
+	}
+	if bad > 0 {
+		return reported(fmt.Errorf("%d skill(s) failed verification", bad))
+	}
+	return nil
+}
+
+// skillLintReport 单个 skill 的检查结果，用于 --json 输出
+type skillLintReport struct {
+	Name string `json:"name"`
+	*services.LintResult
+}
+
+func runSkillsLint(r *runner, args []string) error {
+	fs := r.newFlagSet("skills lint", "skills lint [name]... [--file SKILL.md] [--strict]")
+	file := fs.String("file", "", "检查指定的 SKILL.md 文件（不检查名称与目录、相对链接）")
+	strict := fs.Bool("strict", false, "警告也视为失败")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	r.start()
+
+	var reports []skillLintReport
+	if *file != "" {
+		content, err := os.ReadFile(*file)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, skillLintReport{Name: *file, LintResult: r.skills.LintSkillContent(string(content))})
+	} else {
+		if len(names) == 0 {
+			entries, err := os.ReadDir(r.env.SkillsDir)
+			if err != nil {
+				return err
+			}
+			for _, e := range entries {
+				if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+					names = append(names, e.Name())
+				}
+			}
+		}
+		for _, name := range names {
+			result, err := r.skills.LintSkill(name)
+			if err != nil {
+				return err
+			}
+			reports = append(reports, skillLintReport{Name: name, LintResult: result})
+		}
+	}
+
+	failed := 0
+	for _, rep := range reports {
+		if rep.Errors > 0 || (*strict && rep.Warnings > 0) {
+			failed++
+		}
+	}
+	if err := r.print(reports, func(w io.Writer) {
+		for _, rep := range reports {
+			status := "ok"
+			if rep.Errors > 0 || rep.Warnings > 0 {
+				status = fmt.Sprintf("%d error(s), %d warning(s)", rep.Errors, rep.Warnings)
+			}
+			fmt.Fprintf(w, "  %s: %s\n", rep.Name, status)
+			for _, d := range rep.Diagnostics {
+				fmt.Fprintf(w, "    %s:%d:%d %s %s [%s]\n", d.File, d.Line, d.Column, d.Severity, d.Message, d.Rule)
+			}
+		}
+	}); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return reported(fmt.Errorf("%d skill(s) failed lint", failed))
+	}
+	return nil
+}
+
+// ---- health ----
+
+func runSkillsScan(r *runner, args []string) error {
+	fs := r.newFlagSet("skills scan", "skills scan <name | source@skill[#ref]>...")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return usageError(fs, "expected at least one skill")
+	}
+	r.start()
+
+	reports := make([]*services.SkillScanReport, 0, len(names))
+	blocked := 0
+	for _, name := range names {
+		var report *services.SkillScanReport
+		if strings.Contains(name, "@") {
+			report, err = r.skills.ScanRemoteSkill(name)
+		} else {
+			report, err = r.skills.ScanSkill(name)
+		}
+		if err != nil {
+			return err
+		}
+		if report.Blocked {
+			blocked++
+		}
+		reports = append(reports, report)
+	}
+	if err := r.print(reports, func(w io.Writer) {
+		for _, rep := range reports {
+			status := "risk " + rep.Risk
+			if rep.Blocked {
+				status += fmt.Sprintf(" (blocked at threshold %s)", rep.Threshold)
+			}
+			fmt.Fprintf(w, "  %s: %s, %d file(s) scanned\n", rep.Skill, status, rep.FilesScanned)
+			for _, f := range rep.Findings {
+				loc := f.File
+				if f.Line > 0 {
+					loc = fmt.Sprintf("%s:%d", f.File, f.Line)
+				}
+				fmt.Fprintf(w, "    %-8s %s %s [%s]\n", f.Severity, loc, f.Message, f.Rule)
+				if f.Snippet != "" {
+					fmt.Fprintf(w, "             %s\n", f.Snippet)
+				}
+			}
+		}
+	}); err != nil {
+		return err
+	}
+	if blocked > 0 {
+		return reported(fmt.Errorf("%d skill(s) reach the scan threshold", blocked))
+	}
+	return nil
+}
+
+func runSkillsHash(r *runner, args []string) error {
+	fs := r.newFlagSet("skills hash", "skills hash <dir>")
+	dirs, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(dirs) != 1 {
+		return usageError(fs, "expected a skill directory")
+	}
+	r.start()
+	treeHash, err := r.skills.SkillTreeHash(dirs[0])
+	if err != nil {
+		return err
+	}
+	return r.print(map[string]string{"treeHash": treeHash}, func(w io.Writer) {
+		fmt.Fprint(w, treeHash)
+	})
+}
+
+func runSkillsSign(r *runner, args []string) error {
+	fs := r.newFlagSet("skills sign", "skills sign <dir> --ssh-key <path>")
+	keyPath := fs.String("ssh-key", "", "签名使用的 SSH 私钥")
+	dirs, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(dirs) != 1 {
+		return usageError(fs, "expected a skill directory")
+	}
+	if *keyPath == "" {
+		return usageError(fs, "--ssh-key is required")
+	}
+	r.start()
+	sigPath, err := r.skills.SignSkillWithSSHKey(dirs[0], *keyPath)
+	if err != nil {
+		return err
+	}
+	return r.print(map[string]string{"signature": sigPath}, func(w io.Writer) {
+		fmt.Fprintf(w, "signature written to %s\n", sigPath)
+	})
+}
+
+func runHealth(r *runner, args []string) error {
+	fs := r.newFlagSet("health", "health [--repair]")
+	repair := fs.Bool("repair", false, "删除断裂的软链接")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	r.start()
+
+	repaired := 0
+	if *repair {
+		n, err := r.skills.RepairBrokenLinks()
+		if err != nil {
+			return err
+		}
+		repaired = n
+	}
+	result, err := r.skills.HealthCheck()
+	if err != nil {
+		return err
+	}
+
+	output := struct {
+		*services.HealthCheckResult
+		Repaired int `json:"repaired"`
+	}{result, repaired}
+	return r.print(output, func(w io.Writer) {
+		fmt.Fprintf(w, "Links: %d healthy / %d total\n", result.HealthyLinks, result.TotalLinks)
+		if *repair {
+			fmt.Fprintf(w, "Repaired: %d broken links removed\n", repaired)
+		}
+		if len(result.BrokenLinks) > 0 {
+			fmt.Fprintln(w, "\nBroken links:")
+			tw := newTable(w)
+			for _, b := range result.BrokenLinks {
+				fmt.Fprintf(tw, "  %s\t%s\t%s\n", b.AgentName, b.SkillName, b.Error)
+			}
+			tw.Flush()
+		}
+		if len(result.OrphanSkills) > 0 {
+			fmt.Fprintf(w, "\nOrphan skills: %s\n", strings.Join(result.OrphanSkills, ", "))
+		}
+		if len(result.UnknownFiles) > 0 {
+			fmt.Fprintln(w, "\nUnknown files:")
+			for _, f := range result.UnknownFiles {
+				fmt.Fprintf(w, "  %s\n", f.FilePath)
+			}
+		}
+		if len(result.ModifiedSkills) > 0 {
+			fmt.Fprintln(w, "\nModified skills:")
+			for _, v := range result.ModifiedSkills {
+				printVerifyResult(w, v)
+			}
+		}
+	})
+}
+
+// printVerifyResult 输出单个 skill 的校验结果及变动文件
+func printVerifyResult(w io.Writer, v services.SkillVerifyResult) {
+	fmt.Fprintf(w, "  %s: %s\n", v.Name, v.Status)
+	for _, f := range v.ModifiedFiles {
+		fmt.Fprintf(w, "    M %s\n", f)
+	}
+	for _, f := range v.MissingFiles {
+		fmt.Fprintf(w, "    D %s\n", f)
+	}
+	for _, f := range v.ExtraFiles {
+		fmt.Fprintf(w, "    A %s\n", f)
+	}
+}
+
+// ---- config ----
+
+const configUsage = `config <subcommand> [arguments]
+
+Subcommands:
+  export [--output file]   导出配置（默认输出到 stdout）
+  import <file|-> [--dry-run]
+                           导入配置并安装缺失的 skills（--dry-run 只显示变更计划）`
+
+func runConfig(r *runner, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintf(r.stderr, "Usage: agent-hub %s\n", configUsage)
+		if len(args) == 0 {
+			return errUsage
+		}
+		return nil
+	}
+	switch args[0] {
+	case "export":
+		return runConfigExport(r, args[1:])
+	case "import":
+		return runConfigImport(r, args[1:])
+	}
+	fmt.Fprintf(r.stderr, "unknown config subcommand: %s\n\nUsage: agent-hub %s\n", args[0], configUsage)
+	return errUsage
+}
+
+func runConfigExport(r *runner, args []string) error {
+	fs := r.newFlagSet("config export", "config export [--output file]")
+	output := fs.String("output", "", "输出文件路径（默认 stdout）")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	r.start()
+
+	config, err := r.skills.ExportConfig()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %v", err)
+	}
+	if *output == "" {
+		_, err = fmt.Fprintln(r.stdout, string(data))
+		return err
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	return r.print(map[string]string{"output": *output}, func(w io.Writer) {
+		fmt.Fprintf(w, "exported %d skills to %s\n", len(config.Skills), *output)
+	})
+}
+
+func runConfigImport(r *runner, args []string) error {
+	fs := r.newFlagSet("config import", "config import <file|-> [--dry-run]")
+	dryRun := fs.Bool("dry-run", false, "只显示将要安装的 skills 与链接变更，不导入")
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return usageError(fs, "expected a config file path (or - for stdin)")
+	}
+
+	var data []byte
+	if files[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(files[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config: %v", err)
+	}
+
+	r.start()
+	if *dryRun {
+		plan, err := r.skills.PlanImportConfig(string(data))
+		if err != nil {
+			return err
+		}
+		return r.printPlan(plan)
+	}
+	result, err := r.skills.ImportConfig(string(data))
+	if err != nil {
+		return err
+	}
+	if err := r.print(result, func(w io.Writer) {
+		fmt.Fprintf(w, "installed %d, skipped %d, failed %d\n", result.InstalledCount, result.SkippedCount, result.FailedCount)
+		for _, v := range result.PolicyViolations {
+			fmt.Fprintf(w, "  %s\n", v)
+		}
+	}); err != nil {
+		return err
+	}
+	if result.FailedCount > 0 {
+		return reported(fmt.Errorf("%d skills failed to install", result.FailedCount))
+	}
+	return nil
+}
+
+// ---- cache ----
+
+const cacheUsage = `cache <subcommand> [arguments]
+
+Subcommands:
+  list                                     列出仓库克隆缓存
+  prune [--all]                            清理过期或超出大小上限的缓存（--all 清空全部）`
+
+func runCache(r *runner, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintf(r.stderr, "Usage: agent-hub %s\n", cacheUsage)
+		if len(args) == 0 {
+			return errUsage
+		}
+		return nil
+	}
+	switch args[0] {
+	case "list", "ls":
+		return runCacheList(r, args[1:])
+	case "prune":
+		return runCachePrune(r, args[1:])
+	}
+	fmt.Fprintf(r.stderr, "unknown cache subcommand: %s\n\nUsage: agent-hub %s\n", args[0], cacheUsage)
+	return errUsage
+}
+
+func runCacheList(r *runner, args []string) error {
+	fs := r.newFlagSet("cache list", "cache list")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	r.start()
+	info, err := r.skills.GetCloneCacheInfo()
+	if err != nil {
+		return err
+	}
+	return r.print(info, func(w io.Writer) {
+		tw := newTable(w)
+		fmt.Fprintln(tw, "URL\tSIZE\tLAST USED\tLAST FETCHED\tMIRROR")
+		for _, e := range info.Entries {
+			mirror := ""
+			if e.Mirrored {
+				mirror = "yes"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.URL, formatBytes(e.Size),
+				e.LastUsed.Local().Format("2006-01-02 15:04"), e.LastFetched.Local().Format("2006-01-02 15:04"), mirror)
+		}
+		tw.Flush()
+		fmt.Fprintf(w, "%d repositories, %s in %s\n", len(info.Entries), formatBytes(info.TotalBytes), info.Dir)
+	})
+}
+
+func runCachePrune(r *runner, args []string) error {
+	fs := r.newFlagSet("cache prune", "cache prune [--all]")
+	all := fs.Bool("all", false, "清空全部缓存")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	r.start()
+	result, err := r.skills.PruneCloneCache(*all)
+	if err != nil {
+		return err
+	}
+	return r.print(result, func(w io.Writer) {
+		for _, url := range result.Removed {
+			fmt.Fprintf(w, "removed %s\n", url)
+		}
+		fmt.Fprintf(w, "freed %s, %d repositories (%s) remaining\n", formatBytes(result.FreedBytes), result.Remaining, formatBytes(result.RemainingBytes))
+	})
+}
+
+// formatBytes 以 KB / MB / GB 显示字节数
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// ---- mirror ----
+
+const mirrorUsage = `mirror <subcommand> [arguments]
+
+Subcommands:
+  sync [source]...                         同步源到本地镜像（owner/repo、自定义源名称或 git URL，默认为已安装 skills 的来源与全部自定义源）
+  list                                     列出已镜像的源
+  remove <source>                          删除源的本地镜像`
+
+func runMirror(r *runner, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintf(r.stderr, "Usage: agent-hub %s\n", mirrorUsage)
+		if len(args) == 0 {
+			return errUsage
+		}
+		return nil
+	}
+	switch args[0] {
+	case "sync":
+		return runMirrorSync(r, args[1:])
+	case "list", "ls":
+		return runMirrorList(r, args[1:])
+	case "remove", "rm":
+		return runMirrorRemove(r, args[1:])
+	}
+	fmt.Fprintf(r.stderr, "unknown mirror subcommand: %s\n\nUsage: agent-hub %s\n", args[0], mirrorUsage)
+	return errUsage
+}
+
+func runMirrorSync(r *runner, args []string) error {
+	fs := r.newFlagSet("mirror sync", "mirror sync [source]...")
+	sources, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	r.start()
+	results, err := r.skills.MirrorSources(sources)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return r.print(results, func(w io.Writer) { fmt.Fprintln(w, "nothing to mirror") })
+	}
+	items := make([]itemResult, 0, len(results))
+	for _, res := range results {
+		items = append(items, itemResult{Name: res.Source, OK: res.OK, Error: res.Error})
+	}
+	return r.printItemResults("mirrored", items)
+}
+
+func runMirrorList(r *runner, args []string) error {
+	fs := r.newFlagSet("mirror list", "mirror list")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	r.start()
+	mirrors, err := r.skills.GetMirrors()
+	if err != nil {
+		return err
+	}
+	return r.print(mirrors, func(w io.Writer) {
+		tw := newTable(w)
+		fmt.Fprintln(tw, "SOURCE\tSKILLS\tCOMMIT\tSYNCED")
+		for _, m := range mirrors {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", m.Source, len(m.Skills), shortSHA(m.CommitSHA), m.SyncedAt)
+		}
+		tw.Flush()
+	})
+}
+
+func runMirrorRemove(r *runner, args []string) error {
+	fs := r.newFlagSet("mirror remove", "mirror remove <source>")
+	sources, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(sources) != 1 {
+		return usageError(fs, "expected exactly one source")
+	}
+	r.start()
+	if err := r.skills.RemoveMirror(sources[0]); err != nil {
+		return err
+	}
+	return r.print(map[string]string{"removed": sources[0]}, func(w io.Writer) {
+		fmt.Fprintf(w, "removed mirror %s\n", sources[0])
+	})
+}
+
+// ---- credentials ----
+
+const credentialsUsage = `credentials <subcommand> [arguments]
+
+Subcommands:
+  list                                     列出已配置凭据的 git host（不显示 token）
+  set <host> [--token t | --token-env VAR] 设置 host 的 HTTPS token 和 / 或 SSH 私钥，用于私有仓库的克隆、更新与 API 请求
+         [--username u] [--ssh-key path]
+  remove <host>                            删除 host 的凭据`
+
+func runCredentials(r *runner, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintf(r.stderr, "Usage: agent-hub %s\n", credentialsUsage)
+		if len(args) == 0 {
+			return errUsage
+		}
+		return nil
+	}
+	switch args[0] {
+	case "list", "ls":
+		return runCredentialsList(r, args[1:])
+	case "set":
+		return runCredentialsSet(r, args[1:])
+	case "remove", "rm":
+		return runCredentialsRemove(r, args[1:])
+	}
+	fmt.Fprintf(r.stderr, "unknown credentials subcommand: %s\n\nUsage: agent-hub %s\n", args[0], credentialsUsage)
+	return errUsage
+}
+
+func runCredentialsList(r *runner, args []string) error {
+	fs := r.newFlagSet("credentials list", "credentials list")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	r.start()
+	creds, err := r.skills.GetHostCredentials()
+	if err != nil {
+		return err
+	}
+	for i := range creds {
+		if creds[i].Token != "" {
+			creds[i].Token = "***"
+		}
+	}
+	return r.print(creds, func(w io.Writer) {
+		tw := newTable(w)
+		fmt.Fprintln(tw, "HOST\tUSERNAME\tTOKEN\tSSH KEY")
+		for _, c := range creds {
+			token := ""
+			if c.Token != "" {
+				token = "yes"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Host, c.Username, token, c.SSHKeyPath)
+		}
+		tw.Flush()
+	})
+}
+
+func runCredentialsSet(r *runner, args []string) error {
+	fs := r.newFlagSet("credentials set", "credentials set <host> [--token t | --token-env VAR] [--username u] [--ssh-key path]")
+	var cred services.HostCredential
+	tokenEnv := fs.String("token-env", "", "从指定环境变量读取 token（避免 token 出现在命令历史中）")
+	fs.StringVar(&cred.Token, "token", "", "HTTPS access token")
+	fs.StringVar(&cred.Username, "username", "", "HTTPS 用户名（默认 GitHub 为 x-access-token，其余为 oauth2）")
+	fs.StringVar(&cred.SSHKeyPath, "ssh-key", "", "SSH 私钥路径")
+	hosts, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(hosts) != 1 {
+		return usageError(fs, "expected exactly one host")
+	}
+	if *tokenEnv != "" {
+		if cred.Token != "" {
+			return usageError(fs, "--token and --token-env are mutually exclusive")
+		}
+		if cred.Token = os.Getenv(*tokenEnv); cred.Token == "" {
+			return usageError(fs, "environment variable %s is empty", *tokenEnv)
+		}
+	}
+	cred.Host = hosts[0]
+	r.start()
+	if err := r.skills.SetHostCredential(cred); err != nil {
+		return err
+	}
+	return r.print(map[string]string{"host": cred.Host}, func(w io.Writer) {
+		fmt.Fprintf(w, "saved credential for %s\n", cred.Host)
+	})
+}
+
+func runCredentialsRemove(r *runner, args []string) error {
+	fs := r.newFlagSet("credentials remove", "credentials remove <host>")
+	hosts, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(hosts) != 1 {
+		return usageError(fs, "expected exactly one host")
+	}
+	r.start()
+	if err := r.skills.RemoveHostCredential(hosts[0]); err != nil {
+		return err
+	}
+	return r.print(map[string]string{"removed": hosts[0]}, func(w io.Writer) {
+		fmt.Fprintf(w, "removed credential for %s\n", hosts[0])
+	})
+}
+
+// ---- registry ----
+
+const registryUsage = `registry <subcommand> [arguments]
+
+Subcommands:
+  list                      按查询顺序列出 registry
+  add <name> <url>          添加自建的 HTTP/JSON registry（追加到末尾）
+  remove <name>             删除 registry
+  enable <name>             启用 registry
+  disable <name>            停用 registry
+  move <name> <position>    调整 registry 的查询顺序（从 1 开始）
+  search <query>            在所有启用的 registry 中搜索 skills
+  show <source@skill>       查询 skill 的 registry 元数据（也可只给 skill 名称）`
+
+func runRegistry(r *runner, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintf(r.stderr, "Usage: agent-hub %s\n", registryUsage)
+		if len(args) == 0 {
+			return errUsage
+		}
+		return nil
+	}
+	switch args[0] {
+	case "list", "ls":
+		return runRegistryList(r, args[1:])
+	case "add":
+		return runRegistryAdd(r, args[1:])
+	case "remove", "rm":
+		return runRegistryRemove(r, args[1:])
+	case "enable":
+		return runRegistryEnable(r, args[1:], true)
+	case "disable":
+		return runRegistryEnable(r, args[1:], false)
+	case "move":
+		return runRegistryMove(r, args[1:])
+	case "search":
+		return runRegistrySearch(r, args[1:])
+	case "show":
+		return runRegistryShow(r, args[1:])
+	}
+	fmt.Fprintf(r.stderr, "unknown registry subcommand: %s\n\nUsage: agent-hub %s\n", args[0], registryUsage)
+	return errUsage
+}
+
+func runRegistryList(r *runner, args []string) error {
+	fs := r.newFlagSet("registry list", "registry list")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	r.start()
+	regs, err := r.skills.GetRegistries()
+	if err != nil {
+		return err
+	}
+	return r.print(regs, func(w io.Writer) {
+		tw := newTable(w)
+		fmt.Fprintln(tw, "#\tNAME\tTYPE\tURL\tENABLED")
+		for i, reg := range regs {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%v\n", i+1, reg.Name, reg.Type, reg.URL, reg.Enabled)
+		}
+		tw.Flush()
+	})
+}
+
+func runRegistryAdd(r *runner, args []string) error {
+	fs := r.newFlagSet("registry add", "registry add <name> <url>")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 2 {
+		return usageError(fs, "expected a name and a URL")
+	}
+	r.start()
+	if err := r.skills.AddRegistry(rest[0], rest[1]); err != nil {
+		return err
+	}
+	return r.print(map[string]string{"added": rest[0]}, func(w io.Writer) {
+		fmt.Fprintf(w, "added registry %s\n", rest[0])
+	})
+}
+
+func runRegistryRemove(r *runner, args []string) error {
+	fs := r.newFlagSet("registry remove", "registry remove <name>")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		return usageError(fs, "expected exactly one registry name")
+	}
+	r.start()
+	if err := r.skills.RemoveRegistry(names[0]); err != nil {
+		return err
+	}
+	return r.print(map[string]string{"removed": names[0]}, func(w io.Writer) {
+		fmt.Fprintf(w, "removed registry %s\n", names[0])
+	})
+}
+
+func runRegistryEnable(r *runner, args []string, enabled bool) error {
+	action := "enable"
+	if !enabled {
+		action = "disable"
+	}
+	fs := r.newFlagSet("registry "+action, "registry "+action+" <name>")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		return usageError(fs, "expected exactly one registry name")
+	}
+	r.start()
+	if err := r.skills.SetRegistryEnabled(names[0], enabled); err != nil {
+		return err
+	}
+	return r.print(map[string]any{"name": names[0], "enabled": enabled}, func(w io.Writer) {
+		fmt.Fprintf(w, "%sd registry %s\n", action, names[0])
+	})
+}
+
+func runRegistryMove(r *runner, args []string) error {
+	fs := r.newFlagSet("registry move", "registry move <name> <position>")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 2 {
+		return usageError(fs, "expected a registry name and a position")
+	}
+	position, err := strconv.Atoi(rest[1])
+	if err != nil || position < 1 {
+		return usageError(fs, "invalid position: %s", rest[1])
+	}
+	r.start()
+	if err := r.skills.MoveRegistry(rest[0], position-1); err != nil {
+		return err
+	}
+	return runRegistryList(r, nil)
+}
+
+func runRegistrySearch(r *runner, args []string) error {
+	fs := r.newFlagSet("registry search", "registry search <query>")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return usageError(fs, "expected a search query")
+	}
+	r.start()
+	skills, err := r.skills.FindRemoteSkills(strings.Join(rest, " "))
+	if err != nil {
+		return err
+	}
+	return r.print(skills, func(w io.Writer) {
+		tw := newTable(w)
+		fmt.Fprintln(tw, "NAME\tREGISTRY\tINSTALLS\tINSTALLED\tDESCRIPTION")
+		for _, s := range skills {
+			installed := ""
+			if s.Installed {
+				installed = "yes"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", s.FullName, s.Registry, s.Installs, installed, s.Description)
+		}
+		tw.Flush()
+	})
+}
+
+func runRegistryShow(r *runner, args []string) error {
+	fs := r.newFlagSet("registry show", "registry show <source@skill>")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usageError(fs, "expected exactly one skill")
+	}
+	r.start()
+	skill, err := r.skills.GetRegistrySkill(rest[0])
+	if err != nil {
+		return err
+	}
+	return r.print(skill, func(w io.Writer) {
+		tw := newTable(w)
+		fmt.Fprintf(tw, "Name:\t%s\n", skill.Name)
+		fmt.Fprintf(tw, "Install:\t%s\n", skill.FullName)
+		fmt.Fprintf(tw, "Source:\t%s\n", skill.Source)
+		fmt.Fprintf(tw, "Registry:\t%s\n", skill.Registry)
+		if skill.Description != "" {
+			fmt.Fprintf(tw, "Description:\t%s\n", skill.Description)
+		}
+		if skill.URL != "" {
+			fmt.Fprintf(tw, "URL:\t%s\n", skill.URL)
+		}
+		if len(skill.SupportedAgents) > 0 {
+			fmt.Fprintf(tw, "Agents:\t%s\n", strings.Join(skill.SupportedAgents, ", "))
+		}
+		fmt.Fprintf(tw, "Installed:\t%v\n", skill.Installed)
+		tw.Flush()
+	})
+}
+
+// ---- serve-registry ----
+
+func runServeRegistry(r *runner, args []string) error {
+	fs := r.newFlagSet("serve-registry", "serve-registry --dir <dir> [--dir <dir>...] [--addr host:port] [--base-url url] [--token-env VAR] [--refresh 1m]")
+	var opts services.RegistryServerOptions
+	fs.Func("dir", "扫描的目录（可重复）：包含 skills 的仓库，或由多个仓库组成的目录", func(v string) error {
+		opts.Dirs = append(opts.Dirs, v)
+		return nil
+	})
+	addr := fs.String("addr", "127.0.0.1:8780", "监听地址")
+	fs.StringVar(&opts.BaseURL, "base-url", "", "客户端访问本服务的地址，用于生成下载链接（默认取请求的 Host）")
+	tokenEnv := fs.String("token-env", "", "从指定环境变量读取访问 token，请求需携带 Authorization: Bearer <token>")
+	fs.DurationVar(&opts.Refresh, "refresh", time.Minute, "索引刷新间隔")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	opts.Dirs = append(opts.Dirs, rest...)
+	if len(opts.Dirs) == 0 {
+		return usageError(fs, "at least one --dir is required")
+	}
+	if *tokenEnv != "" {
+		if opts.Token = os.Getenv(*tokenEnv); opts.Token == "" {
+			return usageError(fs, "environment variable %s is empty", *tokenEnv)
+		}
+	}
+
+	server, err := services.NewRegistryServer(opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(r.stderr, "serving %d skills from %s on http://%s\n", server.SkillCount(), strings.Join(opts.Dirs, ", "), *addr)
+	fmt.Fprintf(r.stderr, "add it with: agent-hub registry add <name> http://%s\n", *addr)
+	return http.ListenAndServe(*addr, server)
+}
+
+// ---- secrets ----
+
+const secretsUsage = `secrets <subcommand> [arguments]
+
+Subcommands:
+  info                                     显示 API Key 与 token 的存储后端和 vault 状态
+  passphrase [--current-env VAR]           设置、修改或移除（不带 --new-env）vault 口令并重新加密全部条目
+         [--new-env VAR]                   口令从指定环境变量读取`
+
+func runSecrets(r *runner, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintf(r.stderr, "Usage: agent-hub %s\n", secretsUsage)
+		if len(args) == 0 {
+			return errUsage
+		}
+		return nil
+	}
+	switch args[0] {
+	case "info":
+		return runSecretsInfo(r, args[1:])
+	case "passphrase":
+		return runSecretsPassphrase(r, args[1:])
+	}
+	fmt.Fprintf(r.stderr, "unknown secrets subcommand: %s\n\nUsage: agent-hub %s\n", args[0], secretsUsage)
+	return errUsage
+}
+
+func runSecretsInfo(r *runner, args []string) error {
+	fs := r.newFlagSet("secrets info", "secrets info")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	r.start()
+	info, err := r.skills.GetSecretStoreInfo()
+	if err != nil {
+		return err
+	}
+	return r.print(info, func(w io.Writer) {
+		tw := newTable(w)
+		fmt.Fprintf(tw, "backend:\t%s (configured: %s)\n", info.Backend, info.Configured)
+		fmt.Fprintf(tw, "available:\t%s\n", strings.Join(info.AvailableBackends, ", "))
+		fmt.Fprintf(tw, "vault:\t%s\n", info.VaultPath)
+		state := "key file"
+		if info.PassphraseProtected {
+			state = "passphrase, unlocked"
+			if info.Locked {
+				state = fmt.Sprintf("passphrase, locked (set %s)", services.EnvSecretPassphrase)
+			}
+		}
+		fmt.Fprintf(tw, "vault key:\t%s\n", state)
+		tw.Flush()
+	})
+}
+
+func runSecretsPassphrase(r *runner, args []string) error {
+	fs := r.newFlagSet("secrets passphrase", "secrets passphrase [--current-env VAR] [--new-env VAR]")
+	currentEnv := fs.String("current-env", "", "读取当前口令的环境变量（vault 已设置口令时必填）")
+	newEnv := fs.String("new-env", "", "读取新口令的环境变量，省略时移除口令并改用本机密钥文件")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	current, next := "", ""
+	if *currentEnv != "" {
+		if current = os.Getenv(*currentEnv); current == "" {
+			return usageError(fs, "environment variable %s is empty", *currentEnv)
+		}
+	}
+	if *newEnv != "" {
+		if next = os.Getenv(*newEnv); next == "" {
+			return usageError(fs, "environment variable %s is empty", *newEnv)
+		}
+	}
+	r.start()
+	if err := r.skills.SetSecretVaultPassphrase(current, next); err != nil {
+		return err
+	}
+	return r.print(map[string]bool{"passphraseProtected": next != ""}, func(w io.Writer) {
+		if next != "" {
+			fmt.Fprintln(w, "vault is now protected by a passphrase")
+		} else {
+			fmt.Fprintln(w, "vault now uses the local key file")
+		}
+	})
+}
+
+// ---- store ----
+
+const storeUsage = `store <subcommand> [arguments]
+
+Subcommands:
+  info                                     显示当前元数据存储（标签、评分、活动日志、性能指标）的后端与记录数
+  import                                   将 JSON 文件导入内嵌数据库 metadata.db（可重复执行，设置 storageBackend 为 bolt 后生效）`
+
+func runStore(r *runner, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintf(r.stderr, "Usage: agent-hub %s\n", storeUsage)
+		if len(args) == 0 {
+			return errUsage
+		}
+		return nil
+	}
+	switch args[0] {
+	case "info":
+		return runStoreInfo(r, args[1:])
+	case "import":
+		return runStoreImport(r, args[1:])
+	}
+	fmt.Fprintf(r.stderr, "unknown store subcommand: %s\n\nUsage: agent-hub %s\n", args[0], storeUsage)
+	return errUsage
+}
+
+func runStoreInfo(r *runner, args []string) error {
+	fs := r.newFlagSet("store info", "store info")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	r.start()
+	info, err := r.skills.GetMetadataStoreInfo()
+	if err != nil {
+		return err
+	}
+	return r.print(info, func(w io.Writer) {
+		tw := newTable(w)
+		fmt.Fprintf(tw, "backend:\t%s\n", info.Backend)
+		fmt.Fprintf(tw, "path:\t%s\n", info.Path)
+		if info.ImportedAt != "" {
+			fmt.Fprintf(tw, "imported:\t%s\n", info.ImportedAt)
+		}
+		fmt.Fprintf(tw, "tags:\t%d\n", info.Tags)
+		fmt.Fprintf(tw, "ratings:\t%d\n", info.Ratings)
+		fmt.Fprintf(tw, "activity logs:\t%d\n", info.ActivityLogs)
+		fmt.Fprintf(tw, "metrics:\t%d\n", info.Metrics)
+		tw.Flush()
+	})
+}
+
+func runStoreImport(r *runner, args []string) error {
+	fs := r.newFlagSet("store import", "store import")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	r.start()
+	result, err := r.skills.ImportMetadataFromJSON()
+	if err != nil {
+		return err
+	}
+	return r.print(result, func(w io.Writer) {
+		fmt.Fprintf(w, "imported %d tagged skills, %d ratings, %d activity logs, %d metrics\n",
+			result.Tags, result.Ratings, result.ActivityLogs, result.Metrics)
+	})
+}
+
+// ---- policy ----
+
+const policyUsage = `policy <subcommand> [arguments]
+
+Subcommands:
+  show                                     显示当前信任策略与文件位置
+  check <source@skill[#ref]>...            检查 skill 来源是否被信任策略允许（签名要求在安装时校验）
+  set <file | ->                           校验并替换信任策略（团队分发的 trust-policy.json）`
+
+func runPolicy(r *runner, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintf(r.stderr, "Usage: agent-hub %s\n", policyUsage)
+		if len(args) == 0 {
+			return errUsage
+		}
+		return nil
+	}
+	switch args[0] {
+	case "show":
+		return runPolicyShow(r, args[1:])
+	case "check":
+		return runPolicyCheck(r, args[1:])
+	case "set":
+		return runPolicySet(r, args[1:])
+	}
+	fmt.Fprintf(r.stderr, "unknown policy subcommand: %s\n\nUsage: agent-hub %s\n", args[0], policyUsage)
+	return errUsage
+}
+
+func runPolicyShow(r *runner, args []string) error {
+	fs := r.newFlagSet("policy show", "policy show")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	r.start()
+	info, err := r.skills.GetTrustPolicy()
+	if err != nil {
+		return err
+	}
+	return r.print(info, func(w io.Writer) {
+		p := info.Policy
+		tw := newTable(w)
+		fmt.Fprintf(tw, "path:\t%s\n", info.Path)
+		if !info.Exists {
+			fmt.Fprintf(tw, "policy:\tnone (all sources allowed)\n")
+			tw.Flush()
+			return
+		}
+		list := func(items []string) string {
+			if len(items) == 0 {
+				return "-"
+			}
+			return strings.Join(items, ", ")
+		}
+		fmt.Fprintf(tw, "allowed owners:\t%s\n", list(p.AllowedOwners))
+		fmt.Fprintf(tw, "allowed hosts:\t%s\n", list(p.AllowedHosts))
+		fmt.Fprintf(tw, "allow local:\t%v\n", p.AllowLocal)
+		fmt.Fprintf(tw, "blocked skills:\t%s\n", list(p.BlockedSkills))
+		fmt.Fprintf(tw, "require pin:\t%v\n", p.RequirePin)
+		fmt.Fprintf(tw, "require signature:\t%v\n", p.RequireSignature)
+		tw.Flush()
+	})
+}
+
+func runPolicyCheck(r *runner, args []string) error {
+	fs := r.newFlagSet("policy check", "policy check <source@skill[#ref]>...")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return usageError(fs, "expected at least one skill (source@skill)")
+	}
+	r.start()
+	results := make([]itemResult, 0, len(names))
+	for _, name := range names {
+		res := itemResult{Name: name, OK: true}
+		if err := r.skills.CheckTrustPolicy(name); err != nil {
+			res.OK, res.Error = false, err.Error()
+		}
+		results = append(results, res)
+	}
+	return r.printItemResults("allowed", results)
+}
+
+func runPolicySet(r *runner, args []string) error {
+	fs := r.newFlagSet("policy set", "policy set <file | ->")
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return usageError(fs, "expected a policy file path (or - for stdin)")
+	}
+
+	var data []byte
+	if files[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(files[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read policy: %v", err)
+	}
+	// 拒绝未知字段，避免拼错的规则被静默忽略
+	var policy services.TrustPolicy
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&policy); err != nil {
+		return fmt.Errorf("invalid policy: %v", err)
+	}
+
+	r.start()
+	if err := r.skills.SaveTrustPolicy(policy); err != nil {
+		return err
+	}
+	info, err := r.skills.GetTrustPolicy()
+	if err != nil {
+		return err
+	}
+	return r.print(info, func(w io.Writer) {
+		fmt.Fprintf(w, "trust policy saved to %s\n", info.Path)
+	})
+}
+
+// ---- keys ----
+
+const keysUsage = `keys <subcommand> [arguments]
+
+Subcommands:
+  list                                     列出校验 skill 签名的受信任公钥
+  add <name> <public-key | @file>          添加 minisign 公钥（RW...）或 SSH 公钥（ssh-ed25519 AAAA...）
+  remove <name>                            删除受信任公钥`
+
+func runKeys(r *runner, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintf(r.stderr, "Usage: agent-hub %s\n", keysUsage)
+		if len(args) == 0 {
+			return errUsage
+		}
+		return nil
+	}
+	switch args[0] {
+	case "list", "ls":
+		return runKeysList(r, args[1:])
+	case "add":
+		return runKeysAdd(r, args[1:])
+	case "remove", "rm":
+		return runKeysRemove(r, args[1:])
+	}
+	fmt.Fprintf(r.stderr, "unknown keys subcommand: %s\n\nUsage: agent-hub %s\n", args[0], keysUsage)
+	return errUsage
+}
+
+func runKeysList(r *runner, args []string) error {
+	fs := r.newFlagSet("keys list", "keys list")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	r.start()
+	keys, err := r.skills.GetTrustedKeys()
+	if err != nil {
+		return err
+	}
+	return r.print(keys, func(w io.Writer) {
+		if len(keys) == 0 {
+			fmt.Fprintln(w, "no trusted keys")
+			return
+		}
+		tw := newTable(w)
+		fmt.Fprintln(tw, "NAME\tFORMAT\tKEY ID\tADDED")
+		for _, k := range keys {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", k.Name, k.Format, k.KeyID, k.AddedAt)
+		}
+		tw.Flush()
+	})
+}
+
+func runKeysAdd(r *runner, args []string) error {
+	fs := r.newFlagSet("keys add", "keys add <name> <public-key | @file>")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 2 {
+		return usageError(fs, "expected a name and a public key")
+	}
+	publicKey := rest[1]
+	if file, ok := strings.CutPrefix(publicKey, "@"); ok {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read public key: %v", err)
+		}
+		publicKey = string(data)
+	}
+	r.start()
+	if err := r.skills.AddTrustedKey(rest[0], publicKey); err != nil {
+		return err
+	}
+	return r.print(map[string]string{"added": rest[0]}, func(w io.Writer) {
+		fmt.Fprintf(w, "trusted key %s added\n", rest[0])
+	})
+}
+
+func runKeysRemove(r *runner, args []string) error {
+	fs := r.newFlagSet("keys remove", "keys remove <name>")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		return usageError(fs, "expected exactly one key name")
+	}
+	r.start()
+	if err := r.skills.RemoveTrustedKey(names[0]); err != nil {
+		return err
+	}
+	return r.print(map[string]string{"removed": names[0]}, func(w io.Writer) {
+		fmt.Fprintf(w, "trusted key %s removed\n", names[0])
+	})
+}
+
+// ---- activity ----
+
+func runActivity(r *runner, args []string) error {
+	fs := r.newFlagSet("activity", "activity [--skill name] [--action action] [--since time] [--until time] [--offset n] [--limit n]")
+	var query services.ActivityLogQuery
+	fs.StringVar(&query.SkillName, "skill", "", "只显示指定 skill 的日志")
+	fs.StringVar(&query.Action, "action", "", "只显示指定操作（install / delete / update ...）的日志")
+	fs.StringVar(&query.Since, "since", "", "起始时间（RFC3339，包含）")
+	fs.StringVar(&query.Until, "until", "", "截止时间（RFC3339，不包含）")
+	fs.IntVar(&query.Offset, "offset", 0, "跳过的条数")
+	fs.IntVar(&query.Limit, "limit", 20, "返回的条数，0 表示全部")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if query.Offset < 0 || query.Limit < 0 {
+		return usageError(fs, "--offset and --limit must not be negative")
+	}
+	r.start()
+	page, err := r.skills.QueryActivityLogs(query)
+	if err != nil {
+		return err
+	}
+	return r.print(page, func(w io.Writer) {
+		tw := newTable(w)
+		fmt.Fprintln(tw, "TIME\tACTION\tSKILL\tDETAIL")
+		for _, log := range page.Logs {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", log.Timestamp, log.Action, log.SkillName, log.Detail)
+		}
+		tw.Flush()
+		fmt.Fprintf(w, "\n%d-%d of %d\n", min(query.Offset+1, page.Total), query.Offset+len(page.Logs), page.Total)
+	})
+}
@@ -9,7 +9,6 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"sync"
 	"time"
 )
 
@@ -18,35 +17,14 @@ import (
 // sharedHTTPClient 复用连接池，避免每次请求创建新 Client
 var sharedHTTPClient = &http.Client{Timeout: 15 * time.Second}
 
-// homeDir 缓存，避免重复系统调用
-var (
-	cachedHomeDir string
-	homeDirOnce   sync.Once
-	homeDirErr    error
-)
-
-// getCachedHomeDir 返回缓存的 home 目录路径
-func getCachedHomeDir() (string, error) {
-	homeDirOnce.Do(func() {
-		cachedHomeDir, homeDirErr = os.UserHomeDir()
-	})
-	return cachedHomeDir, homeDirErr
-}
-
-// customAgents 缓存，避免每次调用都读磁盘 + JSON 解析
-var (
-	customAgentsMu      sync.RWMutex
-	cachedCustomAgents  []CustomAgentConfig
-	customAgentsCached  bool
-)
-
 // AgentService 负责 Agent 相关的所有操作
 type AgentService struct {
 	ctx context.Context
+	env *Environment
 }
 
-func NewAgentService() *AgentService {
-	return &AgentService{}
+func NewAgentService(env *Environment) *AgentService {
+	return &AgentService{env: env}
 }
 
 func (as *AgentService) Startup(ctx context.Context) {
@@ -119,57 +97,36 @@ var defaultAgents = []AgentConfig{
 	{"Cortex Code", []string{".cortex/skills"}, ".snowflake/cortex/skills"},
 }
 
-// supportedAgents 运行时的 agents 列表，从 agents.json 加载
-var supportedAgents []AgentConfig
-
-// init 在启动时从 agents.json 加载 agent 列表，若文件不存在则用默认列表初始化
-func init() {
-	agents, err := loadAgentsFromFile()
-	if err != nil || len(agents) == 0 {
-		// 文件不存在或为空，用默认列表初始化并写入文件
-		supportedAgents = make([]AgentConfig, len(defaultAgents))
-		copy(supportedAgents, defaultAgents)
-		_ = saveAgentsToFile(supportedAgents)
-	} else {
-		supportedAgents = agents
-	}
+// supportedAgents 返回运行时的内置 agents 列表，首次调用时从 agents.json 加载，
+// 若文件不存在则用默认列表初始化并写入文件
+func supportedAgents(env *Environment) []AgentConfig {
+	env.agentsOnce.Do(func() {
+		agents, err := loadAgentsFromFile(env)
+		if err != nil || len(agents) == 0 {
+			env.agents = make([]AgentConfig, len(defaultAgents))
+			copy(env.agents, defaultAgents)
+			_ = saveAgentsToFile(env, env.agents)
+		} else {
+			env.agents = agents
+		}
+	})
+	return env.agents
 }
 
 // ---- 配置文件路径 ----
 
-// getConfigDir 获取 skills-manager 配置目录
-func getConfigDir() (string, error) {
-	homeDir, err := getCachedHomeDir()
-	if err != nil {
-		return "", err
-	}
-	configDir := filepath.Join(homeDir, ".skills-manager")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return "", err
-	}
-	return configDir, nil
-}
-
-func getCustomAgentsFilePath() (string, error) {
-	configDir, err := getConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "custom-agents.json"), nil
+func getCustomAgentsFilePath(env *Environment) (string, error) {
+	return env.configFilePath("custom-agents.json")
 }
 
-func getAgentsFilePath() (string, error) {
-	configDir, err := getConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "agents.json"), nil
+func getAgentsFilePath(env *Environment) (string, error) {
+	return env.configFilePath("agents.json")
 }
 
 // ---- agents.json 持久化 ----
 
-func loadAgentsFromFile() ([]AgentConfig, error) {
-	filePath, err := getAgentsFilePath()
+func loadAgentsFromFile(env *Environment) ([]AgentConfig, error) {
+	filePath, err := getAgentsFilePath(env)
 	if err != nil {
 		return nil, err
 	}
@@ -187,33 +144,29 @@ func loadAgentsFromFile() ([]AgentConfig, error) {
 	return agents, nil
 }
 
-func saveAgentsToFile(agents []AgentConfig) error {
-	filePath, err := getAgentsFilePath()
+func saveAgentsToFile(env *Environment, agents []AgentConfig) error {
+	filePath, err := getAgentsFilePath(env)
 	if err != nil {
 		return err
 	}
-	data, err := json.MarshalIndent(agents, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(filePath, data, 0644)
+	return saveJSONConfig(filePath, agents)
 }
 
 // ---- 自定义 Agent 持久化 ----
 
-func loadCustomAgents() ([]CustomAgentConfig, error) {
+func loadCustomAgents(env *Environment) ([]CustomAgentConfig, error) {
 	// 优先返回缓存
-	customAgentsMu.RLock()
-	if customAgentsCached {
-		result := make([]CustomAgentConfig, len(cachedCustomAgents))
-		copy(result, cachedCustomAgents)
-		customAgentsMu.RUnlock()
+	env.customAgentsMu.RLock()
+	if env.customAgentsCached {
+		result := make([]CustomAgentConfig, len(env.cachedCustomAgents))
+		copy(result, env.cachedCustomAgents)
+		env.customAgentsMu.RUnlock()
 		return result, nil
 	}
-	customAgentsMu.RUnlock()
+	env.customAgentsMu.RUnlock()
 
 	// 缓存未命中，读磁盘
-	filePath, err := getCustomAgentsFilePath()
+	filePath, err := getCustomAgentsFilePath(env)
 	if err != nil {
 		return nil, err
 	}
@@ -221,10 +174,10 @@ func loadCustomAgents() ([]CustomAgentConfig, error) {
 	if err != nil {
 		if os.IsNotExist(err) {
 			// 缓存空结果
-			customAgentsMu.Lock()
-			cachedCustomAgents = []CustomAgentConfig{}
-			customAgentsCached = true
-			customAgentsMu.Unlock()
+			env.customAgentsMu.Lock()
+			env.cachedCustomAgents = []CustomAgentConfig{}
+			env.customAgentsCached = true
+			env.customAgentsMu.Unlock()
 			return []CustomAgentConfig{}, nil
 		}
 		return nil, err
@@ -235,48 +188,58 @@ func loadCustomAgents() ([]CustomAgentConfig, error) {
 	}
 
 	// 写入缓存
-	customAgentsMu.Lock()
-	cachedCustomAgents = agents
-	customAgentsCached = true
-	customAgentsMu.Unlock()
+	env.customAgentsMu.Lock()
+	env.cachedCustomAgents = agents
+	env.customAgentsCached = true
+	env.customAgentsMu.Unlock()
 
 	result := make([]CustomAgentConfig, len(agents))
 	copy(result, agents)
 	return result, nil
 }
 
-func saveCustomAgents(agents []CustomAgentConfig) error {
-	filePath, err := getCustomAgentsFilePath()
+// updateCustomAgents 在文件锁保护下读取、修改并写回自定义 agent 列表
+// 读取前先使缓存失效，确保基于磁盘上的最新内容修改（其他进程可能已写入）
+func updateCustomAgents(env *Environment, fn func(agents []CustomAgentConfig) ([]CustomAgentConfig, error)) error {
+	filePath, err := getCustomAgentsFilePath(env)
 	if err != nil {
 		return err
 	}
-	data, err := json.MarshalIndent(agents, "", "  ")
-	if err != nil {
-		return err
-	}
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return err
-	}
-	// invalidate 缓存
-	invalidateCustomAgentsCache()
-	return nil
+	return withFileLock(filePath, func() error {
+		invalidateCustomAgentsCache(env)
+		agents, err := loadCustomAgents(env)
+		if err != nil {
+			return fmt.Errorf("读取配置失败: %v", err)
+		}
+		agents, err = fn(agents)
+		if err != nil {
+			return err
+		}
+		if err := writeJSONFile(filePath, agents); err != nil {
+			return err
+		}
+		// invalidate 缓存
+		invalidateCustomAgentsCache(env)
+		return nil
+	})
 }
 
 // invalidateCustomAgentsCache 使自定义 agent 缓存失效
-func invalidateCustomAgentsCache() {
-	customAgentsMu.Lock()
-	cachedCustomAgents = nil
-	customAgentsCached = false
-	customAgentsMu.Unlock()
+func invalidateCustomAgentsCache(env *Environment) {
+	env.customAgentsMu.Lock()
+	env.cachedCustomAgents = nil
+	env.customAgentsCached = false
+	env.customAgentsMu.Unlock()
 }
 
 // ---- 包级辅助函数 ----
 
 // getAllAgentConfigs 获取所有 agent 配置（内置 + 自定义）
-func getAllAgentConfigs() []AgentConfig {
-	all := make([]AgentConfig, len(supportedAgents))
-	copy(all, supportedAgents)
-	customs, err := loadCustomAgents()
+func getAllAgentConfigs(env *Environment) []AgentConfig {
+	builtin := supportedAgents(env)
+	all := make([]AgentConfig, len(builtin))
+	copy(all, builtin)
+	customs, err := loadCustomAgents(env)
 	if err == nil {
 		for _, c := range customs {
 			all = append(all, AgentConfig{Name: c.Name, GlobalPaths: c.GlobalPaths, LocalPath: c.LocalPath})
@@ -302,13 +265,13 @@ func httpGet(url string) ([]byte, error) {
 
 // GetSupportedAgents 返回所有支持的 agent 列表（内置 + 自定义）
 func (as *AgentService) GetSupportedAgents() []AgentInfo {
-	customs, _ := loadCustomAgents()
+	customs, _ := loadCustomAgents(as.env)
 	customNames := make(map[string]bool)
 	for _, c := range customs {
 		customNames[c.Name] = true
 	}
 
-	allConfigs := getAllAgentConfigs()
+	allConfigs := getAllAgentConfigs(as.env)
 	agents := make([]AgentInfo, len(allConfigs))
 	for i, a := range allConfigs {
 		agents[i] = AgentInfo{Name: a.Name, GlobalPaths: a.GlobalPaths, LocalPath: a.LocalPath, IsCustom: customNames[a.Name]}
@@ -321,7 +284,7 @@ func (as *AgentService) EnableProjectAgent(projectPath string, agentName string)
 	if projectPath == "" || agentName == "" {
 		return fmt.Errorf("project path and agent name are required")
 	}
-	allConfigs := getAllAgentConfigs()
+	allConfigs := getAllAgentConfigs(as.env)
 	var targetAgent *AgentConfig
 	for _, a := range allConfigs {
 		if a.Name == agentName {
@@ -345,8 +308,7 @@ func (as *AgentService) EnableProjectAgent(projectPath string, agentName string)
 
 // syncSkillsToAgent 将项目中其他 agent 已有的 skills 同步到目标 agent
 func (as *AgentService) syncSkillsToAgent(projectPath string, targetAgent *AgentConfig, allConfigs []AgentConfig) {
-	homeDir, _ := getCachedHomeDir()
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := as.env.SkillsDir
 	targetSkillsDir := filepath.Join(projectPath, targetAgent.LocalPath)
 
 	// 收集项目中其他 agent 已有的 skills（去重）
@@ -436,7 +398,7 @@ func (as *AgentService) DisableProjectAgent(projectPath string, agentName string
 	if projectPath == "" || agentName == "" {
 		return fmt.Errorf("project path and agent name are required")
 	}
-	allConfigs := getAllAgentConfigs()
+	allConfigs := getAllAgentConfigs(as.env)
 	var targetAgent *AgentConfig
 	for _, a := range allConfigs {
 		if a.Name == agentName {
@@ -497,7 +459,7 @@ func (as *AgentService) GetProjectAgentSkillCount(projectPath string, agentName
 	if projectPath == "" || agentName == "" {
 		return 0
 	}
-	allConfigs := getAllAgentConfigs()
+	allConfigs := getAllAgentConfigs(as.env)
 	for _, a := range allConfigs {
 		if a.Name == agentName {
 			agentDir := filepath.Join(projectPath, a.LocalPath)
@@ -522,8 +484,8 @@ func (as *AgentService) GetProjectAgents(projectPath string) []AgentInfo {
 	if projectPath == "" {
 		return []AgentInfo{}
 	}
-	allConfigs := getAllAgentConfigs()
-	customs, _ := loadCustomAgents()
+	allConfigs := getAllAgentConfigs(as.env)
+	customs, _ := loadCustomAgents(as.env)
 	customNames := make(map[string]bool)
 	for _, c := range customs {
 		customNames[c.Name] = true
@@ -547,41 +509,36 @@ func (as *AgentService) AddCustomAgent(name string) error {
 	globalPath := "." + pathSegment + "/skills"
 	localPath := "." + pathSegment + "/skills"
 
-	for _, a := range supportedAgents {
+	for _, a := range supportedAgents(as.env) {
 		if strings.EqualFold(a.Name, name) {
 			return fmt.Errorf("与内置 Agent \"%s\" 名称冲突", a.Name)
 		}
 	}
-	customs, err := loadCustomAgents()
-	if err != nil {
-		return fmt.Errorf("读取配置失败: %v", err)
-	}
-	for _, c := range customs {
-		if strings.EqualFold(c.Name, name) {
-			return fmt.Errorf("自定义 Agent \"%s\" 已存在", name)
+	return updateCustomAgents(as.env, func(customs []CustomAgentConfig) ([]CustomAgentConfig, error) {
+		for _, c := range customs {
+			if strings.EqualFold(c.Name, name) {
+				return nil, fmt.Errorf("自定义 Agent \"%s\" 已存在", name)
+			}
 		}
-	}
-	customs = append(customs, CustomAgentConfig{Name: name, GlobalPaths: []string{globalPath}, LocalPath: localPath})
-	return saveCustomAgents(customs)
+		return append(customs, CustomAgentConfig{Name: name, GlobalPaths: []string{globalPath}, LocalPath: localPath}), nil
+	})
 }
 
 // RemoveCustomAgent 删除自定义 agent
 func (as *AgentService) RemoveCustomAgent(name string) error {
-	customs, err := loadCustomAgents()
-	if err != nil {
-		return fmt.Errorf("读取配置失败: %v", err)
-	}
-	found := false
-	result := make([]CustomAgentConfig, 0, len(customs))
-	for _, c := range customs {
-		if c.Name == name {
-			found = true
-			continue
+	return updateCustomAgents(as.env, func(customs []CustomAgentConfig) ([]CustomAgentConfig, error) {
+		found := false
+		result := make([]CustomAgentConfig, 0, len(customs))
+		for _, c := range customs {
+			if c.Name == name {
+				found = true
+				continue
+			}
+			result = append(result, c)
 		}
-		result = append(result, c)
-	}
-	if !found {
-		return fmt.Errorf("未找到自定义 Agent \"%s\"", name)
-	}
-	return saveCustomAgents(result)
+		if !found {
+			return nil, fmt.Errorf("未找到自定义 Agent \"%s\"", name)
+		}
+		return result, nil
+	})
 }
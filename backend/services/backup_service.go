@@ -16,6 +16,7 @@ import (
 // BackupService 备份服务
 type BackupService struct {
 	ctx context.Context
+	env *Environment
 }
 
 // BackupConfig 备份配置
@@ -78,8 +79,8 @@ type BackupItem struct {
 	Description string `json:"description"`
 }
 
-func NewBackupService() *BackupService {
-	return &BackupService{}
+func NewBackupService(env *Environment) *BackupService {
+	return &BackupService{env: env}
 }
 
 func (bs *BackupService) Startup(ctx context.Context) {
@@ -103,8 +104,7 @@ func (bs *BackupService) CreateBackup(name, description string, items []string)
 	
 	backupDir := config.BackupLocation
 	if backupDir == "" {
-		homeDir, _ := os.UserHomeDir()
-		backupDir = filepath.Join(homeDir, ".skills-manager", "backups")
+		backupDir = filepath.Join(bs.env.ConfigDir, "backups")
 	}
 	
 	if err := os.MkdirAll(backupDir, 0755); err != nil {
@@ -155,12 +155,7 @@ func (bs *BackupService) CreateBackup(name, description string, items []string)
 
 // GetBackups 获取备份列表
 func (bs *BackupService) GetBackups() ([]BackupInfo, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	backupsFile := filepath.Join(homeDir, ".skills-manager", "backups.json")
+	backupsFile := filepath.Join(bs.env.ConfigDir, "backups.json")
 	
 	if _, err := os.Stat(backupsFile); os.IsNotExist(err) {
 		return []BackupInfo{}, nil
@@ -222,33 +217,88 @@ func (bs *BackupService) RestoreBackup(options RestoreOptions) error {
 
 // DeleteBackup 删除备份
 func (bs *BackupService) DeleteBackup(backupID string) error {
+	return bs.updateBackupList(func(backups []BackupInfo) ([]BackupInfo, error) {
+		var targetBackup *BackupInfo
+		var remainingBackups []BackupInfo
+		
+		for _, backup := range backups {
+			if backup.ID == backupID {
+				targetBackup = &backup
+			} else {
+				remainingBackups = append(remainingBackups, backup)
+			}
+		}
+		
+		if targetBackup == nil {
+			return nil, fmt.Errorf("backup not found: %s", backupID)
+		}
+		
+		// 删除备份文件
+		if err := os.Remove(targetBackup.FilePath); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to delete backup file: %w", err)
+		}
+		
+		// 更新备份列表
+		return remainingBackups, nil
+	})
+}
+
+// CompareBackupSkills 对比备份中的 skills 目录与当前 skills 目录（备份 -> 当前）
+func (bs *BackupService) CompareBackupSkills(backupID string) (*TreeDiff, error) {
 	backups, err := bs.GetBackups()
 	if err != nil {
-		return fmt.Errorf("failed to get backups: %w", err)
+		return nil, fmt.Errorf("failed to get backups: %w", err)
 	}
 	
 	var targetBackup *BackupInfo
-	var remainingBackups []BackupInfo
-	
 	for _, backup := range backups {
 		if backup.ID == backupID {
 			targetBackup = &backup
-		} else {
-			remainingBackups = append(remainingBackups, backup)
+			break
 		}
 	}
 	
 	if targetBackup == nil {
-		return fmt.Errorf("backup not found: %s", backupID)
+		return nil, fmt.Errorf("backup not found: %s", backupID)
 	}
 	
-	// 删除备份文件
-	if err := os.Remove(targetBackup.FilePath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete backup file: %w", err)
+	zipReader, err := zip.OpenReader(targetBackup.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup file: %w", err)
 	}
+	defer zipReader.Close()
 	
-	// 更新备份列表
-	return bs.saveBackupList(remainingBackups)
+	// 只解压 skills 目录部分
+	tempDir := filepath.Join(bs.env.ConfigDir, "temp-compare")
+	os.RemoveAll(tempDir)
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+	
+	const skillsPrefix = "Skills Directory/"
+	for _, file := range zipReader.File {
+		if !strings.HasPrefix(file.Name, skillsPrefix) || strings.Contains(file.Name, "..") {
+			continue
+		}
+		if err := bs.extractFile(file, tempDir); err != nil {
+			fmt.Printf("Warning: failed to extract %s: %v\n", file.Name, err)
+		}
+	}
+	
+	// 与备份时的规则一致：忽略隐藏文件和目录（.skills-lock 除外）
+	skipHidden := func(relPath string) bool {
+		if relPath == ".skills-lock" {
+			return false
+		}
+		for _, part := range strings.Split(relPath, "/") {
+			if strings.HasPrefix(part, ".") {
+				return true
+			}
+		}
+		return false
+	}
+	return diffTrees(filepath.Join(tempDir, strings.TrimSuffix(skillsPrefix, "/")), bs.env.SkillsDir, skipHidden)
 }
 
 // GetBackupConfig 获取备份配置
@@ -258,24 +308,14 @@ func (bs *BackupService) GetBackupConfig() (*BackupConfig, error) {
 
 // SetBackupConfig 设置备份配置
 func (bs *BackupService) SetBackupConfig(config BackupConfig) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
+	configDir := bs.env.ConfigDir
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 	
 	configFile := filepath.Join(configDir, "backup-config.json")
 	
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
-	}
-	
-	if err := os.WriteFile(configFile, data, 0644); err != nil {
+	if err := saveJSONConfig(configFile, config); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 	
@@ -284,13 +324,8 @@ func (bs *BackupService) SetBackupConfig(config BackupConfig) error {
 
 // GetBackupItems 获取可备份项目列表
 func (bs *BackupService) GetBackupItems() ([]BackupItem, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
-	skillsDir := filepath.Join(homeDir, ".agents", "skills")
+	configDir := bs.env.ConfigDir
+	skillsDir := bs.env.SkillsDir
 	
 	items := []BackupItem{
 		{
@@ -349,6 +384,13 @@ func (bs *BackupService) GetBackupItems() ([]BackupItem, error) {
 			Required:    false,
 			Description: "Usage statistics and performance data",
 		},
+		{
+			Name:        "Metadata Database",
+			Type:        "file",
+			SourcePath:  filepath.Join(configDir, metadataDBFile),
+			Required:    false,
+			Description: "Tags, ratings, activity logs and metrics (bolt storage backend)",
+		},
 		{
 			Name:        "Skills Directory",
 			Type:        "directory",
@@ -417,13 +459,8 @@ func (bs *BackupService) performRestore(backup *BackupInfo, options RestoreOptio
 	}
 	defer zipReader.Close()
 	
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
 	// 创建临时目录
-	tempDir := filepath.Join(homeDir, ".skills-manager", "temp-restore")
+	tempDir := filepath.Join(bs.env.ConfigDir, "temp-restore")
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
 		return fmt.Errorf("failed to create temp directory: %w", err)
 	}
@@ -561,13 +598,8 @@ func (bs *BackupService) extractFile(file *zip.File, destDir string) error {
 
 // restoreFiles 恢复文件到目标位置
 func (bs *BackupService) restoreFiles(tempDir string, options RestoreOptions) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
-	skillsDir := filepath.Join(homeDir, ".agents", "skills")
+	configDir := bs.env.ConfigDir
+	skillsDir := bs.env.SkillsDir
 	
 	// 恢复配置文件
 	if options.RestoreSettings {
@@ -577,6 +609,7 @@ func (bs *BackupService) restoreFiles(tempDir string, options RestoreOptions) er
 			"skill-tags.json",
 			"favorites.json",
 			"collections.json",
+			metadataDBFile,
 		}
 		
 		for _, file := range configFiles {
@@ -590,7 +623,7 @@ func (bs *BackupService) restoreFiles(tempDir string, options RestoreOptions) er
 					}
 				}
 				
-				bs.copyFile(srcPath, destPath)
+				bs.restoreConfigFile(srcPath, destPath)
 			}
 		}
 	}
@@ -602,7 +635,7 @@ func (bs *BackupService) restoreFiles(tempDir string, options RestoreOptions) er
 		
 		if _, err := os.Stat(srcPath); err == nil {
 			if options.OverwriteExisting || !bs.fileExists(destPath) {
-				bs.copyFile(srcPath, destPath)
+				bs.restoreConfigFile(srcPath, destPath)
 			}
 		}
 	}
@@ -630,7 +663,7 @@ func (bs *BackupService) restoreFiles(tempDir string, options RestoreOptions) er
 			
 			if _, err := os.Stat(srcPath); err == nil {
 				if options.OverwriteExisting || !bs.fileExists(destPath) {
-					bs.copyFile(srcPath, destPath)
+					bs.restoreConfigFile(srcPath, destPath)
 				}
 			}
 		}
@@ -639,14 +672,21 @@ func (bs *BackupService) restoreFiles(tempDir string, options RestoreOptions) er
 	return nil
 }
 
-// getBackupConfig 获取备份配置
-func (bs *BackupService) getBackupConfig() (*BackupConfig, error) {
-	homeDir, err := os.UserHomeDir()
+// restoreConfigFile 在文件锁保护下用备份中的文件原子替换配置文件
+func (bs *BackupService) restoreConfigFile(srcPath, destPath string) error {
+	data, err := os.ReadFile(srcPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return err
 	}
 	
-	configFile := filepath.Join(homeDir, ".skills-manager", "backup-config.json")
+	return withFileLock(destPath, func() error {
+		return writeFileAtomic(destPath, data, 0644)
+	})
+}
+
+// getBackupConfig 获取备份配置
+func (bs *BackupService) getBackupConfig() (*BackupConfig, error) {
+	configFile := filepath.Join(bs.env.ConfigDir, "backup-config.json")
 	
 	// 如果配置文件不存在，返回默认配置
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
@@ -682,41 +722,37 @@ func (bs *BackupService) getBackupConfig() (*BackupConfig, error) {
 
 // saveBackupInfo 保存备份信息
 func (bs *BackupService) saveBackupInfo(backup *BackupInfo) error {
-	backups, err := bs.GetBackups()
-	if err != nil {
-		backups = []BackupInfo{}
-	}
-	
-	// 添加新备份
-	backups = append(backups, *backup)
-	
-	return bs.saveBackupList(backups)
+	return bs.updateBackupList(func(backups []BackupInfo) ([]BackupInfo, error) {
+		// 添加新备份
+		return append(backups, *backup), nil
+	})
 }
 
-// saveBackupList 保存备份列表
-func (bs *BackupService) saveBackupList(backups []BackupInfo) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
+// updateBackupList 在文件锁保护下读取、修改并写回备份列表，fn 返回错误时不写入
+func (bs *BackupService) updateBackupList(fn func(backups []BackupInfo) ([]BackupInfo, error)) error {
+	configDir := bs.env.ConfigDir
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 	
 	backupsFile := filepath.Join(configDir, "backups.json")
 	
-	data, err := json.MarshalIndent(backups, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal backups: %w", err)
-	}
-	
-	if err := os.WriteFile(backupsFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write backups file: %w", err)
-	}
-	
-	return nil
+	return withFileLock(backupsFile, func() error {
+		backups, err := bs.GetBackups()
+		if err != nil {
+			return fmt.Errorf("failed to get backups: %w", err)
+		}
+		
+		backups, err = fn(backups)
+		if err != nil {
+			return err
+		}
+		
+		if err := writeJSONFile(backupsFile, backups); err != nil {
+			return fmt.Errorf("failed to write backups file: %w", err)
+		}
+		return nil
+	})
 }
 
 // calculateChecksum 计算文件校验和
@@ -754,24 +790,21 @@ func (bs *BackupService) cleanupOldBackups(maxBackups int) {
 		return
 	}
 	
-	backups, err := bs.GetBackups()
-	if err != nil {
-		return
-	}
-	
-	if len(backups) <= maxBackups {
-		return
-	}
-	
-	// 删除最旧的备份
-	toDelete := backups[maxBackups:]
-	remaining := backups[:maxBackups]
-	
-	for _, backup := range toDelete {
-		os.Remove(backup.FilePath)
-	}
-	
-	bs.saveBackupList(remaining)
+	bs.updateBackupList(func(backups []BackupInfo) ([]BackupInfo, error) {
+		if len(backups) <= maxBackups {
+			return backups, nil
+		}
+		
+		// 删除最旧的备份
+		toDelete := backups[maxBackups:]
+		remaining := backups[:maxBackups]
+		
+		for _, backup := range toDelete {
+			os.Remove(backup.FilePath)
+		}
+		
+		return remaining, nil
+	})
 }
 
 // startAutoBackup 启动自动备份
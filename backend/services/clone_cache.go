@@ -0,0 +1,368 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---- 仓库克隆缓存 ----
+// 每个源 URL 对应 <ConfigDir>/clone-cache/<key>/ 下的一个 bare 仓库（只同步分支与 tag），
+// 安装 / 更新 / diff / 扫描时先 fetch 刷新缓存，再从本地缓存克隆出工作目录。
+// 同一仓库的 fetch / 克隆 / 淘汰通过 <key>.lock 文件锁在进程之间互斥（GUI 与命令行共用缓存）
+
+const (
+	cloneCacheDirName          = "clone-cache"
+	defaultCloneCacheTTLDays   = 30
+	defaultCloneCacheMaxSizeMB = 1024
+	// cloneCacheFetchInterval 同一仓库在该时间内重复使用时不再 fetch（例如批量安装同一仓库的多个 skill），
+	// 取值较短以保证单独的安装 / 更新总能拿到最新内容
+	cloneCacheFetchInterval = 15 * time.Second
+	// cloneCacheLockTimeout 等待其他进程克隆 / fetch 同一仓库的最长时间
+	cloneCacheLockTimeout = 10 * time.Minute
+	// cloneCacheEvictInterval 自动淘汰的最小间隔，记录在 clone-cache/.last-evict 的修改时间中
+	cloneCacheEvictInterval = 24 * time.Hour
+)
+
+// cloneCacheMeta 缓存条目的元数据，保存在 <key>/meta.json
+type cloneCacheMeta struct {
+	URL         string    `json:"url"`
+	LastFetched time.Time `json:"lastFetched"`
+	LastUsed    time.Time `json:"lastUsed"`
+	Mirrored    bool      `json:"mirrored,omitempty"` // 由 MirrorSources 同步的离线镜像，不参与自动淘汰
+}
+
+// CloneCacheEntry 缓存中的一个仓库
+type CloneCacheEntry struct {
+	URL         string    `json:"url"`
+	Size        int64     `json:"size"`
+	LastFetched time.Time `json:"lastFetched"`
+	LastUsed    time.Time `json:"lastUsed"`
+	Mirrored    bool      `json:"mirrored"`
+}
+
+// CloneCacheInfo 克隆缓存概况
+type CloneCacheInfo struct {
+	Dir        string            `json:"dir"`
+	Entries    []CloneCacheEntry `json:"entries"`
+	TotalBytes int64             `json:"totalBytes"`
+}
+
+// CloneCachePruneResult 清理结果
+type CloneCachePruneResult struct {
+	Removed        []string `json:"removed"` // 被删除的仓库 URL
+	FreedBytes     int64    `json:"freedBytes"`
+	Remaining      int      `json:"remaining"` // 剩余的缓存仓库数（不含离线镜像）
+	RemainingBytes int64    `json:"remainingBytes"`
+}
+
+func (ss *SkillsService) cloneCacheDir() string {
+	return filepath.Join(ss.env.ConfigDir, cloneCacheDirName)
+}
+
+// cloneCacheKeyDir 返回 URL 对应的缓存目录
+func (ss *SkillsService) cloneCacheKeyDir(repoURL string) string {
+	sum := sha256.Sum256([]byte(repoURL))
+	return filepath.Join(ss.cloneCacheDir(), hex.EncodeToString(sum[:])[:16])
+}
+
+// withCloneCacheLock 持有缓存条目的跨进程锁执行 fn，锁文件为 <key>.lock，删除条目时不受影响
+func withCloneCacheLock(keyDir string, fn func() error) error {
+	unlock, err := lockFileTimeout(keyDir, cloneCacheLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+func readCloneCacheMeta(keyDir string) (cloneCacheMeta, error) {
+	var meta cloneCacheMeta
+	data, err := os.ReadFile(filepath.Join(keyDir, "meta.json"))
+	if err != nil {
+		return meta, err
+	}
+	err = json.Unmarshal(data, &meta)
+	return meta, err
+}
+
+func writeCloneCacheMeta(keyDir string, meta cloneCacheMeta) error {
+	return writeJSONFile(filepath.Join(keyDir, "meta.json"), meta)
+}
+
+// cloneCacheLimits 读取设置中的缓存有效期与大小上限
+func (ss *SkillsService) cloneCacheLimits() (time.Duration, int64) {
+	ttlDays, maxMB := defaultCloneCacheTTLDays, defaultCloneCacheMaxSizeMB
+	if settings, err := ss.GetSettings(); err == nil {
+		if settings.CloneCacheTTLDays > 0 {
+			ttlDays = settings.CloneCacheTTLDays
+		}
+		if settings.CloneCacheMaxSizeMB > 0 {
+			maxMB = settings.CloneCacheMaxSizeMB
+		}
+	}
+	return time.Duration(ttlDays) * 24 * time.Hour, int64(maxMB) << 20
+}
+
+// checkoutRepo 从缓存中检出仓库到 destDir（ref 为空时使用默认分支），必要时先克隆或 fetch 缓存
+// 返回值与 git clone 一致：命令输出与错误
+func (ss *SkillsService) checkoutRepo(repoURL, destDir, ref string) ([]byte, error) {
+	out, err := ss.checkoutCachedRepo(repoURL, destDir, ref)
+	if err == nil {
+		ss.maybeEvictCloneCache()
+	}
+	return out, err
+}
+
+// checkoutCachedRepo 与 checkoutRepo 相同，但不触发缓存淘汰
+func (ss *SkillsService) checkoutCachedRepo(repoURL, destDir, ref string) ([]byte, error) {
+	keyDir := ss.cloneCacheKeyDir(repoURL)
+	var out []byte
+	err := withCloneCacheLock(keyDir, func() error {
+		var err error
+		out, err = ss.checkoutRepoLocked(repoURL, keyDir, destDir, ref)
+		return err
+	})
+	return out, err
+}
+
+func (ss *SkillsService) checkoutRepoLocked(repoURL, keyDir, destDir, ref string) ([]byte, error) {
+	repoDir := filepath.Join(keyDir, "repo.git")
+	meta, _ := readCloneCacheMeta(keyDir)
+	offline := ss.IsOfflineMode()
+	auth := ss.hostAuth(repoURL)
+	fetched := false
+
+	if _, err := os.Stat(repoDir); err != nil {
+		if offline {
+			return nil, fmt.Errorf("repository %s is not mirrored locally (offline mode)", repoURL)
+		}
+		if out, err := cloneCacheRepo(repoURL, keyDir, auth); err != nil {
+			return out, err
+		}
+		meta.LastFetched, fetched = time.Now(), true
+	} else if !offline && !(ref != "" && gitHasCommit(repoDir, ref)) && time.Since(meta.LastFetched) > cloneCacheFetchInterval {
+		// 已缓存的 commit SHA 不会变化，无需 fetch；分支 / tag / 默认分支需要刷新
+		if out, err := fetchCacheRepo(repoDir, auth); err != nil {
+			return out, err
+		}
+		meta.LastFetched, fetched = time.Now(), true
+	}
+
+	sha, err := resolveCachedRef(repoDir, ref)
+	if err != nil && !fetched && !offline {
+		// 跳过了 fetch 时 ref 可能是刚推送的，刷新后重试
+		if out, err := fetchCacheRepo(repoDir, auth); err != nil {
+			return out, err
+		}
+		meta.LastFetched = time.Now()
+		sha, err = resolveCachedRef(repoDir, ref)
+	}
+	meta.URL = repoURL
+	meta.LastUsed = time.Now()
+	writeCloneCacheMeta(keyDir, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	// 本地克隆会硬链接对象文件，几乎不占额外空间
+	out, err := exec.Command("git", "clone", "--no-checkout", "--quiet", "--", repoDir, destDir).CombinedOutput()
+	if err != nil {
+		return out, err
+	}
+	checkoutOut, err := exec.Command("git", "-C", destDir, "checkout", "--quiet", "--detach", sha).CombinedOutput()
+	out = append(out, checkoutOut...)
+	return out, err
+}
+
+// cloneCacheRepo 创建 keyDir/repo.git：bare 克隆后只配置分支与 tag 的 refspec，
+// 不同步 pull request 等其他 ref。先克隆到临时目录再改名，避免中断后留下不完整的缓存
+func cloneCacheRepo(repoURL, keyDir string, auth hostAuth) ([]byte, error) {
+	if strings.HasPrefix(repoURL, "-") {
+		return nil, fmt.Errorf("invalid repository URL: %s", repoURL)
+	}
+	if err := os.MkdirAll(keyDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create clone cache: %v", err)
+	}
+	tmpDir, err := os.MkdirTemp(keyDir, "repo-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clone cache: %v", err)
+	}
+	out, err := auth.gitCommand("clone", "--bare", "--quiet", "--", repoURL, tmpDir).CombinedOutput()
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return out, err
+	}
+	for _, args := range [][]string{
+		{"config", "remote.origin.fetch", "+refs/heads/*:refs/heads/*"},
+		{"config", "--add", "remote.origin.fetch", "+refs/tags/*:refs/tags/*"},
+	} {
+		if configOut, err := exec.Command("git", append([]string{"--git-dir", tmpDir}, args...)...).CombinedOutput(); err != nil {
+			os.RemoveAll(tmpDir)
+			return append(out, configOut...), err
+		}
+	}
+	if err := os.Rename(tmpDir, filepath.Join(keyDir, "repo.git")); err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("failed to create clone cache: %v", err)
+	}
+	return out, nil
+}
+
+// fetchCacheRepo 按配置的 refspec 从远程刷新缓存中的分支与 tag
+func fetchCacheRepo(repoDir string, auth hostAuth) ([]byte, error) {
+	return auth.gitCommand("--git-dir", repoDir, "fetch", "--prune", "--quiet", "origin").CombinedOutput()
+}
+
+// resolveCachedRef 将 ref（为空时为默认分支）解析为缓存中的 commit SHA
+func resolveCachedRef(repoDir, ref string) (string, error) {
+	rev := "HEAD"
+	if ref != "" {
+		rev = ref
+	}
+	out, err := exec.Command("git", "--git-dir", repoDir, "rev-parse", "--verify", "--quiet", rev+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("ref %q not found", rev)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitHasCommit 判断 ref 是否为缓存中已存在的 commit SHA
+func gitHasCommit(repoDir, ref string) bool {
+	if len(ref) < 7 || strings.Trim(strings.ToLower(ref), "0123456789abcdef") != "" {
+		return false
+	}
+	return exec.Command("git", "--git-dir", repoDir, "cat-file", "-e", ref+"^{commit}").Run() == nil
+}
+
+// listCloneCache 列出缓存条目，返回缓存目录到条目的映射
+func (ss *SkillsService) listCloneCache() (map[string]CloneCacheEntry, error) {
+	entries, err := os.ReadDir(ss.cloneCacheDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]CloneCacheEntry{}, nil
+		}
+		return nil, fmt.Errorf("failed to read clone cache: %v", err)
+	}
+	result := make(map[string]CloneCacheEntry, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		keyDir := filepath.Join(ss.cloneCacheDir(), e.Name())
+		meta, _ := readCloneCacheMeta(keyDir)
+		result[keyDir] = CloneCacheEntry{
+			URL:         meta.URL,
+			Size:        cacheDirSize(keyDir),
+			LastFetched: meta.LastFetched,
+			LastUsed:    meta.LastUsed,
+			Mirrored:    meta.Mirrored,
+		}
+	}
+	return result, nil
+}
+
+// cacheDirSize 计算目录占用的字节数
+func cacheDirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// GetCloneCacheInfo 返回克隆缓存中的仓库列表及总大小
+func (ss *SkillsService) GetCloneCacheInfo() (*CloneCacheInfo, error) {
+	cache, err := ss.listCloneCache()
+	if err != nil {
+		return nil, err
+	}
+	info := &CloneCacheInfo{Dir: ss.cloneCacheDir(), Entries: []CloneCacheEntry{}}
+	for _, e := range cache {
+		info.Entries = append(info.Entries, e)
+		info.TotalBytes += e.Size
+	}
+	sort.Slice(info.Entries, func(i, j int) bool {
+		return info.Entries[i].LastUsed.After(info.Entries[j].LastUsed)
+	})
+	return info, nil
+}
+
+// PruneCloneCache 清理克隆缓存：all 为 true 时清空，否则删除过期条目并按最近使用时间淘汰到大小上限以内
+// 离线镜像不会被清理，需通过 RemoveMirror 删除
+func (ss *SkillsService) PruneCloneCache(all bool) (*CloneCachePruneResult, error) {
+	return ss.evictCloneCache(all)
+}
+
+// maybeEvictCloneCache 距上次自动淘汰超过 cloneCacheEvictInterval 时执行淘汰，检出仓库后调用
+// 计算缓存大小需要遍历所有仓库，不在每次检出后执行
+func (ss *SkillsService) maybeEvictCloneCache() {
+	stamp := filepath.Join(ss.cloneCacheDir(), ".last-evict")
+	if info, err := os.Stat(stamp); err == nil && time.Since(info.ModTime()) < cloneCacheEvictInterval {
+		return
+	}
+	if err := os.WriteFile(stamp, nil, 0644); err != nil {
+		return
+	}
+	ss.evictCloneCache(false)
+}
+
+// evictCloneCache 执行缓存淘汰，正在使用（其他进程或 goroutine 持有锁）的条目会被跳过
+func (ss *SkillsService) evictCloneCache(all bool) (*CloneCachePruneResult, error) {
+	cache, err := ss.listCloneCache()
+	if err != nil {
+		return nil, err
+	}
+	ttl, maxBytes := ss.cloneCacheLimits()
+
+	keyDirs := make([]string, 0, len(cache))
+	var total int64
+	for keyDir, e := range cache {
+		if e.Mirrored {
+			continue
+		}
+		keyDirs = append(keyDirs, keyDir)
+		total += e.Size
+	}
+	// 最久未使用的排在前面
+	sort.Slice(keyDirs, func(i, j int) bool {
+		return cache[keyDirs[i]].LastUsed.Before(cache[keyDirs[j]].LastUsed)
+	})
+
+	result := &CloneCachePruneResult{Removed: []string{}}
+	now := time.Now()
+	for _, keyDir := range keyDirs {
+		e := cache[keyDir]
+		if !all && now.Sub(e.LastUsed) <= ttl && total <= maxBytes {
+			continue
+		}
+		unlock, err := lockFileTimeout(keyDir, 0)
+		if err != nil {
+			continue
+		}
+		err = os.RemoveAll(keyDir)
+		unlock()
+		if err != nil {
+			continue
+		}
+		total -= e.Size
+		result.Removed = append(result.Removed, e.URL)
+		result.FreedBytes += e.Size
+	}
+	result.Remaining = len(keyDirs) - len(result.Removed)
+	result.RemainingBytes = total
+	return result, nil
+}
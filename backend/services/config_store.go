@@ -0,0 +1,37 @@
+package services
+
+import (
+	"encoding/json"
+)
+
+// ---- JSON 配置文件读写 ----
+// 配置目录下的 JSON 文件（favorites.json、collections.json 等）统一通过这里写入：
+// 同一文件的读-改-写在进程内互斥锁与跨进程文件锁（GUI 与命令行同时运行时）保护下执行，
+// 写入先落到临时文件再重命名，中途崩溃不会留下截断的文件。
+// 读取不需要加锁：重命名是原子的，读者只会看到完整的旧内容或新内容
+
+// withFileLock 持有 path 的文件锁执行 fn
+func withFileLock(path string, fn func() error) error {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+// writeJSONFile 将 v 格式化后原子写入 path，调用方需已持有 path 的文件锁
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// saveJSONConfig 加锁后原子写入整个配置，用于不依赖旧内容的覆盖写
+func saveJSONConfig(path string, v interface{}) error {
+	return withFileLock(path, func() error {
+		return writeJSONFile(path, v)
+	})
+}
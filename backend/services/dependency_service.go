@@ -11,17 +11,19 @@ import (
 // DependencyService 依赖管理服务
 type DependencyService struct {
 	ctx           context.Context
+	env           *Environment
 	skillsService *SkillsService
 }
 
 // SkillDependency 技能依赖关系
 type SkillDependency struct {
-	Name         string   `json:"name"`
-	Dependencies []string `json:"dependencies"`
-	Conflicts    []string `json:"conflicts"`
-	Optional     []string `json:"optional"`
-	Version      string   `json:"version"`
-	Description  string   `json:"description"`
+	Name         string            `json:"name"`
+	Dependencies []string          `json:"dependencies"`
+	Conflicts    []string          `json:"conflicts"`
+	Optional     []string          `json:"optional"`
+	Version      string            `json:"version"`
+	Description  string            `json:"description"`
+	Constraints  map[string]string `json:"constraints,omitempty"` // 依赖名 -> 版本约束
 }
 
 // DependencyGraph 依赖图
@@ -84,8 +86,9 @@ type InstallPlan struct {
 	CanInstall       bool     `json:"canInstall"`
 }
 
-func NewDependencyService(skillsService *SkillsService) *DependencyService {
+func NewDependencyService(env *Environment, skillsService *SkillsService) *DependencyService {
 	return &DependencyService{
+		env:           env,
 		skillsService: skillsService,
 	}
 }
@@ -102,11 +105,12 @@ func (ds *DependencyService) GetDependencyGraph() (*DependencyGraph, error) {
 		return nil, fmt.Errorf("failed to get skills: %w", err)
 	}
 	
-	// 获取依赖信息
+	// 获取依赖信息（配置文件 + SKILL.md frontmatter 中的声明）
 	dependencies, err := ds.loadDependencies()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load dependencies: %w", err)
 	}
+	dependencies = mergeManifestDependencies(dependencies, skills)
 	
 	graph := &DependencyGraph{
 		Nodes: []DependencyNode{},
@@ -129,6 +133,12 @@ func (ds *DependencyService) GetDependencyGraph() (*DependencyGraph, error) {
 				"source":    skill.Source,
 			},
 		}
+		if skill.Manifest != nil {
+			node.Version = skill.Manifest.Version
+			if skill.Manifest.License != "" {
+				node.Metadata["license"] = skill.Manifest.License
+			}
+		}
 		
 		graph.Nodes = append(graph.Nodes, node)
 		nodeMap[skill.Name] = true
@@ -145,9 +155,10 @@ func (ds *DependencyService) GetDependencyGraph() (*DependencyGraph, error) {
 			// 如果依赖的技能也已安装，创建边
 			if nodeMap[reqDep] {
 				edge := DependencyEdge{
-					Source: dep.Name,
-					Target: reqDep,
-					Type:   "requires",
+					Source:     dep.Name,
+					Target:     reqDep,
+					Type:       "requires",
+					Constraint: dep.Constraints[reqDep],
 				}
 				graph.Edges = append(graph.Edges, edge)
 			} else {
@@ -162,9 +173,10 @@ func (ds *DependencyService) GetDependencyGraph() (*DependencyGraph, error) {
 				nodeMap[reqDep] = true
 				
 				edge := DependencyEdge{
-					Source: dep.Name,
-					Target: reqDep,
-					Type:   "requires",
+					Source:     dep.Name,
+					Target:     reqDep,
+					Type:       "requires",
+					Constraint: dep.Constraints[reqDep],
 				}
 				graph.Edges = append(graph.Edges, edge)
 			}
@@ -186,9 +198,10 @@ func (ds *DependencyService) GetDependencyGraph() (*DependencyGraph, error) {
 		for _, optional := range dep.Optional {
 			if nodeMap[optional] {
 				edge := DependencyEdge{
-					Source: dep.Name,
-					Target: optional,
-					Type:   "optional",
+					Source:     dep.Name,
+					Target:     optional,
+					Type:       "optional",
+					Constraint: dep.Constraints[optional],
 				}
 				graph.Edges = append(graph.Edges, edge)
 			}
@@ -331,43 +344,42 @@ func (ds *DependencyService) AutoInstallDependencies(skillName string, agents []
 
 // UpdateDependencyInfo 更新依赖信息
 func (ds *DependencyService) UpdateDependencyInfo(skillName string, dep SkillDependency) error {
-	dependencies, err := ds.loadDependencies()
-	if err != nil {
-		return fmt.Errorf("failed to load dependencies: %w", err)
-	}
+	depsFile := filepath.Join(ds.env.ConfigDir, "skill-dependencies.json")
 	
-	// 更新或添加依赖信息
-	found := false
-	for i, d := range dependencies {
-		if d.Name == skillName {
-			dependencies[i] = dep
-			found = true
-			break
+	return withFileLock(depsFile, func() error {
+		dependencies, err := ds.loadDependencies()
+		if err != nil {
+			return fmt.Errorf("failed to load dependencies: %w", err)
 		}
-	}
-	
-	if !found {
-		dependencies = append(dependencies, dep)
-	}
-	
-	// 保存到文件
-	return ds.saveDependencies(dependencies)
+		
+		// 更新或添加依赖信息
+		found := false
+		for i, d := range dependencies {
+			if d.Name == skillName {
+				dependencies[i] = dep
+				found = true
+				break
+			}
+		}
+		
+		if !found {
+			dependencies = append(dependencies, dep)
+		}
+		
+		// 保存到文件
+		return ds.writeDependencies(depsFile, dependencies)
+	})
 }
 
 // loadDependencies 加载依赖信息
 func (ds *DependencyService) loadDependencies() ([]SkillDependency, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
+	configDir := ds.env.ConfigDir
 	depsFile := filepath.Join(configDir, "skill-dependencies.json")
 	
 	if _, err := os.Stat(depsFile); os.IsNotExist(err) {
 		// 创建默认依赖配置
 		defaultDeps := ds.getDefaultDependencies()
-		ds.saveDependencies(defaultDeps)
+		ds.writeDependencies(depsFile, defaultDeps)
 		return defaultDeps, nil
 	}
 	
@@ -384,26 +396,64 @@ func (ds *DependencyService) loadDependencies() ([]SkillDependency, error) {
 	return dependencies, nil
 }
 
-// saveDependencies 保存依赖信息
-func (ds *DependencyService) saveDependencies(dependencies []SkillDependency) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+// mergeManifestDependencies 将 SKILL.md frontmatter 中声明的依赖合并到配置文件的依赖信息中
+// 两处都有声明时按字段以 frontmatter 为准，frontmatter 未声明的字段保留配置文件中的值
+func mergeManifestDependencies(dependencies []SkillDependency, skills []Skills) []SkillDependency {
+	index := make(map[string]int, len(dependencies))
+	for i, dep := range dependencies {
+		index[dep.Name] = i
 	}
 	
-	depsFile := filepath.Join(configDir, "skill-dependencies.json")
-	
-	data, err := json.MarshalIndent(dependencies, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal dependencies: %w", err)
+	for _, skill := range skills {
+		m := skill.Manifest
+		if m == nil || (len(m.Dependencies) == 0 && len(m.Conflicts) == 0 && m.Version == "") {
+			continue
+		}
+		
+		i, ok := index[skill.Name]
+		if !ok {
+			dependencies = append(dependencies, SkillDependency{
+				Name:         skill.Name,
+				Dependencies: []string{},
+				Conflicts:    []string{},
+				Optional:     []string{},
+				Description:  skill.Desc,
+			})
+			i = len(dependencies) - 1
+			index[skill.Name] = i
+		}
+		dep := &dependencies[i]
+		
+		if required := m.RequiredDependencies(); len(required) > 0 {
+			dep.Dependencies = required
+		}
+		if optional := m.OptionalDependencies(); len(optional) > 0 {
+			dep.Optional = optional
+		}
+		if len(m.Conflicts) > 0 {
+			dep.Conflicts = m.Conflicts
+		}
+		if m.Version != "" {
+			dep.Version = m.Version
+		}
+		for _, d := range m.Dependencies {
+			if d.Version == "" {
+				continue
+			}
+			if dep.Constraints == nil {
+				dep.Constraints = make(map[string]string)
+			}
+			dep.Constraints[d.Name] = d.Version
+		}
 	}
 	
-	if err := os.WriteFile(depsFile, data, 0644); err != nil {
+	return dependencies
+}
+
+// writeDependencies 原子写入依赖信息，修改已有内容时调用方需持有 depsFile 的文件锁
+// （loadDependencies 首次写入的默认配置内容固定，并发写入结果相同，不加锁）
+func (ds *DependencyService) writeDependencies(depsFile string, dependencies []SkillDependency) error {
+	if err := writeJSONFile(depsFile, dependencies); err != nil {
 		return fmt.Errorf("failed to write dependencies file: %w", err)
 	}
 	
@@ -689,6 +739,14 @@ func (ds *DependencyService) getSkillDependency(skillName string) (*SkillDepende
 		return nil, err
 	}
 	
+	// 已安装的 skill 以 SKILL.md 中的声明为准
+	skillMdPath := filepath.Join(ds.env.SkillsDir, skillName, "SKILL.md")
+	if content, err := os.ReadFile(skillMdPath); err == nil {
+		skill := parseSkillMd(string(content), filepath.Dir(skillMdPath))
+		skill.Name = skillName
+		dependencies = mergeManifestDependencies(dependencies, []Skills{skill})
+	}
+	
 	for _, dep := range dependencies {
 		if dep.Name == skillName {
 			return &dep, nil
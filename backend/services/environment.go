@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// 覆盖默认目录的环境变量
+const (
+	EnvHomeDir   = "AGENT_HUB_HOME"       // 替代用户 home 目录（agent 全局目录、默认 skills/配置目录的根）
+	EnvSkillsDir = "AGENT_HUB_SKILLS_DIR" // 中央 skills 目录，默认 <home>/.agents/skills
+	EnvConfigDir = "AGENT_HUB_CONFIG_DIR" // 应用配置目录，默认 <home>/.skills-manager
+)
+
+// EnvOffline 设为 1 / true 时以离线模式运行，只使用本地镜像（见 MirrorSources）
+const EnvOffline = "AGENT_HUB_OFFLINE"
+
+// EnvTrustPolicy 指定信任策略文件路径，覆盖默认的 <config>/trust-policy.json
+const EnvTrustPolicy = "AGENT_HUB_TRUST_POLICY"
+
+// EnvSecretPassphrase 密钥 vault 设置了口令时用于自动解锁（命令行与 CI 场景）
+const EnvSecretPassphrase = "AGENT_HUB_SECRET_PASSPHRASE"
+
+// Environment 描述服务使用的文件系统根目录，由 main / CLI 创建后注入到各个服务。
+// 通过指向临时目录即可运行隔离的沙箱、多用户配置或测试，而不会触碰真实的 ~ 目录。
+type Environment struct {
+	HomeDir   string `json:"homeDir"`   // agent 全局路径（GlobalPaths）的根目录
+	SkillsDir string `json:"skillsDir"` // 中央 skills 目录
+	ConfigDir string `json:"configDir"` // skills-manager 配置目录
+	Offline   bool   `json:"offline"`   // 强制离线模式（命令行 --offline 或 AGENT_HUB_OFFLINE），设置中的离线开关同样生效
+	// TrustPolicyPath 信任策略文件（AGENT_HUB_TRUST_POLICY），为空时使用配置目录下的 trust-policy.json
+	TrustPolicyPath string `json:"trustPolicyPath"`
+
+	// agents.json 加载结果，首次使用时懒加载
+	agentsOnce sync.Once
+	agents     []AgentConfig
+
+	// customAgents 缓存，避免每次调用都读磁盘 + JSON 解析
+	customAgentsMu     sync.RWMutex
+	cachedCustomAgents []CustomAgentConfig
+	customAgentsCached bool
+}
+
+// NewEnvironment 以 homeDir 为根创建环境，skillsDir / configDir 为空时使用默认位置
+func NewEnvironment(homeDir, skillsDir, configDir string) *Environment {
+	if skillsDir == "" {
+		skillsDir = filepath.Join(homeDir, ".agents", "skills")
+	}
+	if configDir == "" {
+		configDir = filepath.Join(homeDir, ".skills-manager")
+	}
+	return &Environment{
+		HomeDir:   absPath(homeDir),
+		SkillsDir: absPath(skillsDir),
+		ConfigDir: absPath(configDir),
+	}
+}
+
+// absPath 转为绝对路径（软链接目标必须是绝对路径），失败时退回 Clean 后的原路径
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return filepath.Clean(p)
+}
+
+// LoadEnvironment 解析运行环境，优先级：参数（命令行 flag）> 环境变量 > 当前用户 home 目录下的默认位置
+func LoadEnvironment(homeDir, skillsDir, configDir string) (*Environment, error) {
+	if homeDir == "" {
+		homeDir = os.Getenv(EnvHomeDir)
+	}
+	if homeDir == "" {
+		var err error
+		homeDir, err = os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %v", err)
+		}
+	}
+	if skillsDir == "" {
+		skillsDir = os.Getenv(EnvSkillsDir)
+	}
+	if configDir == "" {
+		configDir = os.Getenv(EnvConfigDir)
+	}
+	env := NewEnvironment(homeDir, skillsDir, configDir)
+	env.Offline, _ = strconv.ParseBool(os.Getenv(EnvOffline))
+	if p := os.Getenv(EnvTrustPolicy); p != "" {
+		env.TrustPolicyPath = absPath(p)
+	}
+	return env, nil
+}
+
+// SkillsLockPath 返回中央 skills 目录下的 .skills-lock 路径
+func (e *Environment) SkillsLockPath() string {
+	return filepath.Join(e.SkillsDir, ".skills-lock")
+}
+
+// getConfigDir 获取配置目录，不存在时自动创建
+func (e *Environment) getConfigDir() (string, error) {
+	if err := os.MkdirAll(e.ConfigDir, 0755); err != nil {
+		return "", err
+	}
+	return e.ConfigDir, nil
+}
+
+// configFilePath 返回配置目录下的文件路径，并确保配置目录存在
+func (e *Environment) configFilePath(name string) (string, error) {
+	configDir, err := e.getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, name), nil
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ---- 跨进程文件锁与原子写入 ----
+
+// fileLockTimeout 等待其他进程释放文件锁的最长时间
+const fileLockTimeout = 10 * time.Second
+
+// fileLockMutexes 同一进程内按锁文件路径串行，避免多个 goroutine 轮询同一把文件锁
+var fileLockMutexes sync.Map
+
+// errFileLockBusy 不等待地获取锁时，锁已被占用
+var errFileLockBusy = errors.New("lock is held by another operation")
+
+// lockFile 获取 path 对应的排他锁（锁文件为 path + ".lock"），返回释放函数
+// 锁只在协作的进程之间生效（GUI 与 agent-hub 命令行），不会阻止其他程序直接写 path
+func lockFile(path string) (func(), error) {
+	return lockFileTimeout(path, fileLockTimeout)
+}
+
+// lockFileTimeout 与 lockFile 相同，最多等待其他进程 timeout；timeout 为 0 时不等待，
+// 锁被占用（包括同一进程内）时返回 errFileLockBusy
+func lockFileTimeout(path string, timeout time.Duration) (func(), error) {
+	lockPath := path + ".lock"
+	mu, _ := fileLockMutexes.LoadOrStore(lockPath, &sync.Mutex{})
+	if timeout > 0 {
+		mu.(*sync.Mutex).Lock()
+	} else if !mu.(*sync.Mutex).TryLock() {
+		return nil, errFileLockBusy
+	}
+
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		mu.(*sync.Mutex).Unlock()
+		return nil, fmt.Errorf("failed to create lock directory: %v", err)
+	}
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		mu.(*sync.Mutex).Unlock()
+		return nil, fmt.Errorf("failed to open lock file: %v", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			mu.(*sync.Mutex).Unlock()
+			return nil, fmt.Errorf("failed to lock %s: %v", filepath.Base(path), err)
+		}
+		if locked {
+			break
+		}
+		if timeout <= 0 {
+			f.Close()
+			mu.(*sync.Mutex).Unlock()
+			return nil, errFileLockBusy
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			mu.(*sync.Mutex).Unlock()
+			return nil, fmt.Errorf("timed out waiting for lock on %s (held by another process)", filepath.Base(path))
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+		mu.(*sync.Mutex).Unlock()
+	}, nil
+}
+
+// writeFileAtomic 先写入同目录下的临时文件再重命名，读者不会看到写了一半的文件
+// 目标是符号链接时写入链接指向的文件；目标已存在时沿用其权限，perm 只用于新建文件
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
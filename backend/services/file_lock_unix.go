@@ -0,0 +1,25 @@
+//go:build !windows
+
+package services
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLockFile 以非阻塞方式获取 flock 排他锁，锁被占用时返回 false
+func tryLockFile(f *os.File) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return false, nil
+	}
+	return false, err
+}
+
+func unlockFile(f *os.File) {
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package services
+
+import "os"
+
+// Windows 下没有 flock，只依赖 lockFile 中的进程内互斥与原子写入
+func tryLockFile(f *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlockFile(f *os.File) {}
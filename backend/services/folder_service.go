@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -15,12 +16,15 @@ type folderConfig struct {
 
 type FolderService struct {
 	ctx        context.Context
+	env        *Environment
+	mu         sync.Mutex // 保护 folders
 	folders    []string
 	configPath string
 }
 
-func NewFolderService() *FolderService {
+func NewFolderService(env *Environment) *FolderService {
 	return &FolderService{
+		env:     env,
 		folders: []string{},
 	}
 }
@@ -29,12 +33,9 @@ func (fs *FolderService) Startup(ctx context.Context) {
 	fs.ctx = ctx
 
 	// 初始化配置文件路径: ~/.skills-manager/config.json
-	homeDir, err := os.UserHomeDir()
-	if err == nil {
-		configDir := filepath.Join(homeDir, ".skills-manager")
-		os.MkdirAll(configDir, 0755)
-		fs.configPath = filepath.Join(configDir, "config.json")
-	}
+	configDir := fs.env.ConfigDir
+	os.MkdirAll(configDir, 0755)
+	fs.configPath = filepath.Join(configDir, "config.json")
 
 	// 启动时从磁盘加载已保存的文件夹列表
 	fs.loadFromDisk()
@@ -49,6 +50,8 @@ func (fs *FolderService) SelectFolder() (string, error) {
 		return "", err
 	}
 	if folder != "" {
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
 		// 去重
 		for _, f := range fs.folders {
 			if f == folder {
@@ -63,11 +66,15 @@ func (fs *FolderService) SelectFolder() (string, error) {
 
 // GetFolders returns the list of opened folders
 func (fs *FolderService) GetFolders() []string {
-	return fs.folders
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return append([]string{}, fs.folders...)
 }
 
 // RemoveFolder removes a folder from the list
 func (fs *FolderService) RemoveFolder(folder string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
 	for i, f := range fs.folders {
 		if f == folder {
 			fs.folders = append(fs.folders[:i], fs.folders[i+1:]...)
@@ -97,18 +104,15 @@ func (fs *FolderService) loadFromDisk() {
 			valid = append(valid, f)
 		}
 	}
+	fs.mu.Lock()
 	fs.folders = valid
+	fs.mu.Unlock()
 }
 
-// saveToDisk writes the folder list to the config file
+// saveToDisk writes the folder list to the config file; callers hold fs.mu
 func (fs *FolderService) saveToDisk() {
 	if fs.configPath == "" {
 		return
 	}
-	cfg := folderConfig{Folders: fs.folders}
-	data, err := json.MarshalIndent(cfg, "", "  ")
-	if err != nil {
-		return
-	}
-	os.WriteFile(fs.configPath, data, 0644)
+	saveJSONConfig(fs.configPath, folderConfig{Folders: fs.folders})
 }
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---- 私有仓库认证 ----
+// 克隆 / fetch / API 请求按仓库 host 查找凭据：
+//  1. custom-sources.json 中 URL 相同的自定义源的 token
+//  2. host-credentials.json 中该 host 的 token / SSH 私钥
+//  3. github.com 回退到环境变量 GITHUB_TOKEN / GH_TOKEN
+//
+// HTTPS 仓库通过只作用于该 host 的 git credential helper 提供 token（token 经环境变量传入，不出现在命令行和远程 URL 中），
+// SSH 仓库通过 GIT_SSH_COMMAND 指定私钥；HTTP 请求使用 Authorization: Bearer 头
+
+// githubTokenEnvVars 未配置 github.com 凭据时依次读取的环境变量
+var githubTokenEnvVars = []string{"GITHUB_TOKEN", "GH_TOKEN"}
+
+// HostCredential 某个 git host 的访问凭据
+type HostCredential struct {
+	Host       string `json:"host"`                 // 例如 github.com、gitlab.example.com:8443
+	Username   string `json:"username,omitempty"`   // HTTPS 用户名，为空时按 host 使用默认值
+	Token      string `json:"token,omitempty"`      // PAT / access token，用于 HTTPS 克隆与 API 请求；文件中保存密钥引用
+	SSHKeyPath string `json:"sshKeyPath,omitempty"` // SSH 私钥路径，用于 ssh:// 与 git@host:path 仓库
+	AddedAt    string `json:"addedAt"`
+}
+
+// HostCredentialsConfig 凭据配置文件
+type HostCredentialsConfig struct {
+	Credentials []HostCredential `json:"credentials"`
+}
+
+func getHostCredentialsFilePath(env *Environment) (string, error) {
+	return env.configFilePath("host-credentials.json")
+}
+
+// hostCredentialSecretKey host token 在密钥存储中的名称
+func hostCredentialSecretKey(host string) string {
+	return "host/" + host + "/token"
+}
+
+// loadHostCredentials 读取 host 凭据并解析 token 引用
+func loadHostCredentials(env *Environment) ([]HostCredential, error) {
+	creds, err := readHostCredentials(env)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range creds {
+		creds[i].Token = openSecretOrRef(env, c.Token)
+	}
+	return creds, nil
+}
+
+// readHostCredentials 读取 host-credentials.json 原始内容
+func readHostCredentials(env *Environment) ([]HostCredential, error) {
+	filePath, err := getHostCredentialsFilePath(env)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []HostCredential{}, nil
+		}
+		return nil, err
+	}
+	var config HostCredentialsConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse host credentials: %v", err)
+	}
+	return config.Credentials, nil
+}
+
+// updateHostCredentials 在文件锁保护下读取、修改并写回凭据列表，token 写入密钥存储
+func updateHostCredentials(env *Environment, fn func(creds []HostCredential) ([]HostCredential, error)) error {
+	filePath, err := getHostCredentialsFilePath(env)
+	if err != nil {
+		return err
+	}
+	return withFileLock(filePath, func() error {
+		raw, err := readHostCredentials(env)
+		if err != nil {
+			return err
+		}
+		// 文件中原有的密钥引用，用于删除已移除或已清空的 token
+		prevTokens := map[string]string{}
+		for _, c := range raw {
+			prevTokens[c.Host] = c.Token
+		}
+		creds, err := loadHostCredentials(env)
+		if err != nil {
+			return err
+		}
+		removed := map[string]bool{}
+		for _, c := range creds {
+			removed[c.Host] = true
+		}
+		creds, err = fn(creds)
+		if err != nil {
+			return err
+		}
+		onDisk := make([]HostCredential, len(creds))
+		for i, c := range creds {
+			delete(removed, c.Host)
+			if c.Token, err = sealSecret(env, hostCredentialSecretKey(c.Host), c.Token, prevTokens[c.Host]); err != nil {
+				return err
+			}
+			onDisk[i] = c
+		}
+		for host := range removed {
+			dropSecret(env, prevTokens[host])
+		}
+		return writeJSONFile(filePath, HostCredentialsConfig{Credentials: onDisk})
+	})
+}
+
+// normalizeCredentialHost 接受 host、host:port 或完整 URL，返回小写的 host[:port]
+func normalizeCredentialHost(host string) string {
+	host = strings.TrimSpace(host)
+	if strings.Contains(host, "://") {
+		if u, err := url.Parse(host); err == nil {
+			host = u.Host
+		}
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "/"))
+}
+
+// GetHostCredentials 获取已配置的 host 凭据
+func (ss *SkillsService) GetHostCredentials() ([]HostCredential, error) {
+	creds, err := loadHostCredentials(ss.env)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(creds, func(i, j int) bool { return creds[i].Host < creds[j].Host })
+	return creds, nil
+}
+
+// SetHostCredential 添加或替换某个 host 的凭据，token 与 SSH 私钥至少提供一个
+func (ss *SkillsService) SetHostCredential(cred HostCredential) error {
+	cred.Host = normalizeCredentialHost(cred.Host)
+	cred.Username = strings.TrimSpace(cred.Username)
+	cred.Token = strings.TrimSpace(cred.Token)
+	cred.SSHKeyPath = strings.TrimSpace(cred.SSHKeyPath)
+	if cred.Host == "" || strings.ContainsAny(cred.Host, "/@ ") {
+		return fmt.Errorf("invalid host: %q", cred.Host)
+	}
+	if cred.Token == "" && cred.SSHKeyPath == "" {
+		return fmt.Errorf("a token or an SSH key is required")
+	}
+	if cred.SSHKeyPath != "" {
+		keyPath := ss.expandHome(cred.SSHKeyPath)
+		if info, err := os.Stat(keyPath); err != nil || info.IsDir() {
+			return fmt.Errorf("SSH key not found: %s", cred.SSHKeyPath)
+		}
+		cred.SSHKeyPath = keyPath
+	}
+	cred.AddedAt = time.Now().Format(time.RFC3339)
+
+	err := updateHostCredentials(ss.env, func(creds []HostCredential) ([]HostCredential, error) {
+		for i, c := range creds {
+			if c.Host == cred.Host {
+				creds[i] = cred
+				return creds, nil
+			}
+		}
+		return append(creds, cred), nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save host credential: %v", err)
+	}
+	return nil
+}
+
+// RemoveHostCredential 删除某个 host 的凭据
+func (ss *SkillsService) RemoveHostCredential(host string) error {
+	host = normalizeCredentialHost(host)
+	return updateHostCredentials(ss.env, func(creds []HostCredential) ([]HostCredential, error) {
+		result := make([]HostCredential, 0, len(creds))
+		for _, c := range creds {
+			if c.Host != host {
+				result = append(result, c)
+			}
+		}
+		if len(result) == len(creds) {
+			return nil, fmt.Errorf("no credential for host: %s", host)
+		}
+		return result, nil
+	})
+}
+
+// expandHome 展开路径开头的 ~/
+func (ss *SkillsService) expandHome(p string) string {
+	if strings.HasPrefix(p, "~/") {
+		return filepath.Join(ss.env.HomeDir, p[2:])
+	}
+	return p
+}
+
+// ---- 凭据查找与应用 ----
+
+// hostAuth 访问某个 URL 时使用的凭据，零值表示匿名访问
+type hostAuth struct {
+	scheme   string // https / http / ssh
+	host     string // host[:port]
+	username string
+	token    string
+	sshKey   string
+}
+
+// splitRepoURL 返回仓库地址的 scheme 与 host[:port]，git@host:path 视为 ssh，本地路径返回空
+func splitRepoURL(repoURL string) (string, string) {
+	if scpLikeGitURL.MatchString(repoURL) {
+		host := repoURL[strings.Index(repoURL, "@")+1:]
+		return "ssh", strings.ToLower(host[:strings.Index(host, ":")])
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host == "" {
+		return "", ""
+	}
+	return strings.ToLower(u.Scheme), strings.ToLower(u.Host)
+}
+
+// sameRepoURL 忽略大小写、末尾的 / 与 .git 比较两个仓库地址
+func sameRepoURL(a, b string) bool {
+	norm := func(s string) string {
+		return strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "/"), ".git")
+	}
+	return norm(a) == norm(b)
+}
+
+// hostAuth 查找访问 rawURL 所用的凭据
+func (ss *SkillsService) hostAuth(rawURL string) hostAuth {
+	scheme, host := splitRepoURL(rawURL)
+	auth := hostAuth{scheme: scheme, host: host}
+	if host == "" {
+		return auth
+	}
+
+	if sources, err := loadCustomSources(ss.env); err == nil {
+		for _, s := range sources {
+			if s.Token != "" && !isSecretRef(s.Token) && sameRepoURL(s.URL, rawURL) {
+				auth.token = s.Token
+				break
+			}
+		}
+	}
+
+	if creds, err := loadHostCredentials(ss.env); err == nil {
+		hostname := strings.Split(host, ":")[0]
+		var match *HostCredential
+		for i, c := range creds {
+			if c.Host == host {
+				match = &creds[i]
+				break
+			}
+			if c.Host == hostname && match == nil {
+				match = &creds[i]
+			}
+		}
+		if match != nil {
+			auth.username = match.Username
+			auth.sshKey = match.SSHKeyPath
+			if auth.token == "" && !isSecretRef(match.Token) {
+				auth.token = match.Token
+			}
+		}
+	}
+
+	if auth.token == "" && host == "github.com" {
+		for _, name := range githubTokenEnvVars {
+			if v := os.Getenv(name); v != "" {
+				auth.token = v
+				break
+			}
+		}
+	}
+	return auth
+}
+
+// gitUsername HTTPS 认证使用的用户名，未配置时 GitHub 使用 x-access-token，其余 host 使用 oauth2（GitLab / Gitea 均接受）
+func (a hostAuth) gitUsername() string {
+	if a.username != "" {
+		return a.username
+	}
+	if a.host == "github.com" {
+		return "x-access-token"
+	}
+	return "oauth2"
+}
+
+// gitEnv 返回运行 git 时追加的环境变量
+func (a hostAuth) gitEnv() []string {
+	var env []string
+	switch {
+	case a.scheme == "ssh" && a.sshKey != "":
+		key := strings.ReplaceAll(filepath.ToSlash(a.sshKey), "'", `'\''`)
+		env = append(env, "GIT_SSH_COMMAND=ssh -i '"+key+"' -o IdentitiesOnly=yes -o BatchMode=yes")
+	case (a.scheme == "https" || a.scheme == "http") && a.token != "":
+		// 先清空该 host 已有的 helper，再注册只在 get 时输出用户名和 token 的 helper
+		key := "credential." + a.scheme + "://" + a.host + ".helper"
+		helper := `!f() { test "$1" = get && printf 'username=%s\npassword=%s\n' "$AGENT_HUB_GIT_USERNAME" "$AGENT_HUB_GIT_TOKEN"; }; f`
+		env = append(env,
+			"GIT_CONFIG_COUNT=2",
+			"GIT_CONFIG_KEY_0="+key, "GIT_CONFIG_VALUE_0=",
+			"GIT_CONFIG_KEY_1="+key, "GIT_CONFIG_VALUE_1="+helper,
+			"AGENT_HUB_GIT_USERNAME="+a.gitUsername(),
+			"AGENT_HUB_GIT_TOKEN="+a.token,
+		)
+	default:
+		return nil
+	}
+	// 凭据无效时直接失败，不等待终端输入
+	return append(env, "GIT_TERMINAL_PROMPT=0")
+}
+
+// gitCommand 创建带有凭据的 git 命令
+func (a hostAuth) gitCommand(args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	if env := a.gitEnv(); env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	return cmd
+}
+
+// authorize 为 HTTP 请求设置 Authorization 头
+func (a hostAuth) authorize(req *http.Request) {
+	if a.token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	}
+}
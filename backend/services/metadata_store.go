@@ -0,0 +1,310 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ---- 元数据存储 ----
+// 标签、评分、活动日志与性能指标通过 MetadataStore 读写。
+// 默认使用配置目录下的 JSON 文件；设置中 storageBackend 为 bolt 时使用内嵌数据库 metadata.db，
+// 首次打开数据库时自动导入已有的 JSON 文件
+
+// 存储后端
+const (
+	StorageBackendJSON = "json"
+	StorageBackendBolt = "bolt"
+)
+
+// MetadataStore 元数据存储接口
+type MetadataStore interface {
+	// Backend 返回后端名称（StorageBackendJSON / StorageBackendBolt）
+	Backend() string
+	Info() (*MetadataStoreInfo, error)
+
+	SkillTags(skillName string) ([]string, error)
+	SetSkillTags(skillName string, tags []string) error // tags 为空时删除
+	AllSkillTags() (map[string][]string, error)         // skill name -> tags
+	SkillsWithTag(tag string) ([]string, error)
+
+	Rating(skillName string) (*SkillRating, error) // 未评分时返回 nil
+	SetRating(rating SkillRating) error
+	DeleteRating(skillName string) error
+	AllRatings() (map[string]SkillRating, error)
+
+	AddActivityLog(log ActivityLog) error
+	QueryActivityLogs(query ActivityLogQuery) (*ActivityLogPage, error)
+	ClearActivityLogs() error
+
+	LoadMetrics() (map[string]*PerformanceMetric, error)
+	SaveMetrics(metrics map[string]*PerformanceMetric) error
+	ClearMetrics() error
+}
+
+// MetadataStoreInfo 当前存储的位置与各类记录数
+type MetadataStoreInfo struct {
+	Backend      string `json:"backend"`
+	Path         string `json:"path"`                 // 数据库文件，JSON 后端为配置目录
+	ImportedAt   string `json:"importedAt,omitempty"` // 数据库最近一次导入 JSON 文件的时间
+	Tags         int    `json:"tags"`
+	Ratings      int    `json:"ratings"`
+	ActivityLogs int    `json:"activityLogs"`
+	Metrics      int    `json:"metrics"`
+}
+
+// ActivityLogQuery 活动日志查询条件，结果按时间倒序
+type ActivityLogQuery struct {
+	Offset    int    `json:"offset"`
+	Limit     int    `json:"limit"`     // 0 表示不限制
+	Action    string `json:"action"`    // 为空表示全部
+	SkillName string `json:"skillName"` // 为空表示全部
+	Since     string `json:"since"`     // RFC3339，包含
+	Until     string `json:"until"`     // RFC3339，不包含
+}
+
+// ActivityLogPage 活动日志分页结果
+type ActivityLogPage struct {
+	Logs  []ActivityLog `json:"logs"`
+	Total int           `json:"total"` // 符合条件的总条数
+}
+
+// activityLogFilter 编译后的查询条件
+type activityLogFilter struct {
+	action, skillName string
+	since, until      time.Time
+}
+
+func (q ActivityLogQuery) filter() (activityLogFilter, error) {
+	f := activityLogFilter{action: q.Action, skillName: q.SkillName}
+	var err error
+	if q.Since != "" {
+		if f.since, err = time.Parse(time.RFC3339, q.Since); err != nil {
+			return f, fmt.Errorf("invalid since: %v", err)
+		}
+	}
+	if q.Until != "" {
+		if f.until, err = time.Parse(time.RFC3339, q.Until); err != nil {
+			return f, fmt.Errorf("invalid until: %v", err)
+		}
+	}
+	return f, nil
+}
+
+// unfiltered 是否没有任何过滤条件
+func (f activityLogFilter) unfiltered() bool {
+	return f.action == "" && f.skillName == "" && f.since.IsZero() && f.until.IsZero()
+}
+
+func (f activityLogFilter) match(log ActivityLog) bool {
+	if f.action != "" && log.Action != f.action {
+		return false
+	}
+	if f.skillName != "" && log.SkillName != f.skillName {
+		return false
+	}
+	if !f.since.IsZero() || !f.until.IsZero() {
+		t, err := time.Parse(time.RFC3339, log.Timestamp)
+		if err != nil {
+			return false
+		}
+		if !f.since.IsZero() && t.Before(f.since) {
+			return false
+		}
+		if !f.until.IsZero() && !t.Before(f.until) {
+			return false
+		}
+	}
+	return true
+}
+
+// inPage 判断第 index 条（从 0 开始）符合条件的记录是否落在请求的页内
+func (q ActivityLogQuery) inPage(index int) bool {
+	return index >= q.Offset && (q.Limit <= 0 || index < q.Offset+q.Limit)
+}
+
+// metadataStore 按设置返回当前使用的存储
+func metadataStore(env *Environment) MetadataStore {
+	if loadAppSettings(env).StorageBackend == StorageBackendBolt {
+		return newBoltMetadataStore(env)
+	}
+	return jsonMetadataStore{env: env}
+}
+
+// ---- JSON 文件实现 ----
+
+// jsonMetadataStore 每类数据一个 JSON 文件（skill-tags.json、ratings.json、activity-log.json、performance-metrics.json）
+type jsonMetadataStore struct {
+	env *Environment
+}
+
+func (s jsonMetadataStore) Backend() string { return StorageBackendJSON }
+
+func (s jsonMetadataStore) Info() (*MetadataStoreInfo, error) {
+	info := &MetadataStoreInfo{Backend: StorageBackendJSON, Path: s.env.ConfigDir}
+	tags, err := s.AllSkillTags()
+	if err != nil {
+		return nil, err
+	}
+	ratings, err := s.AllRatings()
+	if err != nil {
+		return nil, err
+	}
+	logs, err := loadActivityLogs(s.env)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := s.LoadMetrics()
+	if err != nil {
+		return nil, err
+	}
+	info.Tags, info.Ratings, info.ActivityLogs, info.Metrics = len(tags), len(ratings), len(logs.Logs), len(metrics)
+	return info, nil
+}
+
+func (s jsonMetadataStore) SkillTags(skillName string) ([]string, error) {
+	config, err := loadSkillTags(s.env)
+	if err != nil {
+		return []string{}, err
+	}
+	tags, ok := config.Tags[skillName]
+	if !ok {
+		return []string{}, nil
+	}
+	return tags, nil
+}
+
+func (s jsonMetadataStore) SetSkillTags(skillName string, tags []string) error {
+	return updateSkillTags(s.env, func(config *SkillTagsConfig) error {
+		if len(tags) == 0 {
+			delete(config.Tags, skillName)
+		} else {
+			config.Tags[skillName] = tags
+		}
+		return nil
+	})
+}
+
+func (s jsonMetadataStore) AllSkillTags() (map[string][]string, error) {
+	config, err := loadSkillTags(s.env)
+	if err != nil {
+		return nil, err
+	}
+	return config.Tags, nil
+}
+
+func (s jsonMetadataStore) SkillsWithTag(tag string) ([]string, error) {
+	config, err := loadSkillTags(s.env)
+	if err != nil {
+		return nil, err
+	}
+	skills := []string{}
+	for skillName, tags := range config.Tags {
+		if contains(tags, tag) {
+			skills = append(skills, skillName)
+		}
+	}
+	sort.Strings(skills)
+	return skills, nil
+}
+
+func (s jsonMetadataStore) Rating(skillName string) (*SkillRating, error) {
+	config, err := loadRatings(s.env)
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := config.Ratings[skillName]; ok {
+		return &r, nil
+	}
+	return nil, nil
+}
+
+func (s jsonMetadataStore) SetRating(rating SkillRating) error {
+	return updateRatings(s.env, func(config *RatingsConfig) {
+		config.Ratings[rating.SkillName] = rating
+	})
+}
+
+func (s jsonMetadataStore) DeleteRating(skillName string) error {
+	return updateRatings(s.env, func(config *RatingsConfig) {
+		delete(config.Ratings, skillName)
+	})
+}
+
+func (s jsonMetadataStore) AllRatings() (map[string]SkillRating, error) {
+	config, err := loadRatings(s.env)
+	if err != nil {
+		return nil, err
+	}
+	return config.Ratings, nil
+}
+
+func (s jsonMetadataStore) AddActivityLog(log ActivityLog) error {
+	return updateActivityLogs(s.env, func(config *ActivityLogsConfig) {
+		config.Logs = append([]ActivityLog{log}, config.Logs...)
+	})
+}
+
+func (s jsonMetadataStore) QueryActivityLogs(query ActivityLogQuery) (*ActivityLogPage, error) {
+	filter, err := query.filter()
+	if err != nil {
+		return nil, err
+	}
+	config, err := loadActivityLogs(s.env)
+	if err != nil {
+		return nil, err
+	}
+	page := &ActivityLogPage{Logs: []ActivityLog{}}
+	for _, log := range config.Logs {
+		if !filter.match(log) {
+			continue
+		}
+		if query.inPage(page.Total) {
+			page.Logs = append(page.Logs, log)
+		}
+		page.Total++
+	}
+	return page, nil
+}
+
+func (s jsonMetadataStore) ClearActivityLogs() error {
+	return updateActivityLogs(s.env, func(config *ActivityLogsConfig) {
+		config.Logs = nil
+	})
+}
+
+func (s jsonMetadataStore) metricsFile() string {
+	return filepath.Join(s.env.ConfigDir, "performance-metrics.json")
+}
+
+func (s jsonMetadataStore) LoadMetrics() (map[string]*PerformanceMetric, error) {
+	metrics := make(map[string]*PerformanceMetric)
+	data, err := os.ReadFile(s.metricsFile())
+	if os.IsNotExist(err) {
+		return metrics, nil // 文件不存在，使用空指标
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metrics file: %v", err)
+	}
+	if err := json.Unmarshal(data, &metrics); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metrics: %v", err)
+	}
+	return metrics, nil
+}
+
+func (s jsonMetadataStore) SaveMetrics(metrics map[string]*PerformanceMetric) error {
+	if err := saveJSONConfig(s.metricsFile(), metrics); err != nil {
+		return fmt.Errorf("failed to write metrics file: %v", err)
+	}
+	return nil
+}
+
+func (s jsonMetadataStore) ClearMetrics() error {
+	if err := os.Remove(s.metricsFile()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove metrics file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,581 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ---- bbolt 实现 ----
+
+// metadataDBFile 内嵌数据库文件名（位于配置目录）
+const metadataDBFile = "metadata.db"
+
+// boltActivityLogLimit 数据库中保留的活动日志条数（JSON 文件只保留 200 条），每追加 100 条清理一次超出的旧日志
+const boltActivityLogLimit = 10000
+
+var (
+	bucketMeta          = []byte("meta")
+	bucketTags          = []byte("tags")           // skill -> JSON []string
+	bucketTagIndex      = []byte("tag-skills")     // tag \x00 skill -> 空
+	bucketRatings       = []byte("ratings")        // skill -> JSON SkillRating
+	bucketActivity      = []byte("activity")       // 8 字节大端序号 -> JSON ActivityLog
+	bucketActivityIndex = []byte("activity-skill") // skill \x00 序号 -> 空
+	bucketMetrics       = []byte("metrics")        // skill -> JSON PerformanceMetric
+
+	metadataBuckets = [][]byte{bucketMeta, bucketTags, bucketTagIndex, bucketRatings, bucketActivity, bucketActivityIndex, bucketMetrics}
+
+	// metaImportedAt meta 中记录最近一次导入 JSON 文件的时间
+	metaImportedAt = []byte("importedAt")
+)
+
+// boltMetadataStore 基于 bbolt 的元数据存储
+// 每次操作打开数据库、完成后立即关闭：bbolt 打开期间持有文件锁，常驻打开会让同时运行的命令行一直等待
+type boltMetadataStore struct {
+	env  *Environment
+	path string
+}
+
+func newBoltMetadataStore(env *Environment) *boltMetadataStore {
+	return &boltMetadataStore{env: env, path: filepath.Join(env.ConfigDir, metadataDBFile)}
+}
+
+func (s *boltMetadataStore) Backend() string { return StorageBackendBolt }
+
+func (s *boltMetadataStore) Info() (*MetadataStoreInfo, error) {
+	info := &MetadataStoreInfo{Backend: StorageBackendBolt, Path: s.path}
+	err := s.view(func(tx *bolt.Tx) error {
+		count := func(name []byte) int {
+			if b := tx.Bucket(name); b != nil {
+				return b.Stats().KeyN
+			}
+			return 0
+		}
+		info.Tags, info.Ratings, info.ActivityLogs, info.Metrics = count(bucketTags), count(bucketRatings), count(bucketActivity), count(bucketMetrics)
+		if meta := tx.Bucket(bucketMeta); meta != nil {
+			info.ImportedAt = string(meta.Get(metaImportedAt))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// open 打开数据库，首次打开时创建 bucket 并导入已有的 JSON 文件
+func (s *boltMetadataStore) open(readOnly bool) (*bolt.DB, error) {
+	if _, err := os.Stat(s.path); err != nil {
+		readOnly = false // 只读模式不能创建文件
+		if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+			return nil, err
+		}
+	}
+	db, err := bolt.Open(s.path, 0644, &bolt.Options{Timeout: fileLockTimeout, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", metadataDBFile, err)
+	}
+	if readOnly {
+		return db, nil
+	}
+
+	initialized := false
+	db.View(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(bucketMeta)
+		initialized = meta != nil && meta.Get(metaImportedAt) != nil
+		return nil
+	})
+	if initialized {
+		return db, nil
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range metadataBuckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		_, err := importJSONMetadata(s.env, tx)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize %s: %v", metadataDBFile, err)
+	}
+	return db, nil
+}
+
+func (s *boltMetadataStore) view(fn func(tx *bolt.Tx) error) error {
+	db, err := s.open(true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+func (s *boltMetadataStore) update(fn func(tx *bolt.Tx) error) error {
+	db, err := s.open(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(fn)
+}
+
+// getJSON 读取 key 对应的 JSON 值，bucket 或 key 不存在时返回 false
+func getJSON(b *bolt.Bucket, key string, v interface{}) (bool, error) {
+	if b == nil {
+		return false, nil
+	}
+	data := b.Get([]byte(key))
+	if data == nil {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func putJSON(b *bolt.Bucket, key []byte, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, data)
+}
+
+// resetBucket 清空 bucket
+func resetBucket(tx *bolt.Tx, name []byte) error {
+	if err := tx.DeleteBucket(name); err != nil && err != bolt.ErrBucketNotFound {
+		return err
+	}
+	_, err := tx.CreateBucket(name)
+	return err
+}
+
+// indexKey 拼接二级索引的 key：prefix \x00 suffix
+func indexKey(prefix string, suffix []byte) []byte {
+	key := make([]byte, 0, len(prefix)+1+len(suffix))
+	key = append(key, prefix...)
+	key = append(key, 0)
+	return append(key, suffix...)
+}
+
+func seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+// ---- 标签 ----
+
+func (s *boltMetadataStore) SkillTags(skillName string) ([]string, error) {
+	tags := []string{}
+	err := s.view(func(tx *bolt.Tx) error {
+		_, err := getJSON(tx.Bucket(bucketTags), skillName, &tags)
+		return err
+	})
+	return tags, err
+}
+
+func (s *boltMetadataStore) SetSkillTags(skillName string, tags []string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return setSkillTagsTx(tx, skillName, tags)
+	})
+}
+
+// setSkillTagsTx 写入标签并维护 tag -> skill 索引
+func setSkillTagsTx(tx *bolt.Tx, skillName string, tags []string) error {
+	b, index := tx.Bucket(bucketTags), tx.Bucket(bucketTagIndex)
+	var old []string
+	if _, err := getJSON(b, skillName, &old); err != nil {
+		return err
+	}
+	for _, tag := range old {
+		if err := index.Delete(indexKey(tag, []byte(skillName))); err != nil {
+			return err
+		}
+	}
+	if len(tags) == 0 {
+		return b.Delete([]byte(skillName))
+	}
+	for _, tag := range tags {
+		if err := index.Put(indexKey(tag, []byte(skillName)), []byte{}); err != nil {
+			return err
+		}
+	}
+	return putJSON(b, []byte(skillName), tags)
+}
+
+func (s *boltMetadataStore) AllSkillTags() (map[string][]string, error) {
+	result := make(map[string][]string)
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketTags)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var tags []string
+			if err := json.Unmarshal(v, &tags); err != nil {
+				return err
+			}
+			result[string(k)] = tags
+			return nil
+		})
+	})
+	return result, err
+}
+
+func (s *boltMetadataStore) SkillsWithTag(tag string) ([]string, error) {
+	skills := []string{}
+	err := s.view(func(tx *bolt.Tx) error {
+		index := tx.Bucket(bucketTagIndex)
+		if index == nil {
+			return nil
+		}
+		prefix := indexKey(tag, nil)
+		c := index.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			skills = append(skills, string(k[len(prefix):]))
+		}
+		return nil
+	})
+	return skills, err
+}
+
+// ---- 评分 ----
+
+func (s *boltMetadataStore) Rating(skillName string) (*SkillRating, error) {
+	var rating SkillRating
+	found := false
+	err := s.view(func(tx *bolt.Tx) error {
+		var err error
+		found, err = getJSON(tx.Bucket(bucketRatings), skillName, &rating)
+		return err
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+func (s *boltMetadataStore) SetRating(rating SkillRating) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(bucketRatings), []byte(rating.SkillName), rating)
+	})
+}
+
+func (s *boltMetadataStore) DeleteRating(skillName string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketRatings).Delete([]byte(skillName))
+	})
+}
+
+func (s *boltMetadataStore) AllRatings() (map[string]SkillRating, error) {
+	result := make(map[string]SkillRating)
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketRatings)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var rating SkillRating
+			if err := json.Unmarshal(v, &rating); err != nil {
+				return err
+			}
+			result[string(k)] = rating
+			return nil
+		})
+	})
+	return result, err
+}
+
+// ---- 活动日志 ----
+
+func (s *boltMetadataStore) AddActivityLog(log ActivityLog) error {
+	return s.update(func(tx *bolt.Tx) error {
+		seq, err := addActivityLogTx(tx, log)
+		if err != nil {
+			return err
+		}
+		// 每 100 条检查一次是否超出保留上限
+		if seq%100 == 0 {
+			return trimActivityLogsTx(tx, boltActivityLogLimit)
+		}
+		return nil
+	})
+}
+
+// addActivityLogTx 追加一条日志并维护 skill 索引，返回序号
+func addActivityLogTx(tx *bolt.Tx, log ActivityLog) (uint64, error) {
+	b := tx.Bucket(bucketActivity)
+	seq, err := b.NextSequence()
+	if err != nil {
+		return 0, err
+	}
+	key := seqKey(seq)
+	if err := putJSON(b, key, log); err != nil {
+		return 0, err
+	}
+	if log.SkillName != "" {
+		if err := tx.Bucket(bucketActivityIndex).Put(indexKey(log.SkillName, key), []byte{}); err != nil {
+			return 0, err
+		}
+	}
+	return seq, nil
+}
+
+// trimActivityLogsTx 删除超出 limit 的最旧日志
+func trimActivityLogsTx(tx *bolt.Tx, limit int) error {
+	b, index := tx.Bucket(bucketActivity), tx.Bucket(bucketActivityIndex)
+	excess := b.Stats().KeyN - limit
+	if excess <= 0 {
+		return nil
+	}
+	type entry struct {
+		key   []byte
+		skill string
+	}
+	var stale []entry
+	c := b.Cursor()
+	for k, v := c.First(); k != nil && len(stale) < excess; k, v = c.Next() {
+		var log ActivityLog
+		json.Unmarshal(v, &log)
+		stale = append(stale, entry{key: append([]byte{}, k...), skill: log.SkillName})
+	}
+	for _, e := range stale {
+		if err := b.Delete(e.key); err != nil {
+			return err
+		}
+		if e.skill != "" {
+			if err := index.Delete(indexKey(e.skill, e.key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *boltMetadataStore) QueryActivityLogs(query ActivityLogQuery) (*ActivityLogPage, error) {
+	filter, err := query.filter()
+	if err != nil {
+		return nil, err
+	}
+	page := &ActivityLogPage{Logs: []ActivityLog{}}
+	err = s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketActivity)
+		if b == nil {
+			return nil
+		}
+
+		// 没有过滤条件时总数直接取 bucket 统计，游标跳过 offset 条且不解码
+		if filter.unfiltered() {
+			page.Total = b.Stats().KeyN
+			c := b.Cursor()
+			i := 0
+			for k, v := c.Last(); k != nil && (query.Limit <= 0 || len(page.Logs) < query.Limit); k, v = c.Prev() {
+				if i >= query.Offset {
+					var log ActivityLog
+					if err := json.Unmarshal(v, &log); err != nil {
+						return err
+					}
+					page.Logs = append(page.Logs, log)
+				}
+				i++
+			}
+			return nil
+		}
+
+		collect := func(v []byte) error {
+			var log ActivityLog
+			if err := json.Unmarshal(v, &log); err != nil {
+				return err
+			}
+			if !filter.match(log) {
+				return nil
+			}
+			if query.inPage(page.Total) {
+				page.Logs = append(page.Logs, log)
+			}
+			page.Total++
+			return nil
+		}
+
+		// 按 skill 过滤时只遍历该 skill 的索引
+		if filter.skillName != "" {
+			index := tx.Bucket(bucketActivityIndex)
+			prefix := indexKey(filter.skillName, nil)
+			c := index.Cursor()
+			k, _ := c.Seek(append([]byte(filter.skillName), 1))
+			if k == nil {
+				k, _ = c.Last()
+			} else {
+				k, _ = c.Prev()
+			}
+			for ; k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Prev() {
+				if v := b.Get(k[len(prefix):]); v != nil {
+					if err := collect(v); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+
+		c := b.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			if err := collect(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (s *boltMetadataStore) ClearActivityLogs() error {
+	return s.update(func(tx *bolt.Tx) error {
+		if err := resetBucket(tx, bucketActivity); err != nil {
+			return err
+		}
+		return resetBucket(tx, bucketActivityIndex)
+	})
+}
+
+// ---- 性能指标 ----
+
+func (s *boltMetadataStore) LoadMetrics() (map[string]*PerformanceMetric, error) {
+	metrics := make(map[string]*PerformanceMetric)
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketMetrics)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var metric PerformanceMetric
+			if err := json.Unmarshal(v, &metric); err != nil {
+				return err
+			}
+			metrics[string(k)] = &metric
+			return nil
+		})
+	})
+	return metrics, err
+}
+
+func (s *boltMetadataStore) SaveMetrics(metrics map[string]*PerformanceMetric) error {
+	return s.update(func(tx *bolt.Tx) error {
+		if err := resetBucket(tx, bucketMetrics); err != nil {
+			return err
+		}
+		b := tx.Bucket(bucketMetrics)
+		for name, metric := range metrics {
+			if err := putJSON(b, []byte(name), metric); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *boltMetadataStore) ClearMetrics() error {
+	return s.update(func(tx *bolt.Tx) error {
+		return resetBucket(tx, bucketMetrics)
+	})
+}
+
+// ---- 从 JSON 文件导入 ----
+
+// MetadataImportResult 从 JSON 文件导入数据库的记录数
+type MetadataImportResult struct {
+	Tags         int `json:"tags"`
+	Ratings      int `json:"ratings"`
+	ActivityLogs int `json:"activityLogs"`
+	Metrics      int `json:"metrics"`
+}
+
+// importJSONMetadata 将 JSON 文件导入数据库：标签、评分、指标按 skill 覆盖，活动日志按 ID 去重后追加
+// 可重复执行，数据库中 JSON 文件里没有的记录保持不变
+func importJSONMetadata(env *Environment, tx *bolt.Tx) (*MetadataImportResult, error) {
+	source := jsonMetadataStore{env: env}
+	result := &MetadataImportResult{}
+
+	tags, err := source.AllSkillTags()
+	if err != nil {
+		return nil, err
+	}
+	for skillName, list := range tags {
+		if err := setSkillTagsTx(tx, skillName, list); err != nil {
+			return nil, err
+		}
+		result.Tags++
+	}
+
+	ratings, err := source.AllRatings()
+	if err != nil {
+		return nil, err
+	}
+	for skillName, rating := range ratings {
+		if err := putJSON(tx.Bucket(bucketRatings), []byte(skillName), rating); err != nil {
+			return nil, err
+		}
+		result.Ratings++
+	}
+
+	logs, err := source.QueryActivityLogs(ActivityLogQuery{})
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]bool{}
+	tx.Bucket(bucketActivity).ForEach(func(k, v []byte) error {
+		var log ActivityLog
+		if json.Unmarshal(v, &log) == nil {
+			existing[log.ID] = true
+		}
+		return nil
+	})
+	// JSON 文件中新日志在前，倒序遍历以按时间顺序追加
+	for i := len(logs.Logs) - 1; i >= 0; i-- {
+		log := logs.Logs[i]
+		if existing[log.ID] {
+			continue
+		}
+		if _, err := addActivityLogTx(tx, log); err != nil {
+			return nil, err
+		}
+		result.ActivityLogs++
+	}
+
+	metrics, err := source.LoadMetrics()
+	if err != nil {
+		return nil, err
+	}
+	for skillName, metric := range metrics {
+		if err := putJSON(tx.Bucket(bucketMetrics), []byte(skillName), metric); err != nil {
+			return nil, err
+		}
+		result.Metrics++
+	}
+
+	if err := tx.Bucket(bucketMeta).Put(metaImportedAt, []byte(time.Now().Format(time.RFC3339))); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ImportJSON 将 JSON 文件导入数据库（数据库不存在时创建）
+func (s *boltMetadataStore) ImportJSON() (*MetadataImportResult, error) {
+	var result *MetadataImportResult
+	err := s.update(func(tx *bolt.Tx) error {
+		var err error
+		result, err = importJSONMetadata(s.env, tx)
+		return err
+	})
+	return result, err
+}
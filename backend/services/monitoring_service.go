@@ -4,8 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sort"
 	"sync"
 	"time"
@@ -14,6 +12,7 @@ import (
 // MonitoringService 性能监控服务
 type MonitoringService struct {
 	ctx     context.Context
+	env     *Environment
 	metrics map[string]*PerformanceMetric
 	mutex   sync.RWMutex
 }
@@ -120,8 +119,9 @@ type ExecutionContext struct {
 	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
-func NewMonitoringService() *MonitoringService {
+func NewMonitoringService(env *Environment) *MonitoringService {
 	return &MonitoringService{
+		env:     env,
 		metrics: make(map[string]*PerformanceMetric),
 	}
 }
@@ -381,20 +381,8 @@ func (ms *MonitoringService) ClearMetrics() error {
 	
 	ms.metrics = make(map[string]*PerformanceMetric)
 	
-	// 删除持久化文件
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
-	metricsFile := filepath.Join(configDir, "performance-metrics.json")
-	
-	if err := os.Remove(metricsFile); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to remove metrics file: %w", err)
-	}
-	
-	return nil
+	// 删除持久化数据
+	return metadataStore(ms.env).ClearMetrics()
 }
 
 // ExportMetrics 导出指标数据
@@ -449,45 +437,18 @@ func (ms *MonitoringService) ImportMetrics(data []byte) error {
 
 // loadMetrics 加载指标数据
 func (ms *MonitoringService) loadMetrics() error {
-	homeDir, err := os.UserHomeDir()
+	metrics, err := metadataStore(ms.env).LoadMetrics()
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
-	metricsFile := filepath.Join(configDir, "performance-metrics.json")
-	
-	if _, err := os.Stat(metricsFile); os.IsNotExist(err) {
-		return nil // 文件不存在，使用空指标
-	}
-	
-	data, err := os.ReadFile(metricsFile)
-	if err != nil {
-		return fmt.Errorf("failed to read metrics file: %w", err)
-	}
-	
-	if err := json.Unmarshal(data, &ms.metrics); err != nil {
-		return fmt.Errorf("failed to unmarshal metrics: %w", err)
+		return err
 	}
 	
+	ms.metrics = metrics
 	return nil
 }
 
 // saveMetrics 保存指标数据
 func (ms *MonitoringService) saveMetrics() error {
-	homeDir, err := getCachedHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
-	}
-	
-	metricsFile := filepath.Join(configDir, "performance-metrics.json")
-	
-	// 在 RLock 内复制数据，RLock 外执行 JSON marshal 和写磁盘
+	// 在 RLock 内复制数据，RLock 外序列化和写入存储
 	ms.mutex.RLock()
 	metricsCopy := make(map[string]*PerformanceMetric, len(ms.metrics))
 	for k, v := range ms.metrics {
@@ -496,16 +457,7 @@ func (ms *MonitoringService) saveMetrics() error {
 	}
 	ms.mutex.RUnlock()
 	
-	data, err := json.MarshalIndent(metricsCopy, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal metrics: %w", err)
-	}
-	
-	if err := os.WriteFile(metricsFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write metrics file: %w", err)
-	}
-	
-	return nil
+	return metadataStore(ms.env).SaveMetrics(metricsCopy)
 }
 
 // startPeriodicSave 启动定期保存任务
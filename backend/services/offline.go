@@ -0,0 +1,375 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---- 离线模式与本地镜像 ----
+// MirrorSources 将远程源同步为克隆缓存中的常驻镜像，并记录镜像中的 skills 索引；
+// 离线模式下搜索、安装、更新检测只使用这些镜像，不访问网络
+
+// ownerRepoPattern 匹配 GitHub 的 owner/repo 简写
+var ownerRepoPattern = regexp.MustCompile(`^[\w.-]+/[\w.-]+$`)
+
+// MirrorSource 一个已镜像的源
+type MirrorSource struct {
+	Source    string        `json:"source"` // owner/repo、自定义源名称或 git URL
+	URL       string        `json:"url"`
+	CommitSHA string        `json:"commitSha"` // 同步时默认分支的 commit
+	SyncedAt  string        `json:"syncedAt"`
+	Skills    []MirrorSkill `json:"skills"`
+}
+
+// MirrorSkill 镜像中的一个 skill
+type MirrorSkill struct {
+	Name        string `json:"name"`
+	FullName    string `json:"fullName"`
+	Description string `json:"description"`
+}
+
+// MirrorSyncResult 单个源的同步结果
+type MirrorSyncResult struct {
+	Source    string `json:"source"`
+	URL       string `json:"url"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+	CommitSHA string `json:"commitSha,omitempty"`
+	Skills    int    `json:"skills"`
+}
+
+// mirrorIndex mirrors.json 文件结构
+type mirrorIndex struct {
+	Sources []MirrorSource `json:"sources"`
+}
+
+// IsOfflineMode 是否处于离线模式（命令行 / 环境变量强制，或设置中开启）
+func (ss *SkillsService) IsOfflineMode() bool {
+	if ss.env.Offline {
+		return true
+	}
+	settings, err := ss.GetSettings()
+	return err == nil && settings.OfflineMode
+}
+
+func (ss *SkillsService) loadMirrorIndex() mirrorIndex {
+	index := mirrorIndex{Sources: []MirrorSource{}}
+	filePath, err := ss.env.configFilePath("mirrors.json")
+	if err != nil {
+		return index
+	}
+	if data, err := os.ReadFile(filePath); err == nil {
+		json.Unmarshal(data, &index)
+	}
+	if index.Sources == nil {
+		index.Sources = []MirrorSource{}
+	}
+	return index
+}
+
+// updateMirrorIndex 在文件锁保护下读取、修改并写回 mirrors.json，fn 返回错误时不写入
+func (ss *SkillsService) updateMirrorIndex(fn func(index *mirrorIndex) error) error {
+	filePath, err := ss.env.configFilePath("mirrors.json")
+	if err != nil {
+		return err
+	}
+	return withFileLock(filePath, func() error {
+		index := ss.loadMirrorIndex()
+		if err := fn(&index); err != nil {
+			return err
+		}
+		return writeJSONFile(filePath, index)
+	})
+}
+
+// resolveMirrorSource 将源描述解析为仓库 URL：自定义源名称、owner/repo 简写或 git URL
+// 本地目录与压缩包不需要镜像
+func (ss *SkillsService) resolveMirrorSource(source string) (string, error) {
+	src, _, err := ss.resolveSkillSource(source, "")
+	if err != nil {
+		return "", err
+	}
+	if !src.isGit() {
+		return "", fmt.Errorf("%s is not a git source", source)
+	}
+	return src.URL, nil
+}
+
+// defaultMirrorSources 未指定源时镜像 .skills-lock 中的所有远程源和全部自定义源
+func (ss *SkillsService) defaultMirrorSources() []string {
+	seen := map[string]bool{}
+	var sources []string
+	add := func(s string) {
+		if s != "" && s != "local" && !seen[s] {
+			seen[s] = true
+			sources = append(sources, s)
+		}
+	}
+	if lock, err := ss.loadSkillsLock(); err == nil {
+		for _, entry := range lock.Skills {
+			if src, err := ss.entrySource(entry); err == nil && src.isGit() {
+				add(entry.Source)
+			}
+		}
+	}
+	sort.Strings(sources)
+	if custom, err := loadCustomSources(ss.env); err == nil {
+		for _, s := range custom {
+			add(s.Name)
+		}
+	}
+	return sources
+}
+
+// MirrorSources 同步源到本地镜像（需要网络），sources 为空时镜像已安装 skills 的来源与全部自定义源
+// 单个源失败不影响其他源，结果中逐项返回
+func (ss *SkillsService) MirrorSources(sources []string) ([]MirrorSyncResult, error) {
+	if len(sources) == 0 {
+		sources = ss.defaultMirrorSources()
+	}
+	results := make([]MirrorSyncResult, 0, len(sources))
+	synced := []MirrorSource{}
+	for _, source := range sources {
+		result := MirrorSyncResult{Source: source}
+		mirror, err := ss.syncMirrorSource(source)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.OK = true
+		result.URL = mirror.URL
+		result.CommitSHA = mirror.CommitSHA
+		result.Skills = len(mirror.Skills)
+		results = append(results, result)
+		synced = append(synced, *mirror)
+	}
+
+	// 同步需要网络，完成后再加锁合并到索引，避免长时间持有文件锁
+	if err := ss.updateMirrorIndex(func(index *mirrorIndex) error {
+		for _, mirror := range synced {
+			replaced := false
+			for i := range index.Sources {
+				if index.Sources[i].Source == mirror.Source {
+					index.Sources[i] = mirror
+					replaced = true
+				}
+			}
+			if !replaced {
+				index.Sources = append(index.Sources, mirror)
+			}
+		}
+		return nil
+	}); err != nil {
+		return results, fmt.Errorf("failed to save mirror index: %v", err)
+	}
+	return results, nil
+}
+
+// syncMirrorSource 克隆或 fetch 单个源，并扫描默认分支上的 skills
+func (ss *SkillsService) syncMirrorSource(source string) (*MirrorSource, error) {
+	repoURL, err := ss.resolveMirrorSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	keyDir := ss.cloneCacheKeyDir(repoURL)
+	repoDir := filepath.Join(keyDir, "repo.git")
+	auth := ss.hostAuth(repoURL)
+	var out []byte
+	err = withCloneCacheLock(keyDir, func() error {
+		var err error
+		if _, statErr := os.Stat(repoDir); statErr != nil {
+			out, err = cloneCacheRepo(repoURL, keyDir, auth)
+		} else {
+			out, err = fetchCacheRepo(repoDir, auth)
+		}
+		if err == nil {
+			now := time.Now()
+			writeCloneCacheMeta(keyDir, cloneCacheMeta{URL: repoURL, LastFetched: now, LastUsed: now, Mirrored: true})
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to mirror %s: %v\n%s", repoURL, err, strings.TrimSpace(string(out)))
+	}
+
+	tempDir, err := os.MkdirTemp("", "skills-mirror-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+	workDir := filepath.Join(tempDir, "repo")
+	if out, err := ss.checkoutRepo(repoURL, workDir, ""); err != nil {
+		return nil, fmt.Errorf("failed to check out %s: %v\n%s", repoURL, err, strings.TrimSpace(string(out)))
+	}
+
+	ownerRepo := source
+	if ownerRepoPattern.MatchString(source) {
+		ownerRepo = strings.TrimSuffix(source, ".git")
+	}
+	repoName := strings.TrimSuffix(filepath.Base(strings.TrimSuffix(repoURL, "/")), ".git")
+	mirror := &MirrorSource{
+		Source:    source,
+		URL:       repoURL,
+		CommitSHA: gitHeadSHA(workDir),
+		SyncedAt:  time.Now().Format(time.RFC3339),
+		Skills:    []MirrorSkill{},
+	}
+	for _, s := range scanRepoSkills(workDir, ownerRepo, repoName) {
+		mirror.Skills = append(mirror.Skills, MirrorSkill{Name: s.Name, FullName: s.FullName, Description: s.Desc})
+	}
+	return mirror, nil
+}
+
+// GetMirrors 返回已镜像的源
+func (ss *SkillsService) GetMirrors() ([]MirrorSource, error) {
+	return ss.loadMirrorIndex().Sources, nil
+}
+
+// RemoveMirror 删除源的本地镜像
+func (ss *SkillsService) RemoveMirror(source string) error {
+	return ss.updateMirrorIndex(func(index *mirrorIndex) error {
+		kept := make([]MirrorSource, 0, len(index.Sources))
+		var removed *MirrorSource
+		for i, m := range index.Sources {
+			if m.Source == source {
+				removed = &index.Sources[i]
+				continue
+			}
+			kept = append(kept, m)
+		}
+		if removed == nil {
+			return fmt.Errorf("mirror not found: %s", source)
+		}
+
+		keyDir := ss.cloneCacheKeyDir(removed.URL)
+		err := withCloneCacheLock(keyDir, func() error {
+			return os.RemoveAll(keyDir)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to remove mirror: %v", err)
+		}
+		index.Sources = kept
+		return nil
+	})
+}
+
+// searchMirrors 在本地镜像索引中按名称、描述和来源搜索 skills
+func (ss *SkillsService) searchMirrors(query string) []RemoteSkill {
+	query = strings.ToLower(strings.TrimSpace(query))
+	installed := map[string]SkillLockEntry{}
+	if lock, err := ss.loadSkillsLock(); err == nil {
+		installed = lock.Skills
+	}
+
+	skills := []RemoteSkill{}
+	for _, m := range ss.loadMirrorIndex().Sources {
+		owner, repo := "", ""
+		if parts := strings.SplitN(m.Source, "/", 2); len(parts) == 2 && ownerRepoPattern.MatchString(m.Source) {
+			owner, repo = parts[0], parts[1]
+		}
+		for _, s := range m.Skills {
+			haystack := strings.ToLower(s.Name + "\n" + s.Description + "\n" + m.Source)
+			if query != "" && !strings.Contains(haystack, query) {
+				continue
+			}
+			entry, ok := installed[s.Name]
+			skills = append(skills, RemoteSkill{
+				FullName:    s.FullName,
+				Owner:       owner,
+				Repo:        repo,
+				Name:        s.Name,
+				Source:      m.Source,
+				Description: s.Description,
+				Installed:   ok && entry.Source == m.Source,
+				Offline:     true,
+			})
+		}
+	}
+	return skills
+}
+
+// hasMirrors 是否存在本地镜像
+func (ss *SkillsService) hasMirrors() bool {
+	return len(ss.loadMirrorIndex().Sources) > 0
+}
+
+// checkSkillUpdatesOffline 离线模式下基于本地镜像检测更新：
+// 镜像中 ref 对应的 commit 与安装时不同，且 skill 内容哈希发生变化时视为有更新
+func (ss *SkillsService) checkSkillUpdatesOffline(lock SkillsLock) []SkillUpdateInfo {
+	results := []SkillUpdateInfo{}
+	for name, e := range lock.Skills {
+		if e.Source == "" || e.Source == "local" {
+			continue
+		}
+		src, err := ss.entrySource(e)
+		if err == nil && !src.isGit() {
+			// 本地目录与本地压缩包离线时也能检测，下载地址的压缩包会返回错误
+			results = append(results, ss.checkSourceUpdate(name, e))
+			continue
+		}
+		info := SkillUpdateInfo{
+			Name:       name,
+			Source:     e.Source,
+			CurrentSHA: e.CommitSHA,
+			Ref:        e.Ref,
+			Offline:    true,
+		}
+		info.LocalModified = ss.verifySkill(name, e).mayBeModified()
+		if err != nil {
+			info.Error = err.Error()
+			results = append(results, info)
+			continue
+		}
+
+		repoURL := src.URL
+		repoDir := filepath.Join(ss.cloneCacheKeyDir(repoURL), "repo.git")
+		if _, err := os.Stat(repoDir); err != nil {
+			info.Error = "source is not mirrored locally"
+			results = append(results, info)
+			continue
+		}
+		latest, err := resolveCachedRef(repoDir, e.Ref)
+		if err != nil {
+			info.Error = err.Error()
+			results = append(results, info)
+			continue
+		}
+		info.LatestSHA = latest
+		if latest != e.CommitSHA {
+			info.HasUpdate = ss.mirroredSkillChanged(repoURL, latest, name, e.TreeHash)
+		}
+		results = append(results, info)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
+
+// mirroredSkillChanged 检出镜像中的指定 commit，对比 skill 内容哈希是否与安装时不同
+func (ss *SkillsService) mirroredSkillChanged(repoURL, sha, skillName, treeHash string) bool {
+	if treeHash == "" {
+		return true
+	}
+	tempDir, err := os.MkdirTemp("", "skills-mirror-")
+	if err != nil {
+		return false
+	}
+	defer os.RemoveAll(tempDir)
+	workDir := filepath.Join(tempDir, "repo")
+	if _, err := ss.checkoutRepo(repoURL, workDir, sha); err != nil {
+		return false
+	}
+	skillDir := findSkillInRepo(workDir, skillName)
+	if skillDir == "" {
+		return false
+	}
+	hash, _, err := hashSkillTree(skillDir)
+	return err == nil && hash != treeHash
+}
@@ -5,18 +5,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"time"
 )
 
 // ProfileService 管理 Agent 配置方案
 type ProfileService struct {
 	ctx           context.Context
+	env           *Environment
 	skillsService *SkillsService
 }
 
-func NewProfileService(ss *SkillsService) *ProfileService {
-	return &ProfileService{skillsService: ss}
+func NewProfileService(env *Environment, ss *SkillsService) *ProfileService {
+	return &ProfileService{env: env, skillsService: ss}
 }
 
 func (ps *ProfileService) Startup(ctx context.Context) {
@@ -38,16 +38,12 @@ type ProfilesConfig struct {
 	Active   string    `json:"active"` // 当前激活的方案名
 }
 
-func getProfilesFilePath() (string, error) {
-	configDir, err := getConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "profiles.json"), nil
+func getProfilesFilePath(env *Environment) (string, error) {
+	return env.configFilePath("profiles.json")
 }
 
-func loadProfiles() (ProfilesConfig, error) {
-	filePath, err := getProfilesFilePath()
+func loadProfiles(env *Environment) (ProfilesConfig, error) {
+	filePath, err := getProfilesFilePath(env)
 	if err != nil {
 		return ProfilesConfig{}, err
 	}
@@ -62,21 +58,40 @@ func loadProfiles() (ProfilesConfig, error) {
 	return config, nil
 }
 
-func saveProfiles(config ProfilesConfig) error {
-	filePath, err := getProfilesFilePath()
+// updateProfiles 在文件锁保护下读取、修改并写回配置方案，fn 返回错误时不写入
+func updateProfiles(env *Environment, fn func(config *ProfilesConfig) error) error {
+	filePath, err := getProfilesFilePath(env)
 	if err != nil {
 		return err
 	}
-	data, err := json.MarshalIndent(config, "", "  ")
+	return withFileLock(filePath, func() error {
+		config, _ := loadProfiles(env)
+		if err := fn(&config); err != nil {
+			return err
+		}
+		return writeJSONFile(filePath, config)
+	})
+}
+
+// snapshotAgentSkills 返回当前所有 agent 链接的 skill
+func (ps *ProfileService) snapshotAgentSkills() (map[string][]string, error) {
+	skills, err := ps.skillsService.GetAllAgentSkills()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return os.WriteFile(filePath, data, 0644)
+
+	agentSkills := make(map[string][]string)
+	for _, skill := range skills {
+		for _, agent := range skill.Agents {
+			agentSkills[agent] = append(agentSkills[agent], skill.Name)
+		}
+	}
+	return agentSkills, nil
 }
 
 // GetProfiles 获取所有配置方案
 func (ps *ProfileService) GetProfiles() (ProfilesConfig, error) {
-	return loadProfiles()
+	return loadProfiles(ps.env)
 }
 
 // SaveCurrentAsProfile 将当前的 agent-skill 链接状态保存为配置方案
@@ -85,48 +100,37 @@ func (ps *ProfileService) SaveCurrentAsProfile(name string, description string)
 		return fmt.Errorf("profile name is required")
 	}
 
-	config, err := loadProfiles()
-	if err != nil {
-		return err
-	}
-
-	// 检查重名
-	for _, p := range config.Profiles {
-		if p.Name == name {
-			return fmt.Errorf("profile already exists: %s", name)
-		}
-	}
-
 	// 获取当前所有 skill 的 agent 链接状态
-	skills, err := ps.skillsService.GetAllAgentSkills()
+	agentSkills, err := ps.snapshotAgentSkills()
 	if err != nil {
 		return err
 	}
 
-	agentSkills := make(map[string][]string)
-	for _, skill := range skills {
-		for _, agent := range skill.Agents {
-			agentSkills[agent] = append(agentSkills[agent], skill.Name)
+	return updateProfiles(ps.env, func(config *ProfilesConfig) error {
+		// 检查重名
+		for _, p := range config.Profiles {
+			if p.Name == name {
+				return fmt.Errorf("profile already exists: %s", name)
+			}
 		}
-	}
 
-	now := time.Now().Format(time.RFC3339)
-	config.Profiles = append(config.Profiles, Profile{
-		Name:        name,
-		Description: description,
-		AgentSkills: agentSkills,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		now := time.Now().Format(time.RFC3339)
+		config.Profiles = append(config.Profiles, Profile{
+			Name:        name,
+			Description: description,
+			AgentSkills: agentSkills,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		return nil
 	})
-
-	return saveProfiles(config)
 }
 
-// ApplyProfile 应用配置方案 - 重新配置所有 agent-skill 链接
-func (ps *ProfileService) ApplyProfile(name string) error {
-	config, err := loadProfiles()
+// profileSkillAgents 查找配置方案并反转为 skill -> agents 映射
+func (ps *ProfileService) profileSkillAgents(name string) (map[string][]string, error) {
+	config, err := loadProfiles(ps.env)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var profile *Profile
@@ -137,7 +141,7 @@ func (ps *ProfileService) ApplyProfile(name string) error {
 		}
 	}
 	if profile == nil {
-		return fmt.Errorf("profile not found: %s", name)
+		return nil, fmt.Errorf("profile not found: %s", name)
 	}
 
 	// 反转映射: skill -> agents
@@ -147,6 +151,15 @@ func (ps *ProfileService) ApplyProfile(name string) error {
 			skillAgents[skill] = append(skillAgents[skill], agent)
 		}
 	}
+	return skillAgents, nil
+}
+
+// ApplyProfile 应用配置方案 - 重新配置所有 agent-skill 链接
+func (ps *ProfileService) ApplyProfile(name string) error {
+	skillAgents, err := ps.profileSkillAgents(name)
+	if err != nil {
+		return err
+	}
 
 	// 获取当前所有 skill
 	allSkills, err := ps.skillsService.GetAllAgentSkills()
@@ -164,67 +177,68 @@ func (ps *ProfileService) ApplyProfile(name string) error {
 	}
 
 	// 更新激活状态
-	config.Active = name
-	return saveProfiles(config)
+	return updateProfiles(ps.env, func(config *ProfilesConfig) error {
+		config.Active = name
+		return nil
+	})
 }
 
-// DeleteProfile 删除配置方案
-func (ps *ProfileService) DeleteProfile(name string) error {
-	config, err := loadProfiles()
+// PlanApplyProfile 预览 ApplyProfile 将要增删的 agent 软链接
+func (ps *ProfileService) PlanApplyProfile(name string) (*ChangePlan, error) {
+	skillAgents, err := ps.profileSkillAgents(name)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	found := false
-	result := make([]Profile, 0, len(config.Profiles))
-	for _, p := range config.Profiles {
-		if p.Name == name {
-			found = true
-			continue
-		}
-		result = append(result, p)
-	}
-	if !found {
-		return fmt.Errorf("profile not found: %s", name)
+	allSkills, err := ps.skillsService.GetAllAgentSkills()
+	if err != nil {
+		return nil, err
 	}
-	config.Profiles = result
-	if config.Active == name {
-		config.Active = ""
+
+	plan := NewChangePlan("apply-profile")
+	for _, skill := range allSkills {
+		ps.skillsService.planSyncLinks(plan, skill.Name, skillAgents[skill.Name])
 	}
-	return saveProfiles(config)
+	return plan, nil
+}
+
+// DeleteProfile 删除配置方案
+func (ps *ProfileService) DeleteProfile(name string) error {
+	return updateProfiles(ps.env, func(config *ProfilesConfig) error {
+		found := false
+		result := make([]Profile, 0, len(config.Profiles))
+		for _, p := range config.Profiles {
+			if p.Name == name {
+				found = true
+				continue
+			}
+			result = append(result, p)
+		}
+		if !found {
+			return fmt.Errorf("profile not found: %s", name)
+		}
+		config.Profiles = result
+		if config.Active == name {
+			config.Active = ""
+		}
+		return nil
+	})
 }
 
 // UpdateProfile 更新配置方案（重新快照当前状态）
 func (ps *ProfileService) UpdateProfile(name string) error {
-	config, err := loadProfiles()
+	agentSkills, err := ps.snapshotAgentSkills()
 	if err != nil {
 		return err
 	}
 
-	idx := -1
-	for i, p := range config.Profiles {
-		if p.Name == name {
-			idx = i
-			break
+	return updateProfiles(ps.env, func(config *ProfilesConfig) error {
+		for i, p := range config.Profiles {
+			if p.Name == name {
+				config.Profiles[i].AgentSkills = agentSkills
+				config.Profiles[i].UpdatedAt = time.Now().Format(time.RFC3339)
+				return nil
+			}
 		}
-	}
-	if idx == -1 {
 		return fmt.Errorf("profile not found: %s", name)
-	}
-
-	skills, err := ps.skillsService.GetAllAgentSkills()
-	if err != nil {
-		return err
-	}
-
-	agentSkills := make(map[string][]string)
-	for _, skill := range skills {
-		for _, agent := range skill.Agents {
-			agentSkills[agent] = append(agentSkills[agent], skill.Name)
-		}
-	}
-
-	config.Profiles[idx].AgentSkills = agentSkills
-	config.Profiles[idx].UpdatedAt = time.Now().Format(time.RFC3339)
-	return saveProfiles(config)
+	})
 }
@@ -18,7 +18,7 @@ type ProviderConfig struct {
 	ID        string            `json:"id"`
 	Name      string            `json:"name"`
 	AppType   string            `json:"appType"`
-	APIKey    string            `json:"apiKey"`
+	APIKey    string            `json:"apiKey"` // providers.json 中保存密钥引用（见 sealSecret），内存中为解析后的值
 	BaseURL   string            `json:"baseUrl"`
 	Models    map[string]string `json:"models"`
 	Note      string            `json:"note"`
@@ -43,15 +43,27 @@ type ProviderTestResult struct {
 	Error   string `json:"error"`
 }
 
+// providersExport 导出文件结构，secretEncryption 仅在加密导出时出现
+type providersExport struct {
+	ProvidersData
+	SecretEncryption *SecretEncryption `json:"secretEncryption,omitempty"`
+}
+
 // ProviderService 供应商配置管理服务
 type ProviderService struct {
 	ctx  context.Context
+	env  *Environment
 	mu   sync.RWMutex
 	data ProvidersData
+	// plaintextKeys providers.json 中仍有明文 API Key（旧版本写入），启动时迁移到密钥存储
+	plaintextKeys bool
+	// lockedKeys 有 API Key 暂时无法从密钥存储读取（vault 未解锁），读取列表时重试
+	lockedKeys bool
 }
 
-func NewProviderService() *ProviderService {
+func NewProviderService(env *Environment) *ProviderService {
 	return &ProviderService{
+		env: env,
 		data: ProvidersData{
 			Providers: []ProviderConfig{},
 			ActiveMap: map[string]string{},
@@ -62,16 +74,15 @@ func NewProviderService() *ProviderService {
 func (ps *ProviderService) Startup(ctx context.Context) {
 	ps.ctx = ctx
 	ps.loadData()
+	if ps.plaintextKeys {
+		ps.updateData(func(data *ProvidersData) error { return nil })
+	}
 }
 
 // --- Data persistence ---
 
 func (ps *ProviderService) dataFilePath() (string, error) {
-	configDir, err := getConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "providers.json"), nil
+	return ps.env.configFilePath("providers.json")
 }
 
 func (ps *ProviderService) loadData() {
@@ -93,29 +104,89 @@ func (ps *ProviderService) loadData() {
 	if data.ActiveMap == nil {
 		data.ActiveMap = map[string]string{}
 	}
+	plaintext, locked := false, false
+	for i, p := range data.Providers {
+		if p.APIKey != "" && !isSecretRef(p.APIKey) {
+			plaintext = true
+		}
+		data.Providers[i].APIKey = openSecretOrRef(ps.env, p.APIKey)
+		if isSecretRef(data.Providers[i].APIKey) {
+			locked = true
+		}
+	}
 	ps.mu.Lock()
 	ps.data = data
+	ps.plaintextKeys, ps.lockedKeys = plaintext, locked
 	ps.mu.Unlock()
 }
 
-func (ps *ProviderService) saveData() error {
+// providerSecretKey API Key 在密钥存储中的名称
+func providerSecretKey(id string) string {
+	return "provider/" + id + "/apiKey"
+}
+
+// updateData 在文件锁保护下重新读取 providers.json、执行 fn 并原子写回
+// 重新读取使其他进程的修改不会被内存中的旧数据覆盖；fn 返回错误时不写入
+func (ps *ProviderService) updateData(fn func(data *ProvidersData) error) error {
 	fp, err := ps.dataFilePath()
 	if err != nil {
 		return err
 	}
-	ps.mu.RLock()
-	raw, err := json.MarshalIndent(ps.data, "", "  ")
-	ps.mu.RUnlock()
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(fp, raw, 0644)
+	return withFileLock(fp, func() error {
+		// 文件中原有的密钥引用，用于删除已移除或已清空的 API Key
+		prevKeys := map[string]string{}
+		if raw, err := os.ReadFile(fp); err == nil {
+			var onDisk ProvidersData
+			if json.Unmarshal(raw, &onDisk) == nil {
+				for _, p := range onDisk.Providers {
+					prevKeys[p.ID] = p.APIKey
+				}
+			}
+		}
+		ps.loadData()
+		ps.mu.Lock()
+		defer ps.mu.Unlock()
+		removed := map[string]bool{}
+		for _, p := range ps.data.Providers {
+			removed[p.ID] = true
+		}
+		if err := fn(&ps.data); err != nil {
+			return err
+		}
+		// 文件中只写入密钥引用，已删除供应商的密钥一并清除
+		onDisk := ps.data
+		onDisk.Providers = make([]ProviderConfig, len(ps.data.Providers))
+		for i, p := range ps.data.Providers {
+			delete(removed, p.ID)
+			ref, err := sealSecret(ps.env, providerSecretKey(p.ID), p.APIKey, prevKeys[p.ID])
+			if err != nil {
+				return err
+			}
+			p.APIKey = ref
+			onDisk.Providers[i] = p
+		}
+		for id := range removed {
+			dropSecret(ps.env, prevKeys[id])
+		}
+		if err := writeJSONFile(fp, onDisk); err != nil {
+			return err
+		}
+		ps.plaintextKeys = false
+		return nil
+	})
 }
 
 // --- CRUD ---
 
 // GetAllProviders 返回所有供应商配置
 func (ps *ProviderService) GetAllProviders() ProvidersData {
+	ps.mu.RLock()
+	locked := ps.lockedKeys
+	ps.mu.RUnlock()
+	if locked {
+		// vault 可能已在其他页面解锁
+		ps.loadData()
+	}
 	ps.mu.RLock()
 	defer ps.mu.RUnlock()
 	return ps.data
@@ -138,11 +209,10 @@ func (ps *ProviderService) AddProvider(configJSON string) (ProviderConfig, error
 		cfg.Models = map[string]string{}
 	}
 
-	ps.mu.Lock()
-	ps.data.Providers = append(ps.data.Providers, cfg)
-	ps.mu.Unlock()
-
-	if err := ps.saveData(); err != nil {
+	if err := ps.updateData(func(data *ProvidersData) error {
+		data.Providers = append(data.Providers, cfg)
+		return nil
+	}); err != nil {
 		return ProviderConfig{}, fmt.Errorf("failed to save: %w", err)
 	}
 	return cfg, nil
@@ -155,45 +225,39 @@ func (ps *ProviderService) UpdateProvider(configJSON string) error {
 		return fmt.Errorf("invalid config: %w", err)
 	}
 
-	ps.mu.Lock()
-	found := false
-	for i, p := range ps.data.Providers {
-		if p.ID == cfg.ID {
-			cfg.CreatedAt = p.CreatedAt
-			cfg.UpdatedAt = time.Now().Format(time.RFC3339)
-			if cfg.Models == nil {
-				cfg.Models = map[string]string{}
+	return ps.updateData(func(data *ProvidersData) error {
+		for i, p := range data.Providers {
+			if p.ID == cfg.ID {
+				cfg.CreatedAt = p.CreatedAt
+				cfg.UpdatedAt = time.Now().Format(time.RFC3339)
+				if cfg.Models == nil {
+					cfg.Models = map[string]string{}
+				}
+				data.Providers[i] = cfg
+				return nil
 			}
-			ps.data.Providers[i] = cfg
-			found = true
-			break
 		}
-	}
-	ps.mu.Unlock()
-
-	if !found {
 		return fmt.Errorf("provider not found: %s", cfg.ID)
-	}
-	return ps.saveData()
+	})
 }
 
 // DeleteProvider 删除供应商配置
 func (ps *ProviderService) DeleteProvider(id string) error {
-	ps.mu.Lock()
-	newList := make([]ProviderConfig, 0, len(ps.data.Providers))
-	for _, p := range ps.data.Providers {
-		if p.ID != id {
-			newList = append(newList, p)
+	return ps.updateData(func(data *ProvidersData) error {
+		newList := make([]ProviderConfig, 0, len(data.Providers))
+		for _, p := range data.Providers {
+			if p.ID != id {
+				newList = append(newList, p)
+			}
 		}
-	}
-	ps.data.Providers = newList
-	for k, v := range ps.data.ActiveMap {
-		if v == id {
-			delete(ps.data.ActiveMap, k)
+		data.Providers = newList
+		for k, v := range data.ActiveMap {
+			if v == id {
+				delete(data.ActiveMap, k)
+			}
 		}
-	}
-	ps.mu.Unlock()
-	return ps.saveData()
+		return nil
+	})
 }
 
 // --- Switch / Activate ---
@@ -214,6 +278,13 @@ func (ps *ProviderService) SwitchProvider(id string) error {
 	if target == nil {
 		return fmt.Errorf("provider not found: %s", id)
 	}
+	if isSecretRef(target.APIKey) {
+		key, err := openSecret(ps.env, target.APIKey)
+		if err != nil {
+			return fmt.Errorf("failed to read API key: %w", err)
+		}
+		target.APIKey = key
+	}
 
 	var err error
 	switch target.AppType {
@@ -235,18 +306,18 @@ func (ps *ProviderService) SwitchProvider(id string) error {
 		return err
 	}
 
-	ps.mu.Lock()
-	ps.data.ActiveMap[target.AppType] = id
-	ps.mu.Unlock()
-	return ps.saveData()
+	return ps.updateData(func(data *ProvidersData) error {
+		data.ActiveMap[target.AppType] = id
+		return nil
+	})
 }
 
 // DeactivateProvider 取消激活
 func (ps *ProviderService) DeactivateProvider(appType string) error {
-	ps.mu.Lock()
-	delete(ps.data.ActiveMap, appType)
-	ps.mu.Unlock()
-	return ps.saveData()
+	return ps.updateData(func(data *ProvidersData) error {
+		delete(data.ActiveMap, appType)
+		return nil
+	})
 }
 
 // DetectActiveProviders 检测当前各 Agent 配置文件中的激活状态
@@ -314,10 +385,10 @@ func (ps *ProviderService) DetectActiveProviders() map[string]string {
 		}
 	}
 
-	ps.mu.Lock()
-	ps.data.ActiveMap = result
-	ps.mu.Unlock()
-	_ = ps.saveData()
+	_ = ps.updateData(func(data *ProvidersData) error {
+		data.ActiveMap = result
+		return nil
+	})
 
 	return result
 }
@@ -325,10 +396,7 @@ func (ps *ProviderService) DetectActiveProviders() map[string]string {
 // --- Agent config file readers ---
 
 func (ps *ProviderService) readClaudeCodeAPIKey() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return ""
-	}
+	homeDir := ps.env.HomeDir
 	fp := filepath.Join(homeDir, ".claude", "settings.json")
 	raw, err := os.ReadFile(fp)
 	if err != nil {
@@ -355,10 +423,7 @@ func (ps *ProviderService) readClaudeCodeAPIKey() string {
 }
 
 func (ps *ProviderService) readCodexAPIKey() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return ""
-	}
+	homeDir := ps.env.HomeDir
 	fp := filepath.Join(homeDir, ".codex", "auth.json")
 	raw, err := os.ReadFile(fp)
 	if err != nil {
@@ -378,10 +443,7 @@ func (ps *ProviderService) readCodexAPIKey() string {
 }
 
 func (ps *ProviderService) readGeminiAPIKey() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return ""
-	}
+	homeDir := ps.env.HomeDir
 	fp := filepath.Join(homeDir, ".gemini", ".env")
 	raw, err := os.ReadFile(fp)
 	if err != nil {
@@ -399,10 +461,7 @@ func (ps *ProviderService) readGeminiAPIKey() string {
 // --- Agent config file writers ---
 
 func (ps *ProviderService) writeClaudeCodeConfig(cfg *ProviderConfig) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
+	homeDir := ps.env.HomeDir
 	dir := filepath.Join(homeDir, ".claude")
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
@@ -481,14 +540,11 @@ func (ps *ProviderService) writeClaudeCodeConfig(cfg *ProviderConfig) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(fp, raw, 0644)
+	return writeFileAtomic(fp, raw, 0644)
 }
 
 func (ps *ProviderService) writeCodexConfig(cfg *ProviderConfig) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
+	homeDir := ps.env.HomeDir
 	dir := filepath.Join(homeDir, ".codex")
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
@@ -506,7 +562,7 @@ func (ps *ProviderService) writeCodexConfig(cfg *ProviderConfig) error {
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(authFP, raw, 0644); err != nil {
+	if err := writeFileAtomic(authFP, raw, 0644); err != nil {
 		return err
 	}
 
@@ -567,14 +623,11 @@ func (ps *ProviderService) writeCodexConfig(cfg *ProviderConfig) error {
 	if !strings.HasSuffix(content, "\n") {
 		content += "\n"
 	}
-	return os.WriteFile(configFP, []byte(content), 0644)
+	return writeFileAtomic(configFP, []byte(content), 0644)
 }
 
 func (ps *ProviderService) writeGeminiConfig(cfg *ProviderConfig) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
+	homeDir := ps.env.HomeDir
 	dir := filepath.Join(homeDir, ".gemini")
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
@@ -623,7 +676,7 @@ func (ps *ProviderService) writeGeminiConfig(cfg *ProviderConfig) error {
 	if !strings.HasSuffix(content, "\n") {
 		content += "\n"
 	}
-	if err := os.WriteFile(envFP, []byte(content), 0644); err != nil {
+	if err := writeFileAtomic(envFP, []byte(content), 0644); err != nil {
 		return err
 	}
 
@@ -637,14 +690,11 @@ func (ps *ProviderService) writeGeminiConfig(cfg *ProviderConfig) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(settingsFP, sRaw, 0644)
+	return writeFileAtomic(settingsFP, sRaw, 0644)
 }
 
 func (ps *ProviderService) writeCodeBuddyConfig(cfg *ProviderConfig) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
+	homeDir := ps.env.HomeDir
 	dir := filepath.Join(homeDir, ".codebuddy")
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
@@ -671,10 +721,7 @@ func (ps *ProviderService) writeCodeBuddyConfig(cfg *ProviderConfig) error {
 
 // writeCodeBuddySettingsModel updates the "model" field in ~/.codebuddy/settings.json
 func (ps *ProviderService) writeCodeBuddySettingsModel(modelID string) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
+	homeDir := ps.env.HomeDir
 	fp := filepath.Join(homeDir, ".codebuddy", "settings.json")
 
 	settings := map[string]interface{}{}
@@ -687,15 +734,12 @@ func (ps *ProviderService) writeCodeBuddySettingsModel(modelID string) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(fp, raw, 0644)
+	return writeFileAtomic(fp, raw, 0644)
 }
 
 // writeCodeBuddyModelsJSON writes custom model entry into ~/.codebuddy/models.json
 func (ps *ProviderService) writeCodeBuddyModelsJSON(cfg *ProviderConfig, modelID string) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
+	homeDir := ps.env.HomeDir
 	fp := filepath.Join(homeDir, ".codebuddy", "models.json")
 
 	// Read existing models.json
@@ -803,7 +847,7 @@ func (ps *ProviderService) writeCodeBuddyModelsJSON(cfg *ProviderConfig, modelID
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(fp, raw, 0644)
+	return writeFileAtomic(fp, raw, 0644)
 }
 
 // GetCodeBuddyActiveModel returns the current active model from ~/.codebuddy/settings.json (exposed to frontend)
@@ -821,10 +865,7 @@ func (ps *ProviderService) SwitchCodeBuddyBuiltinModel(modelID string) error {
 
 // readCodeBuddyActiveModel reads the "model" field from ~/.codebuddy/settings.json
 func (ps *ProviderService) readCodeBuddyActiveModel() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return ""
-	}
+	homeDir := ps.env.HomeDir
 	fp := filepath.Join(homeDir, ".codebuddy", "settings.json")
 	raw, err := os.ReadFile(fp)
 	if err != nil {
@@ -842,10 +883,7 @@ func (ps *ProviderService) readCodeBuddyActiveModel() string {
 
 // readOpenCodeAPIKey reads the API key from ~/.config/opencode/opencode.json
 func (ps *ProviderService) readOpenCodeAPIKey() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return ""
-	}
+	homeDir := ps.env.HomeDir
 	fp := filepath.Join(homeDir, ".config", "opencode", "opencode.json")
 	raw, err := os.ReadFile(fp)
 	if err != nil {
@@ -878,10 +916,7 @@ func (ps *ProviderService) readOpenCodeAPIKey() string {
 
 // writeOpenCodeConfig writes provider configuration to ~/.config/opencode/opencode.json
 func (ps *ProviderService) writeOpenCodeConfig(cfg *ProviderConfig) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
+	homeDir := ps.env.HomeDir
 	dir := filepath.Join(homeDir, ".config", "opencode")
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
@@ -970,7 +1005,7 @@ func (ps *ProviderService) writeOpenCodeConfig(cfg *ProviderConfig) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(fp, raw, 0644)
+	return writeFileAtomic(fp, raw, 0644)
 }
 
 // --- Helper functions for type parsing ---
@@ -1042,10 +1077,25 @@ func (ps *ProviderService) TestProvider(id string) ProviderTestResult {
 
 // --- Import / Export ---
 
-// ExportProviders 导出供应商配置
+// ExportProviders 导出供应商配置，API Key 以 REDACTED 代替
 func (ps *ProviderService) ExportProviders() (string, error) {
+	return ps.ExportProvidersWithOptions(SecretExportOptions{Mode: SecretExportRedact})
+}
+
+// ExportProvidersWithOptions 导出供应商配置，按 opts 脱敏、加密或明文导出 API Key
+func (ps *ProviderService) ExportProvidersWithOptions(opts SecretExportOptions) (string, error) {
+	exporter, err := newSecretExporter(opts)
+	if err != nil {
+		return "", err
+	}
 	ps.mu.RLock()
-	raw, err := json.MarshalIndent(ps.data, "", "  ")
+	export := providersExport{ProvidersData: ps.data, SecretEncryption: exporter.encryption}
+	export.Providers = make([]ProviderConfig, len(ps.data.Providers))
+	for i, p := range ps.data.Providers {
+		p.APIKey = exporter.export(providerSecretKey(p.ID), p.APIKey)
+		export.Providers[i] = p
+	}
+	raw, err := json.MarshalIndent(export, "", "  ")
 	ps.mu.RUnlock()
 	if err != nil {
 		return "", err
@@ -1053,28 +1103,42 @@ func (ps *ProviderService) ExportProviders() (string, error) {
 	return string(raw), nil
 }
 
-// ImportProviders 导入供应商配置
+// ImportProviders 导入供应商配置，脱敏导出的 API Key 导入为空
 func (ps *ProviderService) ImportProviders(dataJSON string) (int, error) {
-	var imported ProvidersData
+	return ps.ImportProvidersWithPassphrase(dataJSON, "")
+}
+
+// ImportProvidersWithPassphrase 导入供应商配置，passphrase 用于解密加密导出的 API Key
+func (ps *ProviderService) ImportProvidersWithPassphrase(dataJSON string, passphrase string) (int, error) {
+	var imported providersExport
 	if err := json.Unmarshal([]byte(dataJSON), &imported); err != nil {
 		return 0, fmt.Errorf("invalid JSON: %w", err)
 	}
-
-	ps.mu.Lock()
-	existingIDs := map[string]bool{}
-	for _, p := range ps.data.Providers {
-		existingIDs[p.ID] = true
+	importer, err := newSecretImporter(imported.SecretEncryption, passphrase)
+	if err != nil {
+		return 0, err
 	}
-	count := 0
-	for _, p := range imported.Providers {
-		if !existingIDs[p.ID] {
-			ps.data.Providers = append(ps.data.Providers, p)
-			count++
+	for i, p := range imported.Providers {
+		if imported.Providers[i].APIKey, err = importer.open(providerSecretKey(p.ID), p.APIKey); err != nil {
+			return 0, err
 		}
 	}
-	ps.mu.Unlock()
 
-	if err := ps.saveData(); err != nil {
+	count := 0
+	if err := ps.updateData(func(data *ProvidersData) error {
+		existingIDs := map[string]bool{}
+		for _, p := range data.Providers {
+			existingIDs[p.ID] = true
+		}
+		count = 0
+		for _, p := range imported.Providers {
+			if !existingIDs[p.ID] {
+				data.Providers = append(data.Providers, p)
+				count++
+			}
+		}
+		return nil
+	}); err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -1089,7 +1153,7 @@ type TerminalInfo struct {
 
 // GetAvailableTerminals 返回所有支持的终端应用及其安装状态
 func (ps *ProviderService) GetAvailableTerminals() []TerminalInfo {
-	homeDir, _ := os.UserHomeDir()
+	homeDir := ps.env.HomeDir
 
 	all := []struct {
 		id    string
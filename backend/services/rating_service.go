@@ -5,17 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"time"
 )
 
 // RatingService 管理技能评分和笔记
 type RatingService struct {
 	ctx context.Context
+	env *Environment
 }
 
-func NewRatingService() *RatingService {
-	return &RatingService{}
+func NewRatingService(env *Environment) *RatingService {
+	return &RatingService{env: env}
 }
 
 func (rs *RatingService) Startup(ctx context.Context) {
@@ -35,16 +35,12 @@ type RatingsConfig struct {
 	Ratings map[string]SkillRating `json:"ratings"` // skill name -> rating
 }
 
-func getRatingsFilePath() (string, error) {
-	configDir, err := getConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "ratings.json"), nil
+func getRatingsFilePath(env *Environment) (string, error) {
+	return env.configFilePath("ratings.json")
 }
 
-func loadRatings() (RatingsConfig, error) {
-	filePath, err := getRatingsFilePath()
+func loadRatings(env *Environment) (RatingsConfig, error) {
+	filePath, err := getRatingsFilePath(env)
 	if err != nil {
 		return RatingsConfig{Ratings: make(map[string]SkillRating)}, err
 	}
@@ -62,26 +58,27 @@ func loadRatings() (RatingsConfig, error) {
 	return config, nil
 }
 
-func saveRatings(config RatingsConfig) error {
-	filePath, err := getRatingsFilePath()
-	if err != nil {
-		return err
-	}
-	data, err := json.MarshalIndent(config, "", "  ")
+// updateRatings 在文件锁保护下读取、修改并写回评分配置
+func updateRatings(env *Environment, fn func(config *RatingsConfig)) error {
+	filePath, err := getRatingsFilePath(env)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filePath, data, 0644)
+	return withFileLock(filePath, func() error {
+		config, _ := loadRatings(env)
+		fn(&config)
+		return writeJSONFile(filePath, config)
+	})
 }
 
 // GetRating 获取技能评分
 func (rs *RatingService) GetRating(skillName string) (*SkillRating, error) {
-	config, err := loadRatings()
+	r, err := metadataStore(rs.env).Rating(skillName)
 	if err != nil {
 		return nil, err
 	}
-	if r, ok := config.Ratings[skillName]; ok {
-		return &r, nil
+	if r != nil {
+		return r, nil
 	}
 	return &SkillRating{SkillName: skillName, Rating: 0}, nil
 }
@@ -91,30 +88,21 @@ func (rs *RatingService) SetRating(skillName string, rating int, note string) er
 	if rating < 0 || rating > 5 {
 		return fmt.Errorf("rating must be between 0 and 5")
 	}
-	config, err := loadRatings()
-	if err != nil {
-		return err
-	}
+	store := metadataStore(rs.env)
 	if rating == 0 && note == "" {
-		delete(config.Ratings, skillName)
-	} else {
-		config.Ratings[skillName] = SkillRating{
-			SkillName: skillName,
-			Rating:    rating,
-			Note:      note,
-			UpdatedAt: time.Now().Format(time.RFC3339),
-		}
+		return store.DeleteRating(skillName)
 	}
-	return saveRatings(config)
+	return store.SetRating(SkillRating{
+		SkillName: skillName,
+		Rating:    rating,
+		Note:      note,
+		UpdatedAt: time.Now().Format(time.RFC3339),
+	})
 }
 
 // GetAllRatings 获取所有评分
 func (rs *RatingService) GetAllRatings() (map[string]SkillRating, error) {
-	config, err := loadRatings()
-	if err != nil {
-		return nil, err
-	}
-	return config.Ratings, nil
+	return metadataStore(rs.env).AllRatings()
 }
 
 // CompareResult 技能对比结果
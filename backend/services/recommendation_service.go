@@ -14,6 +14,7 @@ import (
 // RecommendationService 智能推荐服务
 type RecommendationService struct {
 	ctx          context.Context
+	env          *Environment
 	skillsService *SkillsService
 }
 
@@ -65,8 +66,9 @@ type UsagePattern struct {
 	RelatedSkills []string          `json:"relatedSkills"` // 经常一起使用的技能
 }
 
-func NewRecommendationService(skillsService *SkillsService) *RecommendationService {
+func NewRecommendationService(env *Environment, skillsService *SkillsService) *RecommendationService {
 	return &RecommendationService{
+		env:           env,
 		skillsService: skillsService,
 	}
 }
@@ -624,12 +626,7 @@ func (rs *RecommendationService) getCommunityRecommendations() ([]EnhancedRecomm
 
 // getUsagePatterns 获取使用模式
 func (rs *RecommendationService) getUsagePatterns() ([]UsagePattern, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
+	configDir := rs.env.ConfigDir
 	patternsFile := filepath.Join(configDir, "usage-patterns.json")
 	
 	if _, err := os.Stat(patternsFile); os.IsNotExist(err) {
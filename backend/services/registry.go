@@ -0,0 +1,566 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---- 远程 registry ----
+// 搜索、查询 skill 元数据与解析下载来源通过 Registry 接口完成，registries.json 中按顺序配置多个 registry：
+//
+//	skills.sh  https://skills.sh 的搜索 API，不可用时回退到 npx skills find
+//	http       自建的 HTTP/JSON registry，协议如下（URL 为 registry 根地址）
+//
+// HTTP/JSON 协议：
+//
+//	GET <url>/search?q=<query>&limit=<n>     -> {"skills": [RegistrySkill, ...]}
+//	GET <url>/skills/<name>[?source=<src>]   -> RegistrySkill，不存在时返回 404
+//
+// RegistrySkill.source 与 download.source 使用安装名称中的来源格式（见 source_resolver.go），
+// download 缺省时从 source 安装。请求按 registry host 携带 host 凭据中的 token（Authorization: Bearer）
+//
+// 搜索时并发查询所有启用的 registry，结果按 registry 顺序合并，同一来源的同名 skill 只保留最靠前的一条
+
+const (
+	RegistryTypeSkillsSh = "skills.sh"
+	RegistryTypeHTTP     = "http"
+
+	defaultRegistryName = "skills.sh"
+	skillsShURL         = "https://skills.sh"
+)
+
+// errRegistrySkillNotFound registry 中没有该 skill
+var errRegistrySkillNotFound = errors.New("skill not found in registry")
+
+// RegistrySkill registry 协议中的 skill 元数据
+type RegistrySkill struct {
+	Name        string            `json:"name"`
+	Source      string            `json:"source"`                // 例如 owner/repo、git+https://host/group/repo.git
+	Description string            `json:"description,omitempty"` // 技能描述
+	Version     string            `json:"version,omitempty"`     // 最新版本（tag 或 commit）
+	Installs    int               `json:"installs,omitempty"`    // 安装次数
+	URL         string            `json:"url,omitempty"`         // skill 详情页
+	Agents      []string          `json:"agents,omitempty"`      // 支持的 agent 列表
+	Download    *RegistryDownload `json:"download,omitempty"`    // 下载来源，缺省时使用 source
+}
+
+// RegistryDownload skill 的下载来源
+type RegistryDownload struct {
+	Source  string `json:"source"`
+	Ref     string `json:"ref,omitempty"`     // git tag / 分支 / commit
+	Subpath string `json:"subpath,omitempty"` // skill 在来源中的目录
+}
+
+// Registry 远程 skill registry
+type Registry interface {
+	Name() string
+	// Search 按关键词搜索 skills
+	Search(query string, limit int) ([]RegistrySkill, error)
+	// Get 查询 skill 元数据，source 为空时匹配任意来源，不存在时返回 errRegistrySkillNotFound
+	Get(name, source string) (*RegistrySkill, error)
+	// Resolve 返回 skill 的下载来源
+	Resolve(name, source string) (*RegistryDownload, error)
+}
+
+// download 返回 skill 的下载来源，registry 未提供时使用 source
+func (s RegistrySkill) download() RegistryDownload {
+	if s.Download != nil && s.Download.Source != "" {
+		return *s.Download
+	}
+	return RegistryDownload{Source: s.Source}
+}
+
+// fullName 返回可直接用于安装的名称 <source>@<skill>[#ref]
+func (s RegistrySkill) fullName() string {
+	d := s.download()
+	name := d.Source + "@" + s.Name
+	if d.Ref != "" {
+		name += "#" + d.Ref
+	}
+	return name
+}
+
+// remoteSkill 转换为搜索结果
+func (s RegistrySkill) remoteSkill(registry string) RemoteSkill {
+	rs := RemoteSkill{
+		FullName:        s.fullName(),
+		Name:            s.Name,
+		Source:          s.Source,
+		URL:             s.URL,
+		Description:     s.Description,
+		Installs:        s.Installs,
+		SupportedAgents: s.Agents,
+		Registry:        registry,
+	}
+	if ownerRepoPattern.MatchString(s.Source) {
+		parts := strings.SplitN(s.Source, "/", 2)
+		rs.Owner, rs.Repo = parts[0], parts[1]
+	}
+	return rs
+}
+
+// ---- 配置 ----
+
+// RegistryConfig 一个 registry 的配置
+type RegistryConfig struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"` // skills.sh / http
+	URL     string `json:"url"`
+	Enabled bool   `json:"enabled"`
+	AddedAt string `json:"addedAt,omitempty"`
+}
+
+// RegistriesConfig registries.json 文件结构，数组顺序即查询与合并顺序
+type RegistriesConfig struct {
+	Registries []RegistryConfig `json:"registries"`
+}
+
+// defaultRegistries 未配置时只使用 skills.sh
+func defaultRegistries() []RegistryConfig {
+	return []RegistryConfig{{Name: defaultRegistryName, Type: RegistryTypeSkillsSh, URL: skillsShURL, Enabled: true}}
+}
+
+func getRegistriesFilePath(env *Environment) (string, error) {
+	return env.configFilePath("registries.json")
+}
+
+// loadRegistries 读取 registry 配置，文件不存在时返回默认配置
+func loadRegistries(env *Environment) ([]RegistryConfig, error) {
+	filePath, err := getRegistriesFilePath(env)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return defaultRegistries(), nil
+		}
+		return nil, err
+	}
+	var config RegistriesConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse registries: %v", err)
+	}
+	return config.Registries, nil
+}
+
+// updateRegistries 在文件锁保护下读取、修改并写回 registry 配置
+func updateRegistries(env *Environment, fn func(regs []RegistryConfig) ([]RegistryConfig, error)) error {
+	filePath, err := getRegistriesFilePath(env)
+	if err != nil {
+		return err
+	}
+	return withFileLock(filePath, func() error {
+		regs, err := loadRegistries(env)
+		if err != nil {
+			return err
+		}
+		if regs, err = fn(regs); err != nil {
+			return err
+		}
+		return writeJSONFile(filePath, RegistriesConfig{Registries: regs})
+	})
+}
+
+// GetRegistries 获取按查询顺序排列的 registry 配置
+func (ss *SkillsService) GetRegistries() ([]RegistryConfig, error) {
+	return loadRegistries(ss.env)
+}
+
+// AddRegistry 在末尾添加一个 HTTP/JSON registry
+func (ss *SkillsService) AddRegistry(name string, registryURL string) error {
+	name = strings.TrimSpace(name)
+	registryURL = strings.TrimSuffix(strings.TrimSpace(registryURL), "/")
+	if name == "" || registryURL == "" {
+		return fmt.Errorf("name and URL are required")
+	}
+	if !hasURLScheme(registryURL, "https", "http") {
+		return fmt.Errorf("registry URL must start with https:// or http://: %s", registryURL)
+	}
+	return updateRegistries(ss.env, func(regs []RegistryConfig) ([]RegistryConfig, error) {
+		for _, r := range regs {
+			if r.Name == name {
+				return nil, fmt.Errorf("registry already exists: %s", name)
+			}
+		}
+		return append(regs, RegistryConfig{
+			Name:    name,
+			Type:    RegistryTypeHTTP,
+			URL:     registryURL,
+			Enabled: true,
+			AddedAt: time.Now().Format(time.RFC3339),
+		}), nil
+	})
+}
+
+// RemoveRegistry 删除 registry
+func (ss *SkillsService) RemoveRegistry(name string) error {
+	return updateRegistries(ss.env, func(regs []RegistryConfig) ([]RegistryConfig, error) {
+		result := make([]RegistryConfig, 0, len(regs))
+		for _, r := range regs {
+			if r.Name != name {
+				result = append(result, r)
+			}
+		}
+		if len(result) == len(regs) {
+			return nil, fmt.Errorf("registry not found: %s", name)
+		}
+		return result, nil
+	})
+}
+
+// SetRegistryEnabled 启用或停用 registry
+func (ss *SkillsService) SetRegistryEnabled(name string, enabled bool) error {
+	return updateRegistries(ss.env, func(regs []RegistryConfig) ([]RegistryConfig, error) {
+		for i, r := range regs {
+			if r.Name == name {
+				regs[i].Enabled = enabled
+				return regs, nil
+			}
+		}
+		return nil, fmt.Errorf("registry not found: %s", name)
+	})
+}
+
+// MoveRegistry 将 registry 移动到指定位置（从 0 开始），越界时移动到首 / 尾
+func (ss *SkillsService) MoveRegistry(name string, index int) error {
+	return updateRegistries(ss.env, func(regs []RegistryConfig) ([]RegistryConfig, error) {
+		from := -1
+		for i, r := range regs {
+			if r.Name == name {
+				from = i
+				break
+			}
+		}
+		if from < 0 {
+			return nil, fmt.Errorf("registry not found: %s", name)
+		}
+		reg := regs[from]
+		regs = append(regs[:from], regs[from+1:]...)
+		index = max(0, min(index, len(regs)))
+		regs = append(regs[:index], append([]RegistryConfig{reg}, regs[index:]...)...)
+		return regs, nil
+	})
+}
+
+// registries 返回启用的 registry 实例
+func (ss *SkillsService) registries() ([]Registry, error) {
+	configs, err := loadRegistries(ss.env)
+	if err != nil {
+		return nil, err
+	}
+	var regs []Registry
+	for _, c := range configs {
+		if !c.Enabled {
+			continue
+		}
+		switch c.Type {
+		case RegistryTypeSkillsSh:
+			base := c.URL
+			if base == "" {
+				base = skillsShURL
+			}
+			regs = append(regs, &skillsShRegistry{name: c.Name, baseURL: strings.TrimSuffix(base, "/")})
+		case RegistryTypeHTTP:
+			regs = append(regs, &httpRegistry{name: c.Name, baseURL: c.URL, auth: ss.hostAuth(c.URL)})
+		default:
+			fmt.Fprintf(os.Stderr, "[Registry] warning: unsupported registry type %q for %s\n", c.Type, c.Name)
+		}
+	}
+	if len(regs) == 0 {
+		return nil, fmt.Errorf("no registry enabled")
+	}
+	return regs, nil
+}
+
+// searchRegistries 并发搜索所有启用的 registry，按配置顺序合并并去重；全部失败时返回错误
+func (ss *SkillsService) searchRegistries(query string, limit int) ([]RemoteSkill, error) {
+	regs, err := ss.registries()
+	if err != nil {
+		return nil, err
+	}
+	results := make([][]RegistrySkill, len(regs))
+	errs := make([]error, len(regs))
+	var wg sync.WaitGroup
+	for i, reg := range regs {
+		wg.Add(1)
+		go func(i int, reg Registry) {
+			defer wg.Done()
+			results[i], errs[i] = reg.Search(query, limit)
+		}(i, reg)
+	}
+	wg.Wait()
+
+	skills := []RemoteSkill{}
+	index := map[string]int{}
+	failed := 0
+	for i, reg := range regs {
+		if errs[i] != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "[Registry] warning: search %s failed: %v\n", reg.Name(), errs[i])
+			continue
+		}
+		for _, s := range results[i] {
+			if s.Name == "" || s.Source == "" {
+				continue
+			}
+			key := strings.ToLower(strings.TrimSuffix(s.Source, ".git") + "@" + s.Name)
+			if j, ok := index[key]; ok {
+				mergeRemoteSkill(&skills[j], s)
+				continue
+			}
+			index[key] = len(skills)
+			skills = append(skills, s.remoteSkill(reg.Name()))
+		}
+	}
+	if failed == len(regs) {
+		return nil, fmt.Errorf("failed to search remote skills: %v", errors.Join(errs...))
+	}
+	return skills, nil
+}
+
+// mergeRemoteSkill 用后面 registry 的结果补全已有结果中缺失的字段
+func mergeRemoteSkill(dst *RemoteSkill, s RegistrySkill) {
+	if dst.Description == "" {
+		dst.Description = s.Description
+	}
+	if dst.URL == "" {
+		dst.URL = s.URL
+	}
+	if len(dst.SupportedAgents) == 0 {
+		dst.SupportedAgents = s.Agents
+	}
+	dst.Installs = max(dst.Installs, s.Installs)
+}
+
+// lookupRegistrySkill 按顺序在启用的 registry 中查询 skill 元数据，返回第一个匹配及其 registry 名称
+func (ss *SkillsService) lookupRegistrySkill(name, source string) (*RegistrySkill, string, error) {
+	regs, err := ss.registries()
+	if err != nil {
+		return nil, "", err
+	}
+	var errs []error
+	for _, reg := range regs {
+		s, err := reg.Get(name, source)
+		if err == nil {
+			return s, reg.Name(), nil
+		}
+		if !errors.Is(err, errRegistrySkillNotFound) {
+			errs = append(errs, fmt.Errorf("%s: %v", reg.Name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, "", fmt.Errorf("skill %s not found: %v", name, errors.Join(errs...))
+	}
+	return nil, "", fmt.Errorf("skill %s not found in any registry", name)
+}
+
+// GetRegistrySkill 查询 skill 的 registry 元数据，fullName 为 <source>@<skill> 或只有 skill 名称
+func (ss *SkillsService) GetRegistrySkill(fullName string) (*RemoteSkill, error) {
+	source, name := "", fullName
+	if i := strings.LastIndex(fullName, "@"); i > 0 {
+		source, name = fullName[:i], fullName[i+1:]
+	}
+	name, _, _ = strings.Cut(name, "#")
+	s, registry, err := ss.lookupRegistrySkill(name, source)
+	if err != nil {
+		return nil, err
+	}
+	rs := s.remoteSkill(registry)
+	if lock, err := ss.loadSkillsLock(); err == nil {
+		rs.Installed = ss.isRemoteSkillInstalled(lock.Skills, rs)
+	}
+	return &rs, nil
+}
+
+// isRemoteSkillInstalled 判断搜索结果是否为已安装的同一来源 skill
+func (ss *SkillsService) isRemoteSkillInstalled(installed map[string]SkillLockEntry, s RemoteSkill) bool {
+	entry, ok := installed[s.Name]
+	if !ok || s.Source == "" {
+		return false
+	}
+	if entry.Source == s.Source {
+		return true
+	}
+	_, canonical, err := ss.resolveSkillSource(s.Source, "")
+	return err == nil && entry.Source == canonical
+}
+
+// ---- skills.sh ----
+
+// skillsShRegistry skills.sh 搜索 API
+type skillsShRegistry struct {
+	name    string
+	baseURL string
+}
+
+func (r *skillsShRegistry) Name() string { return r.name }
+
+func (r *skillsShRegistry) Search(query string, limit int) ([]RegistrySkill, error) {
+	skills, err := r.search(query, limit)
+	if err != nil {
+		// API 不可用时回退到 CLI 搜索
+		return r.searchCLI(query)
+	}
+	return skills, nil
+}
+
+// search 调用 skills.sh 搜索 API
+func (r *skillsShRegistry) search(query string, limit int) ([]RegistrySkill, error) {
+	apiURL := fmt.Sprintf("%s/api/search?q=%s&limit=%d", r.baseURL, url.QueryEscape(query), limit)
+	resp, err := httpGet(apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query skills.sh API: %v", err)
+	}
+
+	var apiResp struct {
+		Skills []struct {
+			ID       string `json:"id"`
+			Name     string `json:"name"`
+			Source   string `json:"source"`
+			Installs int    `json:"installs"`
+		} `json:"skills"`
+	}
+	if err := json.Unmarshal(resp, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse skills.sh response: %v", err)
+	}
+
+	skills := make([]RegistrySkill, 0, len(apiResp.Skills))
+	for _, s := range apiResp.Skills {
+		skills = append(skills, RegistrySkill{
+			Name:     s.Name,
+			Source:   s.Source,
+			Installs: s.Installs,
+			URL:      fmt.Sprintf("%s/%s", r.baseURL, s.ID),
+		})
+	}
+	return skills, nil
+}
+
+// searchCLI 通过 npx skills find 搜索
+func (r *skillsShRegistry) searchCLI(query string) ([]RegistrySkill, error) {
+	output, err := safeExecCommand("npx", "skills", "find", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search remote skills: %v", err)
+	}
+	var skills []RegistrySkill
+	for _, s := range parseRemoteSkillsOutput(string(output)) {
+		skills = append(skills, RegistrySkill{
+			Name:        s.Name,
+			Source:      s.Owner + "/" + s.Repo,
+			Description: s.Description,
+			URL:         s.URL,
+		})
+	}
+	return skills, nil
+}
+
+func (r *skillsShRegistry) Get(name, source string) (*RegistrySkill, error) {
+	skills, err := r.search(name, 10)
+	if err != nil {
+		return nil, err
+	}
+	// 精确匹配 skill 名称
+	for _, s := range skills {
+		if s.Name == name && (source == "" || strings.EqualFold(s.Source, source)) {
+			return &s, nil
+		}
+	}
+	return nil, errRegistrySkillNotFound
+}
+
+func (r *skillsShRegistry) Resolve(name, source string) (*RegistryDownload, error) {
+	s, err := r.Get(name, source)
+	if err != nil {
+		return nil, err
+	}
+	d := s.download()
+	return &d, nil
+}
+
+// ---- HTTP/JSON ----
+
+// httpRegistry 自建的 HTTP/JSON registry
+type httpRegistry struct {
+	name    string
+	baseURL string
+	auth    hostAuth
+}
+
+func (r *httpRegistry) Name() string { return r.name }
+
+// getJSON 请求 registry 并解析 JSON 响应，404 返回 errRegistrySkillNotFound
+func (r *httpRegistry) getJSON(path string, query url.Values, out any) error {
+	reqURL := strings.TrimSuffix(r.baseURL, "/") + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	r.auth.authorize(req)
+	resp, err := sharedHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errRegistrySkillNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid registry response: %v", err)
+	}
+	return nil
+}
+
+func (r *httpRegistry) Search(query string, limit int) ([]RegistrySkill, error) {
+	var resp struct {
+		Skills []RegistrySkill `json:"skills"`
+	}
+	params := url.Values{"q": {query}, "limit": {fmt.Sprint(limit)}}
+	if err := r.getJSON("/search", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Skills, nil
+}
+
+func (r *httpRegistry) Get(name, source string) (*RegistrySkill, error) {
+	params := url.Values{}
+	if source != "" {
+		params.Set("source", source)
+	}
+	var s RegistrySkill
+	if err := r.getJSON("/skills/"+url.PathEscape(name), params, &s); err != nil {
+		return nil, err
+	}
+	if s.Name == "" || s.Source == "" {
+		return nil, fmt.Errorf("invalid registry response: missing name or source")
+	}
+	return &s, nil
+}
+
+func (r *httpRegistry) Resolve(name, source string) (*RegistryDownload, error) {
+	s, err := r.Get(name, source)
+	if err != nil {
+		return nil, err
+	}
+	d := s.download()
+	return &d, nil
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---- 自建 registry 服务 ----
+// RegistryServer 索引本地目录中的 skills，提供 registry.go 中的 HTTP/JSON 协议（agent-hub serve-registry）：
+//
+//	GET /search?q=&limit=              搜索
+//	GET /skills/<name>?source=         元数据
+//	GET /download/<repo>/<name>.tar.gz skill 目录打包下载，作为元数据中的 source
+//
+// 每个 --dir 按仓库扫描（scanRepoSkills）；目录本身不是 skill 仓库时，将其直接子目录分别作为仓库扫描。
+// 索引在请求时按刷新间隔重建，目录中新增或修改的 skill 无需重启服务
+
+// defaultRegistrySearchLimit 搜索请求未指定 limit 时返回的数量
+const defaultRegistrySearchLimit = 30
+
+// RegistryServerOptions 服务配置
+type RegistryServerOptions struct {
+	Dirs    []string      // 扫描的目录
+	BaseURL string        // 下载地址使用的外部访问地址，为空时按请求的 Host 生成
+	Token   string        // 非空时要求请求携带 Authorization: Bearer <token>
+	Refresh time.Duration // 索引刷新间隔，0 表示每次请求都重新扫描
+}
+
+// registryServerSkill 索引中的 skill
+type registryServerSkill struct {
+	RegistrySkill
+	repo string // 所在仓库目录名
+	dir  string // skill 目录
+}
+
+// RegistryServer 基于本地目录的 registry
+type RegistryServer struct {
+	opts      RegistryServerOptions
+	mu        sync.Mutex
+	skills    []registryServerSkill
+	indexedAt time.Time
+}
+
+// NewRegistryServer 创建 registry 服务并建立初始索引
+func NewRegistryServer(opts RegistryServerOptions) (*RegistryServer, error) {
+	if len(opts.Dirs) == 0 {
+		return nil, fmt.Errorf("at least one directory is required")
+	}
+	for i, dir := range opts.Dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid directory %s: %v", dir, err)
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("directory not found: %s", dir)
+		}
+		opts.Dirs[i] = abs
+	}
+	opts.BaseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	s := &RegistryServer{opts: opts}
+	s.index()
+	return s, nil
+}
+
+// SkillCount 当前索引中的 skill 数量
+func (s *RegistryServer) SkillCount() int {
+	return len(s.index())
+}
+
+// index 返回当前索引，超过刷新间隔时重新扫描
+func (s *RegistryServer) index() []registryServerSkill {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.skills != nil && time.Since(s.indexedAt) < s.opts.Refresh {
+		return s.skills
+	}
+	skills := []registryServerSkill{}
+	seen := map[string]bool{}
+	for _, dir := range s.opts.Dirs {
+		for _, repoDir := range registryRepoDirs(dir) {
+			repo := filepath.Base(repoDir)
+			for _, found := range scanRepoSkills(repoDir, repo, repo) {
+				// 同一仓库名下的同名 skill 只保留第一个，避免下载地址冲突
+				key := repo + "/" + found.Name
+				if seen[key] {
+					continue
+				}
+				skillDir := findSkillInRepo(repoDir, found.Name)
+				if skillDir == "" {
+					continue
+				}
+				seen[key] = true
+				skills = append(skills, registryServerSkill{
+					RegistrySkill: RegistrySkill{
+						Name:        found.Name,
+						Description: found.Desc,
+						Version:     registrySkillVersion(repoDir, skillDir),
+						Agents:      detectSkillAgents(skillDir),
+					},
+					repo: repo,
+					dir:  skillDir,
+				})
+			}
+		}
+	}
+	sort.SliceStable(skills, func(i, j int) bool { return skills[i].Name < skills[j].Name })
+	s.skills, s.indexedAt = skills, time.Now()
+	return skills
+}
+
+// registryRepoDirs 目录本身是 skill 仓库（根目录或 skills/ 下有 SKILL.md）时作为一个仓库，否则返回其直接子目录
+func registryRepoDirs(dir string) []string {
+	if hasSkillMd(dir) {
+		return []string{dir}
+	}
+	if entries, err := os.ReadDir(filepath.Join(dir, "skills")); err == nil {
+		for _, e := range entries {
+			if e.IsDir() && hasSkillMd(filepath.Join(dir, "skills", e.Name())) {
+				return []string{dir}
+			}
+		}
+	}
+	var repos []string
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			repos = append(repos, filepath.Join(dir, e.Name()))
+		}
+	}
+	return repos
+}
+
+// registrySkillVersion git 仓库使用当前 commit，否则使用 skill 内容哈希
+func registrySkillVersion(repoDir, skillDir string) string {
+	if sha := gitHeadSHA(repoDir); sha != "" {
+		return sha
+	}
+	if hash, _, err := hashSkillTree(skillDir); err == nil {
+		return hash
+	}
+	return ""
+}
+
+// detectSkillAgents 按 agentFileMapping 检测 skill 目录中的格式文件
+func detectSkillAgents(skillDir string) []string {
+	var agents []string
+	for _, m := range agentFileMapping {
+		if _, err := os.Stat(filepath.Join(skillDir, m.FileName)); err == nil {
+			agents = append(agents, m.Agent)
+		}
+	}
+	return agents
+}
+
+// ServeHTTP 实现 http.Handler
+func (s *RegistryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.opts.Token != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+	switch {
+	case r.URL.Path == "/search":
+		s.handleSearch(w, r)
+	case strings.HasPrefix(r.URL.Path, "/skills/"):
+		s.handleSkill(w, r, strings.TrimPrefix(r.URL.Path, "/skills/"))
+	case strings.HasPrefix(r.URL.Path, "/download/"):
+		s.handleDownload(w, r, strings.TrimPrefix(r.URL.Path, "/download/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// baseURL 下载地址的前缀
+func (s *RegistryServer) baseURL(r *http.Request) string {
+	if s.opts.BaseURL != "" {
+		return s.opts.BaseURL
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// metadata 返回带下载地址的元数据，下载地址同时作为 source
+func (s *RegistryServer) metadata(r *http.Request, skill registryServerSkill) RegistrySkill {
+	meta := skill.RegistrySkill
+	meta.Source = fmt.Sprintf("%s/download/%s/%s.tar.gz", s.baseURL(r), url.PathEscape(skill.repo), url.PathEscape(skill.Name))
+	return meta
+}
+
+func (s *RegistryServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("q")))
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultRegistrySearchLimit
+	}
+
+	// 名称完全匹配优先，其次名称包含，最后描述或仓库名包含
+	type match struct {
+		skill registryServerSkill
+		rank  int
+	}
+	var matches []match
+	for _, skill := range s.index() {
+		name := strings.ToLower(skill.Name)
+		switch {
+		case query == "" || name == query:
+			matches = append(matches, match{skill, 0})
+		case strings.Contains(name, query):
+			matches = append(matches, match{skill, 1})
+		case strings.Contains(strings.ToLower(skill.Description+"\n"+skill.repo), query):
+			matches = append(matches, match{skill, 2})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].rank < matches[j].rank })
+
+	result := []RegistrySkill{}
+	for i := 0; i < len(matches) && i < limit; i++ {
+		result = append(result, s.metadata(r, matches[i].skill))
+	}
+	writeRegistryJSON(w, map[string]any{"skills": result})
+}
+
+func (s *RegistryServer) handleSkill(w http.ResponseWriter, r *http.Request, name string) {
+	source := r.URL.Query().Get("source")
+	for _, skill := range s.index() {
+		if skill.Name != name {
+			continue
+		}
+		meta := s.metadata(r, skill)
+		if source == "" || source == meta.Source {
+			writeRegistryJSON(w, meta)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// handleDownload 将 skill 目录打包为 tar.gz，顶层目录为 skill 名称
+func (s *RegistryServer) handleDownload(w http.ResponseWriter, r *http.Request, path string) {
+	repo, file, ok := strings.Cut(path, "/")
+	name, isTarball := strings.CutSuffix(file, ".tar.gz")
+	if !ok || !isTarball {
+		http.NotFound(w, r)
+		return
+	}
+	for _, skill := range s.index() {
+		if skill.repo != repo || skill.Name != name {
+			continue
+		}
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".tar.gz"))
+		if r.Method == http.MethodHead {
+			return
+		}
+		if err := writeSkillTarball(w, skill.dir, name); err != nil {
+			fmt.Fprintf(os.Stderr, "[RegistryServer] warning: failed to pack %s/%s: %v\n", repo, name, err)
+		}
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// writeSkillTarball 打包 skill 目录，跳过 .git 与符号链接
+func writeSkillTarball(w io.Writer, dir, prefix string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Name() == ".git" && d.IsDir() {
+			return filepath.SkipDir
+		}
+		if d.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(filepath.Join(prefix, rel))
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeRegistryJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -15,6 +15,7 @@ import (
 // SearchService 高级搜索服务
 type SearchService struct {
 	ctx           context.Context
+	env           *Environment
 	skillsService *SkillsService
 	searchIndex   *SearchIndex
 	indexMu       sync.RWMutex // 保护 searchIndex 的并发读写
@@ -109,8 +110,9 @@ type SearchSuggestion struct {
 	Description string  `json:"description"`
 }
 
-func NewSearchService(skillsService *SkillsService) *SearchService {
+func NewSearchService(env *Environment, skillsService *SkillsService) *SearchService {
 	return &SearchService{
+		env:           env,
 		skillsService: skillsService,
 		searchIndex:   &SearchIndex{
 			Skills:    make(map[string]*IndexedSkill),
@@ -283,12 +285,7 @@ func (ss *SearchService) GetSearchSuggestions(prefix string, limit int) ([]Searc
 
 // GetSearchHistory 获取搜索历史
 func (ss *SearchService) GetSearchHistory(limit int) ([]SearchHistory, error) {
-	homeDir, err := getCachedHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	historyFile := filepath.Join(homeDir, ".skills-manager", "search-history.json")
+	historyFile := filepath.Join(ss.env.ConfigDir, "search-history.json")
 	
 	if _, err := os.Stat(historyFile); os.IsNotExist(err) {
 		return []SearchHistory{}, nil
@@ -319,12 +316,7 @@ func (ss *SearchService) GetSearchHistory(limit int) ([]SearchHistory, error) {
 
 // ClearSearchHistory 清空搜索历史
 func (ss *SearchService) ClearSearchHistory() error {
-	homeDir, err := getCachedHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	historyFile := filepath.Join(homeDir, ".skills-manager", "search-history.json")
+	historyFile := filepath.Join(ss.env.ConfigDir, "search-history.json")
 	
 	if err := os.Remove(historyFile); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove search history: %w", err)
@@ -396,11 +388,15 @@ func (ss *SearchService) indexLocalSkills() error {
 			}
 		}
 		
-		// 获取标签
+		// 获取标签：用户标签 + SKILL.md frontmatter 中声明的标签
 		tags, _ := ss.skillsService.GetSkillTags(skill.Name)
+		if skill.Manifest != nil {
+			tags = appendUnique(tags, skill.Manifest.Tags...)
+		}
 		
-		// 提取关键词
+		// 提取关键词，标签同样参与关键词匹配
 		keywords := ss.extractKeywords(skill.Name, skill.Desc, content)
+		keywords = ss.cleanKeywords(append(keywords, tags...))
 		
 		// 创建索引项
 		indexedSkill := &IndexedSkill{
@@ -856,61 +852,50 @@ func (ss *SearchService) recordSearchHistory(query string, results int) {
 		return
 	}
 	
-	homeDir, err := getCachedHomeDir()
-	if err != nil {
-		return
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
+	configDir := ss.env.ConfigDir
 	historyFile := filepath.Join(configDir, "search-history.json")
 	
-	// 读取现有历史
-	var history []SearchHistory
-	if data, err := os.ReadFile(historyFile); err == nil {
-		json.Unmarshal(data, &history)
-	}
-	
-	// 添加新记录
-	newRecord := SearchHistory{
-		Query:     query,
-		Timestamp: time.Now(),
-		Results:   results,
-	}
-	
-	// 去重：如果查询已存在，更新时间戳
-	found := false
-	for i, record := range history {
-		if record.Query == query {
-			history[i] = newRecord
-			found = true
-			break
+	withFileLock(historyFile, func() error {
+		// 读取现有历史
+		var history []SearchHistory
+		if data, err := os.ReadFile(historyFile); err == nil {
+			json.Unmarshal(data, &history)
 		}
-	}
-	
-	if !found {
-		history = append(history, newRecord)
-	}
-	
-	// 限制历史记录数量
-	if len(history) > 100 {
-		history = history[len(history)-100:]
-	}
-	
-	// 保存历史
-	if data, err := json.MarshalIndent(history, "", "  "); err == nil {
-		os.MkdirAll(configDir, 0755)
-		os.WriteFile(historyFile, data, 0644)
-	}
+		
+		// 添加新记录
+		newRecord := SearchHistory{
+			Query:     query,
+			Timestamp: time.Now(),
+			Results:   results,
+		}
+		
+		// 去重：如果查询已存在，更新时间戳
+		found := false
+		for i, record := range history {
+			if record.Query == query {
+				history[i] = newRecord
+				found = true
+				break
+			}
+		}
+		
+		if !found {
+			history = append(history, newRecord)
+		}
+		
+		// 限制历史记录数量
+		if len(history) > 100 {
+			history = history[len(history)-100:]
+		}
+		
+		// 保存历史
+		return writeJSONFile(historyFile, history)
+	})
 }
 
 // loadSearchIndex 加载搜索索引
 func (ss *SearchService) loadSearchIndex() error {
-	homeDir, err := getCachedHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	indexFile := filepath.Join(homeDir, ".skills-manager", "search-index.json")
+	indexFile := filepath.Join(ss.env.ConfigDir, "search-index.json")
 	
 	if _, err := os.Stat(indexFile); os.IsNotExist(err) {
 		return nil // 索引文件不存在
@@ -930,24 +915,14 @@ func (ss *SearchService) loadSearchIndex() error {
 
 // saveSearchIndex 保存搜索索引
 func (ss *SearchService) saveSearchIndex() error {
-	homeDir, err := getCachedHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
+	configDir := ss.env.ConfigDir
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 	
 	indexFile := filepath.Join(configDir, "search-index.json")
 	
-	data, err := json.MarshalIndent(ss.searchIndex, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal index: %w", err)
-	}
-	
-	if err := os.WriteFile(indexFile, data, 0644); err != nil {
+	if err := saveJSONConfig(indexFile, ss.searchIndex); err != nil {
 		return fmt.Errorf("failed to write index file: %w", err)
 	}
 	
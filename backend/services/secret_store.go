@@ -0,0 +1,428 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// ---- 密钥存储 ----
+// 供应商 API Key、自定义源 token 与 host 凭据的 token 不再明文写入 JSON 文件，
+// 文件中只保存 secret://<backend>/<key> 引用，真实值保存在：
+//
+//	keyring  系统钥匙串（macOS security、Linux secret-tool），可用时默认使用
+//	vault    配置目录下的加密文件 secrets.vault（AES-256-GCM），密钥来自口令或本机密钥文件 secrets.key
+//
+// 设置中的 secretBackend 选择新写入的位置（auto / keyring / vault），已有引用始终从其记录的后端读取。
+// 读取失败（例如 vault 设置了口令但尚未解锁）时引用原样保留在内存中，写回时不会丢失
+
+// 密钥存储后端
+const (
+	SecretBackendAuto    = "auto"
+	SecretBackendKeyring = "keyring"
+	SecretBackendVault   = "vault"
+)
+
+// secretRefPrefix 配置文件中密钥引用的前缀
+const secretRefPrefix = "secret://"
+
+// errSecretVaultLocked vault 使用口令加密且当前进程尚未解锁
+var errSecretVaultLocked = fmt.Errorf("secret vault is locked: unlock it in settings or set %s", EnvSecretPassphrase)
+
+// SecretStore 密钥存储后端
+type SecretStore interface {
+	Backend() string
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+// SecretStoreInfo 密钥存储状态
+type SecretStoreInfo struct {
+	Backend             string   `json:"backend"`             // 新写入使用的后端
+	Configured          string   `json:"configured"`          // 设置中的 secretBackend
+	AvailableBackends   []string `json:"availableBackends"`   // 当前系统可用的后端
+	VaultPath           string   `json:"vaultPath"`           // vault 文件位置
+	PassphraseProtected bool     `json:"passphraseProtected"` // vault 使用口令加密
+	Locked              bool     `json:"locked"`              // vault 使用口令且尚未解锁
+}
+
+// isSecretRef 判断配置值是否为密钥引用
+func isSecretRef(v string) bool {
+	return strings.HasPrefix(v, secretRefPrefix)
+}
+
+// parseSecretRef 拆分 secret://<backend>/<key>
+func parseSecretRef(ref string) (backend, key string, err error) {
+	rest := strings.TrimPrefix(ref, secretRefPrefix)
+	i := strings.Index(rest, "/")
+	if !isSecretRef(ref) || i <= 0 || i == len(rest)-1 {
+		return "", "", fmt.Errorf("invalid secret reference: %s", ref)
+	}
+	return rest[:i], rest[i+1:], nil
+}
+
+// secretStoreFor 返回指定后端的存储
+func secretStoreFor(env *Environment, backend string) (SecretStore, error) {
+	switch backend {
+	case SecretBackendKeyring:
+		if !keyringAvailable() {
+			return nil, fmt.Errorf("OS keyring is not available on this system")
+		}
+		return keyringSecretStore{service: keyringServiceName(env)}, nil
+	case SecretBackendVault:
+		return newVaultSecretStore(env), nil
+	}
+	return nil, fmt.Errorf("unknown secret backend: %s", backend)
+}
+
+// activeSecretBackend 按设置返回新写入使用的后端，auto 时优先系统钥匙串
+func activeSecretBackend(env *Environment) string {
+	switch loadAppSettings(env).SecretBackend {
+	case SecretBackendKeyring:
+		return SecretBackendKeyring
+	case SecretBackendVault:
+		return SecretBackendVault
+	}
+	if keyringAvailable() {
+		return SecretBackendKeyring
+	}
+	return SecretBackendVault
+}
+
+// keyringCache 缓存系统钥匙串中读写过的值（服务名 + 引用 -> 值），避免每次读取配置都调用外部命令
+// 以服务名区分环境，同一进程中的不同环境不会读到彼此的值
+var keyringCache sync.Map
+
+// keyringCacheKey 返回引用在 keyringCache 中的键
+func keyringCacheKey(env *Environment, ref string) string {
+	return keyringServiceName(env) + "\x00" + ref
+}
+
+// sealSecret 将 value 保存到当前后端的 key 下并返回引用；prev 为配置文件中原有的值（引用或空）。
+// value 为空时删除 prev 指向的密钥并返回空串，已经是引用时原样返回；换到新后端后删除旧引用指向的密钥
+func sealSecret(env *Environment, key, value, prev string) (string, error) {
+	if value == "" {
+		dropSecret(env, prev)
+		return "", nil
+	}
+	if isSecretRef(value) {
+		return value, nil
+	}
+	backend := activeSecretBackend(env)
+	ref := secretRefPrefix + backend + "/" + key
+	if backend == SecretBackendKeyring {
+		if cached, ok := keyringCache.Load(keyringCacheKey(env, ref)); ok && cached.(string) == value {
+			return ref, nil
+		}
+	}
+	store, err := secretStoreFor(env, backend)
+	if err != nil {
+		return "", err
+	}
+	if err := store.Set(key, value); err != nil {
+		return "", fmt.Errorf("failed to store secret %s: %v", key, err)
+	}
+	if backend == SecretBackendKeyring {
+		keyringCache.Store(keyringCacheKey(env, ref), value)
+	}
+	if prev != ref {
+		dropSecret(env, prev)
+	}
+	return ref, nil
+}
+
+// openSecret 解析配置值：引用返回其指向的值，其他值原样返回
+func openSecret(env *Environment, value string) (string, error) {
+	if !isSecretRef(value) {
+		return value, nil
+	}
+	backend, key, err := parseSecretRef(value)
+	if err != nil {
+		return "", err
+	}
+	if backend == SecretBackendKeyring {
+		if cached, ok := keyringCache.Load(keyringCacheKey(env, value)); ok {
+			return cached.(string), nil
+		}
+	}
+	store, err := secretStoreFor(env, backend)
+	if err != nil {
+		return "", err
+	}
+	secret, err := store.Get(key)
+	if err != nil {
+		return "", err
+	}
+	if backend == SecretBackendKeyring {
+		keyringCache.Store(keyringCacheKey(env, value), secret)
+	}
+	return secret, nil
+}
+
+// dropSecret 删除引用指向的密钥，只操作引用中记录的后端；ref 不是引用（空值或旧版明文）时不做任何事
+func dropSecret(env *Environment, ref string) {
+	if !isSecretRef(ref) {
+		return
+	}
+	backend, key, err := parseSecretRef(ref)
+	if err != nil {
+		return
+	}
+	if store, err := secretStoreFor(env, backend); err == nil {
+		store.Delete(key)
+	}
+	if backend == SecretBackendKeyring {
+		keyringCache.Delete(keyringCacheKey(env, ref))
+	}
+}
+
+// openSecretOrRef 解析配置值，失败时返回原引用（调用方据此判断密钥不可用）
+func openSecretOrRef(env *Environment, value string) string {
+	if secret, err := openSecret(env, value); err == nil {
+		return secret
+	}
+	return value
+}
+
+// migratePlaintextSecrets 将旧版本明文写入 custom-sources.json 与 host-credentials.json 的 token 移入密钥存储
+// （providers.json 由 ProviderService 启动时迁移）。失败时保留明文，下次写入时再迁移
+func migratePlaintextSecrets(env *Environment) {
+	if sources, err := readCustomSources(env); err == nil {
+		for _, s := range sources {
+			if s.Token != "" && !isSecretRef(s.Token) {
+				updateCustomSources(env, func(sources []CustomSource) ([]CustomSource, error) { return sources, nil })
+				break
+			}
+		}
+	}
+	if creds, err := readHostCredentials(env); err == nil {
+		for _, c := range creds {
+			if c.Token != "" && !isSecretRef(c.Token) {
+				updateHostCredentials(env, func(creds []HostCredential) ([]HostCredential, error) { return creds, nil })
+				break
+			}
+		}
+	}
+}
+
+// ---- 导出 ----
+
+// 导出文件中密钥的处理方式
+const (
+	SecretExportRedact  = "redact"  // 以 REDACTED 代替（默认）
+	SecretExportEncrypt = "encrypt" // 用导出口令加密
+	SecretExportPlain   = "plain"   // 明文导出
+)
+
+// redactedSecret 脱敏导出时代替密钥的占位符，导入时视为空
+const redactedSecret = "REDACTED"
+
+// encryptedSecretPrefix 加密导出的密钥前缀，其后为 base64(nonce | 密文)
+const encryptedSecretPrefix = "enc:"
+
+// exportPBKDF2Iterations 导出口令与 vault 口令的 PBKDF2-SHA256 迭代次数
+const exportPBKDF2Iterations = 600000
+
+// SecretExportOptions 导出时密钥的处理方式
+type SecretExportOptions struct {
+	Mode       string `json:"mode"`       // redact / encrypt / plain
+	Passphrase string `json:"passphrase"` // Mode 为 encrypt 时必填
+}
+
+// SecretEncryption 加密导出文件的密钥派生参数，导入时用相同口令解密
+type SecretEncryption struct {
+	KDF        string `json:"kdf"` // pbkdf2-sha256
+	Iterations int    `json:"iterations"`
+	Salt       string `json:"salt"` // base64
+}
+
+// secretCipher 以口令派生的 AES-256-GCM 加解密单个密钥，附加数据为密钥名称
+type secretCipher struct {
+	aead cipher.AEAD
+}
+
+func newSecretCipher(key []byte) (*secretCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &secretCipher{aead: aead}, nil
+}
+
+// derivePassphraseKey 用 PBKDF2-SHA256 从口令派生 32 字节密钥
+func derivePassphraseKey(passphrase string, salt []byte, iterations int) ([]byte, error) {
+	return pbkdf2.Key([]byte(passphrase), salt, iterations, 32, sha256.New), nil
+}
+
+func (c *secretCipher) seal(name, plaintext string) string {
+	nonce := make([]byte, c.aead.NonceSize())
+	rand.Read(nonce)
+	out := c.aead.Seal(nonce, nonce, []byte(plaintext), []byte(name))
+	return base64.StdEncoding.EncodeToString(out)
+}
+
+func (c *secretCipher) open(name, sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < c.aead.NonceSize() {
+		return "", errors.New("malformed encrypted secret")
+	}
+	n := c.aead.NonceSize()
+	plaintext, err := c.aead.Open(nil, data[:n], data[n:], []byte(name))
+	if err != nil {
+		return "", errors.New("wrong passphrase or corrupted secret")
+	}
+	return string(plaintext), nil
+}
+
+// secretExporter 按导出选项转换密钥
+type secretExporter struct {
+	mode       string
+	cipher     *secretCipher
+	encryption *SecretEncryption
+}
+
+func newSecretExporter(opts SecretExportOptions) (*secretExporter, error) {
+	switch opts.Mode {
+	case "", SecretExportRedact:
+		return &secretExporter{mode: SecretExportRedact}, nil
+	case SecretExportPlain:
+		return &secretExporter{mode: SecretExportPlain}, nil
+	case SecretExportEncrypt:
+		if opts.Passphrase == "" {
+			return nil, fmt.Errorf("a passphrase is required to encrypt exported secrets")
+		}
+		salt := make([]byte, 16)
+		rand.Read(salt)
+		key, err := derivePassphraseKey(opts.Passphrase, salt, exportPBKDF2Iterations)
+		if err != nil {
+			return nil, err
+		}
+		c, err := newSecretCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return &secretExporter{
+			mode:   SecretExportEncrypt,
+			cipher: c,
+			encryption: &SecretEncryption{
+				KDF:        "pbkdf2-sha256",
+				Iterations: exportPBKDF2Iterations,
+				Salt:       base64.StdEncoding.EncodeToString(salt),
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown export mode: %s", opts.Mode)
+}
+
+// export 转换名为 name 的密钥；未能解锁的引用按脱敏处理
+func (e *secretExporter) export(name, value string) string {
+	if value == "" {
+		return ""
+	}
+	if isSecretRef(value) || e.mode == SecretExportRedact {
+		return redactedSecret
+	}
+	if e.mode == SecretExportEncrypt {
+		return encryptedSecretPrefix + e.cipher.seal(name, value)
+	}
+	return value
+}
+
+// secretImporter 解析导出文件中的密钥
+type secretImporter struct {
+	cipher *secretCipher
+}
+
+// newSecretImporter 根据导出文件的加密参数与口令创建导入器，文件未加密时忽略口令
+func newSecretImporter(enc *SecretEncryption, passphrase string) (*secretImporter, error) {
+	if enc == nil {
+		return &secretImporter{}, nil
+	}
+	if enc.KDF != "pbkdf2-sha256" || enc.Iterations <= 0 {
+		return nil, fmt.Errorf("unsupported secret encryption: %s", enc.KDF)
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("the export contains encrypted secrets: a passphrase is required")
+	}
+	salt, err := base64.StdEncoding.DecodeString(enc.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret encryption salt: %v", err)
+	}
+	key, err := derivePassphraseKey(passphrase, salt, enc.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newSecretCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &secretImporter{cipher: c}, nil
+}
+
+// open 还原名为 name 的密钥：脱敏占位符与引用视为空，加密值需要口令
+func (im *secretImporter) open(name, value string) (string, error) {
+	switch {
+	case value == redactedSecret || isSecretRef(value):
+		return "", nil
+	case strings.HasPrefix(value, encryptedSecretPrefix):
+		if im.cipher == nil {
+			return "", fmt.Errorf("secret %s is encrypted but the export has no encryption parameters", name)
+		}
+		secret, err := im.cipher.open(name, strings.TrimPrefix(value, encryptedSecretPrefix))
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt %s: %v", name, err)
+		}
+		return secret, nil
+	}
+	return value, nil
+}
+
+// ---- 管理接口 ----
+
+// GetSecretStoreInfo 返回密钥存储的后端与 vault 状态
+func (ss *SkillsService) GetSecretStoreInfo() (*SecretStoreInfo, error) {
+	configured := loadAppSettings(ss.env).SecretBackend
+	if configured == "" {
+		configured = SecretBackendAuto
+	}
+	info := &SecretStoreInfo{
+		Backend:           activeSecretBackend(ss.env),
+		Configured:        configured,
+		AvailableBackends: []string{SecretBackendVault},
+	}
+	if keyringAvailable() {
+		info.AvailableBackends = []string{SecretBackendKeyring, SecretBackendVault}
+	}
+	vault := newVaultSecretStore(ss.env)
+	info.VaultPath = vault.path
+	protected, locked, err := vault.status()
+	if err != nil {
+		return nil, err
+	}
+	info.PassphraseProtected, info.Locked = protected, locked
+	return info, nil
+}
+
+// UnlockSecretVault 用口令解锁 vault，解锁状态保持到进程退出
+func (ss *SkillsService) UnlockSecretVault(passphrase string) error {
+	return newVaultSecretStore(ss.env).unlock(passphrase)
+}
+
+// SetSecretVaultPassphrase 设置、修改或移除（newPassphrase 为空，改用本机密钥文件）vault 口令并重新加密全部条目
+// vault 已设置口令时需要提供当前口令
+func (ss *SkillsService) SetSecretVaultPassphrase(currentPassphrase, newPassphrase string) error {
+	return newVaultSecretStore(ss.env).rekey(currentPassphrase, newPassphrase)
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ---- 系统钥匙串 ----
+// 通过系统自带的命令行工具访问钥匙串，不引入 cgo 依赖：
+//
+//	macOS  security（登录钥匙串中的通用密码，服务名见 keyringServiceName）
+//	Linux  secret-tool（libsecret / GNOME Keyring / KWallet，需要 D-Bus 会话）
+//
+// 其他系统不可用，auto 模式下回退到 vault。密钥值经标准输入传给工具，不出现在进程参数中
+
+// keyringService 默认环境下钥匙串条目的服务名
+const keyringService = "agent-hub"
+
+// keyringServiceName 返回环境对应的钥匙串服务名：默认配置目录使用 agent-hub，
+// 其他配置目录（--config-dir、AGENT_HUB_CONFIG_DIR 等隔离环境）附加配置目录的哈希，
+// 隔离环境不会读取、覆盖或删除真实用户的密钥
+func keyringServiceName(env *Environment) string {
+	if home, err := os.UserHomeDir(); err == nil && absPath(filepath.Join(home, ".skills-manager")) == env.ConfigDir {
+		return keyringService
+	}
+	sum := sha256.Sum256([]byte(env.ConfigDir))
+	return keyringService + "-" + hex.EncodeToString(sum[:])[:12]
+}
+
+// keyringAvailable 判断当前系统能否使用钥匙串
+func keyringAvailable() bool {
+	switch runtime.GOOS {
+	case "darwin":
+		_, err := exec.LookPath("security")
+		return err == nil
+	case "linux", "freebsd", "openbsd":
+		if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+			return false
+		}
+		_, err := exec.LookPath("secret-tool")
+		return err == nil
+	}
+	return false
+}
+
+// keyringSecretStore 系统钥匙串实现，service 为环境对应的服务名
+type keyringSecretStore struct {
+	service string
+}
+
+func (keyringSecretStore) Backend() string { return SecretBackendKeyring }
+
+func (k keyringSecretStore) Get(key string) (string, error) {
+	if runtime.GOOS == "darwin" {
+		// -g 将密码输出到 stderr，含换行等不可打印字符时为十六进制，比 -w 的输出可以无歧义地还原
+		cmd := exec.Command("security", "find-generic-password", "-s", k.service, "-a", key, "-g")
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("secret not found in keyring: %s", key)
+		}
+		value, ok := parseSecurityPassword(stderr.String())
+		if !ok || value == "" {
+			return "", fmt.Errorf("secret not found in keyring: %s", key)
+		}
+		return value, nil
+	}
+	out, err := exec.Command("secret-tool", "lookup", "service", k.service, "key", key).Output()
+	if err != nil || len(out) == 0 {
+		return "", fmt.Errorf("secret not found in keyring: %s", key)
+	}
+	return string(out), nil
+}
+
+func (k keyringSecretStore) Set(key, value string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		// security -i 从标准输入逐行读取命令，-U 更新已有条目；密钥值以 -X 十六进制传入，
+		// 其中的换行不会截断命令。名称不允许换行
+		if strings.ContainsAny(key, "\r\n") {
+			return fmt.Errorf("invalid secret key: %q", key)
+		}
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n",
+			keyringQuote(k.service), keyringQuote(key), hex.EncodeToString([]byte(value))))
+	} else {
+		cmd = exec.Command("secret-tool", "store", "--label", k.service+" "+key, "service", k.service, "key", key)
+		cmd.Stdin = strings.NewReader(value)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (k keyringSecretStore) Delete(key string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "delete-generic-password", "-s", k.service, "-a", key)
+	} else {
+		cmd = exec.Command("secret-tool", "clear", "service", k.service, "key", key)
+	}
+	cmd.Run() // 条目不存在时同样返回错误，忽略
+	return nil
+}
+
+// keyringQuote 为 security -i 的命令行参数加双引号
+func keyringQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// parseSecurityPassword 解析 security find-generic-password -g 输出的 password 行：
+// 可打印内容为 password: "value"，否则为 password: 0x<HEX>  "<转义内容>"
+func parseSecurityPassword(out string) (string, bool) {
+	for _, line := range strings.Split(out, "\n") {
+		rest, ok := strings.CutPrefix(line, "password: ")
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(rest, "0x") {
+			hexValue, _, _ := strings.Cut(rest[2:], " ")
+			data, err := hex.DecodeString(hexValue)
+			if err != nil {
+				return "", false
+			}
+			return string(data), true
+		}
+		if len(rest) >= 2 && strings.HasPrefix(rest, `"`) && strings.HasSuffix(rest, `"`) {
+			return rest[1 : len(rest)-1], true
+		}
+		return "", rest == ""
+	}
+	return "", false
+}
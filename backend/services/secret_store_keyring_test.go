@@ -0,0 +1,46 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSecurityPassword(t *testing.T) {
+	const header = "keychain: \"/Users/dev/Library/Keychains/login.keychain-db\"\nclass: \"genp\"\nattributes:\n    \"svce\"<blob>=\"agent-hub\"\n"
+	tests := []struct {
+		name  string
+		out   string
+		value string
+		ok    bool
+	}{
+		{name: "printable", out: header + "password: \"sk-123\"\n", value: "sk-123", ok: true},
+		{name: "quotes inside", out: header + "password: \"a\"b\"\n", value: "a\"b", ok: true},
+		{name: "hex with newline", out: header + "password: 0x6C696E65310A6C696E6532  \"line1\\012line2\"\n", value: "line1\nline2", ok: true},
+		{name: "hex only", out: "password: 0x736B2D313233\n", value: "sk-123", ok: true},
+		{name: "empty", out: header + "password: \n", value: "", ok: true},
+		{name: "invalid hex", out: "password: 0xZZ  \"x\"\n", ok: false},
+		{name: "unquoted", out: "password: sk-123\n", ok: false},
+		{name: "no password line", out: header, ok: false},
+	}
+	for _, tt := range tests {
+		value, ok := parseSecurityPassword(tt.out)
+		if ok != tt.ok || value != tt.value {
+			t.Errorf("%s: parseSecurityPassword() = %q, %v, want %q, %v", tt.name, value, ok, tt.value, tt.ok)
+		}
+	}
+}
+
+func TestKeyringServiceName(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	if got := keyringServiceName(&Environment{ConfigDir: absPath(filepath.Join(home, ".skills-manager"))}); got != keyringService {
+		t.Errorf("default environment: keyringServiceName() = %q, want %q", got, keyringService)
+	}
+	a := keyringServiceName(&Environment{ConfigDir: absPath(filepath.Join(home, "a"))})
+	b := keyringServiceName(&Environment{ConfigDir: absPath(filepath.Join(home, "b"))})
+	if a == keyringService || b == keyringService || a == b {
+		t.Errorf("isolated environments share a keyring service: %q, %q", a, b)
+	}
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ---- 加密文件 vault ----
+// secrets.vault 中每个条目单独用 AES-256-GCM 加密（附加数据为条目名称），密钥来自：
+//
+//	keyfile        本机随机密钥 secrets.key（0600），无需输入口令，防止 JSON 配置与导出文件泄露密钥
+//	pbkdf2-sha256  用户口令派生，每个进程需解锁一次（UnlockSecretVault 或 AGENT_HUB_SECRET_PASSPHRASE）
+
+const (
+	secretVaultFileName = "secrets.vault"
+	secretKeyFileName   = "secrets.key"
+	vaultKDFKeyFile     = "keyfile"
+	vaultKDFPassphrase  = "pbkdf2-sha256"
+	// vaultCheckValue 加密后保存在 vault 中，用于校验口令是否正确
+	vaultCheckValue = "agent-hub-secret-vault"
+)
+
+// vaultKeys 已解锁的口令 vault 密钥（vault 路径 -> 密钥），进程内有效
+var vaultKeys sync.Map
+
+// vaultFile secrets.vault 文件结构
+type vaultFile struct {
+	Version    int               `json:"version"`
+	KDF        string            `json:"kdf"`
+	Iterations int               `json:"iterations,omitempty"`
+	Salt       string            `json:"salt,omitempty"`
+	Check      string            `json:"check"`
+	Secrets    map[string]string `json:"secrets"`
+}
+
+// vaultSecretStore 加密文件实现
+type vaultSecretStore struct {
+	path    string
+	keyPath string
+}
+
+func newVaultSecretStore(env *Environment) *vaultSecretStore {
+	return &vaultSecretStore{
+		path:    filepath.Join(env.ConfigDir, secretVaultFileName),
+		keyPath: filepath.Join(env.ConfigDir, secretKeyFileName),
+	}
+}
+
+func (v *vaultSecretStore) Backend() string { return SecretBackendVault }
+
+// load 读取 vault，文件不存在时返回 nil
+func (v *vaultSecretStore) load() (*vaultFile, error) {
+	data, err := os.ReadFile(v.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret vault: %v", err)
+	}
+	var f vaultFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse secret vault: %v", err)
+	}
+	if f.Secrets == nil {
+		f.Secrets = map[string]string{}
+	}
+	return &f, nil
+}
+
+func (v *vaultSecretStore) write(f *vaultFile) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(v.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write secret vault: %v", err)
+	}
+	return nil
+}
+
+// readKeyFile 读取本机密钥文件
+func (v *vaultSecretStore) readKeyFile() ([]byte, error) {
+	data, err := os.ReadFile(v.keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret key file %s: %v", v.keyPath, err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("invalid secret key file: %s", v.keyPath)
+	}
+	return key, nil
+}
+
+// passphraseKey 从口令派生 f 的密钥并校验
+func (v *vaultSecretStore) passphraseKey(f *vaultFile, passphrase string) ([]byte, *secretCipher, error) {
+	salt, err := base64.StdEncoding.DecodeString(f.Salt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid secret vault salt: %v", err)
+	}
+	key, err := derivePassphraseKey(passphrase, salt, f.Iterations)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := newSecretCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if check, err := c.open("check", f.Check); err != nil || check != vaultCheckValue {
+		return nil, nil, errors.New("wrong secret vault passphrase")
+	}
+	return key, c, nil
+}
+
+// cipherFor 返回 f 的加解密器：密钥文件模式读取 secrets.key，口令模式使用已解锁的密钥或环境变量中的口令
+func (v *vaultSecretStore) cipherFor(f *vaultFile) (*secretCipher, error) {
+	if f.KDF == vaultKDFKeyFile {
+		key, err := v.readKeyFile()
+		if err != nil {
+			return nil, err
+		}
+		return newSecretCipher(key)
+	}
+	if f.KDF != vaultKDFPassphrase {
+		return nil, fmt.Errorf("unsupported secret vault kdf: %s", f.KDF)
+	}
+	if key, ok := vaultKeys.Load(v.path); ok {
+		return newSecretCipher(key.([]byte))
+	}
+	passphrase := os.Getenv(EnvSecretPassphrase)
+	if passphrase == "" {
+		return nil, errSecretVaultLocked
+	}
+	key, c, err := v.passphraseKey(f, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", EnvSecretPassphrase, err)
+	}
+	vaultKeys.Store(v.path, key)
+	return c, nil
+}
+
+// newVault 创建空 vault：passphrase 为空时生成本机密钥文件，否则使用口令
+func (v *vaultSecretStore) newVault(passphrase string) (*vaultFile, *secretCipher, error) {
+	f := &vaultFile{Version: 1, Secrets: map[string]string{}}
+	var key []byte
+	if passphrase == "" {
+		key = make([]byte, 32)
+		rand.Read(key)
+		if err := os.MkdirAll(filepath.Dir(v.keyPath), 0755); err != nil {
+			return nil, nil, err
+		}
+		if err := writeFileAtomic(v.keyPath, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+			return nil, nil, fmt.Errorf("failed to write secret key file: %v", err)
+		}
+		f.KDF = vaultKDFKeyFile
+	} else {
+		salt := make([]byte, 16)
+		rand.Read(salt)
+		var err error
+		if key, err = derivePassphraseKey(passphrase, salt, exportPBKDF2Iterations); err != nil {
+			return nil, nil, err
+		}
+		f.KDF, f.Iterations, f.Salt = vaultKDFPassphrase, exportPBKDF2Iterations, base64.StdEncoding.EncodeToString(salt)
+	}
+	c, err := newSecretCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	f.Check = c.seal("check", vaultCheckValue)
+	if f.KDF == vaultKDFPassphrase {
+		vaultKeys.Store(v.path, key)
+	} else {
+		vaultKeys.Delete(v.path)
+	}
+	return f, c, nil
+}
+
+func (v *vaultSecretStore) Get(key string) (string, error) {
+	f, err := v.load()
+	if err != nil {
+		return "", err
+	}
+	if f == nil || f.Secrets[key] == "" {
+		return "", fmt.Errorf("secret not found: %s", key)
+	}
+	c, err := v.cipherFor(f)
+	if err != nil {
+		return "", err
+	}
+	return c.open(key, f.Secrets[key])
+}
+
+func (v *vaultSecretStore) Set(key, value string) error {
+	return withFileLock(v.path, func() error {
+		f, err := v.load()
+		if err != nil {
+			return err
+		}
+		var c *secretCipher
+		if f == nil {
+			f, c, err = v.newVault("")
+		} else {
+			c, err = v.cipherFor(f)
+		}
+		if err != nil {
+			return err
+		}
+		if old, ok := f.Secrets[key]; ok {
+			if current, err := c.open(key, old); err == nil && current == value {
+				return nil
+			}
+		}
+		f.Secrets[key] = c.seal(key, value)
+		return v.write(f)
+	})
+}
+
+func (v *vaultSecretStore) Delete(key string) error {
+	if _, err := os.Stat(v.path); err != nil {
+		return nil
+	}
+	return withFileLock(v.path, func() error {
+		f, err := v.load()
+		if err != nil || f == nil {
+			return err
+		}
+		if _, ok := f.Secrets[key]; !ok {
+			return nil
+		}
+		delete(f.Secrets, key)
+		return v.write(f)
+	})
+}
+
+// status 返回 vault 是否使用口令以及当前是否锁定
+func (v *vaultSecretStore) status() (protected, locked bool, err error) {
+	f, err := v.load()
+	if err != nil || f == nil {
+		return false, false, err
+	}
+	if f.KDF != vaultKDFPassphrase {
+		return false, false, nil
+	}
+	_, err = v.cipherFor(f)
+	return true, err != nil, nil
+}
+
+// unlock 校验口令并缓存密钥
+func (v *vaultSecretStore) unlock(passphrase string) error {
+	f, err := v.load()
+	if err != nil {
+		return err
+	}
+	if f == nil || f.KDF != vaultKDFPassphrase {
+		return nil
+	}
+	key, _, err := v.passphraseKey(f, passphrase)
+	if err != nil {
+		return err
+	}
+	vaultKeys.Store(v.path, key)
+	return nil
+}
+
+// rekey 用新口令（为空时为新的本机密钥文件）重新加密全部条目
+func (v *vaultSecretStore) rekey(currentPassphrase, newPassphrase string) error {
+	return withFileLock(v.path, func() error {
+		f, err := v.load()
+		if err != nil {
+			return err
+		}
+		secrets := map[string]string{}
+		if f != nil {
+			var c *secretCipher
+			if f.KDF == vaultKDFPassphrase {
+				if currentPassphrase == "" {
+					return fmt.Errorf("the current passphrase is required")
+				}
+				_, c, err = v.passphraseKey(f, currentPassphrase)
+			} else {
+				c, err = v.cipherFor(f)
+			}
+			if err != nil {
+				return err
+			}
+			for key, sealed := range f.Secrets {
+				if secrets[key], err = c.open(key, sealed); err != nil {
+					return fmt.Errorf("failed to decrypt %s: %v", key, err)
+				}
+			}
+		}
+
+		next, c, err := v.newVault(newPassphrase)
+		if err != nil {
+			return err
+		}
+		for key, value := range secrets {
+			next.Secrets[key] = c.seal(key, value)
+		}
+		if err := v.write(next); err != nil {
+			return err
+		}
+		if newPassphrase != "" {
+			os.Remove(v.keyPath)
+		}
+		return nil
+	})
+}
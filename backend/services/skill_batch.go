@@ -0,0 +1,408 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ---- 批量操作 ----
+// 批量安装、删除与安装集合以有限并发逐项执行，返回每一项的状态与错误。
+// Atomic 模式下任一项失败即不再启动后续项，并将已完成的项恢复为执行前的状态
+// （内容、agent 软链接、.skills-lock 条目、回滚快照与版本历史）；各项的活动日志在整批提交后才写入
+
+// defaultBatchConcurrency 批量操作默认的并发数
+const defaultBatchConcurrency = 4
+
+// 批量操作单项状态
+const (
+	BatchStatusSucceeded  = "succeeded"
+	BatchStatusFailed     = "failed"
+	BatchStatusRolledBack = "rolled-back" // 已完成，但因其他项失败被回滚
+	BatchStatusSkipped    = "skipped"     // 因其他项失败未执行
+)
+
+// BatchOptions 批量操作选项
+type BatchOptions struct {
+	// Atomic 为 true 时全部成功或全部回滚
+	Atomic bool `json:"atomic"`
+	// Concurrency 同时执行的项数，0 表示默认值
+	Concurrency int `json:"concurrency,omitempty"`
+	// AllowRisk 为 true 时安装不因安全扫描风险而拒绝
+	AllowRisk bool `json:"allowRisk,omitempty"`
+}
+
+// BatchItemResult 批量操作中单项的结果
+type BatchItemResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // succeeded / failed / rolled-back / skipped
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchResult 批量操作结果，Items 与请求顺序一致
+type BatchResult struct {
+	Items      []BatchItemResult `json:"items"`
+	Succeeded  int               `json:"succeeded"`
+	Failed     int               `json:"failed"`
+	RolledBack bool              `json:"rolledBack"` // Atomic 模式下因失败撤销了整个批次
+}
+
+// failure 汇总失败项，没有失败时返回 nil
+func (r *BatchResult) failure() error {
+	var failed []string
+	for _, item := range r.Items {
+		if item.Status == BatchStatusFailed {
+			failed = append(failed, item.Name+": "+item.Error)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d failed: %s", len(failed), len(r.Items), strings.Join(failed, "; "))
+}
+
+// skillBackup 执行单项前保存的 skill 状态，用于 Atomic 模式的回滚
+type skillBackup struct {
+	name          string
+	dir           string            // 内容备份目录，skill 原本不存在时为空
+	entry         *SkillLockEntry   // 原 .skills-lock 条目
+	links         map[string]string // agent 目录中的软链接位置 -> 指向
+	snapshotDir   string            // 回滚快照的备份目录，原本没有快照时为空
+	snapshotEntry []byte            // 回滚快照的 .skills-lock 条目文件内容
+	versionsDir   string            // 版本历史的备份目录，原本没有版本历史时为空
+}
+
+// backupSkill 保存 skill 当前的内容、agent 软链接、.skills-lock 条目、回滚快照以及版本历史
+// 备份目录位于中央目录内并以 . 开头，列表时会被忽略，回滚时可直接 rename
+func (ss *SkillsService) backupSkill(skillName string) (*skillBackup, error) {
+	if err := validSkillName(skillName); err != nil {
+		return nil, err
+	}
+	b := &skillBackup{name: skillName, links: map[string]string{}}
+	ss.forEachAgentLinkPath(skillName, func(agent, linkPath string) {
+		if st := readLinkState(linkPath); st.isLink {
+			b.links[linkPath] = st.target
+		}
+	})
+
+	skillPath := filepath.Join(ss.env.SkillsDir, skillName)
+	if info, err := os.Stat(skillPath); err == nil && info.IsDir() {
+		dir, err := os.MkdirTemp(ss.env.SkillsDir, "."+skillName+".batch-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up %s: %v", skillName, err)
+		}
+		if err := copySkillDir(skillPath, dir); err != nil {
+			os.RemoveAll(dir)
+			return nil, fmt.Errorf("failed to back up %s: %v", skillName, err)
+		}
+		b.dir = dir
+	}
+
+	// 删除与重新安装都会替换回滚快照，一并备份以便恢复后仍可回滚
+	if ss.HasRollback(skillName) {
+		snapshotDir, snapshotEntry := ss.rollbackPaths(skillName)
+		dir, err := os.MkdirTemp(ss.env.SkillsDir, "."+skillName+".batch-rollback-")
+		if err != nil {
+			b.discard()
+			return nil, fmt.Errorf("failed to back up rollback snapshot of %s: %v", skillName, err)
+		}
+		b.snapshotDir = dir
+		if err := copySkillDir(snapshotDir, dir); err != nil {
+			b.discard()
+			return nil, fmt.Errorf("failed to back up rollback snapshot of %s: %v", skillName, err)
+		}
+		b.snapshotEntry, _ = os.ReadFile(snapshotEntry)
+	}
+
+	// 安装、更新与删除会新增版本并淘汰最旧的版本，备份到版本存储目录旁，恢复时整体替换
+	versionsDir := ss.skillVersionsDir(skillName)
+	if info, err := os.Stat(versionsDir); err == nil && info.IsDir() {
+		dir, err := os.MkdirTemp(filepath.Dir(versionsDir), "."+skillName+".batch-")
+		if err != nil {
+			b.discard()
+			return nil, fmt.Errorf("failed to back up versions of %s: %v", skillName, err)
+		}
+		b.versionsDir = dir
+		if err := copySkillDir(versionsDir, dir); err != nil {
+			b.discard()
+			return nil, fmt.Errorf("failed to back up versions of %s: %v", skillName, err)
+		}
+	}
+
+	if lock, err := ss.loadSkillsLock(); err == nil {
+		if entry, ok := lock.Skills[skillName]; ok {
+			b.entry = &entry
+		}
+	}
+	return b, nil
+}
+
+// restoreSkill 将 skill 恢复为备份时的状态
+func (ss *SkillsService) restoreSkill(b *skillBackup) error {
+	unlock, err := ss.lockSkill(b.name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	ss.forEachAgentLinkPath(b.name, func(agent, linkPath string) {
+		if readLinkState(linkPath).isLink {
+			os.Remove(linkPath)
+		}
+	})
+
+	skillPath := filepath.Join(ss.env.SkillsDir, b.name)
+	if err := os.RemoveAll(skillPath); err != nil {
+		return fmt.Errorf("failed to remove %s: %v", b.name, err)
+	}
+	if b.dir != "" {
+		if err := os.Rename(b.dir, skillPath); err != nil {
+			return fmt.Errorf("failed to restore %s: %v", b.name, err)
+		}
+		b.dir = ""
+	}
+
+	if err := ss.restoreRollbackSnapshot(b); err != nil {
+		return fmt.Errorf("failed to restore rollback snapshot of %s: %v", b.name, err)
+	}
+
+	versionsDir := ss.skillVersionsDir(b.name)
+	if err := os.RemoveAll(versionsDir); err != nil {
+		return fmt.Errorf("failed to restore versions of %s: %v", b.name, err)
+	}
+	if b.versionsDir != "" {
+		if err := os.Rename(b.versionsDir, versionsDir); err != nil {
+			return fmt.Errorf("failed to restore versions of %s: %v", b.name, err)
+		}
+		b.versionsDir = ""
+	}
+
+	for linkPath, target := range b.links {
+		if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+			continue
+		}
+		if _, err := os.Lstat(linkPath); err == nil {
+			continue
+		}
+		os.Symlink(target, linkPath)
+	}
+
+	if _, err := os.Stat(ss.env.SkillsLockPath()); err != nil && b.entry == nil {
+		return nil
+	}
+	return ss.modifySkillsLock(func(lock *SkillsLock) error {
+		if b.entry == nil {
+			delete(lock.Skills, b.name)
+		} else {
+			lock.Skills[b.name] = *b.entry
+		}
+		return nil
+	})
+}
+
+// restoreRollbackSnapshot 将回滚快照恢复为备份时的状态，原本没有快照时删除执行中产生的快照
+func (ss *SkillsService) restoreRollbackSnapshot(b *skillBackup) error {
+	ss.removeRollback(b.name)
+	if b.snapshotDir == "" {
+		return nil
+	}
+	snapshotDir, snapshotEntry := ss.rollbackPaths(b.name)
+	if err := os.MkdirAll(filepath.Dir(snapshotDir), 0755); err != nil {
+		return err
+	}
+	if b.snapshotEntry != nil {
+		if err := writeFileAtomic(snapshotEntry, b.snapshotEntry, 0644); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(b.snapshotDir, snapshotDir); err != nil {
+		os.Remove(snapshotEntry)
+		return err
+	}
+	b.snapshotDir = ""
+	return nil
+}
+
+// discard 删除备份内容
+func (b *skillBackup) discard() {
+	if b.dir != "" {
+		os.RemoveAll(b.dir)
+	}
+	if b.snapshotDir != "" {
+		os.RemoveAll(b.snapshotDir)
+	}
+	if b.versionsDir != "" {
+		os.RemoveAll(b.versionsDir)
+	}
+}
+
+// runBatch 以有限并发逐项执行 run；skillOf 返回每一项对应的 skill 名称，同一批中不允许重复
+// opts.Atomic 时任一项失败即停止启动新项，已完成与失败的项都恢复为执行前的状态。
+// run 需通过传入的 SkillsService 执行，Atomic 模式下其活动日志先暂存：整批提交后全部写入，
+// 回滚时只写入失败项的日志（如信任策略拦截记录），被回滚的项不留下日志
+func (ss *SkillsService) runBatch(names []string, opts BatchOptions, skillOf func(name string) (string, error), run func(ss *SkillsService, name string) error) *BatchResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	result := &BatchResult{Items: make([]BatchItemResult, len(names))}
+	backups := make([]*skillBackup, len(names))
+	logs := make([][]ActivityLog, len(names))
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		aborted bool
+	)
+	fail := func(item *BatchItemResult, err error) {
+		item.Status, item.Error = BatchStatusFailed, err.Error()
+		if opts.Atomic {
+			mu.Lock()
+			aborted = true
+			mu.Unlock()
+		}
+	}
+
+	// 先校验全部名称，Atomic 模式下存在无效或重复的项时不执行任何一项
+	skillNames := make([]string, len(names))
+	seen := make(map[string]string)
+	for i, name := range names {
+		item := &result.Items[i]
+		item.Name = name
+		skillName, err := skillOf(name)
+		if err == nil {
+			if prev, ok := seen[skillName]; ok {
+				err = fmt.Errorf("skill %s is already requested by %s", skillName, prev)
+			}
+		}
+		if err != nil {
+			fail(item, err)
+			continue
+		}
+		seen[skillName] = name
+		skillNames[i] = skillName
+	}
+
+	sem := make(chan struct{}, concurrency)
+	for i, name := range names {
+		item := &result.Items[i]
+		if item.Status != "" {
+			continue
+		}
+		skillName := skillNames[i]
+
+		sem <- struct{}{}
+		mu.Lock()
+		stop := aborted
+		mu.Unlock()
+		if stop {
+			<-sem
+			item.Status = BatchStatusSkipped
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, name, skillName string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			item := &result.Items[i]
+			itemSS := ss
+			if opts.Atomic {
+				backup, err := ss.backupSkill(skillName)
+				if err != nil {
+					fail(item, err)
+					return
+				}
+				backups[i] = backup
+				deferred := *ss
+				deferred.deferredLogs = &logs[i]
+				itemSS = &deferred
+			}
+			if err := run(itemSS, name); err != nil {
+				fail(item, err)
+				return
+			}
+			item.Status = BatchStatusSucceeded
+		}(i, name, skillName)
+	}
+	wg.Wait()
+
+	if aborted {
+		// 逆序恢复，失败项也恢复以清理执行到一半的状态
+		for i := len(backups) - 1; i >= 0; i-- {
+			b := backups[i]
+			if b == nil {
+				continue
+			}
+			item := &result.Items[i]
+			if err := ss.restoreSkill(b); err != nil {
+				item.Status, item.Error = BatchStatusFailed, "rollback failed: "+err.Error()
+			} else if item.Status == BatchStatusSucceeded {
+				item.Status = BatchStatusRolledBack
+			}
+		}
+		result.RolledBack = true
+	}
+	for _, b := range backups {
+		if b != nil {
+			b.discard()
+		}
+	}
+	for i, itemLogs := range logs {
+		if aborted && result.Items[i].Status != BatchStatusFailed {
+			continue
+		}
+		for _, log := range itemLogs {
+			if err := metadataStore(ss.env).AddActivityLog(log); err != nil {
+				fmt.Printf("[runBatch] warning: failed to record activity: %v\n", err)
+			}
+		}
+	}
+
+	for _, item := range result.Items {
+		switch item.Status {
+		case BatchStatusSucceeded:
+			result.Succeeded++
+		case BatchStatusFailed:
+			result.Failed++
+		}
+	}
+	return result
+}
+
+// installBatch 批量安装 source@skill，被信任策略拦截或安全扫描阻止的项记为失败
+func (ss *SkillsService) installBatch(fullNames []string, agents []string, opts BatchOptions) *BatchResult {
+	skillOf := func(fullName string) (string, error) {
+		_, skillName, _, err := parseSkillFullName(fullName)
+		if err != nil {
+			return "", err
+		}
+		return skillName, validSkillName(skillName)
+	}
+	return ss.runBatch(fullNames, opts, skillOf, func(ss *SkillsService, fullName string) error {
+		return ss.InstallRemoteSkillWithOptions(fullName, agents, InstallOptions{AllowRisk: opts.AllowRisk})
+	})
+}
+
+// BatchInstallFromRepoWithOptions 批量安装选中的 skills，返回每一项的结果
+func (ss *SkillsService) BatchInstallFromRepoWithOptions(fullNames []string, agents []string, opts BatchOptions) (*BatchResult, error) {
+	return ss.installBatch(fullNames, agents, opts), nil
+}
+
+// InstallCollectionWithOptions 安装集合中的所有 skills，返回每一项的结果
+func (ss *SkillsService) InstallCollectionWithOptions(name string, agents []string, opts BatchOptions) (*BatchResult, error) {
+	collection, err := findCollection(ss.env, name)
+	if err != nil {
+		return nil, err
+	}
+	return ss.installBatch(collection.Skills, agents, opts), nil
+}
+
+// BatchDeleteSkillsWithOptions 批量删除 skills，返回每一项的结果
+func (ss *SkillsService) BatchDeleteSkillsWithOptions(skillNames []string, opts BatchOptions) (*BatchResult, error) {
+	skillOf := func(name string) (string, error) {
+		return name, validSkillName(name)
+	}
+	return ss.runBatch(skillNames, opts, skillOf, (*SkillsService).DeleteSkill), nil
+}
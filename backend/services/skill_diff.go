@@ -0,0 +1,319 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ---- 目录级 diff ----
+
+// diffContextLines 每个 hunk 前后保留的上下文行数（与 git diff 默认值一致）
+const diffContextLines = 3
+
+// 文件变更状态
+const (
+	FileDiffAdded    = "added"
+	FileDiffRemoved  = "removed"
+	FileDiffModified = "modified"
+)
+
+// 行类型
+const (
+	DiffLineContext = "context"
+	DiffLineAdd     = "add"
+	DiffLineDelete  = "delete"
+)
+
+// DiffLine diff 中的一行
+type DiffLine struct {
+	Type    string `json:"type"`    // context / add / delete
+	Content string `json:"content"` // 行内容（不含换行符）
+	OldLine int    `json:"oldLine"` // 旧文件中的行号，新增行为 0
+	NewLine int    `json:"newLine"` // 新文件中的行号，删除行为 0
+}
+
+// DiffHunk 一个 unified diff 块
+type DiffHunk struct {
+	OldStart int        `json:"oldStart"`
+	OldLines int        `json:"oldLines"`
+	NewStart int        `json:"newStart"`
+	NewLines int        `json:"newLines"`
+	Header   string     `json:"header"` // @@ -a,b +c,d @@
+	Lines    []DiffLine `json:"lines"`
+}
+
+// FileDiff 单个文件的变更
+type FileDiff struct {
+	Path      string     `json:"path"`   // 相对路径，统一使用 /
+	Status    string     `json:"status"` // added / removed / modified
+	Binary    bool       `json:"binary"` // 二进制文件只报告状态，不生成 hunk
+	Additions int        `json:"additions"`
+	Deletions int        `json:"deletions"`
+	Hunks     []DiffHunk `json:"hunks"`
+}
+
+// TreeDiff 两个目录之间的差异
+type TreeDiff struct {
+	Files      []FileDiff `json:"files"`
+	Additions  int        `json:"additions"`
+	Deletions  int        `json:"deletions"`
+	HasChanges bool       `json:"hasChanges"`
+}
+
+// diffTrees 对比两个目录（旧 -> 新），任一目录不存在时视为空目录
+// skip 可过滤不参与对比的相对路径，传 nil 表示对比全部文件
+func diffTrees(oldDir, newDir string, skip func(relPath string) bool) (*TreeDiff, error) {
+	oldFiles, err := treeFileHashes(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := treeFileHashes(newDir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(oldFiles)+len(newFiles))
+	for p := range oldFiles {
+		paths = append(paths, p)
+	}
+	for p := range newFiles {
+		if _, ok := oldFiles[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	result := &TreeDiff{Files: []FileDiff{}}
+	for _, p := range paths {
+		if skip != nil && skip(p) {
+			continue
+		}
+		oldHash, inOld := oldFiles[p]
+		newHash, inNew := newFiles[p]
+		if inOld && inNew && oldHash == newHash {
+			continue
+		}
+
+		var oldData, newData []byte
+		status := FileDiffModified
+		if inOld {
+			if oldData, err = readTreeFile(oldDir, p); err != nil {
+				return nil, err
+			}
+		} else {
+			status = FileDiffAdded
+		}
+		if inNew {
+			if newData, err = readTreeFile(newDir, p); err != nil {
+				return nil, err
+			}
+		} else {
+			status = FileDiffRemoved
+		}
+
+		fd := diffFileContents(p, status, oldData, newData)
+		result.Additions += fd.Additions
+		result.Deletions += fd.Deletions
+		result.Files = append(result.Files, fd)
+	}
+	result.HasChanges = len(result.Files) > 0
+	return result, nil
+}
+
+// treeFileHashes 返回目录下所有文件的哈希，目录不存在时返回空
+func treeFileHashes(dir string) (map[string]string, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return map[string]string{}, nil
+	}
+	_, files, err := hashSkillTree(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", dir, err)
+	}
+	return files, nil
+}
+
+// readTreeFile 读取目录中的文件，软链接返回其指向（与 hashSkillTree 一致）
+func readTreeFile(dir, relPath string) ([]byte, error) {
+	path := filepath.Join(dir, filepath.FromSlash(relPath))
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(target + "\n"), nil
+	}
+	return os.ReadFile(path)
+}
+
+// isBinaryContent 判断内容是否为二进制：前 8000 字节含 NUL 或不是合法 UTF-8
+func isBinaryContent(data []byte) bool {
+	head := data
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return true
+	}
+	return !utf8.Valid(data)
+}
+
+// diffFileContents 生成单个文件的 unified diff
+func diffFileContents(path, status string, oldData, newData []byte) FileDiff {
+	fd := FileDiff{Path: path, Status: status, Hunks: []DiffHunk{}}
+	if isBinaryContent(oldData) || isBinaryContent(newData) {
+		fd.Binary = true
+		return fd
+	}
+
+	oldLines, newLines := splitLines(string(oldData)), splitLines(string(newData))
+	matches := lcsMatches(oldLines, newLines)
+
+	// 先生成完整的逐行序列，再按上下文切分为 hunk
+	var all []DiffLine
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		if i < len(oldLines) && matches[i] == -1 {
+			all = append(all, DiffLine{Type: DiffLineDelete, Content: trimEOL(oldLines[i]), OldLine: i + 1})
+			i++
+			continue
+		}
+		if j < len(newLines) && (i >= len(oldLines) || j < matches[i]) {
+			all = append(all, DiffLine{Type: DiffLineAdd, Content: trimEOL(newLines[j]), NewLine: j + 1})
+			j++
+			continue
+		}
+		all = append(all, DiffLine{Type: DiffLineContext, Content: trimEOL(oldLines[i]), OldLine: i + 1, NewLine: j + 1})
+		i++
+		j++
+	}
+
+	for _, l := range all {
+		switch l.Type {
+		case DiffLineAdd:
+			fd.Additions++
+		case DiffLineDelete:
+			fd.Deletions++
+		}
+	}
+	fd.Hunks = buildHunks(all)
+	return fd
+}
+
+// buildHunks 将逐行序列切分为带上下文的 hunk，相距不超过 2*context 的改动合并到同一个 hunk
+func buildHunks(all []DiffLine) []DiffHunk {
+	hunks := []DiffHunk{}
+	for k := 0; k < len(all); {
+		if all[k].Type == DiffLineContext {
+			k++
+			continue
+		}
+		start := max(k-diffContextLines, 0)
+		end := k
+		for end < len(all) {
+			if all[end].Type != DiffLineContext {
+				end++
+				continue
+			}
+			// 统计连续的上下文行，足够长则结束当前 hunk
+			run := end
+			for run < len(all) && all[run].Type == DiffLineContext {
+				run++
+			}
+			if run == len(all) || run-end > 2*diffContextLines {
+				end = min(end+diffContextLines, len(all))
+				break
+			}
+			end = run
+		}
+
+		h := DiffHunk{Lines: all[start:end]}
+		for _, l := range h.Lines {
+			if l.Type != DiffLineAdd {
+				if h.OldStart == 0 {
+					h.OldStart = l.OldLine
+				}
+				h.OldLines++
+			}
+			if l.Type != DiffLineDelete {
+				if h.NewStart == 0 {
+					h.NewStart = l.NewLine
+				}
+				h.NewLines++
+			}
+		}
+		// 空的一侧（新增 / 删除整个文件）按 unified diff 约定使用前一行的行号
+		if h.OldLines == 0 {
+			h.OldStart = start - countType(all[:start], DiffLineAdd)
+		}
+		if h.NewLines == 0 {
+			h.NewStart = start - countType(all[:start], DiffLineDelete)
+		}
+		h.Header = fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))
+		hunks = append(hunks, h)
+		k = end
+	}
+	return hunks
+}
+
+// countType 统计指定类型的行数
+func countType(lines []DiffLine, typ string) int {
+	n := 0
+	for _, l := range lines {
+		if l.Type == typ {
+			n++
+		}
+	}
+	return n
+}
+
+// hunkRange 格式化 hunk 头中的行范围，行数为 1 时省略
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// trimEOL 去掉行尾换行符
+func trimEOL(line string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+}
+
+// UnifiedDiff 将目录差异格式化为 git 风格的 unified diff 文本
+func (d *TreeDiff) UnifiedDiff() string {
+	var b strings.Builder
+	for _, f := range d.Files {
+		oldName, newName := "a/"+f.Path, "b/"+f.Path
+		switch f.Status {
+		case FileDiffAdded:
+			oldName = "/dev/null"
+		case FileDiffRemoved:
+			newName = "/dev/null"
+		}
+		if f.Binary {
+			fmt.Fprintf(&b, "Binary files %s and %s differ\n", oldName, newName)
+			continue
+		}
+		fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+		for _, h := range f.Hunks {
+			b.WriteString(h.Header + "\n")
+			for _, l := range h.Lines {
+				switch l.Type {
+				case DiffLineAdd:
+					b.WriteString("+")
+				case DiffLineDelete:
+					b.WriteString("-")
+				default:
+					b.WriteString(" ")
+				}
+				b.WriteString(l.Content + "\n")
+			}
+		}
+	}
+	return b.String()
+}
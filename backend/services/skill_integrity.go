@@ -0,0 +1,246 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ---- 内容完整性校验 ----
+
+// 校验状态
+const (
+	VerifyStatusOK         = "ok"         // 内容与安装时一致
+	VerifyStatusModified   = "modified"   // 文件被修改、删除或新增
+	VerifyStatusMissing    = "missing"    // skill 目录不存在
+	VerifyStatusUnverified = "unverified" // .skills-lock 中没有记录哈希（本地创建或旧版本安装）
+)
+
+// SkillVerifyResult 单个 skill 的完整性校验结果
+type SkillVerifyResult struct {
+	Name          string   `json:"name"`
+	Status        string   `json:"status"`
+	ExpectedHash  string   `json:"expectedHash"`
+	ActualHash    string   `json:"actualHash"`
+	CommitSHA     string   `json:"commitSha"`
+	ModifiedFiles []string `json:"modifiedFiles"`
+	MissingFiles  []string `json:"missingFiles"`
+	ExtraFiles    []string `json:"extraFiles"`
+}
+
+// hashSkillTree 计算 skill 目录的内容哈希
+// 返回树哈希（sha256:<hex>）以及每个文件的哈希（相对路径 -> sha256），相对路径统一使用 /
+func hashSkillTree(dir string) (string, map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if name == ".git" && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if name == ".DS_Store" {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		h := sha256.New()
+		if info.Mode()&os.ModeSymlink != 0 {
+			// 软链接只记录指向，不跟随
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			h.Write([]byte("symlink:" + target))
+		} else {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(h, f)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+		files[relPath] = hex.EncodeToString(h.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return treeHashOf(files), files, nil
+}
+
+// copySkillDir 复制 skill 目录，软链接按链接本身复制而不跟随，与 hashSkillTree 的处理一致：
+// 暂存、版本快照与批量备份的内容哈希和来源相同
+func copySkillDir(src, dst string) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		srcPath := filepath.Join(src, entry.Name())
+		dstPath := filepath.Join(dst, entry.Name())
+		switch {
+		case entry.Type()&os.ModeSymlink != 0:
+			target, err := os.Readlink(srcPath)
+			if err != nil {
+				return err
+			}
+			if err := os.Symlink(target, dstPath); err != nil {
+				return err
+			}
+		case entry.IsDir():
+			if err := copySkillDir(srcPath, dstPath); err != nil {
+				return err
+			}
+		default:
+			if err := copyFile(srcPath, dstPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkSkillSymlinks 拒绝指向 skill 目录之外的软链接（绝对路径或经 .. 跳出），
+// 从来源获取的 skill 在安装与更新前检查
+func checkSkillSymlinks(dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		relPath, _ := filepath.Rel(dir, path)
+		if filepath.IsAbs(target) || strings.HasPrefix(target, "/") || strings.HasPrefix(target, `\`) {
+			return fmt.Errorf("symlink %s points outside the skill: %s", filepath.ToSlash(relPath), target)
+		}
+		resolved, err := filepath.Rel(dir, filepath.Join(filepath.Dir(path), target))
+		if err != nil || resolved == ".." || strings.HasPrefix(resolved, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("symlink %s points outside the skill: %s", filepath.ToSlash(relPath), target)
+		}
+		return nil
+	})
+}
+
+// treeHashOf 按相对路径排序后对 "路径\x00文件哈希\n" 序列整体求 sha256
+func treeHashOf(files map[string]string) string {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	h := sha256.New()
+	for _, p := range paths {
+		fmt.Fprintf(h, "%s\x00%s\n", p, files[p])
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// gitHeadSHA 返回本地仓库 HEAD 的 commit SHA，失败时返回空字符串
+func gitHeadSHA(repoDir string) string {
+	out, err := exec.Command("git", "-C", repoDir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// verifySkill 将 skill 目录的当前内容与 .skills-lock 中记录的哈希对比
+func (ss *SkillsService) verifySkill(name string, entry SkillLockEntry) SkillVerifyResult {
+	result := SkillVerifyResult{
+		Name:         name,
+		ExpectedHash: entry.TreeHash,
+		CommitSHA:    entry.CommitSHA,
+	}
+
+	skillDir := filepath.Join(ss.env.SkillsDir, name)
+	if info, err := os.Stat(skillDir); err != nil || !info.IsDir() {
+		result.Status = VerifyStatusMissing
+		return result
+	}
+
+	actualHash, actualFiles, err := hashSkillTree(skillDir)
+	if err != nil {
+		result.Status = VerifyStatusMissing
+		return result
+	}
+	result.ActualHash = actualHash
+
+	if entry.TreeHash == "" {
+		result.Status = VerifyStatusUnverified
+		return result
+	}
+	if actualHash == entry.TreeHash {
+		result.Status = VerifyStatusOK
+		return result
+	}
+
+	result.Status = VerifyStatusModified
+	for path, expected := range entry.Files {
+		actual, ok := actualFiles[path]
+		if !ok {
+			result.MissingFiles = append(result.MissingFiles, path)
+		} else if actual != expected {
+			result.ModifiedFiles = append(result.ModifiedFiles, path)
+		}
+	}
+	for path := range actualFiles {
+		if _, ok := entry.Files[path]; !ok {
+			result.ExtraFiles = append(result.ExtraFiles, path)
+		}
+	}
+	sort.Strings(result.ModifiedFiles)
+	sort.Strings(result.MissingFiles)
+	sort.Strings(result.ExtraFiles)
+	return result
+}
+
+// VerifySkills 校验 .skills-lock 中所有 skill 的内容完整性
+// 报告每个 skill 被修改、缺失以及多出的文件
+func (ss *SkillsService) VerifySkills() ([]SkillVerifyResult, error) {
+	lock, err := ss.loadSkillsLock()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(lock.Skills))
+	for name := range lock.Skills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]SkillVerifyResult, 0, len(names))
+	for _, name := range names {
+		results = append(results, ss.verifySkill(name, lock.Skills[name]))
+	}
+	return results, nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckSkillSymlinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		link    string // 相对 skill 目录的链接位置
+		target  string
+		wantErr bool
+	}{
+		{name: "sibling file", link: "README.md", target: "SKILL.md"},
+		{name: "nested to parent", link: "docs/guide.md", target: "../SKILL.md"},
+		{name: "directory", link: "lib", target: "scripts"},
+		{name: "parent of skill", link: "escape", target: "..", wantErr: true},
+		{name: "outside via nested", link: "docs/escape", target: "../../secret", wantErr: true},
+		{name: "absolute", link: "passwd", target: "/etc/passwd", wantErr: true},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeTestFile(t, filepath.Join(dir, "SKILL.md"), "---\nname: pdf\n---\n")
+		writeTestFile(t, filepath.Join(dir, "scripts", "run.sh"), "echo ok\n")
+		linkPath := filepath.Join(dir, filepath.FromSlash(tt.link))
+		if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.FromSlash(tt.target), linkPath); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+		err := checkSkillSymlinks(dir)
+		if tt.wantErr && err == nil {
+			t.Errorf("%s: checkSkillSymlinks() accepted %s -> %s", tt.name, tt.link, tt.target)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: checkSkillSymlinks() error: %v", tt.name, err)
+		}
+	}
+}
+
+func TestCopySkillDirKeepsTreeHash(t *testing.T) {
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "SKILL.md"), "---\nname: pdf\n---\n")
+	writeTestFile(t, filepath.Join(src, "scripts", "run.sh"), "echo ok\n")
+	if err := os.Symlink("SKILL.md", filepath.Join(src, "README.md")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink("scripts", filepath.Join(src, "lib")); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "pdf")
+	if err := copySkillDir(src, dst); err != nil {
+		t.Fatalf("copySkillDir() error: %v", err)
+	}
+	for _, name := range []string{"README.md", "lib"} {
+		info, err := os.Lstat(filepath.Join(dst, name))
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			t.Errorf("%s was not copied as a symlink", name)
+		}
+	}
+
+	srcHash, _, err := hashSkillTree(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstHash, _, err := hashSkillTree(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srcHash != dstHash {
+		t.Errorf("tree hash changed after copy: %s != %s", srcHash, dstHash)
+	}
+}
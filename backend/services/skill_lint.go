@@ -0,0 +1,359 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ---- SKILL.md 校验 ----
+
+// 诊断级别
+const (
+	LintSeverityError   = "error"
+	LintSeverityWarning = "warning"
+)
+
+// 诊断规则
+const (
+	LintFrontmatterMissing      = "frontmatter-missing"
+	LintFrontmatterUnterminated = "frontmatter-unterminated"
+	LintFrontmatterInvalid      = "frontmatter-invalid"
+	LintNameMissing             = "name-missing"
+	LintNameInvalid             = "name-invalid"
+	LintNameMismatch            = "name-mismatch"
+	LintDescriptionMissing      = "description-missing"
+	LintDescriptionTooLong      = "description-too-long"
+	LintFieldType               = "field-type"
+	LintUnsupportedKey          = "unsupported-key"
+	LintBrokenLink              = "broken-link"
+	LintLinkOutsideSkill        = "link-outside-skill"
+	LintEmptyBody               = "empty-body"
+)
+
+const (
+	maxSkillNameLength        = 64
+	maxSkillDescriptionLength = 1024
+)
+
+// LintDiagnostic 一条校验结果，行列号从 1 开始，0 表示不针对具体位置
+type LintDiagnostic struct {
+	Severity string `json:"severity"` // error / warning
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+	File     string `json:"file"` // 相对 skill 目录的文件路径
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+// LintResult SKILL.md 的校验结果
+type LintResult struct {
+	Diagnostics []LintDiagnostic `json:"diagnostics"`
+	Errors      int              `json:"errors"`
+	Warnings    int              `json:"warnings"`
+}
+
+// Valid 没有 error 级别的诊断
+func (r *LintResult) Valid() bool {
+	return r.Errors == 0
+}
+
+// FirstError 返回第一条 error 级别的诊断，用于拼接错误信息
+func (r *LintResult) FirstError() *LintDiagnostic {
+	for i := range r.Diagnostics {
+		if r.Diagnostics[i].Severity == LintSeverityError {
+			return &r.Diagnostics[i]
+		}
+	}
+	return nil
+}
+
+func (r *LintResult) add(severity, rule string, line, column int, format string, args ...interface{}) {
+	r.Diagnostics = append(r.Diagnostics, LintDiagnostic{
+		Severity: severity,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+		File:     "SKILL.md",
+		Line:     line,
+		Column:   column,
+	})
+	if severity == LintSeverityError {
+		r.Errors++
+	} else {
+		r.Warnings++
+	}
+}
+
+// knownManifestKeys SkillManifest 能识别的规范字段名，其余字段视为不支持
+var knownManifestKeys = map[string]bool{
+	"name":                  true,
+	"description":           true,
+	"version":               true,
+	"license":               true,
+	"language":              true,
+	"framework":             true,
+	"tags":                  true,
+	"allowed-tools":         true,
+	"dependencies":          true,
+	"optional-dependencies": true,
+	"conflicts":             true,
+	"compatible-agents":     true,
+	"metadata":              true,
+}
+
+var (
+	reValidSkillName  = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	reYAMLErrorLine   = regexp.MustCompile(`line (\d+)`)
+	reMarkdownLink    = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+["'(][^)]*)?\)`)
+	reMarkdownLinkDef = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s*<?([^\s>]+)>?`)
+	reInlineCode      = regexp.MustCompile("`[^`]*`")
+)
+
+// LintSkill 校验已安装 skill 的 SKILL.md，包括名称与目录是否一致、相对链接指向的文件是否存在
+func (ss *SkillsService) LintSkill(skillName string) (*LintResult, error) {
+	if err := validSkillName(skillName); err != nil {
+		return nil, err
+	}
+	skillPath := filepath.Join(ss.env.SkillsDir, skillName)
+	content, err := os.ReadFile(filepath.Join(skillPath, "SKILL.md"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("skill not found: %s", skillName)
+		}
+		return nil, fmt.Errorf("failed to read SKILL.md: %v", err)
+	}
+	return lintSkill(string(content), skillName, skillPath), nil
+}
+
+// LintSkillContent 校验 SKILL.md 内容，不依赖 skill 目录，因此不检查名称与目录、相对链接
+func (ss *SkillsService) LintSkillContent(content string) *LintResult {
+	return lintSkill(content, "", "")
+}
+
+// lintSkill 校验 SKILL.md；skillName / skillDir 为空时跳过依赖目录的检查
+func lintSkill(content, skillName, skillDir string) *LintResult {
+	result := &LintResult{Diagnostics: []LintDiagnostic{}}
+	content = strings.TrimPrefix(content, "\ufeff")
+	lines := strings.Split(content, "\n")
+
+	// 定位 frontmatter，与 splitFrontmatter 的规则一致
+	start := 0
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	bodyStart := 0
+	if start >= len(lines) || strings.TrimSpace(lines[start]) != "---" {
+		result.add(LintSeverityError, LintFrontmatterMissing, 1, 1, "SKILL.md must start with a YAML frontmatter block delimited by ---")
+	} else {
+		end := -1
+		for i := start + 1; i < len(lines); i++ {
+			if t := strings.TrimRight(lines[i], "\r"); t == "---" || t == "..." {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			result.add(LintSeverityError, LintFrontmatterUnterminated, start+1, 1, "frontmatter is not closed by ---")
+		} else {
+			lintFrontmatter(result, strings.Join(lines[start+1:end], "\n"), start+1, skillName)
+			bodyStart = end + 1
+		}
+	}
+
+	if bodyStart > 0 && strings.TrimSpace(strings.Join(lines[bodyStart:], "\n")) == "" {
+		result.add(LintSeverityWarning, LintEmptyBody, bodyStart, 1, "skill has no instructions after the frontmatter")
+	}
+	if skillDir != "" {
+		lintLinks(result, lines[bodyStart:], bodyStart, skillDir)
+	}
+
+	sort.SliceStable(result.Diagnostics, func(i, j int) bool {
+		a, b := result.Diagnostics[i], result.Diagnostics[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return result
+}
+
+// lintFrontmatter 校验 frontmatter 字段，offset 为 frontmatter 第一行之前的行数
+func lintFrontmatter(result *LintResult, frontmatter string, offset int, skillName string) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(frontmatter), &doc); err != nil {
+		// yaml 错误信息中的行号相对 frontmatter，换算为文件行号后从信息中去掉
+		line, msg := offset+1, strings.TrimPrefix(err.Error(), "yaml: ")
+		if m := reYAMLErrorLine.FindStringSubmatch(msg); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			line = offset + n
+			msg = strings.Replace(msg, m[0]+": ", "", 1)
+		}
+		result.add(LintSeverityError, LintFrontmatterInvalid, line, 1, "invalid YAML: %s", msg)
+		return
+	}
+	if len(doc.Content) == 0 {
+		result.add(LintSeverityError, LintNameMissing, offset, 1, "frontmatter is missing required field \"name\"")
+		result.add(LintSeverityError, LintDescriptionMissing, offset, 1, "frontmatter is missing required field \"description\"")
+		return
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		result.add(LintSeverityError, LintFrontmatterInvalid, offset+root.Line, root.Column, "frontmatter must be a mapping of key: value pairs")
+		return
+	}
+
+	var nameNode, descNode *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keyNode, valueNode := root.Content[i], resolveAlias(root.Content[i+1])
+		canonical := strings.ToLower(keyNode.Value)
+		if alias, ok := manifestKeyAliases[canonical]; ok {
+			canonical = alias
+		}
+		switch {
+		case canonical == "name":
+			nameNode = valueNode
+		case canonical == "description":
+			descNode = valueNode
+		case !knownManifestKeys[canonical]:
+			result.add(LintSeverityWarning, LintUnsupportedKey, offset+keyNode.Line, keyNode.Column,
+				"unsupported frontmatter key %q (move custom fields under \"metadata\")", keyNode.Value)
+			continue
+		}
+		if (canonical == "name" || canonical == "description" || canonical == "version" || canonical == "license") &&
+			valueNode.Kind != yaml.ScalarNode {
+			result.add(LintSeverityError, LintFieldType, offset+valueNode.Line, valueNode.Column, "%q must be a string", keyNode.Value)
+		}
+	}
+
+	name := nodeString(nameNode)
+	switch {
+	case name == "":
+		line, col := offset, 1
+		if nameNode != nil {
+			line, col = offset+nameNode.Line, nameNode.Column
+		}
+		result.add(LintSeverityError, LintNameMissing, line, col, "frontmatter is missing required field \"name\"")
+	default:
+		line, col := offset+nameNode.Line, nameNode.Column
+		if len(name) > maxSkillNameLength {
+			result.add(LintSeverityError, LintNameInvalid, line, col, "name is %d characters long, the limit is %d", len(name), maxSkillNameLength)
+		} else if !reValidSkillName.MatchString(name) {
+			result.add(LintSeverityWarning, LintNameInvalid, line, col, "name %q should only contain lowercase letters, digits and hyphens", name)
+		}
+		if skillName != "" && name != skillName {
+			result.add(LintSeverityError, LintNameMismatch, line, col, "name %q does not match the skill directory %q", name, skillName)
+		}
+	}
+
+	desc := nodeString(descNode)
+	switch {
+	case desc == "":
+		line, col := offset, 1
+		if descNode != nil {
+			line, col = offset+descNode.Line, descNode.Column
+		}
+		result.add(LintSeverityError, LintDescriptionMissing, line, col, "frontmatter is missing required field \"description\"")
+	case utf8.RuneCountInString(desc) > maxSkillDescriptionLength:
+		result.add(LintSeverityError, LintDescriptionTooLong, offset+descNode.Line, descNode.Column,
+			"description is %d characters long, the limit is %d", utf8.RuneCountInString(desc), maxSkillDescriptionLength)
+	}
+}
+
+// lintLinks 检查正文中指向 skill 目录内文件的相对链接，offset 为正文第一行之前的行数
+func lintLinks(result *LintResult, lines []string, offset int, skillDir string) {
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		// 行内代码中的内容不是链接，替换为等长空白以保持列号
+		scan := reInlineCode.ReplaceAllStringFunc(line, func(s string) string { return strings.Repeat(" ", len(s)) })
+
+		var targets [][]int
+		for _, m := range reMarkdownLink.FindAllStringSubmatchIndex(scan, -1) {
+			targets = append(targets, m[2:4])
+		}
+		if m := reMarkdownLinkDef.FindStringSubmatchIndex(scan); m != nil {
+			targets = append(targets, m[2:4])
+		}
+		for _, t := range targets {
+			target := scan[t[0]:t[1]]
+			relPath, ok := localLinkPath(target)
+			if !ok {
+				continue
+			}
+			lineNo, col := offset+i+1, utf8.RuneCountInString(line[:t[0]])+1
+			full := filepath.Join(skillDir, filepath.FromSlash(relPath))
+			if rel, err := filepath.Rel(skillDir, full); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				result.add(LintSeverityWarning, LintLinkOutsideSkill, lineNo, col, "link %q points outside the skill directory", target)
+				continue
+			}
+			if _, err := os.Stat(full); err != nil {
+				result.add(LintSeverityError, LintBrokenLink, lineNo, col, "link %q points to a file that does not exist in the skill", target)
+			}
+		}
+	}
+}
+
+// localLinkPath 返回相对链接指向的文件路径，外部链接、锚点和绝对路径返回 false
+func localLinkPath(target string) (string, bool) {
+	if target == "" || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "/") ||
+		strings.Contains(target, "://") || strings.Contains(target, "{{") {
+		return "", false
+	}
+	if scheme, _, found := strings.Cut(target, ":"); found && !strings.ContainsAny(scheme, "/.") {
+		return "", false // mailto:、data: 等
+	}
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		target = target[:i]
+	}
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+	if target == "" || target == "." {
+		return "", false
+	}
+	return target, true
+}
+
+// lintBeforeWrite 设置要求时，SKILL.md 存在 error 级别的诊断则拒绝写入
+func (ss *SkillsService) lintBeforeWrite(content, skillName, skillDir string) error {
+	settings, err := ss.GetSettings()
+	if err != nil || !settings.BlockSaveOnLintErrors {
+		return nil
+	}
+	result := lintSkill(content, skillName, skillDir)
+	if d := result.FirstError(); d != nil {
+		return fmt.Errorf("SKILL.md has %d lint error(s), line %d: %s", result.Errors, d.Line, d.Message)
+	}
+	return nil
+}
+
+// lintFetchedSkill 校验从来源获取、尚未安装的 skill：设置要求时存在 error 级别的诊断则拒绝安装或更新，
+// 否则返回诊断结果供调用方提示
+func (ss *SkillsService) lintFetchedSkill(skillName, skillDir string) (*LintResult, error) {
+	content, err := os.ReadFile(filepath.Join(skillDir, "SKILL.md"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SKILL.md: %v", err)
+	}
+	result := lintSkill(string(content), skillName, skillDir)
+	if settings, err := ss.GetSettings(); err == nil && settings.BlockSaveOnLintErrors {
+		if d := result.FirstError(); d != nil {
+			return result, fmt.Errorf("SKILL.md of %s has %d lint error(s), line %d: %s", skillName, result.Errors, d.Line, d.Message)
+		}
+	}
+	return result, nil
+}
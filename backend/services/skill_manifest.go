@@ -0,0 +1,346 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ---- SKILL.md frontmatter 解析 ----
+
+// SkillManifest SKILL.md 的 YAML frontmatter
+type SkillManifest struct {
+	Name             string                 `json:"name"`
+	Description      string                 `json:"description"`
+	Version          string                 `json:"version"`
+	License          string                 `json:"license"`
+	Language         string                 `json:"language"`
+	Framework        string                 `json:"framework"`
+	Tags             []string               `json:"tags"`
+	AllowedTools     []string               `json:"allowedTools"`
+	Dependencies     []ManifestDependency   `json:"dependencies"` // 含可选依赖（Optional = true）
+	Conflicts        []string               `json:"conflicts"`
+	CompatibleAgents []string               `json:"compatibleAgents"`
+	Metadata         map[string]interface{} `json:"metadata"` // frontmatter 中 metadata 字段的内容
+	Extra            map[string]interface{} `json:"extra"`    // 其余未识别的字段
+}
+
+// ManifestDependency frontmatter 中声明的依赖
+type ManifestDependency struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"` // 版本约束，未声明时为空
+	Optional bool   `json:"optional"`
+}
+
+// manifestKeyAliases 字段别名，统一为 kebab-case 的规范名
+var manifestKeyAliases = map[string]string{
+	"desc":                  "description",
+	"allowed_tools":         "allowed-tools",
+	"allowedtools":          "allowed-tools",
+	"tools":                 "allowed-tools",
+	"deps":                  "dependencies",
+	"requires":              "dependencies",
+	"optional_dependencies": "optional-dependencies",
+	"optionaldependencies":  "optional-dependencies",
+	"compatible_agents":     "compatible-agents",
+	"compatibleagents":      "compatible-agents",
+	"agents":                "compatible-agents",
+	"keywords":              "tags",
+}
+
+// splitFrontmatter 拆分出 frontmatter 文本与正文，没有 frontmatter 时 ok 为 false
+// frontmatter 必须位于文件开头（允许 BOM 和空行），以 --- 开始，以 --- 或 ... 结束
+func splitFrontmatter(content string) (frontmatter, body string, ok bool) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	lines := strings.SplitAfter(content, "\n")
+	start := 0
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	if start >= len(lines) || strings.TrimSpace(lines[start]) != "---" {
+		return "", content, false
+	}
+	for i := start + 1; i < len(lines); i++ {
+		if t := strings.TrimRight(lines[i], "\r\n"); t == "---" || t == "..." {
+			return strings.Join(lines[start+1:i], ""), strings.Join(lines[i+1:], ""), true
+		}
+	}
+	return "", content, false
+}
+
+// parseSkillManifest 解析 SKILL.md 的 frontmatter
+// YAML 语法错误时返回错误，同时按 "key: value" 逐行尽量提取顶层字段，保证列表页仍能显示名称和描述
+func parseSkillManifest(content string) (*SkillManifest, error) {
+	manifest := &SkillManifest{}
+	frontmatter, _, ok := splitFrontmatter(content)
+	if !ok {
+		return manifest, nil
+	}
+
+	// 解码为 yaml.Node 以保留标量原文（例如 version: 1.0 不会变成 1）
+	var raw map[string]yaml.Node
+	if err := yaml.Unmarshal([]byte(frontmatter), &raw); err != nil {
+		manifest.applyFields(scanFrontmatterLines(frontmatter))
+		return manifest, fmt.Errorf("invalid frontmatter: %v", err)
+	}
+	manifest.applyFields(raw)
+	return manifest, nil
+}
+
+// scanFrontmatterLines 宽松地逐行提取顶层 key: value，仅用于 YAML 解析失败时的兜底
+func scanFrontmatterLines(frontmatter string) map[string]yaml.Node {
+	fields := map[string]yaml.Node{}
+	for _, line := range strings.Split(frontmatter, "\n") {
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' {
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		fields[strings.TrimSpace(key)] = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	}
+	return fields
+}
+
+// applyFields 将解析出的 frontmatter 字段映射到 manifest
+func (m *SkillManifest) applyFields(raw map[string]yaml.Node) {
+	for key, node := range raw {
+		n := resolveAlias(&node)
+		canonical := strings.ToLower(strings.TrimSpace(key))
+		if alias, ok := manifestKeyAliases[canonical]; ok {
+			canonical = alias
+		}
+		switch canonical {
+		case "name":
+			m.Name = nodeString(n)
+		case "description":
+			m.Description = nodeString(n)
+		case "version":
+			m.Version = nodeString(n)
+		case "license":
+			m.License = nodeString(n)
+		case "language":
+			m.Language = nodeString(n)
+		case "framework":
+			m.Framework = nodeString(n)
+		case "tags":
+			m.Tags = appendUnique(m.Tags, nodeList(n)...)
+		case "allowed-tools":
+			m.AllowedTools = appendUnique(m.AllowedTools, nodeList(n)...)
+		case "dependencies":
+			m.Dependencies = append(m.Dependencies, nodeDependencies(n, false)...)
+		case "optional-dependencies":
+			m.Dependencies = append(m.Dependencies, nodeDependencies(n, true)...)
+		case "conflicts":
+			m.Conflicts = appendUnique(m.Conflicts, nodeList(n)...)
+		case "compatible-agents":
+			m.CompatibleAgents = appendUnique(m.CompatibleAgents, nodeList(n)...)
+		case "metadata":
+			if n.Kind != yaml.MappingNode {
+				m.setExtra(key, n)
+				break
+			}
+			if nested, ok := nodeValue(n).(map[string]interface{}); ok {
+				m.Metadata = nested
+			}
+			// 部分 skill 把版本、标签写在 metadata 下
+			if m.Version == "" {
+				m.Version = nodeString(mappingChild(n, "version"))
+			}
+			if len(m.Tags) == 0 {
+				m.Tags = nodeList(mappingChild(n, "tags"))
+			}
+		default:
+			m.setExtra(key, n)
+		}
+	}
+	sort.Slice(m.Dependencies, func(i, j int) bool { return m.Dependencies[i].Name < m.Dependencies[j].Name })
+}
+
+func (m *SkillManifest) setExtra(key string, n *yaml.Node) {
+	if m.Extra == nil {
+		m.Extra = map[string]interface{}{}
+	}
+	m.Extra[key] = nodeValue(n)
+}
+
+// RequiredDependencies 返回必需依赖的名称
+func (m *SkillManifest) RequiredDependencies() []string {
+	return m.dependencyNames(false)
+}
+
+// OptionalDependencies 返回可选依赖的名称
+func (m *SkillManifest) OptionalDependencies() []string {
+	return m.dependencyNames(true)
+}
+
+func (m *SkillManifest) dependencyNames(optional bool) []string {
+	names := []string{}
+	for _, d := range m.Dependencies {
+		if d.Optional == optional {
+			names = append(names, d.Name)
+		}
+	}
+	return names
+}
+
+// resolveAlias 展开 YAML 锚点引用
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// mappingChild 返回映射节点中指定 key 的值，不存在时返回 nil
+func mappingChild(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return resolveAlias(n.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// nodeString 返回标量的原文，null、列表和映射返回空
+func nodeString(n *yaml.Node) string {
+	n = resolveAlias(n)
+	if n == nil || n.Kind != yaml.ScalarNode || n.Tag == "!!null" {
+		return ""
+	}
+	return strings.TrimSpace(n.Value)
+}
+
+// nodeList 接受 YAML 列表或逗号 / 空白分隔的字符串
+func nodeList(n *yaml.Node) []string {
+	n = resolveAlias(n)
+	if n == nil {
+		return nil
+	}
+	var items []string
+	switch n.Kind {
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			if s := nodeString(item); s != "" {
+				items = append(items, s)
+			}
+		}
+	case yaml.ScalarNode:
+		v := nodeString(n)
+		sep := func(r rune) bool { return r == ',' }
+		if !strings.Contains(v, ",") {
+			sep = func(r rune) bool { return r == ' ' || r == '\t' || r == '\n' }
+		}
+		for _, item := range strings.FieldsFunc(v, sep) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// nodeDependencies 解析依赖声明，支持以下写法：
+//
+//	dependencies: [a, b@^1.0]
+//	dependencies: {a: "^1.0", b: "*"}
+//	dependencies: [{name: a, version: ^1.0, optional: true}]
+func nodeDependencies(n *yaml.Node, optional bool) []ManifestDependency {
+	n = resolveAlias(n)
+	if n == nil {
+		return nil
+	}
+	var deps []ManifestDependency
+	add := func(name, version string, opt bool) {
+		if name = strings.TrimSpace(name); name != "" {
+			deps = append(deps, ManifestDependency{Name: name, Version: strings.TrimSpace(version), Optional: opt})
+		}
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			add(n.Content[i].Value, nodeString(n.Content[i+1]), optional)
+		}
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			item = resolveAlias(item)
+			if item.Kind == yaml.MappingNode {
+				opt := optional
+				if o := nodeString(mappingChild(item, "optional")); o != "" {
+					opt = o == "true"
+				}
+				add(nodeString(mappingChild(item, "name")), nodeString(mappingChild(item, "version")), opt)
+				continue
+			}
+			name, version, _ := strings.Cut(nodeString(item), "@")
+			add(name, version, optional)
+		}
+	case yaml.ScalarNode:
+		for _, item := range nodeList(n) {
+			name, version, _ := strings.Cut(item, "@")
+			add(name, version, optional)
+		}
+	}
+	return deps
+}
+
+// nodeValue 将任意节点解码为可 JSON 序列化的值
+func nodeValue(n *yaml.Node) interface{} {
+	var v interface{}
+	if n == nil || n.Decode(&v) != nil {
+		return nil
+	}
+	return normalizeYAML(v)
+}
+
+// normalizeYAML 将 yaml 解码出的 map[interface{}]interface{} 等结构转换为可 JSON 序列化的形式
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = normalizeYAML(item)
+		}
+		return out
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[fmt.Sprint(k)] = normalizeYAML(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalizeYAML(item)
+		}
+		return out
+	}
+	return value
+}
+
+// appendUnique 追加不重复的元素
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, existing := range list {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
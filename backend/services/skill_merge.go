@@ -0,0 +1,302 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ---- 本地修改检测与三方合并 ----
+
+// 更新结果状态
+const (
+	UpdateStatusUpdated  = "updated"  // 直接更新（无本地修改或强制覆盖）
+	UpdateStatusMerged   = "merged"   // 本地修改已合并，无冲突
+	UpdateStatusConflict = "conflict" // 已合并但存在冲突，SKILL.md 中带有冲突标记
+)
+
+// 冲突标记
+const (
+	conflictMarkerOurs   = "<<<<<<< local"
+	conflictMarkerSep    = "======="
+	conflictMarkerTheirs = ">>>>>>> upstream"
+)
+
+// SkillUpdateResult 单个 skill 的更新结果
+type SkillUpdateResult struct {
+	Name             string           `json:"name"`
+	Status           string           `json:"status"`
+	LocalModified    bool             `json:"localModified"`    // 更新前是否存在本地修改
+	MergedFiles      []string         `json:"mergedFiles"`      // 三方合并的文件
+	ConflictFiles    []string         `json:"conflictFiles"`    // 合并后仍有冲突标记的文件
+	KeptFiles        []string         `json:"keptFiles"`        // 上游未改动，保留本地修改的文件
+	OverwrittenFiles []string         `json:"overwrittenFiles"` // 本地与上游都改动，以上游为准的文件（旧内容可从版本历史恢复）
+	LintDiagnostics  []LintDiagnostic `json:"lintDiagnostics"`  // 新版本 SKILL.md 的校验结果（未阻止更新的错误与警告）
+}
+
+// LocalModificationsError skill 存在本地修改，未指定覆盖或合并时拒绝更新
+type LocalModificationsError struct {
+	Name  string
+	Files []string
+}
+
+func (e *LocalModificationsError) Error() string {
+	if len(e.Files) == 0 {
+		return fmt.Sprintf("skill %s has no recorded content hash and may have local modifications; update with force to overwrite, or accept its current content as unmodified first", e.Name)
+	}
+	return fmt.Sprintf("skill %s has local modifications (%s); update with force to overwrite or merge to keep them", e.Name, strings.Join(e.Files, ", "))
+}
+
+// mayBeModified 内容可能与上游不一致：哈希不符，或没有记录哈希而无法判断
+func (r SkillVerifyResult) mayBeModified() bool {
+	return r.Status == VerifyStatusModified || r.Status == VerifyStatusUnverified
+}
+
+// changedFiles 汇总校验结果中被修改、删除和新增的文件
+func (r SkillVerifyResult) changedFiles() []string {
+	files := make([]string, 0, len(r.ModifiedFiles)+len(r.MissingFiles)+len(r.ExtraFiles))
+	files = append(files, r.ModifiedFiles...)
+	files = append(files, r.MissingFiles...)
+	files = append(files, r.ExtraFiles...)
+	return files
+}
+
+// GetSkillLocalChanges 对比 skill 当前内容与安装时记录的哈希，返回本地修改情况
+func (ss *SkillsService) GetSkillLocalChanges(skillName string) (*SkillVerifyResult, error) {
+	if err := validSkillName(skillName); err != nil {
+		return nil, err
+	}
+	lock, err := ss.loadSkillsLock()
+	if err != nil {
+		return nil, err
+	}
+	entry := lock.Skills[skillName]
+	result := ss.verifySkill(skillName, entry)
+	return &result, nil
+}
+
+// AcceptSkillContent 将 skill 当前内容记录为安装时的原始内容（写入树哈希与文件哈希）
+// 用于旧版本安装、没有记录哈希的 skill：确认未做本地修改后，更新与自动更新不再把它视为可能被修改
+func (ss *SkillsService) AcceptSkillContent(skillName string) (*SkillVerifyResult, error) {
+	if err := validSkillName(skillName); err != nil {
+		return nil, err
+	}
+	skillDir := filepath.Join(ss.env.SkillsDir, skillName)
+	if info, err := os.Stat(skillDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("skill not found: %s", skillName)
+	}
+	treeHash, files, err := hashSkillTree(skillDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash skill %s: %v", skillName, err)
+	}
+
+	var entry SkillLockEntry
+	err = ss.modifySkillsLock(func(lock *SkillsLock) error {
+		existing, ok := lock.Skills[skillName]
+		if !ok {
+			return fmt.Errorf("skill not found in .skills-lock: %s", skillName)
+		}
+		existing.TreeHash = treeHash
+		existing.Files = files
+		lock.Skills[skillName] = existing
+		entry = existing
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ss.AddActivityLog("verify", skillName, "accepted current content as unmodified")
+	result := ss.verifySkill(skillName, entry)
+	return &result, nil
+}
+
+// pristineSkillFile 获取 skill 安装时（未经本地修改）的文件内容，作为三方合并的 base
+// 优先从版本历史中查找与 .skills-lock 哈希一致的快照，其次按记录的 commit 重新克隆
+func (ss *SkillsService) pristineSkillFile(skillName string, entry SkillLockEntry, relPath string) ([]byte, bool) {
+	if entry.TreeHash != "" {
+		versions, _ := ss.ListSkillVersions(skillName)
+		for _, v := range versions {
+			if v.TreeHash != entry.TreeHash {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(ss.skillVersionsDir(skillName), v.ID, "files", filepath.FromSlash(relPath)))
+			if err == nil {
+				return data, true
+			}
+		}
+	}
+
+	if entry.CommitSHA == "" || entry.Source == "" {
+		return nil, false
+	}
+	origin, err := ss.entrySource(entry)
+	if err != nil || !origin.isGit() {
+		return nil, false
+	}
+	origin.Ref = entry.CommitSHA
+	fetched, err := ss.fetchSkillSource(origin)
+	if err != nil {
+		return nil, false
+	}
+	defer fetched.Close()
+	skillSourcePath := fetched.skillDir(origin.Subpath, skillName)
+	if skillSourcePath == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(skillSourcePath, filepath.FromSlash(relPath)))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// mergeLocalChanges 将已安装 skill 的本地修改合并到暂存的新版本中
+// SKILL.md 做三方合并（base = 安装时版本，ours = 本地，theirs = 上游）；
+// 其他文件仅在上游未改动时保留本地修改（包括本地新增与删除）
+func (ss *SkillsService) mergeLocalChanges(skillName string, entry SkillLockEntry, local SkillVerifyResult, stagedDir string, result *SkillUpdateResult) error {
+	installedDir := filepath.Join(ss.env.SkillsDir, skillName)
+	_, upstreamFiles, err := hashSkillTree(stagedDir)
+	if err != nil {
+		return fmt.Errorf("failed to hash new version: %v", err)
+	}
+
+	for _, relPath := range local.ModifiedFiles {
+		localPath := filepath.Join(installedDir, filepath.FromSlash(relPath))
+		stagedPath := filepath.Join(stagedDir, filepath.FromSlash(relPath))
+		upstreamHash, inUpstream := upstreamFiles[relPath]
+		switch {
+		case inUpstream && upstreamHash == entry.Files[relPath]:
+			// 上游未改动，直接保留本地版本
+			if err := copyFile(localPath, stagedPath); err != nil {
+				return fmt.Errorf("failed to keep %s: %v", relPath, err)
+			}
+			result.KeptFiles = append(result.KeptFiles, relPath)
+		case inUpstream && relPath == "SKILL.md":
+			base, _ := ss.pristineSkillFile(skillName, entry, relPath)
+			ours, err := os.ReadFile(localPath)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %v", relPath, err)
+			}
+			theirs, err := os.ReadFile(stagedPath)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %v", relPath, err)
+			}
+			merged, conflicts := mergeThreeWay(string(base), string(ours), string(theirs))
+			if err := os.WriteFile(stagedPath, []byte(merged), 0644); err != nil {
+				return fmt.Errorf("failed to write merged %s: %v", relPath, err)
+			}
+			result.MergedFiles = append(result.MergedFiles, relPath)
+			if conflicts > 0 {
+				result.ConflictFiles = append(result.ConflictFiles, relPath)
+			}
+		default:
+			result.OverwrittenFiles = append(result.OverwrittenFiles, relPath)
+		}
+	}
+
+	for _, relPath := range local.ExtraFiles {
+		if _, inUpstream := upstreamFiles[relPath]; inUpstream {
+			result.OverwrittenFiles = append(result.OverwrittenFiles, relPath)
+			continue
+		}
+		stagedPath := filepath.Join(stagedDir, filepath.FromSlash(relPath))
+		if err := os.MkdirAll(filepath.Dir(stagedPath), 0755); err != nil {
+			return err
+		}
+		if err := copyFile(filepath.Join(installedDir, filepath.FromSlash(relPath)), stagedPath); err != nil {
+			return fmt.Errorf("failed to keep %s: %v", relPath, err)
+		}
+		result.KeptFiles = append(result.KeptFiles, relPath)
+	}
+
+	for _, relPath := range local.MissingFiles {
+		upstreamHash, inUpstream := upstreamFiles[relPath]
+		if !inUpstream {
+			continue
+		}
+		if upstreamHash != entry.Files[relPath] {
+			result.OverwrittenFiles = append(result.OverwrittenFiles, relPath)
+			continue
+		}
+		// 本地删除且上游未改动，保持删除
+		os.Remove(filepath.Join(stagedDir, filepath.FromSlash(relPath)))
+		result.KeptFiles = append(result.KeptFiles, relPath)
+	}
+	return nil
+}
+
+// mergeThreeWay 对文本做三方合并，返回合并结果与冲突块数量
+// 两边都修改了同一区域且内容不同时，以 git 风格的冲突标记同时保留两边内容
+func mergeThreeWay(base, ours, theirs string) (string, int) {
+	baseLines, ourLines, theirLines := splitLines(base), splitLines(ours), splitLines(theirs)
+	matchOurs := lcsMatches(baseLines, ourLines)
+	matchTheirs := lcsMatches(baseLines, theirLines)
+
+	var out strings.Builder
+	conflicts := 0
+	writeLines := func(lines []string) {
+		for _, l := range lines {
+			out.WriteString(l)
+		}
+	}
+	// 冲突标记必须独占一行，缺少结尾换行的最后一行需要补上
+	writeBlock := func(lines []string) {
+		writeLines(lines)
+		if n := len(lines); n > 0 && !strings.HasSuffix(lines[n-1], "\n") {
+			out.WriteString("\n")
+		}
+	}
+
+	i, a, b := 0, 0, 0
+	for {
+		// 找到下一个在三个版本中都保持不变的 base 行作为锚点
+		j := i
+		for j < len(baseLines) && (matchOurs[j] < a || matchTheirs[j] < b) {
+			j++
+		}
+		endA, endB := len(ourLines), len(theirLines)
+		if j < len(baseLines) {
+			endA, endB = matchOurs[j], matchTheirs[j]
+		}
+
+		if j == i && a == endA && b == endB {
+			if j >= len(baseLines) {
+				break
+			}
+			out.WriteString(baseLines[j])
+			i, a, b = j+1, endA+1, endB+1
+			continue
+		}
+
+		baseChunk, ourChunk, theirChunk := baseLines[i:j], ourLines[a:endA], theirLines[b:endB]
+		switch {
+		case equalLines(ourChunk, baseChunk):
+			writeLines(theirChunk)
+		case equalLines(theirChunk, baseChunk), equalLines(ourChunk, theirChunk):
+			writeLines(ourChunk)
+		default:
+			conflicts++
+			out.WriteString(conflictMarkerOurs + "\n")
+			writeBlock(ourChunk)
+			out.WriteString(conflictMarkerSep + "\n")
+			writeBlock(theirChunk)
+			out.WriteString(conflictMarkerTheirs + "\n")
+		}
+		i, a, b = j, endA, endB
+	}
+	return out.String(), conflicts
+}
+
+// equalLines 判断两段行是否完全相同
+func equalLines(x, y []string) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,500 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ---- 变更计划（dry-run） ----
+// Plan* 方法按对应操作的逻辑计算将要发生的变更，只读取来源与本地状态，不修改已安装的 skill、配置与 .skills-lock，
+// 也不写入活动日志。git 来源与实际操作一样经克隆缓存获取，因此会 fetch 刷新缓存，但不触发缓存淘汰。
+// 策略拦截、安全扫描阻止、本地修改等会导致实际操作失败的情况记录在 Warnings 中
+
+// 文件变更动作
+const (
+	PlanActionCreate = "create"
+	PlanActionModify = "modify"
+	PlanActionRemove = "remove"
+)
+
+// 软链接与 .skills-lock 条目变更动作
+const (
+	PlanActionAdd    = "add"
+	PlanActionUpdate = "update"
+)
+
+// ChangePlan 一次操作的变更计划
+type ChangePlan struct {
+	Operation    string       `json:"operation"`
+	Files        []FileChange `json:"files"`
+	Links        []LinkChange `json:"links"`
+	Lock         []LockChange `json:"lock"`
+	CustomAgents []string     `json:"customAgents,omitempty"` // 将新增的自定义 agent（导入配置）
+	Warnings     []string     `json:"warnings"`
+}
+
+// FileChange 文件变更
+type FileChange struct {
+	Action string `json:"action"` // create / modify / remove
+	Path   string `json:"path"`
+	Skill  string `json:"skill"`
+}
+
+// LinkChange agent 目录中的软链接变更
+type LinkChange struct {
+	Action string `json:"action"` // add / remove
+	Agent  string `json:"agent"`
+	Skill  string `json:"skill"`
+	Path   string `json:"path"`             // 软链接位置
+	Target string `json:"target,omitempty"` // 软链接指向
+}
+
+// LockChange .skills-lock 条目变更
+type LockChange struct {
+	Action    string `json:"action"` // add / update / remove
+	Skill     string `json:"skill"`
+	Source    string `json:"source"`
+	OldRef    string `json:"oldRef,omitempty"`
+	NewRef    string `json:"newRef,omitempty"`
+	OldCommit string `json:"oldCommit,omitempty"`
+	NewCommit string `json:"newCommit,omitempty"`
+}
+
+// NewChangePlan 创建空的变更计划
+func NewChangePlan(operation string) *ChangePlan {
+	return &ChangePlan{
+		Operation: operation,
+		Files:     []FileChange{},
+		Links:     []LinkChange{},
+		Lock:      []LockChange{},
+		Warnings:  []string{},
+	}
+}
+
+// HasChanges 计划中是否包含任何变更
+func (p *ChangePlan) HasChanges() bool {
+	return len(p.Files) > 0 || len(p.Links) > 0 || len(p.Lock) > 0 || len(p.CustomAgents) > 0
+}
+
+func (p *ChangePlan) warn(format string, args ...interface{}) {
+	p.Warnings = append(p.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Merge 合并另一个计划的变更
+func (p *ChangePlan) Merge(other *ChangePlan) {
+	p.Files = append(p.Files, other.Files...)
+	p.Links = append(p.Links, other.Links...)
+	p.Lock = append(p.Lock, other.Lock...)
+	p.CustomAgents = append(p.CustomAgents, other.CustomAgents...)
+	p.Warnings = append(p.Warnings, other.Warnings...)
+}
+
+// planTreeChanges 对比目标目录与新内容（任一目录不存在时视为空），生成逐文件变更
+func (p *ChangePlan) planTreeChanges(skillName, targetDir, newDir string) error {
+	diff, err := diffTrees(targetDir, newDir, nil)
+	if err != nil {
+		return err
+	}
+	actions := map[string]string{
+		FileDiffAdded:    PlanActionCreate,
+		FileDiffModified: PlanActionModify,
+		FileDiffRemoved:  PlanActionRemove,
+	}
+	for _, f := range diff.Files {
+		p.Files = append(p.Files, FileChange{
+			Action: actions[f.Status],
+			Path:   filepath.Join(targetDir, filepath.FromSlash(f.Path)),
+			Skill:  skillName,
+		})
+	}
+	return nil
+}
+
+// forEachAgentLinkPath 遍历所有 agent 全局目录中 skill 软链接的位置（跳过中央目录本身）
+func (ss *SkillsService) forEachAgentLinkPath(skillName string, fn func(agent, linkPath string)) {
+	for _, agent := range getAllAgentConfigs(ss.env) {
+		for _, gp := range agent.GlobalPaths {
+			agentSkillsDir := filepath.Join(ss.env.HomeDir, gp)
+			if agentSkillsDir == ss.env.SkillsDir {
+				continue
+			}
+			fn(agent.Name, filepath.Join(agentSkillsDir, skillName))
+		}
+	}
+}
+
+// linkState 软链接位置的状态
+type linkState struct {
+	exists bool
+	isLink bool
+	target string // 软链接指向的绝对路径
+}
+
+// readLinkState 读取软链接位置当前的状态
+func readLinkState(linkPath string) linkState {
+	stat, err := os.Lstat(linkPath)
+	if err != nil {
+		return linkState{}
+	}
+	if stat.Mode()&os.ModeSymlink == 0 {
+		return linkState{exists: true}
+	}
+	target, _ := os.Readlink(linkPath)
+	if target != "" && !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(linkPath), target)
+	}
+	return linkState{exists: true, isLink: true, target: filepath.Clean(target)}
+}
+
+// linkSim 按实际操作的顺序模拟软链接增删：多个 agent 共用同一目录（如 ~/.claude/skills）时
+// 同一位置会被反复处理，flush 只输出每个位置最终的净变更
+type linkSim struct {
+	order  []string
+	before map[string]linkState
+	after  map[string]linkState
+	owner  map[string][2]string // 位置 -> 最后处理它的 agent 与 skill
+}
+
+func newLinkSim() *linkSim {
+	return &linkSim{before: map[string]linkState{}, after: map[string]linkState{}, owner: map[string][2]string{}}
+}
+
+func (s *linkSim) state(linkPath string) linkState {
+	if st, ok := s.after[linkPath]; ok {
+		return st
+	}
+	st := readLinkState(linkPath)
+	s.order = append(s.order, linkPath)
+	s.before[linkPath] = st
+	s.after[linkPath] = st
+	return st
+}
+
+func (s *linkSim) set(linkPath, agent, skillName string, st linkState) {
+	s.state(linkPath)
+	s.after[linkPath] = st
+	s.owner[linkPath] = [2]string{agent, skillName}
+}
+
+func (s *linkSim) flush(plan *ChangePlan) {
+	for _, linkPath := range s.order {
+		before, after := s.before[linkPath], s.after[linkPath]
+		if before == after {
+			continue
+		}
+		owner := s.owner[linkPath]
+		if before.isLink {
+			plan.Links = append(plan.Links, LinkChange{Action: PlanActionRemove, Agent: owner[0], Skill: owner[1], Path: linkPath, Target: before.target})
+		}
+		if after.isLink {
+			plan.Links = append(plan.Links, LinkChange{Action: PlanActionAdd, Agent: owner[0], Skill: owner[1], Path: linkPath, Target: after.target})
+		}
+	}
+}
+
+// planCreateLinks 与 createSymlinksForSkill 一致：为指定 agents（为空表示全部）创建软链接，替换已有的软链接
+func (ss *SkillsService) planCreateLinks(plan *ChangePlan, skillName string, agents []string) {
+	agentSet := make(map[string]bool)
+	for _, name := range agents {
+		agentSet[name] = true
+	}
+	sourcePath := filepath.Join(ss.env.SkillsDir, skillName)
+	sim := newLinkSim()
+	ss.forEachAgentLinkPath(skillName, func(agent, linkPath string) {
+		if len(agentSet) > 0 && !agentSet[agent] {
+			return
+		}
+		if st := sim.state(linkPath); st.exists && !st.isLink {
+			plan.warn("%s already exists and is not a symlink, skipped", linkPath)
+			return
+		}
+		sim.set(linkPath, agent, skillName, linkState{exists: true, isLink: true, target: sourcePath})
+	})
+	sim.flush(plan)
+}
+
+// planSyncLinks 与 UpdateSkillAgentLinks 一致：链接到指定 agents，移除其他 agent 的软链接
+func (ss *SkillsService) planSyncLinks(plan *ChangePlan, skillName string, agents []string) {
+	sourcePath := filepath.Join(ss.env.SkillsDir, skillName)
+	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
+		plan.warn("skill not found: %s", skillName)
+		return
+	}
+	agentSet := make(map[string]bool)
+	for _, name := range agents {
+		agentSet[name] = true
+	}
+	sim := newLinkSim()
+	ss.forEachAgentLinkPath(skillName, func(agent, linkPath string) {
+		st := sim.state(linkPath)
+		switch {
+		case agentSet[agent] && !st.exists:
+			sim.set(linkPath, agent, skillName, linkState{exists: true, isLink: true, target: sourcePath})
+		case agentSet[agent] && !st.isLink:
+			plan.warn("%s already exists and is not a symlink, skipped", linkPath)
+		case !agentSet[agent] && st.isLink:
+			sim.set(linkPath, agent, skillName, linkState{})
+		}
+	})
+	sim.flush(plan)
+}
+
+// planRemoveLinks 与 DeleteSkill 一致：移除所有 agent 目录中的同名软链接
+func (ss *SkillsService) planRemoveLinks(plan *ChangePlan, skillName string) {
+	sim := newLinkSim()
+	ss.forEachAgentLinkPath(skillName, func(agent, linkPath string) {
+		if sim.state(linkPath).isLink {
+			sim.set(linkPath, agent, skillName, linkState{})
+		}
+	})
+	sim.flush(plan)
+}
+
+// planLockEntry 生成 .skills-lock 条目的新增或更新
+func planLockEntry(plan *ChangePlan, lock SkillsLock, skillName, source string, origin SkillSource, commitSHA string) {
+	change := LockChange{Action: PlanActionAdd, Skill: skillName, Source: source, NewRef: origin.Ref, NewCommit: commitSHA}
+	if old, ok := lock.Skills[skillName]; ok {
+		change.Action = PlanActionUpdate
+		change.OldRef = old.Ref
+		change.OldCommit = old.CommitSHA
+	}
+	plan.Lock = append(plan.Lock, change)
+}
+
+// planFetchedSkill 对获取到的 skill 做与安装/更新相同的软链接、签名、安全扫描与 SKILL.md 校验检查，结果只记录为警告
+func (ss *SkillsService) planFetchedSkill(plan *ChangePlan, policy TrustPolicy, source, skillName, skillDir string, allowRisk bool) {
+	if err := checkSkillSymlinks(skillDir); err != nil {
+		plan.warn("%v", err)
+	}
+	sig := verifySkillSignature(ss.env, skillDir)
+	if violation := policy.checkSignature(source, skillName, sig); violation != nil {
+		plan.warn("%v", violation)
+	}
+	if report, err := ss.checkSkillScan(skillName, skillDir, allowRisk); err != nil {
+		plan.warn("%v", err)
+	} else if report.Blocked {
+		plan.warn("security scan: %s risk reaches threshold %s, install continues because risk is allowed", report.Risk, report.Threshold)
+	}
+	if _, err := ss.lintFetchedSkill(skillName, skillDir); err != nil {
+		plan.warn("%v", err)
+	}
+}
+
+// PlanInstallRemoteSkill 预览 InstallRemoteSkillWithOptions 的变更
+func (ss *SkillsService) PlanInstallRemoteSkill(fullName string, agents []string, opts InstallOptions) (*ChangePlan, error) {
+	plan := NewChangePlan("install")
+	sourceName, skillName, ref, err := parseSkillFullName(fullName)
+	if err != nil {
+		return nil, err
+	}
+	origin, sourceName, err := ss.resolveSkillSource(sourceName, ref)
+	if err != nil {
+		return nil, err
+	}
+	policy, _, err := loadTrustPolicy(ss.env)
+	if err != nil {
+		return nil, err
+	}
+	if violation := policy.checkSource(sourceName, skillName, origin); violation != nil {
+		plan.warn("%v", violation)
+	}
+
+	fetched, err := ss.fetchSkillSourceForPlan(origin)
+	if err != nil {
+		return nil, err
+	}
+	defer fetched.Close()
+	skillSourcePath := fetched.skillDir("", skillName)
+	if skillSourcePath == "" {
+		return nil, fmt.Errorf("skill not found in source: %s", skillName)
+	}
+	ss.planFetchedSkill(plan, policy, sourceName, skillName, skillSourcePath, opts.AllowRisk)
+
+	if err := plan.planTreeChanges(skillName, filepath.Join(ss.env.SkillsDir, skillName), skillSourcePath); err != nil {
+		return nil, err
+	}
+	lock, _ := ss.loadSkillsLock()
+	planLockEntry(plan, lock, skillName, sourceName, origin, fetched.CommitSHA)
+	ss.planCreateLinks(plan, skillName, agents)
+	return plan, nil
+}
+
+// PlanUpdateSkill 预览 UpdateSkillWithOptions 的变更
+func (ss *SkillsService) PlanUpdateSkill(skillName string, opts UpdateOptions) (*ChangePlan, error) {
+	plan := NewChangePlan("update")
+	lock, err := ss.loadSkillsLock()
+	if err != nil {
+		return nil, err
+	}
+	entry, exists := lock.Skills[skillName]
+	if !exists {
+		origin, source, err := ss.discoverSkillSource(skillName)
+		if err != nil {
+			return nil, fmt.Errorf("skill not found in .skills-lock and could not discover source: %s", skillName)
+		}
+		entry = SkillLockEntry{}
+		entry.setSource(source, origin)
+	}
+
+	local := ss.verifySkill(skillName, entry)
+	if local.mayBeModified() {
+		switch {
+		case opts.Force:
+			plan.warn("local modifications will be overwritten: %v", local.changedFiles())
+		case opts.Merge && local.Status == VerifyStatusUnverified:
+			plan.warn("cannot merge local changes of %s: no recorded content hash; update with force to overwrite, or accept its current content as unmodified first", skillName)
+		case opts.Merge:
+			plan.warn("local modifications will be merged: %v", local.changedFiles())
+		default:
+			plan.warn("%v", &LocalModificationsError{Name: skillName, Files: local.changedFiles()})
+		}
+	}
+
+	origin, err := ss.entrySource(entry)
+	if err != nil {
+		return nil, fmt.Errorf("cannot update %s: %v", skillName, err)
+	}
+	if opts.Float {
+		origin.Ref = ""
+	}
+	policy, _, err := loadTrustPolicy(ss.env)
+	if err != nil {
+		return nil, err
+	}
+	if violation := policy.checkSource(entry.Source, skillName, origin); violation != nil {
+		plan.warn("%v", violation)
+	}
+
+	fetched, err := ss.fetchSkillSourceForPlan(origin)
+	if err != nil {
+		return nil, err
+	}
+	defer fetched.Close()
+	skillSourcePath := fetched.skillDir(origin.Subpath, skillName)
+	if skillSourcePath == "" {
+		return nil, fmt.Errorf("skill not found in source: %s", skillName)
+	}
+	ss.planFetchedSkill(plan, policy, entry.Source, skillName, skillSourcePath, opts.AllowRisk)
+
+	if err := plan.planTreeChanges(skillName, filepath.Join(ss.env.SkillsDir, skillName), skillSourcePath); err != nil {
+		return nil, err
+	}
+	planLockEntry(plan, lock, skillName, entry.Source, origin, fetched.CommitSHA)
+	return plan, nil
+}
+
+// PlanBatchDeleteSkills 预览 BatchDeleteSkills 的变更
+func (ss *SkillsService) PlanBatchDeleteSkills(skillNames []string) (*ChangePlan, error) {
+	plan := NewChangePlan("delete")
+	lock, _ := ss.loadSkillsLock()
+	for _, skillName := range skillNames {
+		skillPath := filepath.Join(ss.env.SkillsDir, skillName)
+		if _, err := os.Stat(skillPath); os.IsNotExist(err) {
+			plan.warn("skill not found: %s", skillName)
+			continue
+		}
+		ss.planRemoveLinks(plan, skillName)
+		if err := plan.planTreeChanges(skillName, skillPath, ""); err != nil {
+			return nil, err
+		}
+		if entry, ok := lock.Skills[skillName]; ok {
+			plan.Lock = append(plan.Lock, LockChange{
+				Action:    PlanActionRemove,
+				Skill:     skillName,
+				Source:    entry.Source,
+				OldRef:    entry.Ref,
+				OldCommit: entry.CommitSHA,
+			})
+		}
+	}
+	return plan, nil
+}
+
+// PlanBatchUpdateSkillAgentLinks 预览 BatchUpdateSkillAgentLinks 的变更
+func (ss *SkillsService) PlanBatchUpdateSkillAgentLinks(skillNames []string, agents []string) (*ChangePlan, error) {
+	plan := NewChangePlan("link")
+	for _, skillName := range skillNames {
+		ss.planSyncLinks(plan, skillName, agents)
+	}
+	return plan, nil
+}
+
+// PlanCloneProjectConfig 预览 CloneProjectConfig 的变更
+func (ss *SkillsService) PlanCloneProjectConfig(sourcePath string, targetPath string) (*ChangePlan, error) {
+	if sourcePath == "" || targetPath == "" {
+		return nil, fmt.Errorf("source and target paths are required")
+	}
+	sourceSkills, err := ss.GetProjectSkills(sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source project: %v", err)
+	}
+
+	plan := NewChangePlan("clone")
+	for _, skill := range sourceSkills {
+		for _, agent := range getAllAgentConfigs(ss.env) {
+			sourceSkillPath := filepath.Join(sourcePath, agent.LocalPath, skill.Name)
+			if _, err := os.Stat(sourceSkillPath); os.IsNotExist(err) {
+				continue
+			}
+			targetSkillPath := filepath.Join(targetPath, agent.LocalPath, skill.Name)
+			if _, err := os.Lstat(targetSkillPath); err == nil {
+				continue
+			}
+			if skill.IsGlobal {
+				plan.Links = append(plan.Links, LinkChange{
+					Action: PlanActionAdd,
+					Agent:  agent.Name,
+					Skill:  skill.Name,
+					Path:   targetSkillPath,
+					Target: filepath.Join(ss.env.SkillsDir, skill.Name),
+				})
+			} else if err := plan.planTreeChanges(skill.Name, targetSkillPath, sourceSkillPath); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return plan, nil
+}
+
+// PlanImportConfig 预览 ImportConfig 的变更，无法获取来源的 skill 记录为警告
+func (ss *SkillsService) PlanImportConfig(configJSON string) (*ChangePlan, error) {
+	var config ExportedConfig
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		return nil, fmt.Errorf("invalid config format: %v", err)
+	}
+
+	plan := NewChangePlan("import")
+	existing, _ := loadCustomAgents(ss.env)
+	existingNames := make(map[string]bool)
+	for _, c := range existing {
+		existingNames[c.Name] = true
+	}
+	for _, c := range config.CustomAgents {
+		if !existingNames[c.Name] {
+			plan.CustomAgents = append(plan.CustomAgents, c.Name)
+		}
+	}
+
+	for _, skill := range config.Skills {
+		_, skillName, _, err := parseSkillFullName(skill.FullName)
+		if err != nil {
+			plan.warn("%s: %v", skill.FullName, err)
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(ss.env.SkillsDir, skillName)); err == nil {
+			if len(skill.LinkedAgents) > 0 {
+				ss.planSyncLinks(plan, skillName, skill.LinkedAgents)
+			}
+			continue
+		}
+		sub, err := ss.PlanInstallRemoteSkill(skill.FullName, skill.LinkedAgents, InstallOptions{})
+		if err != nil {
+			plan.warn("%s: %v", skill.FullName, err)
+			continue
+		}
+		plan.Merge(sub)
+	}
+	return plan, nil
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ---- 原子更新与回滚 ----
+
+// rollbackDirName 中央 skills 目录下保存回滚快照的目录（以 . 开头，列表时会被忽略）
+const rollbackDirName = ".skills-rollback"
+
+// rollbackPaths 返回 skill 回滚快照目录及其 .skills-lock 条目快照文件
+func (ss *SkillsService) rollbackPaths(skillName string) (string, string) {
+	dir := filepath.Join(ss.env.SkillsDir, rollbackDirName)
+	return filepath.Join(dir, skillName), filepath.Join(dir, skillName+".lock.json")
+}
+
+// stageSkillDir 将新版本复制到目标旁边的临时目录，保证后续 rename 在同一文件系统内完成
+func stageSkillDir(srcDir, skillsDir, skillName string) (string, error) {
+	if err := os.MkdirAll(skillsDir, 0755); err != nil {
+		return "", err
+	}
+	stagedDir, err := os.MkdirTemp(skillsDir, "."+skillName+".staging-")
+	if err != nil {
+		return "", err
+	}
+	if err := copySkillDir(srcDir, stagedDir); err != nil {
+		os.RemoveAll(stagedDir)
+		return "", err
+	}
+	// MkdirTemp 创建的目录为 0700，改为与来源一致
+	if info, err := os.Stat(srcDir); err == nil {
+		os.Chmod(stagedDir, info.Mode().Perm())
+	}
+	return stagedDir, nil
+}
+
+// lockSkill 获取单个 skill 的排他锁（锁文件位于回滚目录），返回释放函数
+// 换入新版本、写 .skills-lock 与回滚都在锁内完成，同一 skill 的更新与回滚不会交错执行各自的 rename
+func (ss *SkillsService) lockSkill(skillName string) (func(), error) {
+	snapshotDir, _ := ss.rollbackPaths(skillName)
+	return lockFile(snapshotDir)
+}
+
+// currentLockEntry 返回 skill 当前的 .skills-lock 条目，不存在时返回 nil
+func (ss *SkillsService) currentLockEntry(skillName string) *SkillLockEntry {
+	lock, err := ss.loadSkillsLock()
+	if err != nil {
+		return nil
+	}
+	if e, ok := lock.Skills[skillName]; ok {
+		return &e
+	}
+	return nil
+}
+
+// swapInSkill 用暂存目录原子替换已安装的 skill，旧版本及其 .skills-lock 条目保存为回滚快照
+// 任一步失败时恢复旧版本，已安装的 skill 不会丢失。调用方需持有 lockSkill
+func (ss *SkillsService) swapInSkill(skillName, stagedDir string, oldEntry *SkillLockEntry) error {
+	skillPath := filepath.Join(ss.env.SkillsDir, skillName)
+	snapshotDir, snapshotEntry := ss.rollbackPaths(skillName)
+
+	if err := os.MkdirAll(filepath.Dir(snapshotDir), 0755); err != nil {
+		return fmt.Errorf("failed to create rollback directory: %v", err)
+	}
+	// 只保留最近一次的快照
+	if err := os.RemoveAll(snapshotDir); err != nil {
+		return fmt.Errorf("failed to remove old rollback snapshot: %v", err)
+	}
+	os.Remove(snapshotEntry)
+
+	_, err := os.Lstat(skillPath)
+	hasOld := err == nil
+	// 先写条目快照再移动目录，快照目录存在时一定带有对应的 .skills-lock 条目
+	if hasOld && oldEntry != nil {
+		if err := writeJSONFile(snapshotEntry, oldEntry); err != nil {
+			return fmt.Errorf("failed to save rollback lock entry: %v", err)
+		}
+	}
+	if hasOld {
+		if err := os.Rename(skillPath, snapshotDir); err != nil {
+			os.Remove(snapshotEntry)
+			return fmt.Errorf("failed to move old version aside: %v", err)
+		}
+	}
+
+	if err := os.Rename(stagedDir, skillPath); err != nil {
+		if hasOld {
+			os.Rename(snapshotDir, skillPath)
+			os.Remove(snapshotEntry)
+		}
+		return fmt.Errorf("failed to swap in new version: %v", err)
+	}
+	return nil
+}
+
+// revertSwapIn 撤销 swapInSkill：删除刚换入的新版本，把回滚快照恢复到安装位置
+// 用于换入后写 .skills-lock 失败的情况，避免目录内容与 .skills-lock 记录不一致。调用方需持有 lockSkill
+func (ss *SkillsService) revertSwapIn(skillName string) error {
+	skillPath := filepath.Join(ss.env.SkillsDir, skillName)
+	snapshotDir, snapshotEntry := ss.rollbackPaths(skillName)
+	if err := os.RemoveAll(skillPath); err != nil {
+		return err
+	}
+	// 换入前的旧内容不一定是目录，按 Lstat 判断是否存在
+	if _, err := os.Lstat(snapshotDir); err != nil {
+		// 全新安装没有旧版本
+		return nil
+	}
+	if err := os.Rename(snapshotDir, skillPath); err != nil {
+		return err
+	}
+	os.Remove(snapshotEntry)
+	return nil
+}
+
+// replaceDir 用暂存目录替换 targetPath（不保留快照），替换失败时恢复原内容
+func replaceDir(stagedDir, targetPath string) error {
+	oldDir := ""
+	if _, err := os.Lstat(targetPath); err == nil {
+		oldDir = stagedDir + ".old"
+		if err := os.Rename(targetPath, oldDir); err != nil {
+			return fmt.Errorf("failed to move old version aside: %v", err)
+		}
+	}
+	if err := os.Rename(stagedDir, targetPath); err != nil {
+		if oldDir != "" {
+			os.Rename(oldDir, targetPath)
+		}
+		return fmt.Errorf("failed to swap in new version: %v", err)
+	}
+	if oldDir != "" {
+		os.RemoveAll(oldDir)
+	}
+	return nil
+}
+
+// HasRollback 判断 skill 是否存在可回滚的上一版本
+func (ss *SkillsService) HasRollback(skillName string) bool {
+	snapshotDir, _ := ss.rollbackPaths(skillName)
+	info, err := os.Stat(snapshotDir)
+	return err == nil && info.IsDir()
+}
+
+// RollbackSkill 将 skill 恢复为上一次更新前的版本
+// 当前版本与快照互换，因此再次调用会撤销回滚
+func (ss *SkillsService) RollbackSkill(skillName string) error {
+	if err := validSkillName(skillName); err != nil {
+		return err
+	}
+	unlock, err := ss.lockSkill(skillName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if !ss.HasRollback(skillName) {
+		return fmt.Errorf("no rollback snapshot for skill: %s", skillName)
+	}
+
+	skillPath := filepath.Join(ss.env.SkillsDir, skillName)
+	snapshotDir, snapshotEntry := ss.rollbackPaths(skillName)
+	swapDir := filepath.Join(filepath.Dir(snapshotDir), "."+skillName+".swap")
+
+	lock, err := ss.loadSkillsLock()
+	if err != nil {
+		return err
+	}
+	currentEntry, hasCurrentEntry := lock.Skills[skillName]
+
+	var restoredEntry *SkillLockEntry
+	if data, err := os.ReadFile(snapshotEntry); err == nil {
+		var entry SkillLockEntry
+		if err := json.Unmarshal(data, &entry); err == nil {
+			restoredEntry = &entry
+		}
+	}
+
+	// 当前版本 -> 临时位置，快照 -> 安装位置，临时位置 -> 快照
+	os.RemoveAll(swapDir)
+	hasCurrent := false
+	if _, err := os.Lstat(skillPath); err == nil {
+		if err := os.Rename(skillPath, swapDir); err != nil {
+			return fmt.Errorf("failed to move current version aside: %v", err)
+		}
+		hasCurrent = true
+	}
+	if err := os.Rename(snapshotDir, skillPath); err != nil {
+		if hasCurrent {
+			os.Rename(swapDir, skillPath)
+		}
+		return fmt.Errorf("failed to restore previous version: %v", err)
+	}
+	os.Remove(snapshotEntry)
+	if hasCurrent {
+		// 与 swapInSkill 相同，先写条目快照再移动目录；条目写入失败时不保留快照，避免快照缺少条目
+		saved := !hasCurrentEntry || writeJSONFile(snapshotEntry, currentEntry) == nil
+		if !saved {
+			os.RemoveAll(swapDir)
+		} else if err := os.Rename(swapDir, snapshotDir); err != nil {
+			os.RemoveAll(swapDir)
+			os.Remove(snapshotEntry)
+		}
+	}
+
+	// 恢复 .skills-lock 条目；没有条目快照时保留来源信息，清空内容哈希与 commit：
+	// 无法确认恢复的内容是否为上游原始内容，按未校验处理
+	var newEntry SkillLockEntry
+	if restoredEntry != nil {
+		newEntry = *restoredEntry
+	} else if hasCurrentEntry {
+		currentEntry.TreeHash = ""
+		currentEntry.Files = nil
+		currentEntry.CommitSHA = ""
+		currentEntry.Signature = nil
+		newEntry = currentEntry
+	} else {
+		return nil
+	}
+	return ss.modifySkillsLock(func(lock *SkillsLock) error {
+		lock.Skills[skillName] = newEntry
+		return nil
+	})
+}
+
+// removeRollback 删除 skill 的回滚快照
+func (ss *SkillsService) removeRollback(skillName string) {
+	snapshotDir, snapshotEntry := ss.rollbackPaths(skillName)
+	os.RemoveAll(snapshotDir)
+	os.Remove(snapshotEntry)
+}
@@ -0,0 +1,397 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ---- 安装前安全扫描 ----
+// 安装、更新 skill 前扫描来源中的 skill 目录，生成风险报告：
+//
+//	executable-file       可执行文件（ELF / Mach-O / PE 二进制，或带可执行权限的文件）
+//	oversized-binary      超过 maxScanBinarySize 的二进制文件
+//	symlink-escape        指向 skill 目录之外的符号链接
+//	pipe-to-shell         下载内容直接交给 shell 执行（curl | sh、iwr | iex）
+//	encoded-payload       base64 解码后执行，或大段 base64 数据
+//	ssh-write             写入 ~/.ssh 或 authorized_keys
+//	network-exfiltration  向外发送数据（curl -d / --upload-file、nc、/dev/tcp）
+//	credential-access     读取凭据文件或 API key 环境变量
+//	prompt-injection      SKILL.md 等文档中要求忽略既有指令、对用户隐瞒操作的语句
+//	hidden-unicode        零宽字符与双向控制字符
+//
+// 文档（.md / .txt）中的命令多为说明示例，脚本类规则在文档中降低一级。
+// 报告的风险等级为最高的发现等级，达到设置中的阻止阈值（默认 high）时拒绝安装，可通过 AllowRisk 选项放行
+
+// 风险等级，由低到高
+const (
+	ScanRiskNone     = "none"
+	ScanRiskLow      = "low"
+	ScanRiskMedium   = "medium"
+	ScanRiskHigh     = "high"
+	ScanRiskCritical = "critical"
+	// ScanThresholdOff 阈值为 off 时只生成报告，不阻止安装
+	ScanThresholdOff = "off"
+)
+
+// 扫描规则
+const (
+	ScanRuleExecutable     = "executable-file"
+	ScanRuleOversized      = "oversized-binary"
+	ScanRuleSymlinkEscape  = "symlink-escape"
+	ScanRulePipeToShell    = "pipe-to-shell"
+	ScanRuleEncodedPayload = "encoded-payload"
+	ScanRuleSSHWrite       = "ssh-write"
+	ScanRuleExfiltration   = "network-exfiltration"
+	ScanRuleCredentials    = "credential-access"
+	ScanRulePromptInject   = "prompt-injection"
+	ScanRuleHiddenUnicode  = "hidden-unicode"
+)
+
+const (
+	defaultScanThreshold = ScanRiskHigh
+	// maxScanBinarySize 超过该大小的二进制文件视为异常
+	maxScanBinarySize = 1 << 20
+	// maxScanTextSize 超过该大小的文本文件不逐行扫描
+	maxScanTextSize = 2 << 20
+	// maxScanFindingsPerRule 同一文件同一规则最多记录的条数
+	maxScanFindingsPerRule = 5
+)
+
+// scanRiskOrder 风险等级的排序
+var scanRiskOrder = map[string]int{
+	ScanRiskNone:     0,
+	ScanRiskLow:      1,
+	ScanRiskMedium:   2,
+	ScanRiskHigh:     3,
+	ScanRiskCritical: 4,
+}
+
+// ScanFinding 一条扫描发现，Line 为 0 表示针对整个文件
+type ScanFinding struct {
+	Severity string `json:"severity"` // low / medium / high / critical
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+	File     string `json:"file"` // 相对 skill 目录的路径
+	Line     int    `json:"line"`
+	Snippet  string `json:"snippet,omitempty"`
+}
+
+// SkillScanReport skill 目录的风险报告
+type SkillScanReport struct {
+	Skill        string        `json:"skill"`
+	Risk         string        `json:"risk"` // 最高的发现等级，没有发现时为 none
+	Findings     []ScanFinding `json:"findings"`
+	FilesScanned int           `json:"filesScanned"`
+	Threshold    string        `json:"threshold"` // 阻止安装的阈值
+	Blocked      bool          `json:"blocked"`   // 风险等级达到阈值
+}
+
+func (r *SkillScanReport) add(severity, rule, file string, line int, snippet, format string, args ...interface{}) {
+	if runes := []rune(snippet); len(runes) > 120 {
+		snippet = string(runes[:120]) + "…"
+	}
+	r.Findings = append(r.Findings, ScanFinding{
+		Severity: severity,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+		File:     file,
+		Line:     line,
+		Snippet:  snippet,
+	})
+	if scanRiskOrder[severity] > scanRiskOrder[r.Risk] {
+		r.Risk = severity
+	}
+}
+
+// summary 列出最严重的几条发现，用于错误信息
+func (r *SkillScanReport) summary() string {
+	parts := make([]string, 0, 3)
+	for i, f := range r.Findings {
+		if i == 3 {
+			parts = append(parts, fmt.Sprintf("and %d more", len(r.Findings)-3))
+			break
+		}
+		loc := f.File
+		if f.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		parts = append(parts, fmt.Sprintf("[%s] %s %s", f.Severity, f.Rule, loc))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// SkillScanBlockedError 扫描风险达到阈值，拒绝安装
+type SkillScanBlockedError struct {
+	Report *SkillScanReport
+}
+
+func (e *SkillScanBlockedError) Error() string {
+	return fmt.Sprintf("security scan blocked %s: risk %s reaches threshold %s (%s); install with allow-risk to override",
+		e.Report.Skill, e.Report.Risk, e.Report.Threshold, e.Report.summary())
+}
+
+// scanPattern 文本内容的检测规则
+type scanPattern struct {
+	rule     string
+	severity string
+	re       *regexp.Regexp
+	message  string
+	docsOnly bool // 只检查文档（提示注入类规则）
+}
+
+var scanPatterns = []scanPattern{
+	{rule: ScanRulePipeToShell, severity: ScanRiskHigh, message: "downloaded content is piped into a shell",
+		re: regexp.MustCompile(`(?i)\b(curl|wget|fetch)\b[^|\n]*\|\s*(sudo\s+)?(ba|z|da|k)?sh\b`)},
+	{rule: ScanRulePipeToShell, severity: ScanRiskHigh, message: "downloaded content is passed to Invoke-Expression",
+		re: regexp.MustCompile(`(?i)\b(iwr|irm|invoke-webrequest|invoke-restmethod)\b[^|\n]*\|\s*(iex|invoke-expression)\b`)},
+	{rule: ScanRuleEncodedPayload, severity: ScanRiskCritical, message: "base64-decoded content is executed",
+		re: regexp.MustCompile(`(?i)base64\s+(-d|-D|--decode)\b[^\n]*\|\s*(sudo\s+)?(ba|z|da)?sh\b|\beval\b[^\n]*base64\s+(-d|-D|--decode)`)},
+	{rule: ScanRuleEncodedPayload, severity: ScanRiskMedium, message: "large base64-encoded blob",
+		re: regexp.MustCompile(`[A-Za-z0-9+/]{200,}={0,2}`)},
+	{rule: ScanRuleSSHWrite, severity: ScanRiskCritical, message: "writes to the SSH directory",
+		re: regexp.MustCompile(`(>>?|\btee\s+(-a\s+)?|\b(cp|mv|install|ln)\s+(-\S+\s+)*\S+\s+)\s*["']?(~|\$HOME|\$\{HOME\})/\.ssh\b|>>?\s*\S*authorized_keys`)},
+	{rule: ScanRuleExfiltration, severity: ScanRiskHigh, message: "sends data to a remote host",
+		re: regexp.MustCompile(`(?i)\bcurl\b[^\n]*(\s-d\b|\s--data(-binary|-raw|-urlencode)?\b|\s-F\b|\s--form\b|\s-T\b|\s--upload-file\b)|\bwget\b[^\n]*--post-(data|file)\b|\b(nc|ncat|netcat)\s+(-\S+\s+)*[\w.-]+\s+\d+|/dev/(tcp|udp)/`)},
+	{rule: ScanRuleCredentials, severity: ScanRiskMedium, message: "reads credentials",
+		re: regexp.MustCompile(`(?i)\.aws/credentials|\.netrc\b|\.git-credentials|\.config/gh/hosts|\.docker/config\.json|\.ssh/id_(rsa|dsa|ecdsa|ed25519)\b|\$\{?(ANTHROPIC_API_KEY|OPENAI_API_KEY|GITHUB_TOKEN|GH_TOKEN|AWS_SECRET_ACCESS_KEY|NPM_TOKEN)\b`)},
+	{rule: ScanRulePromptInject, severity: ScanRiskHigh, message: "asks the agent to ignore its instructions", docsOnly: true,
+		re: regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\s+(all\s+|any\s+)?(of\s+)?(the\s+|your\s+)?(previous|prior|above|earlier|system|original)\s+(instructions|prompts?|rules|directives|guidelines)`)},
+	{rule: ScanRulePromptInject, severity: ScanRiskHigh, message: "asks the agent to hide actions from the user", docsOnly: true,
+		re: regexp.MustCompile(`(?i)\b(do\s+not|don't|never)\s+(tell|inform|notify|mention\s+(this\s+)?to|reveal\s+(this\s+)?to|show)\s+the\s+user\b|\bwithout\s+(asking|telling|notifying|informing|the\s+knowledge\s+of)\s+the\s+user\b|\bsilently\s+(run|execute|send|upload)\b`)},
+	{rule: ScanRulePromptInject, severity: ScanRiskHigh, message: "attempts to change the agent's role or extract its prompt", docsOnly: true,
+		re: regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(in\s+)?(developer\s+mode|dan\b|jailbroken|unrestricted)|\b(reveal|print|output|repeat|leak)\s+(your|the)\s+(system\s+prompt|hidden\s+instructions)`)},
+	{rule: ScanRulePromptInject, severity: ScanRiskMedium, message: "hidden HTML comment addressed to the agent", docsOnly: true,
+		re: regexp.MustCompile(`(?i)<!--[^>]*\b(assistant|ai|agent|llm|model|claude|instructions?)\b[^>]*-->`)},
+}
+
+// reHiddenUnicode 零宽字符与双向控制字符（文件开头的 BOM 除外）
+var reHiddenUnicode = regexp.MustCompile("[\u200b\u200c\u200d\u2060\u202a-\u202e\u2066-\u2069]|.\ufeff")
+
+// scanDocExtensions 按文档处理的文件
+var scanDocExtensions = map[string]bool{".md": true, ".markdown": true, ".txt": true, ".rst": true}
+
+// lowerRisk 降低一级风险
+func lowerRisk(severity string) string {
+	switch severity {
+	case ScanRiskCritical:
+		return ScanRiskHigh
+	case ScanRiskHigh:
+		return ScanRiskMedium
+	}
+	return ScanRiskLow
+}
+
+// riskReaches 判断风险等级是否达到阈值，阈值为 off 时从不阻止
+func riskReaches(risk, threshold string) bool {
+	if threshold == ScanThresholdOff {
+		return false
+	}
+	return risk != ScanRiskNone && scanRiskOrder[risk] >= scanRiskOrder[threshold]
+}
+
+// scanThreshold 设置中的阻止阈值，未设置或无效时使用默认值
+func (ss *SkillsService) scanThreshold() string {
+	if settings, _ := ss.GetSettings(); settings != nil {
+		switch t := settings.ScanBlockThreshold; {
+		case t == ScanThresholdOff:
+			return ScanThresholdOff
+		case scanRiskOrder[t] > 0:
+			return t
+		}
+	}
+	return defaultScanThreshold
+}
+
+// scanSkillDir 扫描 skill 目录，生成风险报告（不设置阈值）
+func scanSkillDir(skillName, dir string) (*SkillScanReport, error) {
+	report := &SkillScanReport{Skill: skillName, Risk: ScanRiskNone, Findings: []ScanFinding{}}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan skill: %v", err)
+	}
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		report.FilesScanned++
+
+		if d.Type()&os.ModeSymlink != 0 {
+			scanSymlink(report, root, path, rel)
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return scanFile(report, path, rel, info)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan skill: %v", err)
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return scanRiskOrder[report.Findings[i].Severity] > scanRiskOrder[report.Findings[j].Severity]
+	})
+	return report, nil
+}
+
+// scanSymlink 检查符号链接是否指向 skill 目录之外（无法解析的链接按目标路径判断）
+func scanSymlink(report *SkillScanReport, root, path, rel string) {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		resolved = target
+		if !filepath.IsAbs(resolved) {
+			resolved = filepath.Join(filepath.Dir(path), target)
+		}
+	}
+	if inside, err := filepath.Rel(root, resolved); err != nil || inside == ".." || strings.HasPrefix(inside, ".."+string(filepath.Separator)) || filepath.IsAbs(inside) {
+		report.add(ScanRiskCritical, ScanRuleSymlinkEscape, rel, 0, target, "symlink points outside the skill directory: %s", target)
+	}
+}
+
+// scanFile 检查单个文件：二进制文件看格式与大小，文本文件逐行匹配规则
+func scanFile(report *SkillScanReport, path, rel string, info os.FileInfo) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	head := make([]byte, 8000)
+	n, _ := io.ReadFull(f, head)
+	head = head[:n]
+
+	binary := bytes.IndexByte(head, 0) >= 0
+	if format := executableFormat(head); binary && format != "" {
+		report.add(ScanRiskHigh, ScanRuleExecutable, rel, 0, "", "%s executable binary", format)
+	} else if info.Mode()&0111 != 0 {
+		report.add(ScanRiskLow, ScanRuleExecutable, rel, 0, "", "file has the executable bit set")
+	}
+
+	if binary {
+		if info.Size() > maxScanBinarySize {
+			report.add(ScanRiskMedium, ScanRuleOversized, rel, 0, "", "binary file is %d KB", info.Size()>>10)
+		}
+		return nil
+	}
+	if info.Size() > maxScanTextSize {
+		return nil
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	isDoc := scanDocExtensions[strings.ToLower(filepath.Ext(rel))]
+	counts := map[string]int{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxScanTextSize)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		for _, p := range scanPatterns {
+			if p.docsOnly && !isDoc {
+				continue
+			}
+			if counts[p.rule] >= maxScanFindingsPerRule {
+				continue
+			}
+			match := p.re.FindString(line)
+			if match == "" {
+				continue
+			}
+			severity := p.severity
+			if isDoc && !p.docsOnly {
+				severity = lowerRisk(severity)
+			}
+			counts[p.rule]++
+			report.add(severity, p.rule, rel, lineNo, strings.TrimSpace(match), "%s", p.message)
+		}
+		if counts[ScanRuleHiddenUnicode] < maxScanFindingsPerRule && reHiddenUnicode.MatchString(line) {
+			counts[ScanRuleHiddenUnicode]++
+			report.add(ScanRiskMedium, ScanRuleHiddenUnicode, rel, lineNo, "", "contains invisible or bidirectional control characters")
+		}
+	}
+	return nil
+}
+
+// executableFormat 按文件头识别可执行二进制格式
+func executableFormat(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte("\x7fELF")):
+		return "ELF"
+	case bytes.HasPrefix(head, []byte("MZ")):
+		return "PE"
+	case bytes.HasPrefix(head, []byte{0xcf, 0xfa, 0xed, 0xfe}), bytes.HasPrefix(head, []byte{0xce, 0xfa, 0xed, 0xfe}),
+		bytes.HasPrefix(head, []byte{0xca, 0xfe, 0xba, 0xbe}):
+		return "Mach-O"
+	}
+	return ""
+}
+
+// checkSkillScan 扫描即将安装的 skill 目录，风险达到阈值且未放行时返回 SkillScanBlockedError
+func (ss *SkillsService) checkSkillScan(skillName, dir string, allowRisk bool) (*SkillScanReport, error) {
+	report, err := scanSkillDir(skillName, dir)
+	if err != nil {
+		return nil, err
+	}
+	report.Threshold = ss.scanThreshold()
+	report.Blocked = riskReaches(report.Risk, report.Threshold)
+	if report.Blocked && !allowRisk {
+		return report, &SkillScanBlockedError{Report: report}
+	}
+	return report, nil
+}
+
+// ScanRemoteSkill 获取来源内容并扫描其中的 skill，不安装
+func (ss *SkillsService) ScanRemoteSkill(fullName string) (*SkillScanReport, error) {
+	sourceName, skillName, ref, err := parseSkillFullName(fullName)
+	if err != nil {
+		return nil, err
+	}
+	origin, _, err := ss.resolveSkillSource(sourceName, ref)
+	if err != nil {
+		return nil, err
+	}
+	fetched, err := ss.fetchSkillSource(origin)
+	if err != nil {
+		return nil, err
+	}
+	defer fetched.Close()
+	skillDir := fetched.skillDir("", skillName)
+	if skillDir == "" {
+		return nil, fmt.Errorf("skill not found in source: %s", skillName)
+	}
+	report, err := ss.checkSkillScan(skillName, skillDir, true)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// ScanSkill 扫描已安装的 skill
+func (ss *SkillsService) ScanSkill(skillName string) (*SkillScanReport, error) {
+	if err := validSkillName(skillName); err != nil {
+		return nil, err
+	}
+	skillDir := filepath.Join(ss.env.SkillsDir, skillName)
+	if _, err := os.Stat(skillDir); err != nil {
+		return nil, fmt.Errorf("skill not found: %s", skillName)
+	}
+	return ss.checkSkillScan(skillName, skillDir, true)
+}
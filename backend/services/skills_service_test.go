@@ -0,0 +1,46 @@
+package services
+
+import "testing"
+
+func TestParseSkillFullName(t *testing.T) {
+	tests := []struct {
+		fullName string
+		source   string
+		skill    string
+		ref      string
+		wantErr  bool
+	}{
+		{fullName: "vercel-labs/agent-skills@react-best-practices", source: "vercel-labs/agent-skills", skill: "react-best-practices"},
+		{fullName: "vercel-labs/agent-skills@react-best-practices#v1.2.0", source: "vercel-labs/agent-skills", skill: "react-best-practices", ref: "v1.2.0"},
+		{fullName: "git+ssh://git@git.example.com/team/skills.git@deploy#main", source: "git+ssh://git@git.example.com/team/skills.git", skill: "deploy", ref: "main"},
+		{fullName: "https://example.com/skills.tar.gz@pdf", source: "https://example.com/skills.tar.gz", skill: "pdf"},
+		{fullName: "acme/skills@ns:deploy", source: "acme/skills", skill: "ns:deploy"},
+		{fullName: "acme/skills@deploy.v2", source: "acme/skills", skill: "deploy.v2"},
+		{fullName: "acme/skills", wantErr: true},
+		{fullName: "@deploy", wantErr: true},
+		{fullName: "acme/skills@", wantErr: true},
+		{fullName: "acme/skills@deploy#", wantErr: true},
+		{fullName: "acme/skills@deploy#-c", wantErr: true},
+		{fullName: "acme/skills@a/b", wantErr: true},
+		{fullName: `acme/skills@a\b`, wantErr: true},
+		{fullName: "acme/skills@..", wantErr: true},
+		{fullName: "acme/skills@.", wantErr: true},
+		{fullName: "acme/skills@.skills-lock", wantErr: true},
+	}
+	for _, tt := range tests {
+		source, skill, ref, err := parseSkillFullName(tt.fullName)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseSkillFullName(%q) = %q, %q, %q, want error", tt.fullName, source, skill, ref)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseSkillFullName(%q) error: %v", tt.fullName, err)
+			continue
+		}
+		if source != tt.source || skill != tt.skill || ref != tt.ref {
+			t.Errorf("parseSkillFullName(%q) = %q, %q, %q, want %q, %q, %q", tt.fullName, source, skill, ref, tt.source, tt.skill, tt.ref)
+		}
+	}
+}
@@ -0,0 +1,474 @@
+package services
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ---- skill 来源解析 ----
+// 安装名称为 <source>@<skill>[#ref]，source 支持：
+//
+//	owner/repo                                   GitHub 简写
+//	<自定义源名称>                                 custom-sources.json 中的 git 仓库
+//	git+https://host/group/repo.git              任意 git 仓库（GitLab、Gitea、自建服务），
+//	git+ssh://git@host/group/repo.git            也接受不带 git+ 前缀的 https:// / ssh:// / git@host:path
+//	file:///path/to/dir                          本地目录（单个 skill 或包含多个 skills 的目录）
+//	https://host/skills.zip、/path/skills.tar.gz  压缩包（.zip / .tar.gz / .tgz / .tar），URL 或本地路径
+//
+// 解析结果作为 SkillSource 记录在 .skills-lock 中，更新与更新检测按来源类型重新获取内容
+
+// maxArchiveSize 压缩包下载与解压后的大小上限
+const maxArchiveSize = 256 << 20
+
+// archiveSuffixes 识别为压缩包的文件后缀
+var archiveSuffixes = []string{".zip", ".tar.gz", ".tgz", ".tar"}
+
+// scpLikeGitURL 匹配 git@host:group/repo.git 形式的 ssh 地址
+var scpLikeGitURL = regexp.MustCompile(`^\w[\w.-]*@[\w.-]+:[^/]`)
+
+// archiveHTTPClient 下载压缩包使用较长的超时
+var archiveHTTPClient = &http.Client{Timeout: 5 * time.Minute}
+
+// isGit 来源是否为 git 仓库（可检出 ref、有 commit）
+func (s SkillSource) isGit() bool {
+	return s.Type == SourceTypeGitHub || s.Type == SourceTypeGit || s.Type == SourceTypeCustom
+}
+
+// archiveSuffix 返回路径的压缩包后缀，不是压缩包时为空
+func archiveSuffix(p string) string {
+	p = strings.ToLower(p)
+	if i := strings.IndexAny(p, "?#"); i >= 0 && strings.Contains(p, "://") {
+		p = p[:i]
+	}
+	for _, suffix := range archiveSuffixes {
+		if strings.HasSuffix(p, suffix) {
+			return suffix
+		}
+	}
+	return ""
+}
+
+// hasURLScheme 判断 s 是否以指定 scheme 之一开头
+func hasURLScheme(s string, schemes ...string) bool {
+	for _, scheme := range schemes {
+		if strings.HasPrefix(s, scheme+"://") {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSkillSource 将安装名称中的来源部分解析为来源描述，返回描述与写入 .skills-lock 的规范来源字符串
+func (ss *SkillsService) resolveSkillSource(source, ref string) (SkillSource, string, error) {
+	source = strings.TrimSpace(source)
+	var src SkillSource
+	switch {
+	case source == "":
+		return src, "", fmt.Errorf("empty source")
+
+	case strings.HasPrefix(source, "-"):
+		// 以 - 开头的来源会被 git 当作选项
+		return src, "", fmt.Errorf("unsupported source: %s", source)
+
+	case strings.HasPrefix(source, "file://"):
+		p := strings.TrimPrefix(source, "file://")
+		if !filepath.IsAbs(p) {
+			return src, "", fmt.Errorf("file:// source must be an absolute path: %s", source)
+		}
+		p = filepath.Clean(p)
+		if archiveSuffix(p) != "" {
+			src = SkillSource{Type: SourceTypeArchive, LocalPath: p}
+			source = p
+		} else {
+			src = SkillSource{Type: SourceTypeLocal, LocalPath: p}
+			source = "file://" + p
+		}
+
+	case strings.HasPrefix(source, "git+"):
+		u := strings.TrimPrefix(source, "git+")
+		if !hasURLScheme(u, "https", "http", "ssh", "git", "file") {
+			return src, "", fmt.Errorf("unsupported git source: %s", source)
+		}
+		src = SkillSource{Type: SourceTypeGit, URL: u}
+
+	case archiveSuffix(source) != "" && hasURLScheme(source, "https", "http"):
+		src = SkillSource{Type: SourceTypeArchive, URL: source}
+
+	case archiveSuffix(source) != "":
+		abs, err := filepath.Abs(ss.expandHome(source))
+		if err != nil {
+			return src, "", fmt.Errorf("invalid archive path %s: %v", source, err)
+		}
+		src = SkillSource{Type: SourceTypeArchive, LocalPath: abs}
+		source = abs
+
+	case hasURLScheme(source, "https", "http", "ssh", "git") || scpLikeGitURL.MatchString(source):
+		src = SkillSource{Type: SourceTypeGit, URL: source}
+
+	default:
+		if custom, err := loadCustomSources(ss.env); err == nil {
+			for _, s := range custom {
+				if s.Name == source {
+					src = SkillSource{Type: SourceTypeCustom, URL: s.URL, CustomSource: s.Name}
+					break
+				}
+			}
+		}
+		if src.Type == "" {
+			if !ownerRepoPattern.MatchString(source) {
+				return src, "", fmt.Errorf("unsupported source: %s", source)
+			}
+			source = strings.TrimSuffix(source, ".git")
+			src = githubSource(source, "", "")
+		}
+	}
+
+	if ref != "" && !src.isGit() {
+		return src, "", fmt.Errorf("#ref is only supported for git sources: %s", source)
+	}
+	src.Ref = ref
+	return src, source, nil
+}
+
+// entrySource 返回已安装 skill 的来源描述；缺少 origin 的早期条目按 source 字段重新解析
+func (ss *SkillsService) entrySource(e SkillLockEntry) (SkillSource, error) {
+	src := e.SourceDescriptor()
+	if src.URL != "" || src.LocalPath != "" {
+		return src, nil
+	}
+	if e.Source == "" || e.Source == SourceTypeLocal {
+		return src, fmt.Errorf("skill has no remote source")
+	}
+	resolved, _, err := ss.resolveSkillSource(e.Source, e.Ref)
+	if err != nil {
+		return src, err
+	}
+	resolved.Subpath = src.Subpath
+	return resolved, nil
+}
+
+// fetchedSource 取到本地的来源内容，用完后调用 Close 清理临时文件
+type fetchedSource struct {
+	Dir       string // 来源根目录：仓库工作目录、压缩包解压目录或本地目录本身
+	CommitSHA string // git 来源检出的 commit，其他来源为空
+	tempDir   string
+}
+
+func (f *fetchedSource) Close() {
+	if f.tempDir != "" {
+		os.RemoveAll(f.tempDir)
+	}
+}
+
+// skillDir 在来源内容中定位 skill 目录：优先使用记录的子路径，否则按名称查找
+func (f *fetchedSource) skillDir(subpath, skillName string) string {
+	if subpath != "" {
+		dir := filepath.Join(f.Dir, filepath.FromSlash(subpath))
+		if repoSubpath(f.Dir, dir) != "" && hasSkillMd(dir) {
+			return dir
+		}
+	}
+	return findSkillInRepo(f.Dir, skillName)
+}
+
+// fetchSkillSource 按来源类型获取内容：git 来源经克隆缓存检出 Ref，本地目录直接使用，压缩包下载（或读取本地文件）后解压
+func (ss *SkillsService) fetchSkillSource(src SkillSource) (*fetchedSource, error) {
+	return ss.fetchSource(src, ss.checkoutRepo)
+}
+
+// fetchSkillSourceForPlan 供 Plan* 使用：git 来源同样经克隆缓存检出（缓存会被 fetch 刷新），但不触发缓存淘汰
+func (ss *SkillsService) fetchSkillSourceForPlan(src SkillSource) (*fetchedSource, error) {
+	return ss.fetchSource(src, ss.checkoutCachedRepo)
+}
+
+// fetchSource 获取来源内容，git 来源通过 checkout 检出
+func (ss *SkillsService) fetchSource(src SkillSource, checkout func(repoURL, destDir, ref string) ([]byte, error)) (*fetchedSource, error) {
+	switch {
+	case src.isGit():
+		if src.URL == "" {
+			return nil, fmt.Errorf("git source has no URL")
+		}
+		tempDir, err := os.MkdirTemp("", "skills-repo-")
+		if err != nil {
+			return nil, err
+		}
+		repoDir := filepath.Join(tempDir, "repo")
+		if out, err := checkout(src.URL, repoDir, src.Ref); err != nil {
+			os.RemoveAll(tempDir)
+			return nil, fmt.Errorf("failed to clone repository: %v\nOutput: %s", err, string(out))
+		}
+		return &fetchedSource{Dir: repoDir, CommitSHA: gitHeadSHA(repoDir), tempDir: tempDir}, nil
+
+	case src.Type == SourceTypeLocal:
+		if src.LocalPath == "" {
+			return nil, fmt.Errorf("local source has no path")
+		}
+		if info, err := os.Stat(src.LocalPath); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("local source directory not found: %s", src.LocalPath)
+		}
+		return &fetchedSource{Dir: src.LocalPath}, nil
+
+	case src.Type == SourceTypeArchive:
+		tempDir, err := os.MkdirTemp("", "skills-archive-")
+		if err != nil {
+			return nil, err
+		}
+		archivePath := src.LocalPath
+		if archivePath == "" {
+			if ss.IsOfflineMode() {
+				os.RemoveAll(tempDir)
+				return nil, fmt.Errorf("archive %s cannot be downloaded in offline mode", src.URL)
+			}
+			archivePath = filepath.Join(tempDir, "archive"+archiveSuffix(src.URL))
+			if err := downloadArchive(src.URL, archivePath, ss.hostAuth(src.URL)); err != nil {
+				os.RemoveAll(tempDir)
+				return nil, fmt.Errorf("failed to download %s: %v", src.URL, err)
+			}
+		}
+		contentDir := filepath.Join(tempDir, "content")
+		if err := extractArchive(archivePath, contentDir); err != nil {
+			os.RemoveAll(tempDir)
+			return nil, fmt.Errorf("failed to extract %s: %v", filepath.Base(archivePath), err)
+		}
+		return &fetchedSource{Dir: archiveRoot(contentDir), tempDir: tempDir}, nil
+	}
+	return nil, fmt.Errorf("unsupported source type: %s", src.Type)
+}
+
+// ---- 压缩包 ----
+
+// downloadArchive 下载压缩包到 dest，超过 maxArchiveSize 时报错；配置了 host 凭据时带上 token
+func downloadArchive(url, dest string, auth hostAuth) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	auth.authorize(req)
+	resp, err := archiveHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(resp.Body, maxArchiveSize+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if n > maxArchiveSize {
+		return fmt.Errorf("archive exceeds %d MB", maxArchiveSize>>20)
+	}
+	return nil
+}
+
+// extractArchive 按后缀解压 zip / tar / tar.gz 到 dest，只解出普通文件与目录（忽略符号链接等）
+func extractArchive(archivePath, dest string) error {
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	switch archiveSuffix(archivePath) {
+	case ".zip":
+		return extractZip(archivePath, dest)
+	case ".tar.gz", ".tgz":
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		return extractTar(gz, dest)
+	case ".tar":
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return extractTar(f, dest)
+	}
+	return fmt.Errorf("unsupported archive format: %s", filepath.Base(archivePath))
+}
+
+// archiveEntryPath 返回压缩包条目在 dest 中的路径，拒绝指向 dest 之外的条目
+func archiveEntryPath(dest, name string) (string, error) {
+	target := filepath.Join(dest, filepath.FromSlash(name))
+	if target != dest && !strings.HasPrefix(target, dest+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive entry escapes destination: %s", name)
+	}
+	return target, nil
+}
+
+// writeArchiveFile 写入一个解压出的文件，remaining 为剩余可写入的字节数
+func writeArchiveFile(target string, r io.Reader, mode os.FileMode, remaining *int64) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(r, *remaining+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	*remaining -= n
+	if *remaining < 0 {
+		return fmt.Errorf("archive content exceeds %d MB", maxArchiveSize>>20)
+	}
+	return nil
+}
+
+func extractZip(archivePath, dest string) error {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	remaining := int64(maxArchiveSize)
+	for _, f := range r.File {
+		target, err := archiveEntryPath(dest, f.Name)
+		if err != nil {
+			return err
+		}
+		mode := f.Mode()
+		if mode.IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if !mode.IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writeArchiveFile(target, rc, mode, &remaining)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTar(r io.Reader, dest string) error {
+	tr := tar.NewReader(r)
+	remaining := int64(maxArchiveSize)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := archiveEntryPath(dest, hdr.Name)
+		if err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeArchiveFile(target, tr, os.FileMode(hdr.Mode), &remaining); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// archiveRoot 压缩包只包含一个顶层目录时（例如 GitHub 下载的 repo-main/）以该目录为根
+func archiveRoot(dir string) string {
+	if hasSkillMd(dir) {
+		return dir
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return dir
+	}
+	var only os.DirEntry
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") || e.Name() == "__MACOSX" {
+			continue
+		}
+		if only != nil {
+			return dir
+		}
+		only = e
+	}
+	if only != nil && only.IsDir() {
+		return filepath.Join(dir, only.Name())
+	}
+	return dir
+}
+
+// ---- 更新检测 ----
+
+// checkSourceUpdate 检测非 GitHub 来源的更新：git 来源先对比 ref 当前的 commit，
+// commit 变化（或本地目录、压缩包来源）时再对比 skill 内容哈希
+func (ss *SkillsService) checkSourceUpdate(name string, e SkillLockEntry) SkillUpdateInfo {
+	info := SkillUpdateInfo{
+		Name:       name,
+		Source:     e.Source,
+		CurrentSHA: e.CommitSHA,
+		Ref:        e.Ref,
+		Offline:    ss.IsOfflineMode(),
+	}
+	info.LocalModified = ss.verifySkill(name, e).mayBeModified()
+
+	src, err := ss.entrySource(e)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	fetched, err := ss.fetchSkillSource(src)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	defer fetched.Close()
+
+	info.LatestSHA = fetched.CommitSHA
+	if fetched.CommitSHA != "" && fetched.CommitSHA == e.CommitSHA {
+		return info
+	}
+	skillDir := fetched.skillDir(src.Subpath, name)
+	if skillDir == "" {
+		info.Error = "skill not found in source"
+		return info
+	}
+	hash, _, err := hashSkillTree(skillDir)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.HasUpdate = hash != e.TreeHash
+	return info
+}
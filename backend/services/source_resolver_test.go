@@ -0,0 +1,132 @@
+package services
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveEntryPath(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "content")
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "SKILL.md", want: filepath.Join(dest, "SKILL.md")},
+		{name: "repo-main/skills/pdf/SKILL.md", want: filepath.Join(dest, "repo-main", "skills", "pdf", "SKILL.md")},
+		{name: "repo-main/../SKILL.md", want: filepath.Join(dest, "SKILL.md")},
+		{name: "./", want: dest},
+		{name: "/etc/passwd", want: filepath.Join(dest, "etc", "passwd")},
+		{name: "../evil", wantErr: true},
+		{name: "repo-main/../../evil", wantErr: true},
+		{name: "..", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := archiveEntryPath(dest, tt.name)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("archiveEntryPath(%q) = %q, want error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("archiveEntryPath(%q) error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("archiveEntryPath(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExtractArchiveRejectsEscapingEntries(t *testing.T) {
+	tests := []struct {
+		suffix string
+		build  func(t *testing.T, files map[string]string) []byte
+	}{
+		{suffix: ".tar", build: buildTar},
+		{suffix: ".zip", build: buildZip},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		archivePath := filepath.Join(dir, "skills"+tt.suffix)
+		data := tt.build(t, map[string]string{"skill/SKILL.md": "---\nname: skill\n---\n", "../evil": "x"})
+		if err := os.WriteFile(archivePath, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		dest := filepath.Join(dir, "sub", "content")
+		if err := extractArchive(archivePath, dest); err == nil {
+			t.Errorf("extractArchive(%s) succeeded, want error for ../evil", tt.suffix)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "sub", "evil")); !os.IsNotExist(err) {
+			t.Errorf("extractArchive(%s) wrote outside the destination", tt.suffix)
+		}
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	files := map[string]string{"repo-main/skills/pdf/SKILL.md": "---\nname: pdf\n---\n"}
+	tests := []struct {
+		suffix string
+		build  func(t *testing.T, files map[string]string) []byte
+	}{
+		{suffix: ".tar", build: buildTar},
+		{suffix: ".zip", build: buildZip},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		archivePath := filepath.Join(dir, "skills"+tt.suffix)
+		if err := os.WriteFile(archivePath, tt.build(t, files), 0644); err != nil {
+			t.Fatal(err)
+		}
+		dest := filepath.Join(dir, "content")
+		if err := extractArchive(archivePath, dest); err != nil {
+			t.Fatalf("extractArchive(%s) error: %v", tt.suffix, err)
+		}
+		if got := archiveRoot(dest); got != filepath.Join(dest, "repo-main") {
+			t.Errorf("archiveRoot(%s) = %q, want the single top-level directory", tt.suffix, got)
+		}
+		if !hasSkillMd(filepath.Join(dest, "repo-main", "skills", "pdf")) {
+			t.Errorf("extractArchive(%s) did not extract SKILL.md", tt.suffix)
+		}
+	}
+}
+
+func buildTar(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -16,6 +16,7 @@ import (
 // TemplateService 模板市场服务
 type TemplateService struct {
 	ctx context.Context
+	env *Environment
 }
 
 // EnhancedSkillTemplate 增强的技能模板
@@ -59,8 +60,8 @@ type TemplateSource struct {
 	LastSync    time.Time `json:"lastSync"`
 }
 
-func NewTemplateService() *TemplateService {
-	return &TemplateService{}
+func NewTemplateService(env *Environment) *TemplateService {
+	return &TemplateService{env: env}
 }
 
 func (ts *TemplateService) Startup(ctx context.Context) {
@@ -392,12 +393,7 @@ category: data-science
 
 // getCustomTemplates 获取自定义模板
 func (ts *TemplateService) getCustomTemplates() ([]EnhancedSkillTemplate, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
+	configDir := ts.env.ConfigDir
 	templatesFile := filepath.Join(configDir, "custom-templates.json")
 	
 	if _, err := os.Stat(templatesFile); os.IsNotExist(err) {
@@ -475,44 +471,36 @@ func (ts *TemplateService) GetTemplateCategories() ([]TemplateCategory, error) {
 
 // CreateCustomTemplate 创建自定义模板
 func (ts *TemplateService) CreateCustomTemplate(template EnhancedSkillTemplate) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
+	configDir := ts.env.ConfigDir
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 	
 	templatesFile := filepath.Join(configDir, "custom-templates.json")
 	
-	// 读取现有模板
-	var templates []EnhancedSkillTemplate
-	if data, err := os.ReadFile(templatesFile); err == nil {
-		json.Unmarshal(data, &templates)
-	}
-	
-	// 设置模板属性
-	template.CreatedAt = time.Now()
-	template.UpdatedAt = time.Now()
-	template.IsBuiltIn = false
-	template.Version = "1.0.0"
-	
-	// 添加新模板
-	templates = append(templates, template)
-	
-	// 保存到文件
-	data, err := json.MarshalIndent(templates, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal templates: %w", err)
-	}
-	
-	if err := os.WriteFile(templatesFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write templates file: %w", err)
-	}
-	
-	return nil
+	return withFileLock(templatesFile, func() error {
+		// 读取现有模板
+		var templates []EnhancedSkillTemplate
+		if data, err := os.ReadFile(templatesFile); err == nil {
+			json.Unmarshal(data, &templates)
+		}
+		
+		// 设置模板属性
+		template.CreatedAt = time.Now()
+		template.UpdatedAt = time.Now()
+		template.IsBuiltIn = false
+		template.Version = "1.0.0"
+		
+		// 添加新模板
+		templates = append(templates, template)
+		
+		// 保存到文件
+		if err := writeJSONFile(templatesFile, templates); err != nil {
+			return fmt.Errorf("failed to write templates file: %w", err)
+		}
+		
+		return nil
+	})
 }
 
 // RateTemplate 为模板评分
@@ -521,50 +509,37 @@ func (ts *TemplateService) RateTemplate(templateID string, rating int, comment s
 		return fmt.Errorf("rating must be between 1 and 5")
 	}
 	
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
+	configDir := ts.env.ConfigDir
 	ratingsFile := filepath.Join(configDir, "template-ratings.json")
 	
-	// 读取现有评分
-	var ratings []TemplateRating
-	if data, err := os.ReadFile(ratingsFile); err == nil {
-		json.Unmarshal(data, &ratings)
-	}
-	
-	// 添加新评分
-	newRating := TemplateRating{
-		TemplateID: templateID,
-		Rating:     rating,
-		Comment:    comment,
-		CreatedAt:  time.Now(),
-	}
-	ratings = append(ratings, newRating)
-	
-	// 保存到文件
-	data, err := json.MarshalIndent(ratings, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal ratings: %w", err)
-	}
-	
-	if err := os.WriteFile(ratingsFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write ratings file: %w", err)
-	}
-	
-	return nil
+	return withFileLock(ratingsFile, func() error {
+		// 读取现有评分
+		var ratings []TemplateRating
+		if data, err := os.ReadFile(ratingsFile); err == nil {
+			json.Unmarshal(data, &ratings)
+		}
+		
+		// 添加新评分
+		newRating := TemplateRating{
+			TemplateID: templateID,
+			Rating:     rating,
+			Comment:    comment,
+			CreatedAt:  time.Now(),
+		}
+		ratings = append(ratings, newRating)
+		
+		// 保存到文件
+		if err := writeJSONFile(ratingsFile, ratings); err != nil {
+			return fmt.Errorf("failed to write ratings file: %w", err)
+		}
+		
+		return nil
+	})
 }
 
 // getTemplateSources 获取模板源配置
 func (ts *TemplateService) getTemplateSources() ([]TemplateSource, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
-	}
-	
-	configDir := filepath.Join(homeDir, ".skills-manager")
+	configDir := ts.env.ConfigDir
 	sourcesFile := filepath.Join(configDir, "template-sources.json")
 	
 	// 如果文件不存在，创建默认配置
@@ -578,8 +553,7 @@ func (ts *TemplateService) getTemplateSources() ([]TemplateSource, error) {
 			},
 		}
 		
-		data, _ := json.MarshalIndent(defaultSources, "", "  ")
-		os.WriteFile(sourcesFile, data, 0644)
+		saveJSONConfig(sourcesFile, defaultSources)
 		return defaultSources, nil
 	}
 	
@@ -0,0 +1,75 @@
+package services
+
+import "strings"
+
+// ---- 行级 diff 工具 ----
+
+// maxLCSCells LCS 动态规划表的最大单元数，超过时放弃逐行匹配（避免超大文件占用过多内存）
+const maxLCSCells = 4_000_000
+
+// splitLines 按行拆分文本，每行保留结尾的换行符，便于原样拼回
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lcsMatches 计算 a 与 b 的最长公共子序列
+// 返回长度为 len(a) 的切片：a[i] 匹配到的 b 下标，未匹配为 -1
+func lcsMatches(a, b []string) []int {
+	matches := make([]int, len(a))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	// 去掉公共前后缀，缩小 DP 规模
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		matches[prefix] = prefix
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		matches[len(a)-1-suffix] = len(b) - 1 - suffix
+		suffix++
+	}
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	n, m := len(ma), len(mb)
+	if n == 0 || m == 0 || (n+1)*(m+1) > maxLCSCells {
+		return matches
+	}
+
+	// dp[i][j] = ma[i:] 与 mb[j:] 的 LCS 长度
+	dp := make([]int32, (n+1)*(m+1))
+	at := func(i, j int) int32 { return dp[i*(m+1)+j] }
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case ma[i] == mb[j]:
+				dp[i*(m+1)+j] = at(i+1, j+1) + 1
+			case at(i+1, j) >= at(i, j+1):
+				dp[i*(m+1)+j] = at(i+1, j)
+			default:
+				dp[i*(m+1)+j] = at(i, j+1)
+			}
+		}
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case ma[i] == mb[j]:
+			matches[prefix+i] = prefix + j
+			i++
+			j++
+		case at(i+1, j) >= at(i, j+1):
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
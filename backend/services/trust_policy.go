@@ -0,0 +1,301 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// ---- 信任策略 ----
+// trust-policy.json 限制可以安装的 skill 来源，团队可以统一分发该文件（或通过 AGENT_HUB_TRUST_POLICY 指向共享路径）：
+//
+//	{
+//	  "allowedOwners": ["acme", "vercel-labs", "gitlab.example.com/team"],
+//	  "allowedHosts": ["skills.example.com"],
+//	  "allowLocal": false,
+//	  "blockedSkills": ["*-experimental", "vercel-labs/agent-skills@deploy"],
+//	  "requirePin": true,
+//	  "requireSignature": false
+//	}
+//
+// 安装、更新、导入配置与安装集合时检查，违反策略的请求返回 TrustPolicyViolationError 并写入活动日志。
+// 文件不存在时不做限制；文件无法解析时拒绝所有安装，避免策略损坏后静默放开
+
+// 策略规则
+const (
+	PolicyRuleAllowedSources   = "allowed-sources"
+	PolicyRuleBlockedSkill     = "blocked-skill"
+	PolicyRuleRequirePin       = "require-pin"
+	PolicyRuleRequireSignature = "require-signature"
+)
+
+// activityActionPolicy 策略拦截在活动日志中的 action
+const activityActionPolicy = "policy"
+
+// TrustPolicy 信任策略
+type TrustPolicy struct {
+	AllowedOwners    []string `json:"allowedOwners,omitempty"`    // 允许的仓库 owner，github.com 直接写 owner，其他 host 写 host/owner
+	AllowedHosts     []string `json:"allowedHosts,omitempty"`     // 允许的 host，其上的所有仓库与压缩包都允许
+	AllowLocal       bool     `json:"allowLocal,omitempty"`       // 设置了允许列表时是否仍允许本地目录与本地压缩包
+	BlockedSkills    []string `json:"blockedSkills,omitempty"`    // 禁止安装的 skill：名称或 source@name，支持 * ? 通配
+	RequirePin       bool     `json:"requirePin,omitempty"`       // git 来源必须通过 #ref 固定分支/tag/commit
+	RequireSignature bool     `json:"requireSignature,omitempty"` // skill 必须带有通过校验的签名
+}
+
+// TrustPolicyInfo 当前策略与文件位置
+type TrustPolicyInfo struct {
+	Path   string      `json:"path"`
+	Exists bool        `json:"exists"`
+	Policy TrustPolicy `json:"policy"`
+}
+
+// TrustPolicyViolationError 安装请求违反信任策略
+type TrustPolicyViolationError struct {
+	Skill  string // source@skill
+	Rule   string
+	Reason string
+}
+
+func (e *TrustPolicyViolationError) Error() string {
+	return fmt.Sprintf("trust policy violation: %s: %s (%s)", e.Skill, e.Reason, e.Rule)
+}
+
+// isPolicyViolation 判断错误是否为策略拦截
+func isPolicyViolation(err error) bool {
+	var violation *TrustPolicyViolationError
+	return errors.As(err, &violation)
+}
+
+func getTrustPolicyFilePath(env *Environment) (string, error) {
+	if env.TrustPolicyPath != "" {
+		return env.TrustPolicyPath, nil
+	}
+	return env.configFilePath("trust-policy.json")
+}
+
+// loadTrustPolicy 读取信任策略，文件不存在时返回空策略
+func loadTrustPolicy(env *Environment) (TrustPolicy, bool, error) {
+	var policy TrustPolicy
+	filePath, err := getTrustPolicyFilePath(env)
+	if err != nil {
+		return policy, false, err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return policy, false, nil
+		}
+		return policy, false, fmt.Errorf("failed to read trust policy: %v", err)
+	}
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return policy, true, fmt.Errorf("failed to parse trust policy %s: %v", filePath, err)
+	}
+	if err := policy.normalize(); err != nil {
+		return policy, true, fmt.Errorf("invalid trust policy %s: %v", filePath, err)
+	}
+	return policy, true, nil
+}
+
+// normalize 统一 owner / host 的大小写与格式，并校验通配模式
+func (p *TrustPolicy) normalize() error {
+	clean := func(items []string, fn func(string) string) []string {
+		var result []string
+		for _, item := range items {
+			if item = fn(strings.TrimSpace(item)); item != "" {
+				result = append(result, item)
+			}
+		}
+		return result
+	}
+	p.AllowedOwners = clean(p.AllowedOwners, func(s string) string {
+		return strings.ToLower(strings.Trim(strings.TrimPrefix(strings.TrimPrefix(s, "https://"), "http://"), "/"))
+	})
+	p.AllowedHosts = clean(p.AllowedHosts, normalizeCredentialHost)
+	p.BlockedSkills = clean(p.BlockedSkills, func(s string) string { return s })
+	for _, pattern := range p.BlockedSkills {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid blocked skill pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// restrictsSources 是否配置了来源允许列表
+func (p TrustPolicy) restrictsSources() bool {
+	return len(p.AllowedOwners) > 0 || len(p.AllowedHosts) > 0
+}
+
+// sourceHostOwner 返回来源的 host[:port] 与 owner（仓库路径第一段），本地来源返回空
+func sourceHostOwner(origin SkillSource) (string, string) {
+	if origin.URL == "" {
+		return "", ""
+	}
+	_, host := splitRepoURL(origin.URL)
+	var repoPath string
+	if scpLikeGitURL.MatchString(origin.URL) {
+		repoPath = origin.URL[strings.Index(origin.URL, ":")+1:]
+	} else if u, err := url.Parse(origin.URL); err == nil {
+		repoPath = u.Path
+	}
+	owner, _, _ := strings.Cut(strings.Trim(repoPath, "/"), "/")
+	return host, strings.ToLower(owner)
+}
+
+// hostMatches host 完全一致，或允许项不带端口时按主机名匹配
+func hostMatches(allowed, host string) bool {
+	return allowed == host || allowed == strings.Split(host, ":")[0]
+}
+
+// checkSource 检查来源允许列表、禁止列表与版本固定要求；source 为规范化后的来源名称
+func (p TrustPolicy) checkSource(source, skillName string, origin SkillSource) *TrustPolicyViolationError {
+	fullName := source + "@" + skillName
+	violation := func(rule, format string, args ...interface{}) *TrustPolicyViolationError {
+		return &TrustPolicyViolationError{Skill: fullName, Rule: rule, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	for _, pattern := range p.BlockedSkills {
+		for _, name := range []string{skillName, fullName} {
+			if ok, _ := path.Match(pattern, name); ok {
+				return violation(PolicyRuleBlockedSkill, "skill is blocked by pattern %q", pattern)
+			}
+		}
+	}
+
+	if p.restrictsSources() {
+		host, owner := sourceHostOwner(origin)
+		if host == "" {
+			if !p.AllowLocal {
+				return violation(PolicyRuleAllowedSources, "local sources are not allowed")
+			}
+		} else if !p.allowsHostOwner(host, owner) {
+			return violation(PolicyRuleAllowedSources, "source %s/%s is not in the allowed owners or hosts", host, owner)
+		}
+	}
+
+	if p.RequirePin && origin.isGit() && origin.Ref == "" {
+		return violation(PolicyRuleRequirePin, "git sources must be pinned with #ref")
+	}
+	return nil
+}
+
+func (p TrustPolicy) allowsHostOwner(host, owner string) bool {
+	for _, allowed := range p.AllowedHosts {
+		if hostMatches(allowed, host) {
+			return true
+		}
+	}
+	for _, allowed := range p.AllowedOwners {
+		allowedHost, allowedOwner, ok := strings.Cut(allowed, "/")
+		if !ok {
+			allowedHost, allowedOwner = "github.com", allowed
+		}
+		if allowedOwner == owner && hostMatches(allowedHost, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSignature 检查签名要求，只接受受信任公钥的有效签名
+func (p TrustPolicy) checkSignature(source, skillName string, sig *SkillSignature) *TrustPolicyViolationError {
+	if !p.RequireSignature || sig.Status == SignatureStatusVerified {
+		return nil
+	}
+	reason := "skill is not signed"
+	switch sig.Status {
+	case SignatureStatusUntrusted:
+		reason = fmt.Sprintf("skill is signed by an untrusted key (%s)", sig.KeyID)
+	case SignatureStatusInvalid:
+		reason = "invalid signature: " + sig.Error
+	}
+	return &TrustPolicyViolationError{
+		Skill:  source + "@" + skillName,
+		Rule:   PolicyRuleRequireSignature,
+		Reason: reason,
+	}
+}
+
+// enforceTrustPolicy 安装或更新前检查来源，违反策略时写入活动日志
+func (ss *SkillsService) enforceTrustPolicy(source, skillName string, origin SkillSource) error {
+	policy, _, err := loadTrustPolicy(ss.env)
+	if err != nil {
+		return err
+	}
+	if violation := policy.checkSource(source, skillName, origin); violation != nil {
+		ss.auditPolicyViolation(skillName, violation)
+		return violation
+	}
+	return nil
+}
+
+// enforceSignaturePolicy 获取来源内容后校验签名并检查签名要求，返回的校验结果记录到 .skills-lock
+func (ss *SkillsService) enforceSignaturePolicy(source, skillName, skillDir string) (*SkillSignature, error) {
+	policy, _, err := loadTrustPolicy(ss.env)
+	if err != nil {
+		return nil, err
+	}
+	sig := verifySkillSignature(ss.env, skillDir)
+	if violation := policy.checkSignature(source, skillName, sig); violation != nil {
+		ss.auditPolicyViolation(skillName, violation)
+		return nil, violation
+	}
+	return sig, nil
+}
+
+func (ss *SkillsService) auditPolicyViolation(skillName string, violation *TrustPolicyViolationError) {
+	detail := fmt.Sprintf("blocked %s: %s (%s)", violation.Skill, violation.Reason, violation.Rule)
+	if err := ss.AddActivityLog(activityActionPolicy, skillName, detail); err != nil {
+		fmt.Fprintf(os.Stderr, "[TrustPolicy] warning: failed to record activity: %v\n", err)
+	}
+}
+
+// GetTrustPolicy 获取当前信任策略
+func (ss *SkillsService) GetTrustPolicy() (*TrustPolicyInfo, error) {
+	filePath, err := getTrustPolicyFilePath(ss.env)
+	if err != nil {
+		return nil, err
+	}
+	policy, exists, err := loadTrustPolicy(ss.env)
+	if err != nil {
+		return nil, err
+	}
+	return &TrustPolicyInfo{Path: filePath, Exists: exists, Policy: policy}, nil
+}
+
+// SaveTrustPolicy 校验并保存信任策略
+func (ss *SkillsService) SaveTrustPolicy(policy TrustPolicy) error {
+	if err := policy.normalize(); err != nil {
+		return err
+	}
+	filePath, err := getTrustPolicyFilePath(ss.env)
+	if err != nil {
+		return err
+	}
+	return withFileLock(filePath, func() error {
+		return writeJSONFile(filePath, policy)
+	})
+}
+
+// CheckTrustPolicy 检查 source@skill[#ref] 是否允许安装（只检查来源规则，签名要求在安装时校验）
+func (ss *SkillsService) CheckTrustPolicy(fullName string) error {
+	source, skillName, ref, err := parseSkillFullName(fullName)
+	if err != nil {
+		return err
+	}
+	origin, source, err := ss.resolveSkillSource(source, ref)
+	if err != nil {
+		return err
+	}
+	policy, _, err := loadTrustPolicy(ss.env)
+	if err != nil {
+		return err
+	}
+	if violation := policy.checkSource(source, skillName, origin); violation != nil {
+		return violation
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import "testing"
+
+func TestTrustPolicyCheckSource(t *testing.T) {
+	local := SkillSource{Type: SourceTypeLocal, LocalPath: "/tmp/skills"}
+	gitlab := SkillSource{Type: SourceTypeGit, URL: "https://gitlab.example.com/team/skills.git"}
+	scp := SkillSource{Type: SourceTypeGit, URL: "git@gitlab.example.com:team/skills.git"}
+	archive := SkillSource{Type: SourceTypeArchive, URL: "https://skills.example.com/pdf.tar.gz"}
+
+	tests := []struct {
+		name   string
+		policy TrustPolicy
+		source string
+		skill  string
+		origin SkillSource
+		rule   string // 期望违反的规则，为空表示允许
+	}{
+		{name: "empty policy", source: "acme/skills", skill: "pdf", origin: githubSource("acme/skills", "", "")},
+		{
+			name:   "allowed github owner",
+			policy: TrustPolicy{AllowedOwners: []string{"Acme"}},
+			source: "acme/skills", skill: "pdf", origin: githubSource("acme/skills", "", ""),
+		},
+		{
+			name:   "github owner not allowed",
+			policy: TrustPolicy{AllowedOwners: []string{"acme"}},
+			source: "evil/skills", skill: "pdf", origin: githubSource("evil/skills", "", ""),
+			rule: PolicyRuleAllowedSources,
+		},
+		{
+			name:   "bare owner only matches github.com",
+			policy: TrustPolicy{AllowedOwners: []string{"team"}},
+			source: gitlab.URL, skill: "pdf", origin: gitlab,
+			rule: PolicyRuleAllowedSources,
+		},
+		{
+			name:   "host/owner",
+			policy: TrustPolicy{AllowedOwners: []string{"https://gitlab.example.com/team/"}},
+			source: gitlab.URL, skill: "pdf", origin: gitlab,
+		},
+		{
+			name:   "host/owner with scp-like url",
+			policy: TrustPolicy{AllowedOwners: []string{"gitlab.example.com/team"}},
+			source: scp.URL, skill: "pdf", origin: scp,
+		},
+		{
+			name:   "allowed host",
+			policy: TrustPolicy{AllowedHosts: []string{"https://skills.example.com"}},
+			source: archive.URL, skill: "pdf", origin: archive,
+		},
+		{
+			name:   "local source not allowed",
+			policy: TrustPolicy{AllowedOwners: []string{"acme"}},
+			source: local.LocalPath, skill: "pdf", origin: local,
+			rule: PolicyRuleAllowedSources,
+		},
+		{
+			name:   "local source allowed",
+			policy: TrustPolicy{AllowedOwners: []string{"acme"}, AllowLocal: true},
+			source: local.LocalPath, skill: "pdf", origin: local,
+		},
+		{
+			name:   "blocked skill name pattern",
+			policy: TrustPolicy{BlockedSkills: []string{"*-experimental"}},
+			source: "acme/skills", skill: "pdf-experimental", origin: githubSource("acme/skills", "", ""),
+			rule: PolicyRuleBlockedSkill,
+		},
+		{
+			name:   "blocked full name",
+			policy: TrustPolicy{BlockedSkills: []string{"acme/skills@pdf"}},
+			source: "acme/skills", skill: "pdf", origin: githubSource("acme/skills", "", ""),
+			rule: PolicyRuleBlockedSkill,
+		},
+		{
+			name:   "blocked full name does not match other sources",
+			policy: TrustPolicy{BlockedSkills: []string{"acme/skills@pdf"}},
+			source: "other/skills", skill: "pdf", origin: githubSource("other/skills", "", ""),
+		},
+		{
+			name:   "unpinned git source",
+			policy: TrustPolicy{RequirePin: true},
+			source: "acme/skills", skill: "pdf", origin: githubSource("acme/skills", "", ""),
+			rule: PolicyRuleRequirePin,
+		},
+		{
+			name:   "pinned git source",
+			policy: TrustPolicy{RequirePin: true},
+			source: "acme/skills", skill: "pdf", origin: githubSource("acme/skills", "v1.0.0", ""),
+		},
+		{
+			name:   "pin not required for archives",
+			policy: TrustPolicy{RequirePin: true},
+			source: archive.URL, skill: "pdf", origin: archive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := tt.policy
+			if err := policy.normalize(); err != nil {
+				t.Fatalf("normalize: %v", err)
+			}
+			violation := policy.checkSource(tt.source, tt.skill, tt.origin)
+			switch {
+			case tt.rule == "" && violation != nil:
+				t.Errorf("checkSource() = %v, want allowed", violation)
+			case tt.rule != "" && violation == nil:
+				t.Errorf("checkSource() allowed, want %s violation", tt.rule)
+			case tt.rule != "" && violation.Rule != tt.rule:
+				t.Errorf("checkSource() rule = %s, want %s", violation.Rule, tt.rule)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+import { useTranslation } from "react-i18next"
+import { Button } from "@/components/ui/button"
+import {
+  Dialog,
+  DialogContent,
+  DialogDescription,
+  DialogFooter,
+  DialogHeader,
+  DialogTitle,
+} from "@/components/ui/dialog"
+import { services } from "@wailsjs/go/models"
+
+interface BatchResultDialogProps {
+  open: boolean
+  onOpenChange: (open: boolean) => void
+  title: string
+  result: services.BatchResult | null
+}
+
+const statusClass: Record<string, string> = {
+  succeeded: "text-emerald-600 dark:text-emerald-400",
+  failed: "text-destructive",
+  "rolled-back": "text-amber-600 dark:text-amber-400",
+  skipped: "text-muted-foreground",
+}
+
+// 批量操作存在失败项时展示每一项的状态与错误
+const BatchResultDialog = ({ open, onOpenChange, title, result }: BatchResultDialogProps) => {
+  const { t } = useTranslation()
+
+  return (
+    <Dialog open={open} onOpenChange={onOpenChange}>
+      <DialogContent className="max-w-2xl">
+        <DialogHeader>
+          <DialogTitle>{title}</DialogTitle>
+          <DialogDescription>
+            {result?.rolledBack
+              ? t("batch-result-rolled-back", { failed: result.failed, total: result.items?.length || 0 })
+              : t("batch-result-desc", { succeeded: result?.succeeded || 0, failed: result?.failed || 0 })}
+          </DialogDescription>
+        </DialogHeader>
+        <div className="rounded-lg border border-border/50 bg-muted/30 p-3 max-h-80 overflow-y-auto space-y-1.5 text-[12px]">
+          {(result?.items || []).map((item) => (
+            <div key={item.name}>
+              <div className="flex items-baseline gap-2">
+                <span className={`w-20 shrink-0 font-medium ${statusClass[item.status] || ""}`}>{t(`batch-status-${item.status}`)}</span>
+                <span className="font-mono text-[11px] truncate">{item.name}</span>
+              </div>
+              {item.error && (
+                <div className="ml-[5.5rem] text-[11px] text-muted-foreground whitespace-pre-wrap break-all">{item.error}</div>
+              )}
+            </div>
+          ))}
+        </div>
+        <DialogFooter>
+          <Button size="sm" onClick={() => onOpenChange(false)}>
+            {t("confirm")}
+          </Button>
+        </DialogFooter>
+      </DialogContent>
+    </Dialog>
+  )
+}
+
+export default BatchResultDialog
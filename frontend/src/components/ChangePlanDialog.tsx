@@ -0,0 +1,148 @@
+import { useTranslation } from "react-i18next"
+import { Button } from "@/components/ui/button"
+import {
+  Dialog,
+  DialogContent,
+  DialogDescription,
+  DialogFooter,
+  DialogHeader,
+  DialogTitle,
+} from "@/components/ui/dialog"
+import { services } from "@wailsjs/go/models"
+
+interface ChangePlanDialogProps {
+  open: boolean
+  onOpenChange: (open: boolean) => void
+  title: string
+  description?: string
+  plan: services.ChangePlan | null
+  confirmLabel: string
+  destructive?: boolean
+  busy?: boolean
+  onConfirm: () => void
+}
+
+const actionClass: Record<string, string> = {
+  create: "text-emerald-600 dark:text-emerald-400",
+  add: "text-emerald-600 dark:text-emerald-400",
+  modify: "text-amber-600 dark:text-amber-400",
+  update: "text-amber-600 dark:text-amber-400",
+  remove: "text-destructive",
+}
+
+const shortSHA = (sha?: string) => (sha ? sha.slice(0, 7) : "-")
+
+// 执行前展示操作的变更计划（文件、软链接、.skills-lock），由用户确认后再执行
+const ChangePlanDialog = ({
+  open,
+  onOpenChange,
+  title,
+  description,
+  plan,
+  confirmLabel,
+  destructive,
+  busy,
+  onConfirm,
+}: ChangePlanDialogProps) => {
+  const { t } = useTranslation()
+  const files = plan?.files || []
+  const links = plan?.links || []
+  const lock = plan?.lock || []
+  const customAgents = plan?.customAgents || []
+  const warnings = plan?.warnings || []
+  const empty = files.length + links.length + lock.length + customAgents.length === 0
+
+  const Action = ({ action }: { action: string }) => (
+    <span className={`w-14 shrink-0 font-medium ${actionClass[action] || ""}`}>{t(`plan-action-${action}`)}</span>
+  )
+
+  return (
+    <Dialog open={open} onOpenChange={onOpenChange}>
+      <DialogContent className="max-w-2xl">
+        <DialogHeader>
+          <DialogTitle>{title}</DialogTitle>
+          <DialogDescription>
+            {description || t("plan-desc", { files: files.length, links: links.length, lock: lock.length })}
+          </DialogDescription>
+        </DialogHeader>
+        <div className="rounded-lg border border-border/50 bg-muted/30 p-3 max-h-80 overflow-y-auto space-y-3 text-[12px]">
+          {empty && <p className="text-muted-foreground">{t("plan-no-changes")}</p>}
+          {files.length > 0 && (
+            <div className="space-y-1">
+              <div className="text-[11px] font-semibold text-muted-foreground">{t("plan-files")}</div>
+              {files.map((f, i) => (
+                <div key={i} className="flex items-baseline gap-2">
+                  <Action action={f.action} />
+                  <span className="font-mono text-[11px] truncate">{f.path}</span>
+                </div>
+              ))}
+            </div>
+          )}
+          {links.length > 0 && (
+            <div className="space-y-1">
+              <div className="text-[11px] font-semibold text-muted-foreground">{t("plan-links")}</div>
+              {links.map((l, i) => (
+                <div key={i} className="flex items-baseline gap-2">
+                  <Action action={l.action} />
+                  <span className="w-28 shrink-0 truncate">{l.agent}</span>
+                  <span className="font-mono text-[11px] truncate">{l.path}</span>
+                </div>
+              ))}
+            </div>
+          )}
+          {lock.length > 0 && (
+            <div className="space-y-1">
+              <div className="text-[11px] font-semibold text-muted-foreground">{t("plan-lock")}</div>
+              {lock.map((l, i) => (
+                <div key={i} className="flex items-baseline gap-2">
+                  <Action action={l.action} />
+                  <span className="font-medium">{l.skill}</span>
+                  <span className="text-muted-foreground">{l.source}</span>
+                  {l.oldRef !== l.newRef && (
+                    <span className="font-mono text-[11px] text-muted-foreground">{l.oldRef || "-"} → {l.newRef || "-"}</span>
+                  )}
+                  {l.oldCommit !== l.newCommit && (
+                    <span className="font-mono text-[11px] text-muted-foreground">{shortSHA(l.oldCommit)} → {shortSHA(l.newCommit)}</span>
+                  )}
+                </div>
+              ))}
+            </div>
+          )}
+          {customAgents.length > 0 && (
+            <div className="space-y-1">
+              <div className="text-[11px] font-semibold text-muted-foreground">{t("plan-custom-agents")}</div>
+              {customAgents.map((name) => (
+                <div key={name} className="flex items-baseline gap-2">
+                  <Action action="add" />
+                  <span>{name}</span>
+                </div>
+              ))}
+            </div>
+          )}
+        </div>
+        {warnings.length > 0 && (
+          <div className="space-y-1 text-[11px] text-amber-600 dark:text-amber-400">
+            {warnings.map((w, i) => (
+              <p key={i}>{w}</p>
+            ))}
+          </div>
+        )}
+        <DialogFooter>
+          <Button variant="outline" size="sm" onClick={() => onOpenChange(false)} disabled={busy}>
+            {t("cancel")}
+          </Button>
+          <Button
+            size="sm"
+            variant={destructive ? "destructive" : "default"}
+            onClick={onConfirm}
+            disabled={busy || empty}
+          >
+            {confirmLabel}
+          </Button>
+        </DialogFooter>
+      </DialogContent>
+    </Dialog>
+  )
+}
+
+export default ChangePlanDialog
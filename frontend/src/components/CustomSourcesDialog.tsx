@@ -12,8 +12,13 @@ import {
   DialogTitle,
 } from "@/components/ui/dialog"
 import { Badge } from "@/components/ui/badge"
-import { RefreshIcon, Add01Icon, Delete02Icon, Globe02Icon } from "hugeicons-react"
-import { GetCustomSources, AddCustomSource, RemoveCustomSource } from "@wailsjs/go/services/SkillsService"
+import { RefreshIcon, Add01Icon, Delete02Icon, Globe02Icon, GitBranchIcon, ArrowUp01Icon, ArrowDown01Icon, Search01Icon } from "hugeicons-react"
+import { Switch } from "@/components/ui/switch"
+import {
+  GetCustomSources, AddCustomSource, RemoveCustomSource, GetHostCredentials, SetHostCredential, RemoveHostCredential,
+  GetRegistries, AddRegistry, RemoveRegistry, SetRegistryEnabled, MoveRegistry,
+} from "@wailsjs/go/services/SkillsService"
+import { services } from "@wailsjs/go/models"
 import { toast } from "@/components/ui/use-toast"
 
 interface CustomSourcesDialogProps {
@@ -36,9 +41,24 @@ const CustomSourcesDialog = ({ open, onOpenChange }: CustomSourcesDialogProps) =
   const [url, setUrl] = useState("")
   const [token, setToken] = useState("")
   const [adding, setAdding] = useState(false)
+  const [credentials, setCredentials] = useState<services.HostCredential[]>([])
+  const [showAddCredential, setShowAddCredential] = useState(false)
+  const [credHost, setCredHost] = useState("")
+  const [credUsername, setCredUsername] = useState("")
+  const [credToken, setCredToken] = useState("")
+  const [credSSHKey, setCredSSHKey] = useState("")
+  const [savingCredential, setSavingCredential] = useState(false)
+  const [registries, setRegistries] = useState<services.RegistryConfig[]>([])
+  const [showAddRegistry, setShowAddRegistry] = useState(false)
+  const [registryName, setRegistryName] = useState("")
+  const [registryUrl, setRegistryUrl] = useState("")
 
   useEffect(() => {
-    if (open) loadSources()
+    if (open) {
+      loadSources()
+      loadCredentials()
+      loadRegistries()
+    }
   }, [open])
 
   const loadSources = async () => {
@@ -68,6 +88,78 @@ const CustomSourcesDialog = ({ open, onOpenChange }: CustomSourcesDialogProps) =
     }
   }
 
+  const loadCredentials = async () => {
+    try {
+      const result = await GetHostCredentials()
+      setCredentials(result || [])
+    } catch {}
+  }
+
+  const handleSaveCredential = async () => {
+    if (!credHost.trim() || (!credToken.trim() && !credSSHKey.trim())) return
+    setSavingCredential(true)
+    try {
+      await SetHostCredential(new services.HostCredential({
+        host: credHost.trim(),
+        username: credUsername.trim(),
+        token: credToken.trim(),
+        sshKeyPath: credSSHKey.trim(),
+      }))
+      toast({ title: t("toast-credential-saved", { host: credHost.trim() }), variant: "success" })
+      setCredHost("")
+      setCredUsername("")
+      setCredToken("")
+      setCredSSHKey("")
+      setShowAddCredential(false)
+      await loadCredentials()
+    } catch (error) {
+      toast({ title: t("toast-credential-save-failed", { error }), variant: "destructive" })
+    } finally {
+      setSavingCredential(false)
+    }
+  }
+
+  const handleRemoveCredential = async (host: string) => {
+    try {
+      await RemoveHostCredential(host)
+      toast({ title: t("toast-credential-removed", { host }), variant: "success" })
+      await loadCredentials()
+    } catch (error) {
+      toast({ title: t("toast-credential-remove-failed", { error }), variant: "destructive" })
+    }
+  }
+
+  const loadRegistries = async () => {
+    try {
+      const result = await GetRegistries()
+      setRegistries(result || [])
+    } catch {}
+  }
+
+  const handleAddRegistry = async () => {
+    if (!registryName.trim() || !registryUrl.trim()) return
+    try {
+      await AddRegistry(registryName.trim(), registryUrl.trim())
+      toast({ title: t("toast-registry-added", { name: registryName.trim() }), variant: "success" })
+      setRegistryName("")
+      setRegistryUrl("")
+      setShowAddRegistry(false)
+      await loadRegistries()
+    } catch (error) {
+      toast({ title: t("toast-registry-failed", { error }), variant: "destructive" })
+    }
+  }
+
+  // 启用 / 停用、排序与删除共用同一套错误处理
+  const updateRegistry = async (action: () => Promise<void>) => {
+    try {
+      await action()
+      await loadRegistries()
+    } catch (error) {
+      toast({ title: t("toast-registry-failed", { error }), variant: "destructive" })
+    }
+  }
+
   const handleRemove = async (sourceName: string) => {
     try {
       await RemoveCustomSource(sourceName)
@@ -80,7 +172,7 @@ const CustomSourcesDialog = ({ open, onOpenChange }: CustomSourcesDialogProps) =
 
   return (
     <Dialog open={open} onOpenChange={onOpenChange}>
-      <DialogContent className="max-w-lg">
+      <DialogContent className="max-w-lg max-h-[85vh] overflow-y-auto">
         <DialogHeader>
           <DialogTitle>{t("custom-sources")}</DialogTitle>
           <DialogDescription>{t("custom-sources-desc")}</DialogDescription>
@@ -134,6 +226,127 @@ const CustomSourcesDialog = ({ open, onOpenChange }: CustomSourcesDialogProps) =
             </div>
           )}
         </div>
+
+        <div className="space-y-3 border-t border-border/50 pt-4">
+          <div className="flex items-start justify-between gap-3">
+            <div className="space-y-0.5">
+              <p className="text-[13px] font-medium">{t("registries")}</p>
+              <p className="text-[11px] text-muted-foreground">{t("registries-desc")}</p>
+            </div>
+            {!showAddRegistry && (
+              <Button size="sm" variant="outline" className="shrink-0" onClick={() => setShowAddRegistry(true)}>
+                <Add01Icon size={14} className="mr-1.5" />
+                {t("add-registry")}
+              </Button>
+            )}
+          </div>
+          {registries.map((reg, index) => (
+            <div key={reg.name} className="flex items-center justify-between gap-2 rounded-lg border border-border/50 p-3">
+              <div className="flex items-center gap-2.5 min-w-0 flex-1">
+                <Search01Icon size={16} className="text-primary shrink-0" />
+                <div className="min-w-0 flex-1">
+                  <p className="text-[12.5px] font-medium truncate">{reg.name}</p>
+                  <p className="text-[10.5px] text-muted-foreground truncate">{reg.url}</p>
+                </div>
+                <Badge variant="outline" className="text-[9px] shrink-0">{reg.type}</Badge>
+              </div>
+              <div className="flex items-center gap-0.5 shrink-0">
+                <Button variant="ghost" size="icon" className="h-7 w-7 text-muted-foreground" disabled={index === 0} onClick={() => updateRegistry(() => MoveRegistry(reg.name, index - 1))}>
+                  <ArrowUp01Icon size={14} />
+                </Button>
+                <Button variant="ghost" size="icon" className="h-7 w-7 text-muted-foreground" disabled={index === registries.length - 1} onClick={() => updateRegistry(() => MoveRegistry(reg.name, index + 1))}>
+                  <ArrowDown01Icon size={14} />
+                </Button>
+                <Switch className="mx-1.5" checked={reg.enabled} onCheckedChange={(checked) => updateRegistry(() => SetRegistryEnabled(reg.name, checked))} />
+                <Button variant="ghost" size="icon" className="h-7 w-7 text-muted-foreground hover:text-destructive" onClick={() => updateRegistry(() => RemoveRegistry(reg.name))}>
+                  <Delete02Icon size={14} />
+                </Button>
+              </div>
+            </div>
+          ))}
+
+          {showAddRegistry && (
+            <div className="rounded-lg border border-primary/30 bg-primary/5 p-3 space-y-3">
+              <div className="space-y-1.5">
+                <Label className="text-[11px]">{t("source-name")}</Label>
+                <Input className="h-8 text-xs" placeholder={t("registry-name-placeholder")} value={registryName} onChange={(e) => setRegistryName(e.target.value)} />
+              </div>
+              <div className="space-y-1.5">
+                <Label className="text-[11px]">{t("source-url")}</Label>
+                <Input className="h-8 text-xs" placeholder={t("registry-url-placeholder")} value={registryUrl} onChange={(e) => setRegistryUrl(e.target.value)} />
+              </div>
+              <div className="flex justify-end gap-2">
+                <Button size="sm" variant="outline" onClick={() => setShowAddRegistry(false)}>{t("cancel")}</Button>
+                <Button size="sm" onClick={handleAddRegistry} disabled={!registryName.trim() || !registryUrl.trim()}>
+                  {t("add-registry")}
+                </Button>
+              </div>
+            </div>
+          )}
+        </div>
+
+        <div className="space-y-3 border-t border-border/50 pt-4">
+          <div className="flex items-start justify-between gap-3">
+            <div className="space-y-0.5">
+              <p className="text-[13px] font-medium">{t("host-credentials")}</p>
+              <p className="text-[11px] text-muted-foreground">{t("host-credentials-desc")}</p>
+            </div>
+            {!showAddCredential && (
+              <Button size="sm" variant="outline" className="shrink-0" onClick={() => setShowAddCredential(true)}>
+                <Add01Icon size={14} className="mr-1.5" />
+                {t("add-credential")}
+              </Button>
+            )}
+          </div>
+          {credentials.length === 0 && !showAddCredential ? (
+            <div className="text-center py-4 text-[12px] text-muted-foreground">{t("no-host-credentials")}</div>
+          ) : (
+            credentials.map((cred) => (
+              <div key={cred.host} className="flex items-center justify-between rounded-lg border border-border/50 p-3">
+                <div className="flex items-center gap-2.5 min-w-0 flex-1">
+                  <GitBranchIcon size={16} className="text-primary shrink-0" />
+                  <div className="min-w-0 flex-1">
+                    <p className="text-[12.5px] font-medium truncate">{cred.host}</p>
+                    {cred.sshKeyPath && <p className="text-[10.5px] text-muted-foreground truncate">{cred.sshKeyPath}</p>}
+                  </div>
+                  {cred.token && <Badge variant="outline" className="text-[9px] shrink-0">Token</Badge>}
+                  {cred.sshKeyPath && <Badge variant="outline" className="text-[9px] shrink-0">SSH</Badge>}
+                </div>
+                <Button variant="ghost" size="icon" className="h-7 w-7 text-muted-foreground hover:text-destructive shrink-0" onClick={() => handleRemoveCredential(cred.host)}>
+                  <Delete02Icon size={14} />
+                </Button>
+              </div>
+            ))
+          )}
+
+          {showAddCredential && (
+            <div className="rounded-lg border border-primary/30 bg-primary/5 p-3 space-y-3">
+              <div className="space-y-1.5">
+                <Label className="text-[11px]">{t("credential-host")}</Label>
+                <Input className="h-8 text-xs" placeholder={t("credential-host-placeholder")} value={credHost} onChange={(e) => setCredHost(e.target.value)} />
+              </div>
+              <div className="space-y-1.5">
+                <Label className="text-[11px]">{t("source-token")}</Label>
+                <Input className="h-8 text-xs" type="password" placeholder={t("source-token-placeholder")} value={credToken} onChange={(e) => setCredToken(e.target.value)} />
+              </div>
+              <div className="space-y-1.5">
+                <Label className="text-[11px]">{t("credential-username")}</Label>
+                <Input className="h-8 text-xs" placeholder={t("credential-username-placeholder")} value={credUsername} onChange={(e) => setCredUsername(e.target.value)} />
+              </div>
+              <div className="space-y-1.5">
+                <Label className="text-[11px]">{t("credential-ssh-key")}</Label>
+                <Input className="h-8 text-xs" placeholder={t("credential-ssh-key-placeholder")} value={credSSHKey} onChange={(e) => setCredSSHKey(e.target.value)} />
+              </div>
+              <div className="flex justify-end gap-2">
+                <Button size="sm" variant="outline" onClick={() => setShowAddCredential(false)}>{t("cancel")}</Button>
+                <Button size="sm" onClick={handleSaveCredential} disabled={!credHost.trim() || (!credToken.trim() && !credSSHKey.trim()) || savingCredential}>
+                  {savingCredential ? <RefreshIcon size={12} className="mr-1 animate-spin" /> : null}
+                  {t("add-credential")}
+                </Button>
+              </div>
+            </div>
+          )}
+        </div>
         {!showAdd && (
           <DialogFooter>
             <Button variant="outline" onClick={() => setShowAdd(true)}>
@@ -17,6 +17,7 @@ import {
   Folder01Icon,
   File01Icon,
   RepairIcon,
+  Edit02Icon,
 } from "hugeicons-react"
 import { HealthCheck, RepairBrokenLinks } from "@wailsjs/go/services/SkillsService"
 import { toast } from "@/components/ui/use-toast"
@@ -32,6 +33,7 @@ interface HealthResult {
   unknownFiles: Array<{ agentName: string; fileName: string; filePath: string }>
   totalLinks: number
   healthyLinks: number
+  modifiedSkills: Array<{ name: string; status: string; modifiedFiles: string[]; missingFiles: string[]; extraFiles: string[] }>
 }
 
 const HealthCheckDialog = ({ open, onOpenChange }: HealthCheckDialogProps) => {
@@ -73,7 +75,7 @@ const HealthCheckDialog = ({ open, onOpenChange }: HealthCheckDialogProps) => {
     }
   }
 
-  const hasIssues = result && ((result.brokenLinks?.length || 0) > 0 || (result.orphanSkills?.length || 0) > 0 || (result.unknownFiles?.length || 0) > 0)
+  const hasIssues = result && ((result.brokenLinks?.length || 0) > 0 || (result.orphanSkills?.length || 0) > 0 || (result.unknownFiles?.length || 0) > 0 || (result.modifiedSkills?.length || 0) > 0)
 
   return (
     <Dialog open={open} onOpenChange={onOpenChange}>
@@ -164,6 +166,33 @@ const HealthCheckDialog = ({ open, onOpenChange }: HealthCheckDialogProps) => {
                   </div>
                 )}
 
+                {/* Modified Skills */}
+                {result.modifiedSkills && result.modifiedSkills.length > 0 && (
+                  <div className="space-y-2">
+                    <div className="flex items-center gap-2">
+                      <Edit02Icon size={14} className="text-amber-500" />
+                      <h4 className="text-[12px] font-medium">{t("modified-skills")}</h4>
+                      <Badge variant="outline" className="text-[10px] border-amber-500/30 text-amber-500">{result.modifiedSkills.length}</Badge>
+                    </div>
+                    <div className="space-y-1">
+                      {result.modifiedSkills.map((skill) => (
+                        <div key={skill.name} className="rounded border border-amber-500/20 bg-amber-500/5 p-2.5 text-xs">
+                          <span className="font-mono font-medium">{skill.name}</span>
+                          {skill.status === "missing" ? (
+                            <p className="text-muted-foreground mt-0.5">{t("skill-missing")}</p>
+                          ) : (
+                            <div className="mt-0.5 font-mono text-[11px] text-muted-foreground">
+                              {skill.modifiedFiles?.map((f) => <p key={`m-${f}`} className="truncate">M {f}</p>)}
+                              {skill.missingFiles?.map((f) => <p key={`d-${f}`} className="truncate">D {f}</p>)}
+                              {skill.extraFiles?.map((f) => <p key={`a-${f}`} className="truncate">A {f}</p>)}
+                            </div>
+                          )}
+                        </div>
+                      ))}
+                    </div>
+                  </div>
+                )}
+
                 {/* Unknown Files */}
                 {result.unknownFiles && result.unknownFiles.length > 0 && (
                   <div className="space-y-2">
@@ -0,0 +1,78 @@
+import { useTranslation } from "react-i18next"
+import { Button } from "@/components/ui/button"
+import {
+  Dialog,
+  DialogContent,
+  DialogDescription,
+  DialogFooter,
+  DialogHeader,
+  DialogTitle,
+} from "@/components/ui/dialog"
+import { services } from "@wailsjs/go/models"
+
+export type LocalChangesChoice = "force" | "merge" | "accept"
+
+interface LocalChangesDialogProps {
+  open: boolean
+  onOpenChange: (open: boolean) => void
+  changes: services.SkillVerifyResult | null
+  onChoose: (choice: LocalChangesChoice) => void
+}
+
+// 更新前检测到本地修改时，让用户选择覆盖或合并
+// 没有记录哈希（unverified）时无法合并，可选择覆盖，或确认当前内容未修改后正常更新
+const LocalChangesDialog = ({ open, onOpenChange, changes, onChoose }: LocalChangesDialogProps) => {
+  const { t } = useTranslation()
+  const unverified = changes?.status === "unverified"
+
+  const lines = changes
+    ? [
+        ...(changes.modifiedFiles || []).map((f) => ({ mark: "M", file: f })),
+        ...(changes.missingFiles || []).map((f) => ({ mark: "D", file: f })),
+        ...(changes.extraFiles || []).map((f) => ({ mark: "A", file: f })),
+      ]
+    : []
+
+  return (
+    <Dialog open={open} onOpenChange={onOpenChange}>
+      <DialogContent className="max-w-lg">
+        <DialogHeader>
+          <DialogTitle>{t(unverified ? "local-changes-unverified-title" : "local-changes-title")}</DialogTitle>
+          <DialogDescription>
+            {t(unverified ? "local-changes-unverified-desc" : "local-changes-desc", { name: changes?.name })}
+          </DialogDescription>
+        </DialogHeader>
+        {!unverified && (
+          <div className="rounded-lg border border-border/50 bg-muted/30 p-3 max-h-48 overflow-y-auto">
+            {lines.map(({ mark, file }) => (
+              <div key={`${mark}-${file}`} className="text-[12px] font-mono">
+                <span className="inline-block w-4 text-muted-foreground">{mark}</span>
+                {file}
+              </div>
+            ))}
+          </div>
+        )}
+        <p className="text-[11px] text-muted-foreground">{t(unverified ? "local-changes-unverified-hint" : "local-changes-hint")}</p>
+        <DialogFooter>
+          <Button variant="outline" size="sm" onClick={() => onOpenChange(false)}>
+            {t("cancel")}
+          </Button>
+          <Button variant="outline" size="sm" className="text-destructive hover:text-destructive" onClick={() => onChoose("force")}>
+            {t("local-changes-overwrite")}
+          </Button>
+          {unverified ? (
+            <Button size="sm" onClick={() => onChoose("accept")}>
+              {t("local-changes-accept")}
+            </Button>
+          ) : (
+            <Button size="sm" onClick={() => onChoose("merge")}>
+              {t("local-changes-merge")}
+            </Button>
+          )}
+        </DialogFooter>
+      </DialogContent>
+    </Dialog>
+  )
+}
+
+export default LocalChangesDialog
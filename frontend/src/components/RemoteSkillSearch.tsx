@@ -26,6 +26,12 @@ export interface RemoteSkill {
   installed: boolean
   installs: number
   supportedAgents: string[]
+  /** 结果来自本地镜像（离线模式或网络不可用） */
+  offline?: boolean
+  /** 来源，例如 owner/repo 或 git 地址 */
+  source?: string
+  /** 提供该结果的 registry */
+  registry?: string
 }
 
 interface RemoteSkillSearchProps {
@@ -108,6 +114,13 @@ const RemoteSkillSearch = ({
         </Button>
       </div>
 
+      {!searching && skills.some((s) => s.offline) && (
+        <div className={`flex items-center gap-1.5 text-[11px] text-amber-600 dark:text-amber-400 shrink-0 ${compact ? "pb-2" : "mb-3"}`}>
+          <InformationCircleIcon size={12} />
+          {t("offline-results-notice")}
+        </div>
+      )}
+
       {/* Results */}
       <div className={`flex-1 min-h-0 ${compact ? "overflow-y-auto pr-1" : ""}`}>
         {searching ? (
@@ -156,8 +169,11 @@ const RemoteSkillSearch = ({
                     <p className="text-sm font-medium truncate">{skill.name}</p>
                     <div className="flex items-center gap-2">
                       <p className="text-xs text-muted-foreground truncate">
-                        {skill.owner}/{skill.repo}
+                        {skill.source || `${skill.owner}/${skill.repo}`}
                       </p>
+                      {skill.registry && skill.registry !== "skills.sh" && (
+                        <Badge variant="outline" className="text-[9px] px-1.5 py-0 h-4 font-normal shrink-0">{skill.registry}</Badge>
+                      )}
                       {skill.installs > 0 && (
                         <span className="text-[10px] text-muted-foreground/70 flex items-center gap-0.5 shrink-0">
                           <Download01Icon size={10} />
@@ -221,7 +237,10 @@ const RemoteSkillSearch = ({
                         <div className="flex-1 min-w-0">
                           <CardTitle className="text-[13px] truncate">{skill.name}</CardTitle>
                           <div className="flex items-center gap-2 mt-0.5">
-                            <CardDescription className="text-[11px] truncate">{skill.owner}/{skill.repo}</CardDescription>
+                            <CardDescription className="text-[11px] truncate">{skill.source || `${skill.owner}/${skill.repo}`}</CardDescription>
+                            {skill.registry && skill.registry !== "skills.sh" && (
+                              <Badge variant="outline" className="text-[9px] px-1.5 py-0 h-4 font-normal shrink-0">{skill.registry}</Badge>
+                            )}
                             {skill.installs > 0 && (
                               <span className="text-[10px] text-muted-foreground/70 flex items-center gap-0.5 shrink-0">
                                 <Download01Icon size={10} />
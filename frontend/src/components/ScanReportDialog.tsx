@@ -0,0 +1,68 @@
+import { useTranslation } from "react-i18next"
+import { Button } from "@/components/ui/button"
+import {
+  Dialog,
+  DialogContent,
+  DialogDescription,
+  DialogFooter,
+  DialogHeader,
+  DialogTitle,
+} from "@/components/ui/dialog"
+import { services } from "@wailsjs/go/models"
+
+interface ScanReportDialogProps {
+  open: boolean
+  onOpenChange: (open: boolean) => void
+  report: services.SkillScanReport | null
+  onConfirm: () => void
+}
+
+const severityClass: Record<string, string> = {
+  critical: "text-destructive",
+  high: "text-destructive",
+  medium: "text-amber-600 dark:text-amber-400",
+  low: "text-muted-foreground",
+}
+
+// 安全扫描阻止安装时展示发现的问题，由用户决定是否仍然安装
+const ScanReportDialog = ({ open, onOpenChange, report, onConfirm }: ScanReportDialogProps) => {
+  const { t } = useTranslation()
+
+  return (
+    <Dialog open={open} onOpenChange={onOpenChange}>
+      <DialogContent className="max-w-2xl">
+        <DialogHeader>
+          <DialogTitle>{t("scan-blocked-title")}</DialogTitle>
+          <DialogDescription>
+            {t("scan-blocked-desc", { name: report?.skill, risk: t(`scan-risk-${report?.risk}`), threshold: t(`scan-risk-${report?.threshold}`) })}
+          </DialogDescription>
+        </DialogHeader>
+        <div className="rounded-lg border border-border/50 bg-muted/30 p-3 max-h-72 overflow-y-auto space-y-2">
+          {(report?.findings || []).map((f, i) => (
+            <div key={i} className="text-[12px]">
+              <div className="flex items-baseline gap-2">
+                <span className={`w-14 shrink-0 font-medium ${severityClass[f.severity] || ""}`}>{t(`scan-risk-${f.severity}`)}</span>
+                <span className="font-mono text-muted-foreground">{f.line ? `${f.file}:${f.line}` : f.file}</span>
+                <span>{f.message}</span>
+              </div>
+              {f.snippet && (
+                <div className="ml-16 font-mono text-[11px] text-muted-foreground truncate">{f.snippet}</div>
+              )}
+            </div>
+          ))}
+        </div>
+        <p className="text-[11px] text-muted-foreground">{t("scan-blocked-hint")}</p>
+        <DialogFooter>
+          <Button variant="outline" size="sm" onClick={() => onOpenChange(false)}>
+            {t("cancel")}
+          </Button>
+          <Button variant="outline" size="sm" className="text-destructive hover:text-destructive" onClick={onConfirm}>
+            {t("scan-install-anyway")}
+          </Button>
+        </DialogFooter>
+      </DialogContent>
+    </Dialog>
+  )
+}
+
+export default ScanReportDialog
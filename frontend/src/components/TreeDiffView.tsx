@@ -0,0 +1,107 @@
+import { useState } from "react"
+import { useTranslation } from "react-i18next"
+import { Badge } from "@/components/ui/badge"
+import { ArrowDown01Icon, ArrowRight01Icon } from "hugeicons-react"
+import { services } from "@wailsjs/go/models"
+
+interface TreeDiffViewProps {
+  files: services.FileDiff[]
+  additions?: number
+  deletions?: number
+}
+
+const statusStyles: Record<string, string> = {
+  added: "bg-emerald-500/10 text-emerald-600 dark:text-emerald-400 border-emerald-500/20",
+  removed: "bg-red-500/10 text-red-600 dark:text-red-400 border-red-500/20",
+  modified: "bg-amber-500/10 text-amber-600 dark:text-amber-400 border-amber-500/20",
+}
+
+// 目录级 unified diff：逐文件展示状态、增删行数与带行号的 hunk
+const TreeDiffView = ({ files, additions, deletions }: TreeDiffViewProps) => {
+  const { t } = useTranslation()
+  const [collapsed, setCollapsed] = useState<Set<string>>(new Set())
+
+  const toggle = (path: string) => {
+    setCollapsed(prev => {
+      const next = new Set(prev)
+      if (next.has(path)) next.delete(path)
+      else next.add(path)
+      return next
+    })
+  }
+
+  return (
+    <div className="flex-1 overflow-y-auto space-y-3">
+      <div className="text-[11px] text-muted-foreground">
+        {t("diff-summary", { count: files.length })}
+        {additions !== undefined && <span className="ml-2 text-emerald-600 dark:text-emerald-400">+{additions}</span>}
+        {deletions !== undefined && <span className="ml-1 text-red-600 dark:text-red-400">-{deletions}</span>}
+      </div>
+      {files.map((file) => {
+        const isCollapsed = collapsed.has(file.path)
+        return (
+          <div key={file.path} className="rounded border border-border/50 overflow-hidden">
+            <button
+              className="w-full flex items-center gap-2 px-3 py-1.5 bg-muted/30 border-b border-border/50 text-left"
+              onClick={() => toggle(file.path)}
+            >
+              {isCollapsed ? <ArrowRight01Icon size={12} /> : <ArrowDown01Icon size={12} />}
+              <span className="text-[12px] font-mono flex-1 truncate">{file.path}</span>
+              <Badge variant="outline" className={`text-[10px] ${statusStyles[file.status] || ""}`}>
+                {t(`diff-status-${file.status}`)}
+              </Badge>
+              {!file.binary && (
+                <span className="text-[11px] font-mono">
+                  <span className="text-emerald-600 dark:text-emerald-400">+{file.additions}</span>{" "}
+                  <span className="text-red-600 dark:text-red-400">-{file.deletions}</span>
+                </span>
+              )}
+            </button>
+            {!isCollapsed && (
+              file.binary ? (
+                <div className="px-3 py-2 text-[11px] text-muted-foreground">{t("diff-binary")}</div>
+              ) : (
+                <table className="w-full text-[11px] font-mono border-collapse">
+                  <tbody>
+                    {(file.hunks || []).map((hunk, hi) => (
+                      <HunkRows key={hi} hunk={hunk} />
+                    ))}
+                  </tbody>
+                </table>
+              )
+            )}
+          </div>
+        )
+      })}
+    </div>
+  )
+}
+
+const HunkRows = ({ hunk }: { hunk: services.DiffHunk }) => (
+  <>
+    <tr className="bg-blue-500/5">
+      <td colSpan={3} className="px-2 py-0.5 text-[10px] text-blue-600 dark:text-blue-400">{hunk.header}</td>
+    </tr>
+    {(hunk.lines || []).map((line, li) => (
+      <tr
+        key={li}
+        className={line.type === "add" ? "bg-emerald-500/10" : line.type === "delete" ? "bg-red-500/10" : ""}
+      >
+        <td className="select-none text-right px-1.5 py-0 text-muted-foreground/40 w-[1%] whitespace-nowrap text-[10px]">
+          {line.oldLine || ""}
+        </td>
+        <td className="select-none text-right px-1.5 py-0 text-muted-foreground/40 w-[1%] whitespace-nowrap text-[10px]">
+          {line.newLine || ""}
+        </td>
+        <td className="px-2 py-0 whitespace-pre-wrap break-all">
+          <span className="select-none text-muted-foreground/60 mr-1">
+            {line.type === "add" ? "+" : line.type === "delete" ? "-" : " "}
+          </span>
+          {line.content}
+        </td>
+      </tr>
+    ))}
+  </>
+)
+
+export default TreeDiffView
@@ -8,6 +8,7 @@ const en = {
     delete: "Delete",
     deleting: "Deleting...",
     update: "Update",
+    rollback: "Rollback",
     search: "Search",
     save: "Save",
     add: "Add",
@@ -91,11 +92,19 @@ const en = {
     "try-other-keywords": "Try other keywords, e.g. react, vue, python",
     "explore-remote": "Explore Remote Skills",
     "explore-remote-desc": "Enter keywords above to discover community skills",
+    "offline-results-notice": "Offline: results come from local mirrors",
     reinstalling: "Reinstalling...",
     reinstall: "Reinstall",
     "linked-agents-count": "Linked to {{count}} Agent(s): ",
     "linked-all-agents": "Linked to All Agents",
     "no-agent-linked": "No Agent linked, click to configure",
+    "manifest": "Manifest",
+    "manifest-invalid": "Invalid SKILL.md frontmatter",
+    "manifest-allowed-tools": "Allowed tools",
+    "manifest-dependencies": "Dependencies",
+    "manifest-optional": "optional",
+    "manifest-conflicts": "Conflicts",
+    "manifest-compatible-agents": "Compatible agents",
     "config-agent-link": "Configure Agent Links",
     "no-matching-agent": "No matching Agent found",
     "no-agents": "No Agents",
@@ -140,6 +149,20 @@ const en = {
     "toast-install-failed": "Install failed: {{error}}",
     "toast-skill-updated": "Skill \"{{name}}\" updated to latest version",
     "toast-update-failed": "Update failed: {{error}}",
+    "local-changes-title": "Local modifications detected",
+    "local-changes-desc": "Skill \"{{name}}\" has been modified since it was installed. Updating will overwrite:",
+    "local-changes-hint": "Merge keeps your changes: SKILL.md is merged three-way with upstream and conflicts are marked with <<<<<<< / >>>>>>>. Overwritten content is kept in the version history.",
+    "local-changes-overwrite": "Overwrite",
+    "local-changes-merge": "Merge",
+    "local-changes-unverified-title": "Local modifications unknown",
+    "local-changes-unverified-desc": "Skill \"{{name}}\" has no recorded content hash (installed by an older version), so local modifications cannot be detected.",
+    "local-changes-unverified-hint": "If you have not edited this skill, mark it as unmodified and update normally. Otherwise overwrite it; the current content is kept in the version history.",
+    "local-changes-accept": "Mark as unmodified",
+    "toast-skill-merged": "Skill \"{{name}}\" updated, local changes kept",
+    "toast-skill-merge-conflict": "Skill \"{{name}}\" merged with conflicts, resolve the markers in {{files}}",
+    "toast-batch-update-skipped-local": "Skipped {{count}} skill(s) with local modifications",
+    "toast-skill-rolled-back": "Skill \"{{name}}\" restored to the previous version",
+    "toast-rollback-failed": "Rollback failed: {{error}}",
     "toast-skill-deleted": "Skill \"{{name}}\" deleted successfully",
     "toast-delete-failed": "Delete failed: {{error}}",
     "toast-agent-added": "Custom Agent \"{{name}}\" added",
@@ -231,6 +254,7 @@ const en = {
     "toast-export-failed": "Failed to export config: {{error}}",
     "toast-import-success": "Import complete: installed {{installed}}, skipped {{skipped}}",
     "toast-import-failed": "Failed to import config: {{error}}",
+    "toast-import-policy-blocked": "{{count}} skill(s) blocked by the trust policy",
     "import-config-title": "Import Config",
     "import-config-desc": "Restore skills and Agent link configuration from exported JSON file",
     "import-file-hint": "Click to select or drag a JSON file here",
@@ -266,6 +290,8 @@ const en = {
     "broken-links": "Broken Links",
     "orphan-skills": "Orphan Skills",
     "unknown-files": "Unknown Files",
+    "modified-skills": "Modified Skills",
+    "skill-missing": "Directory missing",
     "healthy-links": "Healthy Links",
     "total-links-count": "Total Links",
     "no-issues": "All clear, no issues found",
@@ -329,13 +355,37 @@ const en = {
     "editor-framework": "Framework",
     "editor-lines": "{{count}} lines",
     "editor-cursor": "Ln {{line}}, Col {{col}}",
+    "lint-ok": "No problems",
+    "lint-summary": "{{errors}} errors, {{warnings}} warnings",
 
     // Diff Preview
     "diff-preview": "Diff Preview",
     "diff-preview-desc": "Compare local and latest remote version",
+    "version-history": "History",
+    "version-history-desc": "Snapshots saved automatically on every update, edit and import. Preview and restore any of them.",
+    "version-history-load-failed": "Failed to load version history: {{error}}",
+    "no-versions": "No versions yet",
+    "restore-version": "Restore this version",
+    "version-view-content": "Content",
+    "version-view-diff": "Diff vs current",
+    "version-same-as-current": "Identical to the current version",
+    "toast-version-restored": "Skill \"{{name}}\" restored to the selected version",
+    "toast-restore-version-failed": "Restore failed: {{error}}",
+    "version-reason-before-update": "Before update",
+    "version-reason-update": "Update",
+    "version-reason-before-save": "Before edit",
+    "version-reason-save": "Edit",
+    "version-reason-before-install": "Before reinstall",
+    "version-reason-install": "Install / import",
+    "version-reason-before-restore": "Before restore",
     "local-version": "Local Version",
     "remote-version": "Remote Version",
     "no-changes": "No changes, content is identical",
+    "diff-summary": "{{count}} file(s) changed",
+    "diff-status-added": "Added",
+    "diff-status-removed": "Removed",
+    "diff-status-modified": "Modified",
+    "diff-binary": "Binary file, content diff not shown",
     "loading-diff": "Fetching remote version...",
     "diff-load-failed": "Failed to load diff: {{error}}",
 
@@ -348,13 +398,34 @@ const en = {
     "source-url": "Git Repository URL",
     "source-url-placeholder": "https://github.com/team/skills.git",
     "source-token": "Access Token (optional)",
-    "source-token-placeholder": "GitHub PAT token",
+    "source-token-placeholder": "PAT / access token",
     "no-custom-sources": "No custom sources added yet",
     "browse-source": "Browse",
     "toast-source-added": "Source \"{{name}}\" added",
     "toast-source-add-failed": "Failed to add source: {{error}}",
     "toast-source-removed": "Source \"{{name}}\" removed",
     "toast-source-remove-failed": "Failed to remove source: {{error}}",
+    "host-credentials": "Host Credentials",
+    "host-credentials-desc": "Configure a token or SSH key for hosts serving private repositories; used for clones, updates and API requests",
+    "no-host-credentials": "No host credentials configured yet",
+    "add-credential": "Add Credential",
+    "credential-host": "Host",
+    "credential-host-placeholder": "gitlab.example.com",
+    "credential-username": "Username (optional)",
+    "credential-username-placeholder": "Defaults to x-access-token on GitHub, oauth2 elsewhere",
+    "credential-ssh-key": "SSH Key Path (optional)",
+    "credential-ssh-key-placeholder": "~/.ssh/id_ed25519",
+    "toast-credential-saved": "Saved credential for {{host}}",
+    "toast-credential-save-failed": "Failed to save credential: {{error}}",
+    "toast-credential-removed": "Removed credential for {{host}}",
+    "toast-credential-remove-failed": "Failed to remove credential: {{error}}",
+    "registries": "Registries",
+    "registries-desc": "Registries are searched in this order and results merged; duplicates from the same source keep the earliest entry",
+    "add-registry": "Add Registry",
+    "registry-name-placeholder": "e.g. team",
+    "registry-url-placeholder": "https://skills.example.com/v1",
+    "toast-registry-added": "Registry {{name}} added",
+    "toast-registry-failed": "Registry operation failed: {{error}}",
 
     // Project Wizard
     "project-wizard": "Project Wizard",
@@ -393,6 +464,53 @@ const en = {
     "auto-update-enabled": "Auto update enabled",
     "auto-update-disabled": "Auto update disabled",
     "update-interval": "Check interval (hours)",
+    "max-skill-versions": "Version history size",
+    "max-skill-versions-desc": "Number of versions kept per skill when it is updated, edited or imported",
+    "clone-cache": "Repository clone cache",
+    "clone-cache-desc": "Cached repositories reused by installs and updates, currently {{size}}; pruned automatically past the age or size limit",
+    "days-count": "{{count}} days",
+    "toast-clone-cache-cleared": "Clone cache cleared, freed {{size}}",
+    "toast-clone-cache-clear-failed": "Failed to clear clone cache: {{error}}",
+    "offline-mode": "Offline mode",
+    "offline-mode-desc": "Search, install and check updates from local mirrors only, without network access ({{count}} sources mirrored)",
+    "storage-backend": "Metadata Storage",
+    "storage-backend-desc": "Where tags, ratings, activity logs and metrics are stored. Existing JSON files are imported when switching to the database ({{count}} records)",
+    "storage-backend-json": "JSON files",
+    "storage-backend-bolt": "Embedded database",
+    "secret-backend": "Secret Storage",
+    "secret-backend-desc": "Provider API keys and access tokens are kept out of config files; currently stored in: {{backend}}",
+    "secret-backend-auto": "Auto",
+    "secret-backend-keyring": "OS keyring",
+    "secret-backend-vault": "Encrypted file",
+    "vault-passphrase": "Vault Passphrase",
+    "vault-keyfile-desc": "The encrypted file uses a local key file; with a passphrase it must be unlocked after each start",
+    "vault-passphrase-desc": "The encrypted file is passphrase protected; leave the new passphrase empty to switch back to the local key file",
+    "vault-locked-desc": "The encrypted file is locked; API keys and tokens are unavailable until it is unlocked",
+    "vault-current-passphrase": "Current passphrase",
+    "vault-new-passphrase": "New passphrase",
+    "vault-unlock": "Unlock",
+    "vault-set-passphrase": "Set passphrase",
+    "vault-remove-passphrase": "Remove passphrase",
+    "toast-vault-unlocked": "Vault unlocked",
+    "toast-vault-unlock-failed": "Failed to unlock: {{error}}",
+    "toast-vault-passphrase-set": "Vault passphrase set",
+    "toast-vault-passphrase-removed": "Passphrase removed, using the local key file",
+    "toast-vault-passphrase-failed": "Failed to set passphrase: {{error}}",
+    "trusted-keys": "Signing keys",
+    "trusted-keys-desc": "SKILL.sig signatures (minisign or ssh-keygen -Y sign) are verified against these keys on install and update; the trust policy can require a valid signature",
+    "trusted-key-name": "Name",
+    "trusted-key-placeholder": "minisign public key (RW...) or SSH public key (ssh-ed25519 AAAA...)",
+    "trusted-key-add": "Add",
+    "toast-trusted-key-added": "Signing key {{name}} added",
+    "toast-trusted-key-failed": "Signing key operation failed: {{error}}",
+    "signature-verified": "Signed · {{signer}}",
+    "signature-untrusted": "Signed by an untrusted key",
+    "signature-invalid": "Invalid signature",
+    "signature-unsigned": "Unsigned",
+    "sync-mirrors": "Sync mirrors",
+    "toast-mirror-synced": "Mirrored {{count}} sources locally",
+    "toast-mirror-sync-partial": "{{failed}} of {{total}} sources failed to sync",
+    "toast-mirror-sync-failed": "Failed to sync mirrors: {{error}}",
     "toast-auto-update-saved": "Auto update settings saved",
     "toast-auto-update-result": "Auto update complete: updated {{count}} skill(s)",
 
@@ -415,6 +533,7 @@ const en = {
     "activity-log": "Activity Log",
     "activity-log-desc": "View timeline of all operations",
     "no-activity": "No activity records yet",
+    "load-more": "Load more ({{loaded}} / {{total}})",
     "clear-logs": "Clear Logs",
     "action-install": "Install",
     "action-delete": "Delete",
@@ -424,6 +543,7 @@ const en = {
     "action-unlink": "Unlink",
     "action-import": "Import",
     "action-export": "Export",
+    "action-policy": "Policy",
     "toast-logs-cleared": "Activity logs cleared",
 
     // Skill Preview
@@ -469,6 +589,7 @@ const en = {
 
     // Clone Config
     "clone-config": "Clone Config",
+    "clone-plan-title": "Clone project config",
     "clone-config-desc": "Copy skill configuration from one project to another",
     "source-project": "Source Project",
     "target-project": "Target Project",
@@ -491,6 +612,42 @@ const en = {
     "skill-display": "Skill Display",
     "show-path-in-card": "Show path in card",
     "compact-mode": "Compact Mode",
+    "block-save-on-lint-errors": "Block saving on lint errors",
+    "block-save-on-lint-errors-desc": "Refuse to save, create, install or update a skill whose SKILL.md has errors (missing frontmatter, name not matching the directory, broken links, ...)",
+    "atomic-batch": "All-or-nothing batch operations",
+    "atomic-batch-desc": "If any item of a batch install, batch delete or collection install fails, undo the items already completed",
+    "batch-install-result": "Batch install result",
+    "batch-delete-result": "Batch delete result",
+    "batch-result-desc": "{{succeeded}} succeeded, {{failed}} failed",
+    "batch-result-rolled-back": "{{failed}} failed, changes to all {{total}} items were undone",
+    "batch-status-succeeded": "Succeeded",
+    "batch-status-failed": "Failed",
+    "batch-status-rolled-back": "Rolled back",
+    "batch-status-skipped": "Skipped",
+    "scan-block-threshold": "Security scan blocking level",
+    "scan-block-threshold-desc": "Skills are scanned for pipe-to-shell scripts, encoded payloads, credential access, prompt injection and more before install or update; installs at or above this level are refused",
+    "scan-risk-none": "None",
+    "scan-risk-low": "Low",
+    "scan-risk-medium": "Medium",
+    "scan-risk-high": "High",
+    "scan-risk-critical": "Critical",
+    "scan-block-off": "Never block",
+    "scan-blocked-title": "Security scan found risks",
+    "scan-blocked-desc": "{{name}} has risk level \"{{risk}}\", which reaches the blocking level \"{{threshold}}\"",
+    "scan-blocked-hint": "Make sure the content above comes from a trusted source before installing. The blocking level can be changed in Settings",
+    "scan-install-anyway": "Install anyway",
+    "plan-desc": "Will change {{files}} files, {{links}} symlinks and {{lock}} .skills-lock entries",
+    "plan-preview": "Preview changes",
+    "plan-no-changes": "Nothing to change",
+    "plan-files": "Files",
+    "plan-links": "Agent symlinks",
+    "plan-lock": ".skills-lock",
+    "plan-custom-agents": "Custom agents",
+    "plan-action-create": "create",
+    "plan-action-modify": "modify",
+    "plan-action-remove": "remove",
+    "plan-action-add": "add",
+    "plan-action-update": "update",
     "default-install-agents": "Default Install Agents",
     "default-install-agents-desc": "Agents selected by default when installing skills",
     "data-management": "Data Management",
@@ -616,6 +773,8 @@ const en = {
     "backup-list": "Backup List",
     "backup-items-count": "items",
     "backup-restore": "Restore",
+    "backup-compare": "Compare",
+    "backup-compare-desc": "Compare skills in this backup with the current skills directory (backup → current)",
     "backup-restore-desc": "Restore data from backup \"{{name}}\"",
     "backup-restore-skills": "Restore Skills",
     "backup-restore-settings": "Restore Settings",
@@ -664,6 +823,7 @@ const en = {
     "profile-name-placeholder": "Enter profile name...",
     "profile-desc-placeholder": "Describe this profile (optional)...",
     "profile-apply": "Apply",
+    "profile-apply-plan-title": "Apply profile \"{{name}}\"",
     "profile-reapply": "Reapply",
     "profile-snapshot": "Update Snapshot",
     "profile-active": "Active",
@@ -749,6 +909,17 @@ const en = {
     "prov-export": "Export",
     "prov-exported": "Provider config exported",
     "prov-imported": "Successfully imported {{count}} provider(s)",
+    "prov-export-title": "Export Providers",
+    "prov-export-desc": "Choose how API keys are written to the export file",
+    "prov-export-redact": "Hide API keys",
+    "prov-export-redact-desc": "API keys are replaced with REDACTED and must be re-entered after import",
+    "prov-export-encrypt": "Encrypt API keys",
+    "prov-export-encrypt-desc": "API keys are encrypted with a passphrase required on import",
+    "prov-export-plain": "Plain text",
+    "prov-export-plain-desc": "Includes full API keys; keep the file safe",
+    "prov-export-passphrase": "Passphrase",
+    "prov-import-passphrase-title": "Enter Export Passphrase",
+    "prov-import-passphrase-desc": "The API keys in this file are encrypted; enter the passphrase used when exporting",
 
     // Provider Form Page
     "prov-add-new": "Add Provider",
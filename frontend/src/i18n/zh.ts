@@ -8,6 +8,7 @@ const zh = {
     delete: "删除",
     deleting: "删除中...",
     update: "更新",
+    rollback: "回滚",
     search: "搜索",
     save: "保存",
     add: "添加",
@@ -91,11 +92,19 @@ const zh = {
     "try-other-keywords": "试试换个关键词搜索，例如 react、vue、python",
     "explore-remote": "探索远程技能",
     "explore-remote-desc": "在上方搜索框输入关键词，发现社区分享的技能",
+    "offline-results-notice": "离线模式：结果来自本地镜像",
     reinstalling: "重新安装中...",
     reinstall: "重新安装",
     "linked-agents-count": "已链接 {{count}} 个 Agent: ",
     "linked-all-agents": "已链接全部 Agent",
     "no-agent-linked": "未链接任何 Agent，点击配置",
+    "manifest": "清单",
+    "manifest-invalid": "SKILL.md frontmatter 格式错误",
+    "manifest-allowed-tools": "允许的工具",
+    "manifest-dependencies": "依赖",
+    "manifest-optional": "可选",
+    "manifest-conflicts": "冲突",
+    "manifest-compatible-agents": "兼容的 Agent",
     "config-agent-link": "配置 Agent 链接",
     "no-matching-agent": "未找到匹配的 Agent",
     "no-agents": "暂无 Agent",
@@ -140,6 +149,20 @@ const zh = {
     "toast-install-failed": "安装失败: {{error}}",
     "toast-skill-updated": "Skill \"{{name}}\" 已成功更新到最新版本",
     "toast-update-failed": "更新失败: {{error}}",
+    "local-changes-title": "检测到本地修改",
+    "local-changes-desc": "Skill \"{{name}}\" 在安装后被修改过，直接更新会覆盖这些修改：",
+    "local-changes-hint": "合并会保留本地修改：SKILL.md 与上游三方合并，冲突处以 <<<<<<< / >>>>>>> 标记；覆盖前的内容会保存在历史版本中。",
+    "local-changes-overwrite": "覆盖",
+    "local-changes-merge": "合并",
+    "local-changes-unverified-title": "无法判断本地修改",
+    "local-changes-unverified-desc": "Skill \"{{name}}\" 没有记录内容哈希（由旧版本安装），无法检测是否存在本地修改。",
+    "local-changes-unverified-hint": "如果没有修改过该 skill，可标记为未修改后正常更新；否则选择覆盖，当前内容会保存在历史版本中。",
+    "local-changes-accept": "标记为未修改",
+    "toast-skill-merged": "Skill \"{{name}}\" 已更新并保留本地修改",
+    "toast-skill-merge-conflict": "Skill \"{{name}}\" 合并存在冲突，请在 {{files}} 中处理冲突标记",
+    "toast-batch-update-skipped-local": "{{count}} 个技能存在本地修改，已跳过",
+    "toast-skill-rolled-back": "Skill \"{{name}}\" 已恢复到更新前的版本",
+    "toast-rollback-failed": "回滚失败: {{error}}",
     "toast-skill-deleted": "Skill \"{{name}}\" 已成功删除",
     "toast-delete-failed": "删除失败: {{error}}",
     "toast-agent-added": "自定义 Agent \"{{name}}\" 已添加",
@@ -231,6 +254,7 @@ const zh = {
     "toast-export-failed": "导出配置失败: {{error}}",
     "toast-import-success": "导入完成：安装 {{installed}} 个，跳过 {{skipped}} 个",
     "toast-import-failed": "导入配置失败: {{error}}",
+    "toast-import-policy-blocked": "{{count}} 个 skill 被信任策略拦截",
     "import-config-title": "导入配置",
     "import-config-desc": "从导出的 JSON 文件恢复技能和 Agent 链接配置",
     "import-file-hint": "点击选择或拖拽 JSON 文件到此处",
@@ -266,6 +290,8 @@ const zh = {
     "broken-links": "断裂链接",
     "orphan-skills": "孤立技能",
     "unknown-files": "异常文件",
+    "modified-skills": "内容被修改的技能",
+    "skill-missing": "目录缺失",
     "healthy-links": "健康链接",
     "total-links-count": "总链接数",
     "no-issues": "一切正常，没有发现问题",
@@ -329,13 +355,37 @@ const zh = {
     "editor-framework": "框架",
     "editor-lines": "{{count}} 行",
     "editor-cursor": "行 {{line}}, 列 {{col}}",
+    "lint-ok": "格式正确",
+    "lint-summary": "{{errors}} 个错误，{{warnings}} 个警告",
 
     // Diff Preview
     "diff-preview": "变更预览",
     "diff-preview-desc": "对比本地和远程最新版本的差异",
+    "version-history": "历史版本",
+    "version-history-desc": "每次更新、编辑或导入时自动保存的快照，可预览并恢复",
+    "version-history-load-failed": "加载历史版本失败: {{error}}",
+    "no-versions": "暂无历史版本",
+    "restore-version": "恢复此版本",
+    "version-view-content": "内容",
+    "version-view-diff": "与当前对比",
+    "version-same-as-current": "与当前版本一致",
+    "toast-version-restored": "Skill \"{{name}}\" 已恢复到所选版本",
+    "toast-restore-version-failed": "恢复版本失败: {{error}}",
+    "version-reason-before-update": "更新前",
+    "version-reason-update": "更新",
+    "version-reason-before-save": "编辑前",
+    "version-reason-save": "编辑",
+    "version-reason-before-install": "重新安装前",
+    "version-reason-install": "安装/导入",
+    "version-reason-before-restore": "恢复前",
     "local-version": "本地版本",
     "remote-version": "远程版本",
     "no-changes": "内容一致，无需更新",
+    "diff-summary": "{{count}} 个文件变更",
+    "diff-status-added": "新增",
+    "diff-status-removed": "删除",
+    "diff-status-modified": "修改",
+    "diff-binary": "二进制文件，不显示内容差异",
     "loading-diff": "正在获取远程版本...",
     "diff-load-failed": "获取 diff 失败: {{error}}",
 
@@ -348,13 +398,34 @@ const zh = {
     "source-url": "Git 仓库 URL",
     "source-url-placeholder": "https://github.com/team/skills.git",
     "source-token": "访问令牌（可选）",
-    "source-token-placeholder": "GitHub PAT token",
+    "source-token-placeholder": "PAT / access token",
     "no-custom-sources": "还没有添加自定义源",
     "browse-source": "浏览",
     "toast-source-added": "源 \"{{name}}\" 已添加",
     "toast-source-add-failed": "添加源失败: {{error}}",
     "toast-source-removed": "源 \"{{name}}\" 已删除",
     "toast-source-remove-failed": "删除源失败: {{error}}",
+    "host-credentials": "Host 凭据",
+    "host-credentials-desc": "为私有仓库所在的 host 配置 token 或 SSH 私钥，克隆、更新与 API 请求时自动使用",
+    "no-host-credentials": "还没有配置 host 凭据",
+    "add-credential": "添加凭据",
+    "credential-host": "Host",
+    "credential-host-placeholder": "gitlab.example.com",
+    "credential-username": "用户名（可选）",
+    "credential-username-placeholder": "默认 GitHub 为 x-access-token，其余为 oauth2",
+    "credential-ssh-key": "SSH 私钥路径（可选）",
+    "credential-ssh-key-placeholder": "~/.ssh/id_ed25519",
+    "toast-credential-saved": "已保存 {{host}} 的凭据",
+    "toast-credential-save-failed": "保存凭据失败: {{error}}",
+    "toast-credential-removed": "已删除 {{host}} 的凭据",
+    "toast-credential-remove-failed": "删除凭据失败: {{error}}",
+    "registries": "Registry",
+    "registries-desc": "按顺序搜索以下 registry 并合并结果，同一来源的同名 skill 只保留靠前的一条",
+    "add-registry": "添加 Registry",
+    "registry-name-placeholder": "例如: team",
+    "registry-url-placeholder": "https://skills.example.com/v1",
+    "toast-registry-added": "已添加 registry {{name}}",
+    "toast-registry-failed": "操作 registry 失败: {{error}}",
 
     // Project Wizard
     "project-wizard": "项目初始化向导",
@@ -393,6 +464,53 @@ const zh = {
     "auto-update-enabled": "已启用自动更新",
     "auto-update-disabled": "自动更新已关闭",
     "update-interval": "检查间隔（小时）",
+    "max-skill-versions": "历史版本保留数",
+    "max-skill-versions-desc": "更新、编辑或导入 skill 时保存的历史版本数量",
+    "clone-cache": "仓库克隆缓存",
+    "clone-cache-desc": "安装、更新时复用的仓库缓存，当前占用 {{size}}；超过有效期或大小上限时自动清理",
+    "days-count": "{{count}} 天",
+    "toast-clone-cache-cleared": "已清空克隆缓存，释放 {{size}}",
+    "toast-clone-cache-clear-failed": "清空克隆缓存失败: {{error}}",
+    "offline-mode": "离线模式",
+    "offline-mode-desc": "只使用本地镜像搜索、安装和检测更新，不访问网络（已镜像 {{count}} 个源）",
+    "storage-backend": "元数据存储",
+    "storage-backend-desc": "标签、评分、活动日志与性能指标的存储方式，切换到数据库时自动导入现有 JSON 文件（当前 {{count}} 条记录）",
+    "storage-backend-json": "JSON 文件",
+    "storage-backend-bolt": "内嵌数据库",
+    "secret-backend": "密钥存储",
+    "secret-backend-desc": "供应商 API Key 与访问令牌不以明文写入配置文件，当前保存在：{{backend}}",
+    "secret-backend-auto": "自动",
+    "secret-backend-keyring": "系统钥匙串",
+    "secret-backend-vault": "加密文件",
+    "vault-passphrase": "加密文件口令",
+    "vault-keyfile-desc": "加密文件使用本机密钥文件，设置口令后每次启动需解锁",
+    "vault-passphrase-desc": "加密文件已设置口令，留空新口令可改回本机密钥文件",
+    "vault-locked-desc": "加密文件已锁定，解锁前无法读取 API Key 与令牌",
+    "vault-current-passphrase": "当前口令",
+    "vault-new-passphrase": "新口令",
+    "vault-unlock": "解锁",
+    "vault-set-passphrase": "设置口令",
+    "vault-remove-passphrase": "移除口令",
+    "toast-vault-unlocked": "已解锁加密文件",
+    "toast-vault-unlock-failed": "解锁失败: {{error}}",
+    "toast-vault-passphrase-set": "已设置加密文件口令",
+    "toast-vault-passphrase-removed": "已移除口令，改用本机密钥文件",
+    "toast-vault-passphrase-failed": "设置口令失败: {{error}}",
+    "trusted-keys": "签名公钥",
+    "trusted-keys-desc": "安装和更新时用这些公钥校验 skill 的 SKILL.sig（minisign 或 ssh-keygen -Y sign 签名），信任策略可要求必须通过校验",
+    "trusted-key-name": "名称",
+    "trusted-key-placeholder": "minisign 公钥（RW...）或 SSH 公钥（ssh-ed25519 AAAA...）",
+    "trusted-key-add": "添加",
+    "toast-trusted-key-added": "已添加签名公钥 {{name}}",
+    "toast-trusted-key-failed": "操作签名公钥失败: {{error}}",
+    "signature-verified": "已签名 · {{signer}}",
+    "signature-untrusted": "签名公钥不受信任",
+    "signature-invalid": "签名无效",
+    "signature-unsigned": "未签名",
+    "sync-mirrors": "同步镜像",
+    "toast-mirror-synced": "已同步 {{count}} 个源的本地镜像",
+    "toast-mirror-sync-partial": "{{total}} 个源中有 {{failed}} 个同步失败",
+    "toast-mirror-sync-failed": "同步镜像失败: {{error}}",
     "toast-auto-update-saved": "自动更新设置已保存",
     "toast-auto-update-result": "自动更新完成：更新了 {{count}} 个技能",
 
@@ -415,6 +533,7 @@ const zh = {
     "activity-log": "活动日志",
     "activity-log-desc": "查看所有操作的时间线记录",
     "no-activity": "暂无活动记录",
+    "load-more": "加载更多（{{loaded}} / {{total}}）",
     "clear-logs": "清空日志",
     "action-install": "安装",
     "action-delete": "删除",
@@ -424,6 +543,7 @@ const zh = {
     "action-unlink": "取消链接",
     "action-import": "导入",
     "action-export": "导出",
+    "action-policy": "策略拦截",
     "toast-logs-cleared": "活动日志已清空",
 
     // Skill Preview
@@ -469,6 +589,7 @@ const zh = {
 
     // Clone Config
     "clone-config": "克隆配置",
+    "clone-plan-title": "克隆项目配置",
     "clone-config-desc": "将一个项目的技能配置复制到另一个项目",
     "source-project": "源项目",
     "target-project": "目标项目",
@@ -491,6 +612,42 @@ const zh = {
     "skill-display": "技能显示",
     "show-path-in-card": "卡片中显示路径",
     "compact-mode": "紧凑模式",
+    "block-save-on-lint-errors": "校验失败时禁止保存",
+    "block-save-on-lint-errors-desc": "SKILL.md 存在格式错误（缺少 frontmatter、名称与目录不一致、链接失效等）时拒绝保存、创建、安装和更新",
+    "atomic-batch": "批量操作全部成功或全部回滚",
+    "atomic-batch-desc": "批量安装、批量删除与安装集合中任一项失败时，撤销已完成的项",
+    "batch-install-result": "批量安装结果",
+    "batch-delete-result": "批量删除结果",
+    "batch-result-desc": "{{succeeded}} 项成功，{{failed}} 项失败",
+    "batch-result-rolled-back": "{{failed}} 项失败，已撤销整批 {{total}} 项的变更",
+    "batch-status-succeeded": "成功",
+    "batch-status-failed": "失败",
+    "batch-status-rolled-back": "已回滚",
+    "batch-status-skipped": "未执行",
+    "scan-block-threshold": "安全扫描拦截等级",
+    "scan-block-threshold-desc": "安装或更新 skill 前扫描管道执行脚本、编码载荷、读取凭据、提示注入等风险，达到该等级时拒绝安装",
+    "scan-risk-none": "无风险",
+    "scan-risk-low": "低",
+    "scan-risk-medium": "中",
+    "scan-risk-high": "高",
+    "scan-risk-critical": "严重",
+    "scan-block-off": "不拦截",
+    "scan-blocked-title": "安全扫描发现风险",
+    "scan-blocked-desc": "{{name}} 的风险等级为「{{risk}}」，达到了拦截等级「{{threshold}}」",
+    "scan-blocked-hint": "请确认以上内容来自可信来源后再安装。可以在设置中调整拦截等级",
+    "scan-install-anyway": "仍然安装",
+    "plan-desc": "将变更 {{files}} 个文件、{{links}} 个软链接、{{lock}} 条 .skills-lock 记录",
+    "plan-preview": "预览变更",
+    "plan-no-changes": "没有需要执行的变更",
+    "plan-files": "文件",
+    "plan-links": "Agent 软链接",
+    "plan-lock": ".skills-lock",
+    "plan-custom-agents": "自定义 Agent",
+    "plan-action-create": "新建",
+    "plan-action-modify": "修改",
+    "plan-action-remove": "删除",
+    "plan-action-add": "添加",
+    "plan-action-update": "更新",
     "default-install-agents": "默认安装 Agent",
     "default-install-agents-desc": "安装技能时默认选中的 Agent",
     "data-management": "数据管理",
@@ -616,6 +773,8 @@ const zh = {
     "backup-list": "备份列表",
     "backup-items-count": "项",
     "backup-restore": "恢复",
+    "backup-compare": "对比",
+    "backup-compare-desc": "对比备份中的 Skills 与当前 Skills 目录（备份 → 当前）",
     "backup-restore-desc": "从备份 \"{{name}}\" 恢复数据",
     "backup-restore-skills": "恢复技能",
     "backup-restore-settings": "恢复设置",
@@ -664,6 +823,7 @@ const zh = {
     "profile-name-placeholder": "输入档案名称...",
     "profile-desc-placeholder": "描述这个配置档案（可选）...",
     "profile-apply": "应用",
+    "profile-apply-plan-title": "应用配置方案「{{name}}」",
     "profile-reapply": "重新应用",
     "profile-snapshot": "更新快照",
     "profile-active": "当前激活",
@@ -749,6 +909,17 @@ const zh = {
     "prov-export": "导出",
     "prov-exported": "供应商配置已导出",
     "prov-imported": "成功导入 {{count}} 个供应商配置",
+    "prov-export-title": "导出供应商配置",
+    "prov-export-desc": "选择导出文件中 API Key 的处理方式",
+    "prov-export-redact": "隐藏 API Key",
+    "prov-export-redact-desc": "API Key 以 REDACTED 代替，导入后需重新填写",
+    "prov-export-encrypt": "加密 API Key",
+    "prov-export-encrypt-desc": "用口令加密 API Key，导入时输入相同口令",
+    "prov-export-plain": "明文导出",
+    "prov-export-plain-desc": "包含完整 API Key，请妥善保管导出文件",
+    "prov-export-passphrase": "口令",
+    "prov-import-passphrase-title": "输入导出口令",
+    "prov-import-passphrase-desc": "该文件中的 API Key 已加密，请输入导出时设置的口令",
 
     // Provider Form Page
     "prov-add-new": "添加供应商",
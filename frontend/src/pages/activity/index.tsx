@@ -22,8 +22,10 @@ import {
   Cancel01Icon,
   LinkSquare02Icon,
   Edit02Icon,
+  AlertDiamondIcon,
 } from "hugeicons-react"
-import { GetActivityLogs, ClearActivityLogs } from "@wailsjs/go/services/SkillsService"
+import { QueryActivityLogs, ClearActivityLogs } from "@wailsjs/go/services/SkillsService"
+import { services } from "@wailsjs/go/models"
 
 interface ActivityLogEntry {
   id: string
@@ -42,6 +44,7 @@ const actionIconMap: Record<string, any> = {
   unlink: Cancel01Icon,
   import: Upload04Icon,
   export: Download04Icon,
+  policy: AlertDiamondIcon,
 }
 
 const actionColorMap: Record<string, string> = {
@@ -53,12 +56,18 @@ const actionColorMap: Record<string, string> = {
   unlink: "bg-orange-500/10 text-orange-600 dark:text-orange-400",
   import: "bg-cyan-500/10 text-cyan-600 dark:text-cyan-400",
   export: "bg-indigo-500/10 text-indigo-600 dark:text-indigo-400",
+  policy: "bg-rose-500/10 text-rose-600 dark:text-rose-400",
 }
 
+// 每页加载的日志条数
+const PAGE_SIZE = 50
+
 const ActivityPage = () => {
   const { t } = useTranslation()
   const [logs, setLogs] = useState<ActivityLogEntry[]>([])
+  const [total, setTotal] = useState(0)
   const [loading, setLoading] = useState(true)
+  const [loadingMore, setLoadingMore] = useState(false)
   const [showClearDialog, setShowClearDialog] = useState(false)
 
   useEffect(() => {
@@ -68,16 +77,28 @@ const ActivityPage = () => {
   const loadLogs = async () => {
     try {
       setLoading(true)
-      const result = await GetActivityLogs(200)
-      setLogs(result || [])
+      const page = await QueryActivityLogs(new services.ActivityLogQuery({ offset: 0, limit: PAGE_SIZE }))
+      setLogs(page?.logs || [])
+      setTotal(page?.total || 0)
     } catch {}
     setLoading(false)
   }
 
+  const loadMore = async () => {
+    try {
+      setLoadingMore(true)
+      const page = await QueryActivityLogs(new services.ActivityLogQuery({ offset: logs.length, limit: PAGE_SIZE }))
+      setLogs(prev => [...prev, ...(page?.logs || [])])
+      setTotal(page?.total || 0)
+    } catch {}
+    setLoadingMore(false)
+  }
+
   const handleClearLogs = async () => {
     try {
       await ClearActivityLogs()
       setLogs([])
+      setTotal(0)
       toast({ title: t("toast-logs-cleared"), variant: "success" })
     } catch {}
     setShowClearDialog(false)
@@ -158,6 +179,14 @@ const ActivityPage = () => {
                 })}
               </div>
             </div>
+            {logs.length < total && (
+              <div className="flex justify-center pt-2">
+                <Button variant="outline" size="sm" onClick={loadMore} disabled={loadingMore}>
+                  {loadingMore && <RefreshIcon size={14} className="mr-1.5 animate-spin" />}
+                  {t("load-more", { loaded: logs.length, total })}
+                </Button>
+              </div>
+            )}
           </div>
         )}
       </div>
@@ -36,8 +36,10 @@ import {
   Clock01Icon,
   Folder01Icon,
   CheckmarkCircle02Icon,
+  GitCompareIcon,
 } from "hugeicons-react"
 import {
+  CompareBackupSkills,
   CreateBackup,
   DeleteBackup,
   GetBackupConfig,
@@ -47,6 +49,7 @@ import {
   SetBackupConfig,
 } from "@wailsjs/go/services/BackupService"
 import { services } from "@wailsjs/go/models"
+import TreeDiffView from "@/components/TreeDiffView"
 
 const BackupPage = () => {
   const { t } = useTranslation()
@@ -60,6 +63,8 @@ const BackupPage = () => {
   const [showRestoreDialog, setShowRestoreDialog] = useState(false)
   const [backupToDelete, setBackupToDelete] = useState<string | null>(null)
   const [backupToRestore, setBackupToRestore] = useState<services.BackupInfo | null>(null)
+  const [compareDiff, setCompareDiff] = useState<services.TreeDiff | null>(null)
+  const [comparing, setComparing] = useState<string | null>(null)
   const [newBackupName, setNewBackupName] = useState("")
   const [newBackupDesc, setNewBackupDesc] = useState("")
   const [selectedItems, setSelectedItems] = useState<string[]>([])
@@ -122,6 +127,17 @@ const BackupPage = () => {
     }
   }
 
+  const handleCompare = async (id: string) => {
+    setComparing(id)
+    try {
+      setCompareDiff(await CompareBackupSkills(id))
+    } catch (error) {
+      toast({ title: t("error"), description: String(error), variant: "destructive" })
+    } finally {
+      setComparing(null)
+    }
+  }
+
   const handleRestore = async () => {
     if (!backupToRestore) return
     try {
@@ -262,6 +278,12 @@ const BackupPage = () => {
                     </div>
                   </div>
                   <div className="flex items-center gap-1.5 shrink-0 ml-4">
+                    <Button size="sm" variant="ghost" className="h-7 text-xs" disabled={comparing === backup.id} onClick={() => handleCompare(backup.id)}>
+                      {comparing === backup.id
+                        ? <RefreshIcon className="h-3.5 w-3.5 mr-1 animate-spin" />
+                        : <GitCompareIcon className="h-3.5 w-3.5 mr-1" />}
+                      {t("backup-compare")}
+                    </Button>
                     <Button size="sm" variant="outline" className="h-7 text-xs" onClick={() => {
                       setBackupToRestore(backup)
                       setShowRestoreDialog(true)
@@ -397,6 +419,23 @@ const BackupPage = () => {
         </DialogContent>
       </Dialog>
 
+      {/* Compare Dialog */}
+      <Dialog open={!!compareDiff} onOpenChange={open => !open && setCompareDiff(null)}>
+        <DialogContent className="max-w-4xl max-h-[80vh] flex flex-col overflow-hidden">
+          <DialogHeader>
+            <DialogTitle>{t("backup-compare")}</DialogTitle>
+            <DialogDescription>{t("backup-compare-desc")}</DialogDescription>
+          </DialogHeader>
+          {compareDiff && (compareDiff.hasChanges ? (
+            <TreeDiffView files={compareDiff.files || []} additions={compareDiff.additions} deletions={compareDiff.deletions} />
+          ) : (
+            <div className="rounded-lg border border-emerald-500/20 bg-emerald-500/5 p-8 text-center">
+              <p className="text-sm font-medium text-emerald-600 dark:text-emerald-400">{t("no-changes")}</p>
+            </div>
+          ))}
+        </DialogContent>
+      </Dialog>
+
       {/* Delete Confirm */}
       <AlertDialog open={!!backupToDelete} onOpenChange={open => !open && setBackupToDelete(null)}>
         <AlertDialogContent>
@@ -40,11 +40,14 @@ import {
   CreateCollection,
   DeleteCollection,
   UpdateCollection,
-  InstallCollection,
+  InstallCollectionWithOptions,
   GetAllAgentSkills,
+  GetSettings,
 } from "@wailsjs/go/services/SkillsService"
 import { GetSupportedAgents } from "@wailsjs/go/services/AgentService"
+import { services } from "@wailsjs/go/models"
 import type { AgentInfo } from "@/types"
+import BatchResultDialog from "@/components/BatchResultDialog"
 import ProfilesPage from "../profiles"
 
 interface SkillCollection {
@@ -63,6 +66,7 @@ const CollectionsPage = () => {
   const [editingCollection, setEditingCollection] = useState<SkillCollection | null>(null)
   const [collectionToDelete, setCollectionToDelete] = useState<string | null>(null)
   const [installingCollection, setInstallingCollection] = useState<string | null>(null)
+  const [batchResult, setBatchResult] = useState<services.BatchResult | null>(null)
 
   // Form
   const [formName, setFormName] = useState("")
@@ -153,8 +157,13 @@ const CollectionsPage = () => {
     try {
       setInstallingCollection(name)
       const agents = allAgents.map(a => a.name)
-      const count = await InstallCollection(name, agents)
-      toast({ title: t("toast-collection-installed", { count }), variant: "success" })
+      const settings = await GetSettings()
+      const result = await InstallCollectionWithOptions(name, agents, new services.BatchOptions({ atomic: !!settings.atomicBatch }))
+      if (result.failed > 0) {
+        setBatchResult(result)
+      } else {
+        toast({ title: t("toast-collection-installed", { count: result.succeeded }), variant: "success" })
+      }
     } catch (error) {
       toast({ title: t("toast-collection-failed", { error }), variant: "destructive" })
     } finally {
@@ -371,6 +380,13 @@ const CollectionsPage = () => {
           </AlertDialogFooter>
         </AlertDialogContent>
       </AlertDialog>
+
+      <BatchResultDialog
+        open={!!batchResult}
+        onOpenChange={(open) => !open && setBatchResult(null)}
+        title={t("batch-install-result")}
+        result={batchResult}
+      />
       </>)}
 
     </div>
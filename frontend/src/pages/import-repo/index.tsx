@@ -20,9 +20,11 @@ import {
   CodeIcon,
   GitBranchIcon,
 } from "hugeicons-react"
-import { ScanGitHubRepo, BatchInstallFromRepo } from "@wailsjs/go/services/SkillsService"
+import { ScanGitHubRepo, BatchInstallFromRepoWithOptions, GetSettings } from "@wailsjs/go/services/SkillsService"
 import { GetSupportedAgents } from "@wailsjs/go/services/AgentService"
+import { services } from "@wailsjs/go/models"
 import type { AgentInfo } from "@/types"
+import BatchResultDialog from "@/components/BatchResultDialog"
 
 interface RepoSkill {
   name: string
@@ -41,6 +43,7 @@ const ImportRepoPage = () => {
   const [selectedAgents, setSelectedAgents] = useState<Set<string>>(new Set())
   const [installing, setInstalling] = useState(false)
   const [scanned, setScanned] = useState(false)
+  const [batchResult, setBatchResult] = useState<services.BatchResult | null>(null)
 
   const handleScan = async () => {
     if (!repoURL.trim()) return
@@ -94,9 +97,14 @@ const ImportRepoPage = () => {
       setInstalling(true)
       const fullNames = Array.from(selected)
       const agentNames = Array.from(selectedAgents)
-      const count = await BatchInstallFromRepo(fullNames, agentNames)
-      toast({ title: t("repo-install-success", { count }), variant: "success" })
+      const settings = await GetSettings()
+      const result = await BatchInstallFromRepoWithOptions(fullNames, agentNames, new services.BatchOptions({ atomic: !!settings.atomicBatch }))
       setShowAgentDialog(false)
+      if (result.failed > 0) {
+        setBatchResult(result)
+      } else {
+        toast({ title: t("repo-install-success", { count: result.succeeded }), variant: "success" })
+      }
     } catch (error) {
       toast({ title: String(error), variant: "destructive" })
     } finally {
@@ -236,6 +244,13 @@ const ImportRepoPage = () => {
           </DialogFooter>
         </DialogContent>
       </Dialog>
+
+      <BatchResultDialog
+        open={!!batchResult}
+        onOpenChange={(open) => !open && setBatchResult(null)}
+        title={t("batch-install-result")}
+        result={batchResult}
+      />
     </div>
   )
 }
@@ -34,6 +34,8 @@ import CreateSkillDialog from "@/components/CreateSkillDialog"
 import HealthCheckDialog from "@/components/HealthCheckDialog"
 import CustomSourcesDialog from "@/components/CustomSourcesDialog"
 import KeyboardShortcutsDialog from "@/components/KeyboardShortcutsDialog"
+import ChangePlanDialog from "@/components/ChangePlanDialog"
+import { services } from "@wailsjs/go/models"
 
 import {
   Home01Icon, 
@@ -58,7 +60,7 @@ import {
 } from "hugeicons-react"
 import { useState, useEffect, useRef, useCallback } from "react"
 import { SelectFolder, GetFolders, RemoveFolder } from "@wailsjs/go/services/FolderService"
-import { ExportConfigToFile, ImportConfig, CheckSkillUpdates, GetAutoUpdateConfig, SetAutoUpdateConfig, RunAutoUpdate, GetSettings, SaveSettings } from "@wailsjs/go/services/SkillsService"
+import { ExportConfigToFile, ImportConfig, PlanImportConfig, CheckSkillUpdates, GetAutoUpdateConfig, SetAutoUpdateConfig, RunAutoUpdate, GetSettings, SaveSettings } from "@wailsjs/go/services/SkillsService"
 import { EventsOn, EventsOff } from "@wailsjs/runtime/runtime"
 
 const PageLayout = () => {
@@ -72,6 +74,7 @@ const PageLayout = () => {
   const [showImportDialog, setShowImportDialog] = useState(false)
   const [importFile, setImportFile] = useState<File | null>(null)
   const [importing, setImporting] = useState(false)
+  const [importPlan, setImportPlan] = useState<services.ChangePlan | null>(null)
   const [exporting, setExporting] = useState(false)
   const fileInputRef = useRef<HTMLInputElement>(null)
   const [commandPaletteOpen, setCommandPaletteOpen] = useState(false)
@@ -308,8 +311,25 @@ const PageLayout = () => {
         }),
         variant: "success",
       })
+      if (result.policyViolations?.length) {
+        toast({ title: t("toast-import-policy-blocked", { count: result.policyViolations.length }), description: result.policyViolations.join("\n"), variant: "destructive" })
+      }
       setShowImportDialog(false)
       setImportFile(null)
+      setImportPlan(null)
+    } catch (error) {
+      toast({ title: t("toast-import-failed", { error }), variant: "destructive" })
+    } finally {
+      setImporting(false)
+    }
+  }, [importFile, t])
+
+  // 导入前预览将要安装的 skills 与链接变更
+  const handlePreviewImport = useCallback(async () => {
+    if (!importFile) return
+    try {
+      setImporting(true)
+      setImportPlan(await PlanImportConfig(await importFile.text()))
     } catch (error) {
       toast({ title: t("toast-import-failed", { error }), variant: "destructive" })
     } finally {
@@ -602,6 +622,9 @@ const PageLayout = () => {
           </div>
           <DialogFooter>
             <Button variant="outline" onClick={() => setShowImportDialog(false)}>{t("cancel")}</Button>
+            <Button variant="outline" onClick={handlePreviewImport} disabled={!importFile || importing}>
+              {t("plan-preview")}
+            </Button>
             <Button onClick={handleImport} disabled={!importFile || importing}>
               {importing ? (
                 <><RefreshIcon size={14} className="mr-1.5 animate-spin" />{t("importing")}</>
@@ -613,6 +636,15 @@ const PageLayout = () => {
         </DialogContent>
       </Dialog>
 
+      <ChangePlanDialog
+        open={importPlan !== null}
+        onOpenChange={(open) => { if (!open) setImportPlan(null) }}
+        title={t("import-config-title")}
+        plan={importPlan}
+        confirmLabel={t("start-import")}
+        busy={importing}
+        onConfirm={handleImport}
+      />
       <CommandPalette open={commandPaletteOpen} onOpenChange={setCommandPaletteOpen} onAction={handleCommandAction} />
       <CreateSkillDialog open={createSkillOpen} onOpenChange={setCreateSkillOpen} onCreated={() => window.dispatchEvent(new CustomEvent("skill-created"))} />
       <HealthCheckDialog open={healthCheckOpen} onOpenChange={setHealthCheckOpen} />
@@ -8,6 +8,7 @@ import {
   GetProfiles,
   SaveCurrentAsProfile,
   ApplyProfile,
+  PlanApplyProfile,
   DeleteProfile,
   UpdateProfile,
 } from "@wailsjs/go/services/ProfileService"
@@ -37,6 +38,8 @@ import {
   PlayIcon,
   Settings02Icon,
 } from "hugeicons-react"
+import ChangePlanDialog from "@/components/ChangePlanDialog"
+import { services } from "@wailsjs/go/models"
 
 interface Profile {
   name: string
@@ -67,6 +70,7 @@ const ProfilesPage = () => {
   const [creating, setCreating] = useState(false)
   const [applying, setApplying] = useState<string | null>(null)
   const [deleteTarget, setDeleteTarget] = useState<string | null>(null)
+  const [applyPlan, setApplyPlan] = useState<{ name: string; plan: services.ChangePlan } | null>(null)
 
   const load = useCallback(async () => {
     try {
@@ -101,11 +105,25 @@ const ProfilesPage = () => {
     }
   }
 
+  // 应用前先预览将要增删的软链接
+  const handlePreviewApply = async (name: string) => {
+    try {
+      setApplying(name)
+      const plan = await PlanApplyProfile(name)
+      setApplyPlan({ name, plan })
+    } catch (error) {
+      toast({ title: String(error), variant: "destructive" })
+    } finally {
+      setApplying(null)
+    }
+  }
+
   const handleApply = async (name: string) => {
     try {
       setApplying(name)
       await applyProfile(name)
       toast({ title: t("profile-applied", { name }), variant: "success" })
+      setApplyPlan(null)
       load()
     } catch (error) {
       toast({ title: String(error), variant: "destructive" })
@@ -204,7 +222,7 @@ const ProfilesPage = () => {
                     size="sm"
                     variant={config.active === profile.name ? "secondary" : "default"}
                     className="h-7 text-[11px] flex-1"
-                    onClick={() => handleApply(profile.name)}
+                    onClick={() => handlePreviewApply(profile.name)}
                     disabled={applying === profile.name}
                   >
                     {applying === profile.name ? (
@@ -238,6 +256,16 @@ const ProfilesPage = () => {
         )}
       </div>
 
+      <ChangePlanDialog
+        open={applyPlan !== null}
+        onOpenChange={(open) => { if (!open) setApplyPlan(null) }}
+        title={t("profile-apply-plan-title", { name: applyPlan?.name })}
+        plan={applyPlan?.plan || null}
+        confirmLabel={t("profile-apply")}
+        busy={applying !== null}
+        onConfirm={() => applyPlan && handleApply(applyPlan.name)}
+      />
+
       {/* Create Dialog */}
       <Dialog open={showCreate} onOpenChange={setShowCreate}>
         <DialogContent className="max-w-md">
@@ -38,11 +38,13 @@ import {
 } from "hugeicons-react"
 import {
   GetProjectSkills,
-  InstallRemoteSkillToProject,
+  InstallRemoteSkillToProjectWithOptions,
+  ScanRemoteSkill,
   RemoveSkillFromProject,
   GetProjectSkillAgentLinks,
   UpdateProjectSkillAgentLinks,
   CloneProjectConfig,
+  PlanCloneProjectConfig,
 } from "@wailsjs/go/services/SkillsService"
 import {
   GetProjectAgents,
@@ -58,6 +60,9 @@ import SkillCard from "@/components/SkillCard"
 import ConfigAgentLinkDialog from "@/components/ConfigAgentLinkDialog"
 import AgentSelectDialog from "@/components/AgentSelectDialog"
 import ProjectWizardDialog from "@/components/ProjectWizardDialog"
+import ChangePlanDialog from "@/components/ChangePlanDialog"
+import ScanReportDialog from "@/components/ScanReportDialog"
+import { services } from "@wailsjs/go/models"
 import type { AgentInfo, SkillData } from "@/types"
 
 const ProjectsPage = () => {
@@ -75,6 +80,7 @@ const ProjectsPage = () => {
   const [allAgents, setAllAgents] = useState<AgentInfo[]>([])
   const [showAgentSelectDialog, setShowAgentSelectDialog] = useState(false)
   const [pendingInstall, setPendingInstall] = useState<{ name: string } | null>(null)
+  const [blockedInstall, setBlockedInstall] = useState<{ fullName: string; agents: string[]; report: services.SkillScanReport } | null>(null)
   const [configDialogOpen, setConfigDialogOpen] = useState(false)
   const [configSkillName, setConfigSkillName] = useState<string | null>(null)
   const [projectAgents, setProjectAgents] = useState<AgentInfo[]>([])
@@ -84,6 +90,7 @@ const ProjectsPage = () => {
   const [skillFilterQuery, setSkillFilterQuery] = useState("")
   const [wizardOpen, setWizardOpen] = useState(false)
   const [cloning, setCloning] = useState(false)
+  const [clonePlan, setClonePlan] = useState<{ source: string; plan: services.ChangePlan } | null>(null)
 
   useEffect(() => {
     if (folderPath) {
@@ -214,22 +221,36 @@ const ProjectsPage = () => {
     setPendingInstall(null)
   }
 
-  const doInstallRemoteToProject = async (fullName: string, agents: string[]) => {
+  const doInstallRemoteToProject = async (fullName: string, agents: string[], allowRisk = false) => {
     if (!folderPath) return
     try {
       setInstallingSkill(fullName)
       const skillName = fullName.split("@")[1] || fullName
-      await InstallRemoteSkillToProject(folderPath, fullName, agents)
+      await InstallRemoteSkillToProjectWithOptions(folderPath, fullName, agents, { allowRisk })
       toast({ title: t("toast-project-skill-installed", { name: skillName, count: agents.length }), variant: "success" })
       await loadProjectSkills(folderPath)
     } catch (error) {
       console.error("Install failed:", error)
+      // 安全扫描阻止时展示报告，由用户决定是否仍然安装
+      if (String(error).includes("security scan blocked")) {
+        try {
+          const report = await ScanRemoteSkill(fullName)
+          setBlockedInstall({ fullName, agents, report })
+          return
+        } catch {}
+      }
       toast({ title: `${error}`, variant: "destructive" })
     } finally {
       setInstallingSkill(null)
     }
   }
 
+  const handleInstallAnyway = async () => {
+    const blocked = blockedInstall
+    setBlockedInstall(null)
+    if (blocked) await doInstallRemoteToProject(blocked.fullName, blocked.agents, true)
+  }
+
   const handleRemoveFromProject = async () => {
     if (!folderPath || !skillToRemove) return
     try {
@@ -271,14 +292,29 @@ const ProjectsPage = () => {
     toast({ title: t("toast-links-updated", { name: skillName, count: agents.length }), variant: "success" })
   }
 
-  const handleCloneConfig = async () => {
+  // 选择源项目后先预览将要创建的软链接与文件
+  const handlePreviewClone = async () => {
     if (!folderPath) return
     try {
       setCloning(true)
       const sourceFolder = await SelectFolder()
       if (!sourceFolder) return
-      const count = await CloneProjectConfig(sourceFolder, folderPath)
+      const plan = await PlanCloneProjectConfig(sourceFolder, folderPath)
+      setClonePlan({ source: sourceFolder, plan })
+    } catch (error) {
+      toast({ title: t("toast-clone-failed", { error }), variant: "destructive" })
+    } finally {
+      setCloning(false)
+    }
+  }
+
+  const handleCloneConfig = async () => {
+    if (!folderPath || !clonePlan) return
+    try {
+      setCloning(true)
+      const count = await CloneProjectConfig(clonePlan.source, folderPath)
       toast({ title: t("toast-clone-success", { count }), variant: "success" })
+      setClonePlan(null)
       await loadProjectSkills(folderPath)
     } catch (error) {
       toast({ title: t("toast-clone-failed", { error }), variant: "destructive" })
@@ -334,7 +370,7 @@ const ProjectsPage = () => {
               <AiBrain01Icon size={14} className="mr-1.5" />
               {t("project-wizard")}
             </Button>
-            <Button size="sm" variant="outline" onClick={handleCloneConfig} disabled={cloning}>
+            <Button size="sm" variant="outline" onClick={handlePreviewClone} disabled={cloning}>
               <Copy01Icon size={14} className="mr-1.5" />
               {cloning ? t("cloning") : t("clone-config")}
             </Button>
@@ -558,6 +594,23 @@ const ProjectsPage = () => {
         </AlertDialogContent>
       </AlertDialog>
 
+      <ScanReportDialog
+        open={blockedInstall !== null}
+        onOpenChange={(open) => { if (!open) setBlockedInstall(null) }}
+        report={blockedInstall?.report ?? null}
+        onConfirm={handleInstallAnyway}
+      />
+
+      <ChangePlanDialog
+        open={clonePlan !== null}
+        onOpenChange={(open) => { if (!open) setClonePlan(null) }}
+        title={t("clone-plan-title")}
+        plan={clonePlan?.plan || null}
+        confirmLabel={t("clone-config")}
+        busy={cloning}
+        onConfirm={handleCloneConfig}
+      />
+
       {/* Project Wizard Dialog */}
       <ProjectWizardDialog
         open={wizardOpen}
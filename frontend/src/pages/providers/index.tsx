@@ -3,6 +3,7 @@ import { useNavigate } from "react-router-dom"
 import { useTranslation } from "react-i18next"
 import { Button } from "@/components/ui/button"
 import { Badge } from "@/components/ui/badge"
+import { Input } from "@/components/ui/input"
 import { Tabs, TabsContent, TabsList, TabsTrigger } from "@/components/ui/tabs"
 import {
   AlertDialog,
@@ -42,12 +43,14 @@ import {
   DeactivateProvider,
   DetectActiveProviders,
   TestProvider,
-  ExportProviders,
+  ExportProvidersWithOptions,
   ImportProviders,
+  ImportProvidersWithPassphrase,
   GetCodeBuddyActiveModel,
   SwitchCodeBuddyBuiltinModel,
   OpenTerminalWithCLI,
 } from "@wailsjs/go/services/ProviderService"
+import { services } from "@wailsjs/go/models"
 import { RefreshTray } from "@wailsjs/go/services/TrayService"
 import { EventsOn, EventsOff } from "@wailsjs/runtime/runtime"
 
@@ -128,6 +131,12 @@ const ProvidersPage = () => {
   const [cbActiveModel, setCbActiveModel] = useState("")
   const [cbSwitching, setCbSwitching] = useState(false)
   const fileInputRef = useRef<HTMLInputElement>(null)
+  const [showExport, setShowExport] = useState(false)
+  const [exportMode, setExportMode] = useState("redact")
+  const [exportPassphrase, setExportPassphrase] = useState("")
+  // 加密导出的文件需要输入口令后再导入
+  const [pendingImport, setPendingImport] = useState<string | null>(null)
+  const [importPassphrase, setImportPassphrase] = useState("")
 
   const loadData = useCallback(async () => {
     try {
@@ -236,7 +245,7 @@ const ProvidersPage = () => {
 
   const handleExport = async () => {
     try {
-      const json = await ExportProviders()
+      const json = await ExportProvidersWithOptions(new services.SecretExportOptions({ mode: exportMode, passphrase: exportPassphrase }))
       const blob = new Blob([json], { type: "application/json" })
       const url = URL.createObjectURL(blob)
       const a = document.createElement("a")
@@ -245,6 +254,8 @@ const ProvidersPage = () => {
       a.click()
       URL.revokeObjectURL(url)
       toast({ title: t("prov-exported"), variant: "success" })
+      setShowExport(false)
+      setExportPassphrase("")
     } catch (error) {
       toast({ title: String(error), variant: "destructive" })
     }
@@ -255,13 +266,30 @@ const ProvidersPage = () => {
     if (!file) return
     try {
       const text = await file.text()
-      const count = await ImportProviders(text)
+      if (text.includes('"secretEncryption"')) {
+        setPendingImport(text)
+      } else {
+        const count = await ImportProviders(text)
+        toast({ title: t("prov-imported", { count }), variant: "success" })
+        await loadData()
+      }
+    } catch (error) {
+      toast({ title: String(error), variant: "destructive" })
+    }
+    if (fileInputRef.current) fileInputRef.current.value = ""
+  }
+
+  const handleEncryptedImport = async () => {
+    if (pendingImport === null) return
+    try {
+      const count = await ImportProvidersWithPassphrase(pendingImport, importPassphrase)
       toast({ title: t("prov-imported", { count }), variant: "success" })
+      setPendingImport(null)
+      setImportPassphrase("")
       await loadData()
     } catch (error) {
       toast({ title: String(error), variant: "destructive" })
     }
-    if (fileInputRef.current) fileInputRef.current.value = ""
   }
 
   const getFilteredProviders = (appType: AppType) =>
@@ -282,7 +310,7 @@ const ProvidersPage = () => {
               <Upload04Icon size={14} className="mr-1.5" />
               {t("prov-import")}
             </Button>
-            <Button variant="outline" size="sm" onClick={handleExport}>
+            <Button variant="outline" size="sm" onClick={() => setShowExport(true)}>
               <Download04Icon size={14} className="mr-1.5" />
               {t("prov-export")}
             </Button>
@@ -527,6 +555,68 @@ const ProvidersPage = () => {
       </div>
       )}
 
+      {/* Export Options */}
+      <AlertDialog open={showExport} onOpenChange={setShowExport}>
+        <AlertDialogContent>
+          <AlertDialogHeader>
+            <AlertDialogTitle>{t("prov-export-title")}</AlertDialogTitle>
+            <AlertDialogDescription>{t("prov-export-desc")}</AlertDialogDescription>
+          </AlertDialogHeader>
+          <div className="space-y-2">
+            {["redact", "encrypt", "plain"].map(mode => (
+              <button
+                key={mode}
+                className={`w-full text-left rounded-lg border p-3 transition-colors ${exportMode === mode ? "border-primary/50 bg-primary/5" : "border-border/50 hover:bg-muted/40"}`}
+                onClick={() => setExportMode(mode)}
+              >
+                <p className="text-[12.5px] font-medium">{t(`prov-export-${mode}`)}</p>
+                <p className="text-[11px] text-muted-foreground mt-0.5">{t(`prov-export-${mode}-desc`)}</p>
+              </button>
+            ))}
+            {exportMode === "encrypt" && (
+              <Input
+                type="password"
+                className="h-8 text-xs"
+                placeholder={t("prov-export-passphrase")}
+                value={exportPassphrase}
+                onChange={e => setExportPassphrase(e.target.value)}
+              />
+            )}
+          </div>
+          <AlertDialogFooter>
+            <AlertDialogCancel>{t("cancel")}</AlertDialogCancel>
+            <Button onClick={handleExport} disabled={exportMode === "encrypt" && !exportPassphrase}>
+              <Download04Icon size={14} className="mr-1.5" />
+              {t("prov-export")}
+            </Button>
+          </AlertDialogFooter>
+        </AlertDialogContent>
+      </AlertDialog>
+
+      {/* Encrypted Import */}
+      <AlertDialog open={pendingImport !== null} onOpenChange={open => !open && setPendingImport(null)}>
+        <AlertDialogContent>
+          <AlertDialogHeader>
+            <AlertDialogTitle>{t("prov-import-passphrase-title")}</AlertDialogTitle>
+            <AlertDialogDescription>{t("prov-import-passphrase-desc")}</AlertDialogDescription>
+          </AlertDialogHeader>
+          <Input
+            type="password"
+            className="h-8 text-xs"
+            placeholder={t("prov-export-passphrase")}
+            value={importPassphrase}
+            onChange={e => setImportPassphrase(e.target.value)}
+          />
+          <AlertDialogFooter>
+            <AlertDialogCancel>{t("cancel")}</AlertDialogCancel>
+            <Button onClick={handleEncryptedImport} disabled={!importPassphrase}>
+              <Upload04Icon size={14} className="mr-1.5" />
+              {t("prov-import")}
+            </Button>
+          </AlertDialogFooter>
+        </AlertDialogContent>
+      </AlertDialog>
+
       {/* Delete Confirm */}
       <AlertDialog open={!!providerToDelete} onOpenChange={open => !open && setProviderToDelete(null)}>
         <AlertDialogContent>
@@ -13,8 +13,10 @@ import {
   ComputerIcon,
   Download04Icon,
   CommandLineIcon,
+  Delete02Icon,
 } from "hugeicons-react"
-import { GetSettings, SaveSettings } from "@wailsjs/go/services/SkillsService"
+import { GetSettings, SaveSettings, GetCloneCacheInfo, PruneCloneCache, GetMirrors, MirrorSources, GetMetadataStoreInfo, GetSecretStoreInfo, UnlockSecretVault, SetSecretVaultPassphrase, GetTrustedKeys, AddTrustedKey, RemoveTrustedKey } from "@wailsjs/go/services/SkillsService"
+import { services } from "@wailsjs/go/models"
 import { GetAvailableTerminals } from "@wailsjs/go/services/ProviderService"
 import { GetSupportedAgents } from "@wailsjs/go/services/AgentService"
 import type { AgentInfo } from "@/types"
@@ -41,6 +43,25 @@ const SettingsPage = () => {
   const [showPath, setShowPath] = useState(true)
   const [compactMode, setCompactMode] = useState(false)
   const [terminal, setTerminal] = useState("terminal")
+  const [maxSkillVersions, setMaxSkillVersions] = useState(10)
+  const [cloneCacheTTLDays, setCloneCacheTTLDays] = useState(30)
+  const [cloneCacheMaxSizeMB, setCloneCacheMaxSizeMB] = useState(1024)
+  const [cloneCacheBytes, setCloneCacheBytes] = useState(0)
+  const [offlineMode, setOfflineMode] = useState(false)
+  const [blockSaveOnLintErrors, setBlockSaveOnLintErrors] = useState(false)
+  const [scanBlockThreshold, setScanBlockThreshold] = useState("high")
+  const [atomicBatch, setAtomicBatch] = useState(false)
+  const [storageBackend, setStorageBackend] = useState("json")
+  const [storeRecords, setStoreRecords] = useState(0)
+  const [secretBackend, setSecretBackend] = useState("auto")
+  const [secretInfo, setSecretInfo] = useState<services.SecretStoreInfo | null>(null)
+  const [vaultPassphrase, setVaultPassphrase] = useState("")
+  const [newVaultPassphrase, setNewVaultPassphrase] = useState("")
+  const [mirrorCount, setMirrorCount] = useState(0)
+  const [syncingMirrors, setSyncingMirrors] = useState(false)
+  const [trustedKeys, setTrustedKeys] = useState<services.TrustedKey[]>([])
+  const [newKeyName, setNewKeyName] = useState("")
+  const [newPublicKey, setNewPublicKey] = useState("")
 
   const initialLoadDone = useRef(false)
 
@@ -48,8 +69,26 @@ const SettingsPage = () => {
     loadSettings()
     loadAgents()
     loadTerminals()
+    loadCloneCache()
+    loadMirrors()
+    loadStoreInfo()
+    loadTrustedKeys()
+    loadSecretInfo()
   }, [])
 
+  // 切换存储后端后（等待自动保存完成）刷新记录数，首次切换到数据库时会自动导入 JSON 文件
+  useEffect(() => {
+    if (!initialLoadDone.current) return
+    const timer = setTimeout(loadStoreInfo, 300)
+    return () => clearTimeout(timer)
+  }, [storageBackend])
+
+  useEffect(() => {
+    if (!initialLoadDone.current) return
+    const timer = setTimeout(loadSecretInfo, 300)
+    return () => clearTimeout(timer)
+  }, [secretBackend])
+
   const loadSettings = async () => {
     try {
       setLoading(true)
@@ -63,6 +102,15 @@ const SettingsPage = () => {
         setShowPath(s.showPath !== false)
         setCompactMode(s.compactMode || false)
         setTerminal(s.terminal || "terminal")
+        setMaxSkillVersions(s.maxSkillVersions || 10)
+        setCloneCacheTTLDays(s.cloneCacheTTLDays || 30)
+        setCloneCacheMaxSizeMB(s.cloneCacheMaxSizeMB || 1024)
+        setOfflineMode(s.offlineMode || false)
+        setBlockSaveOnLintErrors(s.blockSaveOnLintErrors || false)
+        setScanBlockThreshold(s.scanBlockThreshold || "high")
+        setAtomicBatch(s.atomicBatch || false)
+        setStorageBackend(s.storageBackend || "json")
+        setSecretBackend(s.secretBackend || "auto")
       }
     } catch {}
     setLoading(false)
@@ -84,11 +132,120 @@ const SettingsPage = () => {
     } catch {}
   }
 
+  const loadCloneCache = async () => {
+    try {
+      const info = await GetCloneCacheInfo()
+      setCloneCacheBytes(info?.totalBytes || 0)
+    } catch {}
+  }
+
+  const handleClearCloneCache = async () => {
+    try {
+      const result = await PruneCloneCache(true)
+      setCloneCacheBytes(result?.remainingBytes || 0)
+      toast({ title: t("toast-clone-cache-cleared", { size: formatMB(result?.freedBytes || 0) }) })
+    } catch (error) {
+      toast({ title: t("toast-clone-cache-clear-failed", { error }), variant: "destructive" })
+    }
+  }
+
+  const loadMirrors = async () => {
+    try {
+      const mirrors = await GetMirrors()
+      setMirrorCount((mirrors || []).length)
+    } catch {}
+  }
+
+  const loadStoreInfo = async () => {
+    try {
+      const info = await GetMetadataStoreInfo()
+      setStoreRecords(info.tags + info.ratings + info.activityLogs + info.metrics)
+    } catch {}
+  }
+
+  const loadSecretInfo = async () => {
+    try {
+      setSecretInfo(await GetSecretStoreInfo())
+    } catch {}
+  }
+
+  const handleUnlockVault = async () => {
+    try {
+      await UnlockSecretVault(vaultPassphrase)
+      setVaultPassphrase("")
+      toast({ title: t("toast-vault-unlocked"), variant: "success" })
+      loadSecretInfo()
+    } catch (error) {
+      toast({ title: t("toast-vault-unlock-failed", { error }), variant: "destructive" })
+    }
+  }
+
+  const handleSetVaultPassphrase = async () => {
+    try {
+      await SetSecretVaultPassphrase(vaultPassphrase, newVaultPassphrase)
+      toast({ title: t(newVaultPassphrase ? "toast-vault-passphrase-set" : "toast-vault-passphrase-removed"), variant: "success" })
+      setVaultPassphrase("")
+      setNewVaultPassphrase("")
+      loadSecretInfo()
+    } catch (error) {
+      toast({ title: t("toast-vault-passphrase-failed", { error }), variant: "destructive" })
+    }
+  }
+
+  const loadTrustedKeys = async () => {
+    try {
+      setTrustedKeys((await GetTrustedKeys()) || [])
+    } catch {}
+  }
+
+  const handleAddTrustedKey = async () => {
+    try {
+      await AddTrustedKey(newKeyName.trim(), newPublicKey)
+      toast({ title: t("toast-trusted-key-added", { name: newKeyName.trim() }), variant: "success" })
+      setNewKeyName("")
+      setNewPublicKey("")
+      loadTrustedKeys()
+    } catch (error) {
+      toast({ title: t("toast-trusted-key-failed", { error }), variant: "destructive" })
+    }
+  }
+
+  const handleRemoveTrustedKey = async (name: string) => {
+    try {
+      await RemoveTrustedKey(name)
+      loadTrustedKeys()
+    } catch (error) {
+      toast({ title: t("toast-trusted-key-failed", { error }), variant: "destructive" })
+    }
+  }
+
+  const handleSyncMirrors = async () => {
+    setSyncingMirrors(true)
+    try {
+      const results = await MirrorSources([])
+      const failed = (results || []).filter((r) => !r.ok)
+      if (failed.length > 0) {
+        toast({ title: t("toast-mirror-sync-partial", { failed: failed.length, total: results.length }), description: failed.map((r) => `${r.source}: ${r.error}`).join("\n"), variant: "destructive" })
+      } else {
+        toast({ title: t("toast-mirror-synced", { count: (results || []).length }) })
+      }
+      loadMirrors()
+      loadCloneCache()
+    } catch (error) {
+      toast({ title: t("toast-mirror-sync-failed", { error }), variant: "destructive" })
+    } finally {
+      setSyncingMirrors(false)
+    }
+  }
+
   // 自动保存 & 即时应用
   const saveSettings = useCallback(async (settings: {
     theme: string; language: string; autoUpdate: boolean;
     updateInterval: number; defaultAgents: string[];
     showPath: boolean; compactMode: boolean; terminal: string;
+    maxSkillVersions: number; cloneCacheTTLDays: number; cloneCacheMaxSizeMB: number;
+    offlineMode: boolean; blockSaveOnLintErrors: boolean; storageBackend: string;
+    secretBackend: string; scanBlockThreshold: string; atomicBatch: boolean;
   }) => {
     try {
       await SaveSettings(JSON.stringify(settings))
@@ -112,8 +269,8 @@ const SettingsPage = () => {
     }
 
     // 自动保存到后端
-    saveSettings({ theme, language, autoUpdate, updateInterval, defaultAgents, showPath, compactMode, terminal })
-  }, [theme, language, autoUpdate, updateInterval, defaultAgents, showPath, compactMode, terminal])
+    saveSettings({ theme, language, autoUpdate, updateInterval, defaultAgents, showPath, compactMode, terminal, maxSkillVersions, cloneCacheTTLDays, cloneCacheMaxSizeMB, offlineMode, blockSaveOnLintErrors, storageBackend, secretBackend, scanBlockThreshold, atomicBatch })
+  }, [theme, language, autoUpdate, updateInterval, defaultAgents, showPath, compactMode, terminal, maxSkillVersions, cloneCacheTTLDays, cloneCacheMaxSizeMB, offlineMode, blockSaveOnLintErrors, storageBackend, secretBackend, scanBlockThreshold, atomicBatch])
 
   const toggleDefaultAgent = (name: string) => {
     setDefaultAgents(prev =>
@@ -250,6 +407,37 @@ const SettingsPage = () => {
                 <span className="text-[13px]">{t("compact-mode")}</span>
                 <Switch checked={compactMode} onCheckedChange={setCompactMode} />
               </div>
+              <div className="flex items-center justify-between p-4">
+                <div>
+                  <span className="text-[13px]">{t("block-save-on-lint-errors")}</span>
+                  <p className="text-[11px] text-muted-foreground mt-0.5">{t("block-save-on-lint-errors-desc")}</p>
+                </div>
+                <Switch checked={blockSaveOnLintErrors} onCheckedChange={setBlockSaveOnLintErrors} />
+              </div>
+              <div className="flex items-center justify-between p-4">
+                <div>
+                  <span className="text-[13px]">{t("atomic-batch")}</span>
+                  <p className="text-[11px] text-muted-foreground mt-0.5">{t("atomic-batch-desc")}</p>
+                </div>
+                <Switch checked={atomicBatch} onCheckedChange={setAtomicBatch} />
+              </div>
+              <div className="flex items-center justify-between p-4">
+                <div>
+                  <span className="text-[13px]">{t("scan-block-threshold")}</span>
+                  <p className="text-[11px] text-muted-foreground mt-0.5">{t("scan-block-threshold-desc")}</p>
+                </div>
+                <select
+                  className="bg-muted/60 border border-border/50 rounded px-2 py-1 text-[12px]"
+                  value={scanBlockThreshold}
+                  onChange={(e) => setScanBlockThreshold(e.target.value)}
+                >
+                  <option value="low">{t("scan-risk-low")}</option>
+                  <option value="medium">{t("scan-risk-medium")}</option>
+                  <option value="high">{t("scan-risk-high")}</option>
+                  <option value="critical">{t("scan-risk-critical")}</option>
+                  <option value="off">{t("scan-block-off")}</option>
+                </select>
+              </div>
             </div>
           </section>
 
@@ -280,6 +468,202 @@ const SettingsPage = () => {
                   </select>
                 </div>
               )}
+              <div className="flex items-center justify-between p-4">
+                <div>
+                  <span className="text-[13px]">{t("max-skill-versions")}</span>
+                  <p className="text-[11px] text-muted-foreground mt-0.5">{t("max-skill-versions-desc")}</p>
+                </div>
+                <select
+                  className="bg-muted/60 border border-border/50 rounded px-2 py-1 text-[12px]"
+                  value={maxSkillVersions}
+                  onChange={(e) => setMaxSkillVersions(Number(e.target.value))}
+                >
+                  <option value={5}>5</option>
+                  <option value={10}>10</option>
+                  <option value={20}>20</option>
+                  <option value={50}>50</option>
+                </select>
+              </div>
+              <div className="flex items-center justify-between p-4">
+                <div>
+                  <span className="text-[13px]">{t("clone-cache")}</span>
+                  <p className="text-[11px] text-muted-foreground mt-0.5">{t("clone-cache-desc", { size: formatMB(cloneCacheBytes) })}</p>
+                </div>
+                <div className="flex items-center gap-2">
+                  <select
+                    className="bg-muted/60 border border-border/50 rounded px-2 py-1 text-[12px]"
+                    value={cloneCacheTTLDays}
+                    onChange={(e) => setCloneCacheTTLDays(Number(e.target.value))}
+                  >
+                    <option value={7}>{t("days-count", { count: 7 })}</option>
+                    <option value={30}>{t("days-count", { count: 30 })}</option>
+                    <option value={90}>{t("days-count", { count: 90 })}</option>
+                  </select>
+                  <select
+                    className="bg-muted/60 border border-border/50 rounded px-2 py-1 text-[12px]"
+                    value={cloneCacheMaxSizeMB}
+                    onChange={(e) => setCloneCacheMaxSizeMB(Number(e.target.value))}
+                  >
+                    <option value={256}>256 MB</option>
+                    <option value={1024}>1 GB</option>
+                    <option value={4096}>4 GB</option>
+                  </select>
+                  <button
+                    className="flex items-center gap-1 text-[11px] text-muted-foreground hover:text-destructive transition-colors"
+                    onClick={handleClearCloneCache}
+                  >
+                    <Delete02Icon size={13} />
+                    {t("clear-all")}
+                  </button>
+                </div>
+              </div>
+              <div className="flex items-center justify-between p-4">
+                <div>
+                  <span className="text-[13px]">{t("offline-mode")}</span>
+                  <p className="text-[11px] text-muted-foreground mt-0.5">{t("offline-mode-desc", { count: mirrorCount })}</p>
+                </div>
+                <div className="flex items-center gap-3">
+                  <button
+                    className="flex items-center gap-1 text-[11px] text-primary hover:text-primary/80 transition-colors disabled:opacity-50"
+                    onClick={handleSyncMirrors}
+                    disabled={syncingMirrors}
+                  >
+                    <RefreshIcon size={13} className={syncingMirrors ? "animate-spin" : ""} />
+                    {t("sync-mirrors")}
+                  </button>
+                  <Switch checked={offlineMode} onCheckedChange={setOfflineMode} />
+                </div>
+              </div>
+              <div className="flex items-center justify-between p-4">
+                <div>
+                  <span className="text-[13px]">{t("storage-backend")}</span>
+                  <p className="text-[11px] text-muted-foreground mt-0.5">{t("storage-backend-desc", { count: storeRecords })}</p>
+                </div>
+                <div className="flex items-center gap-2">
+                  <button
+                    className={`px-3 py-1.5 rounded text-[12px] transition-colors ${storageBackend === "json" ? "bg-primary/10 text-primary font-medium" : "bg-muted/60 text-muted-foreground hover:text-foreground"}`}
+                    onClick={() => setStorageBackend("json")}
+                  >
+                    {t("storage-backend-json")}
+                  </button>
+                  <button
+                    className={`px-3 py-1.5 rounded text-[12px] transition-colors ${storageBackend === "bolt" ? "bg-primary/10 text-primary font-medium" : "bg-muted/60 text-muted-foreground hover:text-foreground"}`}
+                    onClick={() => setStorageBackend("bolt")}
+                  >
+                    {t("storage-backend-bolt")}
+                  </button>
+                </div>
+              </div>
+              <div className="flex items-center justify-between p-4">
+                <div>
+                  <span className="text-[13px]">{t("secret-backend")}</span>
+                  <p className="text-[11px] text-muted-foreground mt-0.5">{t("secret-backend-desc", { backend: secretInfo ? t(`secret-backend-${secretInfo.backend}`) : "" })}</p>
+                </div>
+                <div className="flex items-center gap-2">
+                  {["auto", "keyring", "vault"].map((b) => (
+                    <button
+                      key={b}
+                      className={`px-3 py-1.5 rounded text-[12px] transition-colors disabled:opacity-50 ${secretBackend === b ? "bg-primary/10 text-primary font-medium" : "bg-muted/60 text-muted-foreground hover:text-foreground"}`}
+                      onClick={() => setSecretBackend(b)}
+                      disabled={b === "keyring" && !!secretInfo && !secretInfo.availableBackends.includes("keyring")}
+                    >
+                      {t(`secret-backend-${b}`)}
+                    </button>
+                  ))}
+                </div>
+              </div>
+              <div className="flex items-center justify-between p-4">
+                <div>
+                  <span className="text-[13px]">{t("vault-passphrase")}</span>
+                  <p className="text-[11px] text-muted-foreground mt-0.5">
+                    {secretInfo?.locked ? t("vault-locked-desc") : secretInfo?.passphraseProtected ? t("vault-passphrase-desc") : t("vault-keyfile-desc")}
+                  </p>
+                </div>
+                <div className="flex items-center gap-2">
+                  {(secretInfo?.passphraseProtected || secretInfo?.locked) && (
+                    <input
+                      type="password"
+                      className="w-32 bg-muted/60 border border-border/50 rounded px-2 py-1 text-[12px]"
+                      placeholder={t("vault-current-passphrase")}
+                      value={vaultPassphrase}
+                      onChange={(e) => setVaultPassphrase(e.target.value)}
+                    />
+                  )}
+                  {secretInfo?.locked ? (
+                    <button
+                      className="text-[11px] text-primary hover:text-primary/80 transition-colors disabled:opacity-50"
+                      onClick={handleUnlockVault}
+                      disabled={!vaultPassphrase}
+                    >
+                      {t("vault-unlock")}
+                    </button>
+                  ) : (
+                    <>
+                      <input
+                        type="password"
+                        className="w-32 bg-muted/60 border border-border/50 rounded px-2 py-1 text-[12px]"
+                        placeholder={t("vault-new-passphrase")}
+                        value={newVaultPassphrase}
+                        onChange={(e) => setNewVaultPassphrase(e.target.value)}
+                      />
+                      <button
+                        className="text-[11px] text-primary hover:text-primary/80 transition-colors disabled:opacity-50"
+                        onClick={handleSetVaultPassphrase}
+                        disabled={!newVaultPassphrase && !secretInfo?.passphraseProtected}
+                      >
+                        {newVaultPassphrase || !secretInfo?.passphraseProtected ? t("vault-set-passphrase") : t("vault-remove-passphrase")}
+                      </button>
+                    </>
+                  )}
+                </div>
+              </div>
+            </div>
+          </section>
+
+          {/* Trusted signing keys */}
+          <section className="space-y-4">
+            <div>
+              <h2 className="text-[14px] font-semibold text-foreground/80">{t("trusted-keys")}</h2>
+              <p className="text-[11px] text-muted-foreground mt-0.5">{t("trusted-keys-desc")}</p>
+            </div>
+
+            <div className="rounded-lg border border-border/50 divide-y divide-border/50">
+              {trustedKeys.map((key) => (
+                <div key={key.name} className="flex items-center justify-between p-4 gap-3">
+                  <div className="flex items-center gap-2 min-w-0">
+                    <span className="text-[13px]">{key.name}</span>
+                    <Badge variant="outline" className="text-[10px]">{key.format}</Badge>
+                    <code className="text-[11px] text-muted-foreground font-mono truncate">{key.keyId}</code>
+                  </div>
+                  <button
+                    className="text-muted-foreground hover:text-destructive transition-colors"
+                    onClick={() => handleRemoveTrustedKey(key.name)}
+                  >
+                    <Delete02Icon size={14} />
+                  </button>
+                </div>
+              ))}
+              <div className="flex items-center gap-2 p-4">
+                <input
+                  className="w-32 bg-muted/60 border border-border/50 rounded px-2 py-1 text-[12px]"
+                  placeholder={t("trusted-key-name")}
+                  value={newKeyName}
+                  onChange={(e) => setNewKeyName(e.target.value)}
+                />
+                <input
+                  className="flex-1 bg-muted/60 border border-border/50 rounded px-2 py-1 text-[12px] font-mono"
+                  placeholder={t("trusted-key-placeholder")}
+                  value={newPublicKey}
+                  onChange={(e) => setNewPublicKey(e.target.value)}
+                />
+                <button
+                  className="text-[11px] text-primary hover:text-primary/80 transition-colors disabled:opacity-50"
+                  onClick={handleAddTrustedKey}
+                  disabled={!newKeyName.trim() || !newPublicKey.trim()}
+                >
+                  {t("trusted-key-add")}
+                </button>
+              </div>
             </div>
           </section>
 
@@ -348,4 +732,6 @@ const SettingsPage = () => {
   )
 }
 
+const formatMB = (bytes: number) => `${(bytes / 1024 / 1024).toFixed(1)} MB`
+
 export default SettingsPage
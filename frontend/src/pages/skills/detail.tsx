@@ -1,4 +1,4 @@
-import { useState, useEffect, useMemo } from "react"
+import { useState, useEffect } from "react"
 import { useSearchParams, useNavigate } from "react-router-dom"
 import { useTranslation } from "react-i18next"
 import { Button } from "@/components/ui/button"
@@ -42,13 +42,19 @@ import {
   FavouriteIcon,
   SourceCodeIcon,
   ArrowDown01Icon,
+  UndoIcon,
+  InformationCircleIcon,
+  AlertCircleIcon,
+  Clock01Icon,
 } from "hugeicons-react"
-import { GetSkillDetail, DeleteSkill, UpdateSkill, GetSkillAgentLinks, UpdateSkillAgentLinks, GetSkillDiff, GetSkillTags, GetFavorites, ToggleFavorite, GetAvailableEditors, OpenSkillInEditor, GetSkillFiles } from "@wailsjs/go/services/SkillsService"
+import { GetSkillDetail, DeleteSkill, UpdateSkillWithOptions, GetSkillLocalChanges, AcceptSkillContent, RollbackSkill, ListSkillVersions, GetSkillVersionContent, DiffSkillVersion, RestoreSkillVersion, GetSkillAgentLinks, UpdateSkillAgentLinks, GetSkillDiff, GetSkillTags, GetFavorites, ToggleFavorite, GetAvailableEditors, OpenSkillInEditor, GetSkillFiles } from "@wailsjs/go/services/SkillsService"
+import { services } from "@wailsjs/go/models"
 import { GetSupportedAgents } from "@wailsjs/go/services/AgentService"
 import { BrowserOpenURL } from "@wailsjs/runtime/runtime"
 import Markdown from "react-markdown"
-import { diffLines } from "diff"
 import ConfigAgentLinkDialog from "@/components/ConfigAgentLinkDialog"
+import TreeDiffView from "@/components/TreeDiffView"
+import LocalChangesDialog, { type LocalChangesChoice } from "@/components/LocalChangesDialog"
 import TagManager from "@/components/TagManager"
 import type { AgentInfo } from "@/types"
 
@@ -60,11 +66,22 @@ interface SkillDetailData {
   framework: string
   agents: string[]
   source: string
+  ref: string
+  commitSha: string
+  signature?: services.SkillSignature
+  canRollback: boolean
   content: string
   installedAt: string
   updatedAt: string
 }
 
+const signatureClass: Record<string, string> = {
+  verified: "text-emerald-600 dark:text-emerald-400 border-emerald-500/30",
+  untrusted: "text-amber-600 dark:text-amber-400 border-amber-500/30",
+  invalid: "text-destructive border-destructive/30",
+  unsigned: "text-muted-foreground",
+}
+
 const SkillDetailPage = () => {
   const { t } = useTranslation()
   const [searchParams] = useSearchParams()
@@ -74,6 +91,8 @@ const SkillDetailPage = () => {
   const [detail, setDetail] = useState<SkillDetailData | null>(null)
   const [loading, setLoading] = useState(true)
   const [updating, setUpdating] = useState(false)
+  const [rollingBack, setRollingBack] = useState(false)
+  const [localChanges, setLocalChanges] = useState<services.SkillVerifyResult | null>(null)
   const [showDeleteDialog, setShowDeleteDialog] = useState(false)
   const [deleting, setDeleting] = useState(false)
   const [configDialogOpen, setConfigDialogOpen] = useState(false)
@@ -86,9 +105,18 @@ const SkillDetailPage = () => {
 
   // Diff preview
   const [showDiffDialog, setShowDiffDialog] = useState(false)
-  const [diffData, setDiffData] = useState<{ localContent: string; remoteContent: string; hasChanges: boolean } | null>(null)
+  const [diffData, setDiffData] = useState<services.SkillDiff | null>(null)
   const [loadingDiff, setLoadingDiff] = useState(false)
 
+  // Version history
+  const [showHistoryDialog, setShowHistoryDialog] = useState(false)
+  const [versions, setVersions] = useState<services.SkillVersion[]>([])
+  const [selectedVersion, setSelectedVersion] = useState<string | null>(null)
+  const [versionContent, setVersionContent] = useState("")
+  const [versionDiff, setVersionDiff] = useState<services.TreeDiff | null>(null)
+  const [versionView, setVersionView] = useState<"content" | "diff">("content")
+  const [restoring, setRestoring] = useState(false)
+
   // Favorites
   const [isFavorite, setIsFavorite] = useState(false)
 
@@ -153,11 +181,37 @@ const SkillDetailPage = () => {
   }
 
   const handleUpdate = async () => {
+    if (!skillName) return
+    // 存在本地修改时先让用户选择覆盖或合并
+    try {
+      const changes = await GetSkillLocalChanges(skillName)
+      if (changes?.status === "modified" || changes?.status === "unverified") {
+        setLocalChanges(changes)
+        return
+      }
+    } catch {}
+    await runUpdate()
+  }
+
+  const handleLocalChangesChoice = async (choice: LocalChangesChoice) => {
+    setLocalChanges(null)
+    await runUpdate(choice)
+  }
+
+  const runUpdate = async (choice?: LocalChangesChoice) => {
     if (!skillName) return
     try {
       setUpdating(true)
-      await UpdateSkill(skillName)
-      toast({ title: t("toast-skill-updated", { name: skillName }), variant: "success" })
+      // 确认当前内容未修改：先记录内容哈希，再按无本地修改正常更新
+      if (choice === "accept") await AcceptSkillContent(skillName)
+      const result = await UpdateSkillWithOptions(skillName, { float: false, force: choice === "force", merge: choice === "merge", allowRisk: false })
+      if (result?.status === "conflict") {
+        toast({ title: t("toast-skill-merge-conflict", { name: skillName, files: (result.conflictFiles || []).join(", ") }), variant: "destructive" })
+      } else if (result?.status === "merged") {
+        toast({ title: t("toast-skill-merged", { name: skillName }), variant: "success" })
+      } else {
+        toast({ title: t("toast-skill-updated", { name: skillName }), variant: "success" })
+      }
       await loadDetail(skillName)
     } catch (error) {
       toast({ title: t("toast-update-failed", { error }), variant: "destructive" })
@@ -166,6 +220,20 @@ const SkillDetailPage = () => {
     }
   }
 
+  const handleRollback = async () => {
+    if (!skillName) return
+    try {
+      setRollingBack(true)
+      await RollbackSkill(skillName)
+      toast({ title: t("toast-skill-rolled-back", { name: skillName }), variant: "success" })
+      await loadDetail(skillName)
+    } catch (error) {
+      toast({ title: t("toast-rollback-failed", { error }), variant: "destructive" })
+    } finally {
+      setRollingBack(false)
+    }
+  }
+
   const handleDelete = async () => {
     if (!skillName) return
     try {
@@ -220,6 +288,50 @@ const SkillDetailPage = () => {
     }
   }
 
+  const handleOpenHistory = async () => {
+    if (!skillName) return
+    setShowHistoryDialog(true)
+    try {
+      const list = await ListSkillVersions(skillName)
+      setVersions(list || [])
+      if (list && list.length > 0) {
+        await handleSelectVersion(list[0].id)
+      }
+    } catch (error) {
+      toast({ title: t("version-history-load-failed", { error }), variant: "destructive" })
+    }
+  }
+
+  const handleSelectVersion = async (id: string) => {
+    if (!skillName) return
+    setSelectedVersion(id)
+    try {
+      setVersionContent(await GetSkillVersionContent(skillName, id))
+    } catch {
+      setVersionContent("")
+    }
+    try {
+      setVersionDiff(await DiffSkillVersion(skillName, id))
+    } catch {
+      setVersionDiff(null)
+    }
+  }
+
+  const handleRestoreVersion = async () => {
+    if (!skillName || !selectedVersion) return
+    try {
+      setRestoring(true)
+      await RestoreSkillVersion(skillName, selectedVersion)
+      toast({ title: t("toast-version-restored", { name: skillName }), variant: "success" })
+      setShowHistoryDialog(false)
+      await loadDetail(skillName)
+    } catch (error) {
+      toast({ title: t("toast-restore-version-failed", { error }), variant: "destructive" })
+    } finally {
+      setRestoring(false)
+    }
+  }
+
   const formatDate = (dateStr: string) => {
     if (!dateStr) return "-"
     try {
@@ -302,6 +414,31 @@ const SkillDetailPage = () => {
                   {detail.source}
                 </Badge>
               )}
+              {detail.ref && (
+                <Badge variant="secondary" className="text-xs font-mono" title={detail.commitSha}>
+                  #{detail.ref}
+                </Badge>
+              )}
+              {detail.signature && (
+                <Badge
+                  variant="outline"
+                  className={`text-xs ${signatureClass[detail.signature.status] || ""}`}
+                  title={[detail.signature.keyId, detail.signature.error].filter(Boolean).join("\n")}
+                >
+                  {detail.signature.status === "verified"
+                    ? t("signature-verified", { signer: detail.signature.signer })
+                    : t(`signature-${detail.signature.status}`)}
+                </Badge>
+              )}
+              {detail.manifest?.version && (
+                <Badge variant="secondary" className="text-xs font-mono">v{detail.manifest.version}</Badge>
+              )}
+              {detail.manifest?.license && (
+                <Badge variant="outline" className="text-xs">{detail.manifest.license}</Badge>
+              )}
+              {detail.manifest?.tags?.map((tag) => (
+                <Badge key={tag} variant="outline" className="text-xs text-muted-foreground">#{tag}</Badge>
+              ))}
             </div>
             <div className="mt-2">
               <TagManager skillName={detail.name} tags={tags} onTagsChange={setTags} compact />
@@ -357,6 +494,16 @@ const SkillDetailPage = () => {
             <RefreshIcon size={13} className={`mr-1 ${updating ? "animate-spin" : ""}`} />
             {t("update")}
           </Button>
+          <Button variant="outline" size="sm" className="h-7 text-[12px]" onClick={handleOpenHistory}>
+            <Clock01Icon size={13} className="mr-1" />
+            {t("version-history")}
+          </Button>
+          {detail.canRollback && (
+            <Button variant="outline" size="sm" className="h-7 text-[12px]" onClick={handleRollback} disabled={rollingBack}>
+              <UndoIcon size={13} className="mr-1" />
+              {t("rollback")}
+            </Button>
+          )}
           <Button variant="outline" size="sm" className="h-7 text-[12px] text-destructive hover:text-destructive hover:bg-destructive/10" onClick={() => setShowDeleteDialog(true)}>
             <Delete02Icon size={13} className="mr-1" />
             {t("delete")}
@@ -407,6 +554,44 @@ const SkillDetailPage = () => {
                 <p className="text-xs text-amber-500">{t("no-agent-linked")}</p>
               )}
             </div>
+
+            {/* Manifest */}
+            {detail.manifestError && (
+              <div className="rounded-lg border border-amber-500/40 bg-amber-500/5 p-3.5 flex items-start gap-2">
+                <AlertCircleIcon size={14} className="text-amber-500 shrink-0 mt-0.5" />
+                <div className="min-w-0">
+                  <p className="text-xs font-medium text-amber-600 dark:text-amber-400">{t("manifest-invalid")}</p>
+                  <p className="text-[11px] text-muted-foreground font-mono break-all mt-0.5">{detail.manifestError}</p>
+                </div>
+              </div>
+            )}
+            {detail.manifest && (
+              (detail.manifest.allowedTools?.length || detail.manifest.dependencies?.length ||
+                detail.manifest.conflicts?.length || detail.manifest.compatibleAgents?.length) ? (
+                <div className="rounded-lg border border-border/50 p-3.5 space-y-2">
+                  <div className="flex items-center gap-2 text-muted-foreground">
+                    <InformationCircleIcon size={14} />
+                    <span className="text-[11px] font-medium uppercase tracking-wide">{t("manifest")}</span>
+                  </div>
+                  {detail.manifest.allowedTools?.length > 0 && (
+                    <ManifestRow label={t("manifest-allowed-tools")} items={detail.manifest.allowedTools} mono />
+                  )}
+                  {detail.manifest.dependencies?.length > 0 && (
+                    <ManifestRow
+                      label={t("manifest-dependencies")}
+                      items={detail.manifest.dependencies.map((d) =>
+                        `${d.name}${d.version ? `@${d.version}` : ""}${d.optional ? ` (${t("manifest-optional")})` : ""}`)}
+                    />
+                  )}
+                  {detail.manifest.conflicts?.length > 0 && (
+                    <ManifestRow label={t("manifest-conflicts")} items={detail.manifest.conflicts} />
+                  )}
+                  {detail.manifest.compatibleAgents?.length > 0 && (
+                    <ManifestRow label={t("manifest-compatible-agents")} items={detail.manifest.compatibleAgents} />
+                  )}
+                </div>
+              ) : null
+            )}
           </div>
 
           {/* Path */}
@@ -511,7 +696,7 @@ const SkillDetailPage = () => {
             </div>
           ) : diffData ? (
             diffData.hasChanges ? (
-              <DiffView localContent={diffData.localContent} remoteContent={diffData.remoteContent} />
+              <TreeDiffView files={diffData.files || []} additions={diffData.additions} deletions={diffData.deletions} />
             ) : (
               <div className="rounded-lg border border-emerald-500/20 bg-emerald-500/5 p-8 text-center">
                 <p className="text-sm font-medium text-emerald-600 dark:text-emerald-400">{t("no-changes")}</p>
@@ -520,89 +705,70 @@ const SkillDetailPage = () => {
           ) : null}
         </DialogContent>
       </Dialog>
-    </div>
-  )
-}
 
-// Side-by-side diff view with line-level highlighting
-type DiffLine = { lineNum: number; text: string; type: "equal" | "removed" | "added" }
-
-function DiffView({ localContent, remoteContent }: { localContent: string; remoteContent: string }) {
-  const { leftLines, rightLines } = useMemo(() => {
-    const changes = diffLines(localContent, remoteContent)
-    const left: DiffLine[] = []
-    const right: DiffLine[] = []
-    let oldNum = 1
-    let newNum = 1
-
-    for (const change of changes) {
-      const texts = change.value.replace(/\n$/, "").split("\n")
-      if (change.removed) {
-        for (const t of texts) {
-          left.push({ lineNum: oldNum++, text: t, type: "removed" })
-          right.push({ lineNum: -1, text: "", type: "removed" })
-        }
-      } else if (change.added) {
-        for (const t of texts) {
-          left.push({ lineNum: -1, text: "", type: "added" })
-          right.push({ lineNum: newNum++, text: t, type: "added" })
-        }
-      } else {
-        for (const t of texts) {
-          left.push({ lineNum: oldNum++, text: t, type: "equal" })
-          right.push({ lineNum: newNum++, text: t, type: "equal" })
-        }
-      }
-    }
-    return { leftLines: left, rightLines: right }
-  }, [localContent, remoteContent])
-
-  const DiffPanel = ({ lines, side }: { lines: DiffLine[]; side: "left" | "right" }) => (
-    <div className="flex-1 min-w-0 overflow-x-auto">
-      <table className="w-full text-[11px] font-mono border-collapse">
-        <tbody>
-          {lines.map((line, i) => {
-            const isEmpty = line.lineNum === -1
-            const isChanged = side === "left" ? line.type === "removed" : line.type === "added"
-            return (
-              <tr
-                key={i}
-                className={
-                  isChanged
-                    ? side === "left" ? "bg-red-500/10" : "bg-emerald-500/10"
-                    : isEmpty ? "bg-muted/30" : ""
-                }
-              >
-                <td className="select-none text-right px-1.5 py-0 text-muted-foreground/40 w-[1%] whitespace-nowrap text-[10px]">
-                  {isEmpty ? "" : line.lineNum}
-                </td>
-                <td className="px-2 py-0 whitespace-pre-wrap break-all">
-                  {isEmpty ? "" : line.text}
-                </td>
-              </tr>
-            )
-          })}
-        </tbody>
-      </table>
-    </div>
-  )
+      <LocalChangesDialog
+        open={localChanges !== null}
+        onOpenChange={(open) => { if (!open) setLocalChanges(null) }}
+        changes={localChanges}
+        onChoose={handleLocalChangesChoice}
+      />
 
-  return (
-    <div className="flex-1 overflow-y-auto">
-      <div className="grid grid-cols-2 gap-0 border border-border/50 rounded overflow-hidden">
-        <div className="flex flex-col min-w-0">
-          <div className="px-3 py-1.5 bg-red-500/10 border-b border-border/50 text-[11px] font-medium text-red-600 dark:text-red-400 sticky top-0 z-10">
-            Local
-          </div>
-          <DiffPanel lines={leftLines} side="left" />
-        </div>
-        <div className="flex flex-col min-w-0 border-l border-border/50">
-          <div className="px-3 py-1.5 bg-emerald-500/10 border-b border-border/50 text-[11px] font-medium text-emerald-600 dark:text-emerald-400 sticky top-0 z-10">
-            Remote
-          </div>
-          <DiffPanel lines={rightLines} side="right" />
-        </div>
-      </div>
+      {/* Version history dialog */}
+      <Dialog open={showHistoryDialog} onOpenChange={(open) => { setShowHistoryDialog(open); if (!open) { setVersions([]); setSelectedVersion(null); setVersionContent(""); setVersionDiff(null) } }}>
+        <DialogContent className="max-w-4xl max-h-[80vh] flex flex-col overflow-hidden">
+          <DialogHeader>
+            <DialogTitle>{t("version-history")}</DialogTitle>
+            <DialogDescription>{t("version-history-desc")}</DialogDescription>
+          </DialogHeader>
+          {versions.length === 0 ? (
+            <div className="rounded-lg border border-border/50 p-8 text-center">
+              <p className="text-sm text-muted-foreground">{t("no-versions")}</p>
+            </div>
+          ) : (
+            <div className="flex gap-3 min-h-0 flex-1">
+              <div className="w-56 shrink-0 overflow-y-auto space-y-1">
+                {versions.map((v) => (
+                  <button
+                    key={v.id}
+                    className={`w-full text-left rounded-md px-2.5 py-2 text-[12px] transition-colors ${selectedVersion === v.id ? "bg-accent" : "hover:bg-accent/50"}`}
+                    onClick={() => handleSelectVersion(v.id)}
+                  >
+                    <div className="font-medium">{formatDate(v.createdAt)}</div>
+                    <div className="text-[11px] text-muted-foreground">
+                      {t(`version-reason-${v.reason}`)} · {v.fileCount} {t("files")}
+                    </div>
+                  </button>
+                ))}
+              </div>
+              <div className="flex-1 min-w-0 flex flex-col gap-2">
+                {versionView === "content" ? (
+                  <pre className="flex-1 overflow-auto rounded-lg border border-border/50 bg-muted/30 p-3 text-[12px] font-mono whitespace-pre-wrap">{versionContent}</pre>
+                ) : versionDiff && versionDiff.hasChanges ? (
+                  <TreeDiffView files={versionDiff.files || []} additions={versionDiff.additions} deletions={versionDiff.deletions} />
+                ) : (
+                  <div className="flex-1 rounded-lg border border-emerald-500/20 bg-emerald-500/5 p-8 text-center">
+                    <p className="text-sm font-medium text-emerald-600 dark:text-emerald-400">{t("version-same-as-current")}</p>
+                  </div>
+                )}
+                <div className="flex justify-between">
+                  <div className="flex gap-1">
+                    <Button variant={versionView === "content" ? "secondary" : "ghost"} size="sm" className="h-7 text-[12px]" onClick={() => setVersionView("content")}>
+                      {t("version-view-content")}
+                    </Button>
+                    <Button variant={versionView === "diff" ? "secondary" : "ghost"} size="sm" className="h-7 text-[12px]" onClick={() => setVersionView("diff")}>
+                      {t("version-view-diff")}
+                    </Button>
+                  </div>
+                  <Button size="sm" className="h-7 text-[12px]" onClick={handleRestoreVersion} disabled={!selectedVersion || restoring}>
+                    <UndoIcon size={13} className="mr-1" />
+                    {t("restore-version")}
+                  </Button>
+                </div>
+              </div>
+            </div>
+          )}
+        </DialogContent>
+      </Dialog>
     </div>
   )
 }
@@ -636,4 +802,15 @@ const EditorIcon = ({ icon, iconBase64 }: { icon: string; iconBase64?: string })
   )
 }
 
+const ManifestRow = ({ label, items, mono }: { label: string; items: string[]; mono?: boolean }) => (
+  <div className="flex items-start gap-3">
+    <span className="text-[11px] text-muted-foreground w-24 shrink-0 pt-0.5">{label}</span>
+    <div className="flex flex-wrap gap-1.5">
+      {items.map((item) => (
+        <Badge key={item} variant="secondary" className={`text-[11px] ${mono ? "font-mono" : ""}`}>{item}</Badge>
+      ))}
+    </div>
+  </div>
+)
+
 export default SkillDetailPage
@@ -40,9 +40,12 @@ import {
   MoreHorizontalIcon,
   SidebarLeft01Icon,
   TextAlignLeftIcon,
+  AlertCircleIcon,
+  CheckmarkCircle02Icon,
 } from "hugeicons-react"
 import { Panel, Group as PanelGroup, Separator as ResizeHandle } from "react-resizable-panels"
-import { GetSkillDetail, SaveSkillContent } from "@wailsjs/go/services/SkillsService"
+import { GetSkillDetail, SaveSkillContent, LintSkillContent } from "@wailsjs/go/services/SkillsService"
+import { services } from "@wailsjs/go/models"
 import Markdown from "react-markdown"
 
 // Frontmatter parser
@@ -182,6 +185,17 @@ const SkillEditPage = () => {
   const [cursorLine, setCursorLine] = useState(1)
   const [cursorCol, setCursorCol] = useState(1)
 
+  // SKILL.md lint diagnostics (debounced)
+  const [lint, setLint] = useState<services.LintResult | null>(null)
+  const [showLint, setShowLint] = useState(false)
+  useEffect(() => {
+    if (loading) return
+    const timer = setTimeout(() => {
+      LintSkillContent(buildContent(meta, body)).then(setLint).catch(() => setLint(null))
+    }, 500)
+    return () => clearTimeout(timer)
+  }, [meta, body, loading])
+
   const handleSave = useCallback(async () => {
     if (!skillName || savingRef.current) return
     try {
@@ -664,11 +678,46 @@ const SkillEditPage = () => {
         )}
       </div>
 
+      {/* Lint diagnostics */}
+      {showLint && lint && lint.diagnostics?.length > 0 && (
+        <div className="shrink-0 max-h-40 overflow-y-auto border-t border-border/60 bg-muted/10 px-3 py-1.5 space-y-0.5">
+          {lint.diagnostics.map((d, i) => (
+            <div key={i} className="flex items-start gap-2 text-[11px]">
+              <AlertCircleIcon
+                size={12}
+                className={`shrink-0 mt-0.5 ${d.severity === "error" ? "text-destructive" : "text-amber-500"}`}
+              />
+              <span className="font-mono text-muted-foreground shrink-0">{d.line}:{d.column}</span>
+              <span className="flex-1">{d.message}</span>
+              <span className="font-mono text-muted-foreground/60 shrink-0">{d.rule}</span>
+            </div>
+          ))}
+        </div>
+      )}
+
       {/* Status bar */}
       <div className="shrink-0 h-6 border-t border-border/60 bg-muted/20 flex items-center justify-between px-3 text-[10px] text-muted-foreground">
         <div className="flex items-center gap-3">
           <span>{t("editor-lines", { count: lineCount })}</span>
           <span>{t("editor-cursor", { line: cursorLine, col: cursorCol })}</span>
+          {lint && (
+            <button
+              className="flex items-center gap-1 hover:text-foreground transition-colors"
+              onClick={() => setShowLint(v => !v)}
+            >
+              {lint.errors === 0 && lint.warnings === 0 ? (
+                <>
+                  <CheckmarkCircle02Icon size={11} className="text-emerald-500" />
+                  {t("lint-ok")}
+                </>
+              ) : (
+                <>
+                  <AlertCircleIcon size={11} className={lint.errors > 0 ? "text-destructive" : "text-amber-500"} />
+                  {t("lint-summary", { errors: lint.errors, warnings: lint.warnings })}
+                </>
+              )}
+            </button>
+          )}
         </div>
         <div className="flex items-center gap-3">
           <span>SKILL.md</span>
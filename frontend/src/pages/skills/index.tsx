@@ -22,12 +22,17 @@ import {
   AlertDialogTitle,
 } from "@/components/ui/alert-dialog"
 import { Search01Icon, Folder01Icon, Add01Icon, CheckListIcon, Cancel01Icon, Delete02Icon, Settings02Icon, MultiplicationSignIcon, RefreshIcon, ArrowUp02Icon, Stethoscope02Icon, Tag01Icon } from "hugeicons-react"
-import { GetAllAgentSkills, InstallRemoteSkill, DeleteSkill, UpdateSkill, GetSkillAgentLinks, UpdateSkillAgentLinks, BatchDeleteSkills, BatchUpdateSkillAgentLinks, CheckSkillUpdates, GetAllSkillTagsMap, GetFavorites, ToggleFavorite } from "@wailsjs/go/services/SkillsService"
+import { GetAllAgentSkills, InstallRemoteSkillWithOptions, ScanRemoteSkill, DeleteSkill, UpdateSkill, UpdateSkillWithOptions, GetSkillLocalChanges, AcceptSkillContent, GetSkillAgentLinks, UpdateSkillAgentLinks, BatchDeleteSkillsWithOptions, PlanBatchDeleteSkills, GetSettings, BatchUpdateSkillAgentLinks, CheckSkillUpdates, GetAllSkillTagsMap, GetFavorites, ToggleFavorite } from "@wailsjs/go/services/SkillsService"
 import { GetSupportedAgents } from "@wailsjs/go/services/AgentService"
 import { useSearchParams } from "react-router-dom"
 import RemoteSkillSearch, { type RemoteSkill } from "@/components/RemoteSkillSearch"
 import SkillCard from "@/components/SkillCard"
 import ConfigAgentLinkDialog from "@/components/ConfigAgentLinkDialog"
+import LocalChangesDialog, { type LocalChangesChoice } from "@/components/LocalChangesDialog"
+import ScanReportDialog from "@/components/ScanReportDialog"
+import ChangePlanDialog from "@/components/ChangePlanDialog"
+import BatchResultDialog from "@/components/BatchResultDialog"
+import { services } from "@wailsjs/go/models"
 import type { AgentInfo, SkillData } from "@/types"
 
 const SkillsPage = () => {
@@ -40,6 +45,8 @@ const SkillsPage = () => {
   const [showInstallDialog, setShowInstallDialog] = useState(false)
   const [installingSkill, setInstallingSkill] = useState<string | null>(null)
   const [updatingSkill, setUpdatingSkill] = useState<string | null>(null)
+  const [localChanges, setLocalChanges] = useState<services.SkillVerifyResult | null>(null)
+  const [blockedInstall, setBlockedInstall] = useState<{ fullName: string; agents: string[]; report: services.SkillScanReport } | null>(null)
   const [deletingSkill, setDeletingSkill] = useState<string | null>(null)
   const [skillToDelete, setSkillToDelete] = useState<string | null>(null)
   const [allAgents, setAllAgents] = useState<AgentInfo[]>([])
@@ -50,7 +57,8 @@ const SkillsPage = () => {
   const [batchMode, setBatchMode] = useState(false)
   const [selectedSkills, setSelectedSkills] = useState<Set<string>>(new Set())
   const [batchDeleting, setBatchDeleting] = useState(false)
-  const [showBatchDeleteDialog, setShowBatchDeleteDialog] = useState(false)
+  const [batchDeletePlan, setBatchDeletePlan] = useState<services.ChangePlan | null>(null)
+  const [batchResult, setBatchResult] = useState<services.BatchResult | null>(null)
   const [batchConfigOpen, setBatchConfigOpen] = useState(false)
 
   // Update check
@@ -206,10 +214,10 @@ const SkillsPage = () => {
     await doInstallSkill(fullName, agents.map(a => a.name))
   }
 
-  const doInstallSkill = async (fullName: string, agents: string[]) => {
+  const doInstallSkill = async (fullName: string, agents: string[], allowRisk = false) => {
     try {
       setInstallingSkill(fullName)
-      await InstallRemoteSkill(fullName, agents)
+      await InstallRemoteSkillWithOptions(fullName, agents, { allowRisk })
       toast({ title: t("toast-skill-installed", { name: fullName.split('@')[1], count: agents.length }), variant: "success" })
       await loadLocalSkills()
       setRemoteSkills(prev => prev.map(s =>
@@ -217,17 +225,57 @@ const SkillsPage = () => {
       ))
     } catch (error) {
       console.error("Failed to install skill:", error)
+      // 安全扫描阻止时展示报告，由用户决定是否仍然安装
+      if (String(error).includes("security scan blocked")) {
+        try {
+          const report = await ScanRemoteSkill(fullName)
+          setBlockedInstall({ fullName, agents, report })
+          return
+        } catch {}
+      }
       toast({ title: t("toast-install-failed", { error }), variant: "destructive" })
     } finally {
       setInstallingSkill(null)
     }
   }
 
+  const handleInstallAnyway = async () => {
+    const blocked = blockedInstall
+    setBlockedInstall(null)
+    if (blocked) await doInstallSkill(blocked.fullName, blocked.agents, true)
+  }
+
   const handleUpdateSkill = async (skillName: string) => {
+    // 存在本地修改时先让用户选择覆盖或合并
+    try {
+      const changes = await GetSkillLocalChanges(skillName)
+      if (changes?.status === "modified" || changes?.status === "unverified") {
+        setLocalChanges(changes)
+        return
+      }
+    } catch {}
+    await runUpdateSkill(skillName)
+  }
+
+  const handleLocalChangesChoice = async (choice: LocalChangesChoice) => {
+    const skillName = localChanges?.name
+    setLocalChanges(null)
+    if (skillName) await runUpdateSkill(skillName, choice)
+  }
+
+  const runUpdateSkill = async (skillName: string, choice?: LocalChangesChoice) => {
     try {
       setUpdatingSkill(skillName)
-      await UpdateSkill(skillName)
-      toast({ title: t("toast-skill-updated", { name: skillName }), variant: "success" })
+      // 确认当前内容未修改：先记录内容哈希，再按无本地修改正常更新
+      if (choice === "accept") await AcceptSkillContent(skillName)
+      const result = await UpdateSkillWithOptions(skillName, { float: false, force: choice === "force", merge: choice === "merge", allowRisk: false })
+      if (result?.status === "conflict") {
+        toast({ title: t("toast-skill-merge-conflict", { name: skillName, files: (result.conflictFiles || []).join(", ") }), variant: "destructive" })
+      } else if (result?.status === "merged") {
+        toast({ title: t("toast-skill-merged", { name: skillName }), variant: "success" })
+      } else {
+        toast({ title: t("toast-skill-updated", { name: skillName }), variant: "success" })
+      }
       setUpdatableSkills(prev => {
         const next = new Set(prev)
         next.delete(skillName)
@@ -309,19 +357,34 @@ const SkillsPage = () => {
     try {
       setBatchDeleting(true)
       const names = Array.from(selectedSkills)
-      const count = await BatchDeleteSkills(names)
-      toast({ title: t("toast-batch-delete-success", { count }), variant: "success" })
+      const settings = await GetSettings()
+      const result = await BatchDeleteSkillsWithOptions(names, new services.BatchOptions({ atomic: !!settings.atomicBatch }))
+      if (result.failed > 0) {
+        setBatchResult(result)
+      } else {
+        toast({ title: t("toast-batch-delete-success", { count: result.succeeded }), variant: "success" })
+      }
+      const deleted = result.items.filter(item => item.status === "succeeded").map(item => item.name)
       setSelectedSkills(new Set())
       setBatchMode(false)
       await loadLocalSkills()
       setRemoteSkills(prev => prev.map(s =>
-        names.includes(s.name) ? { ...s, installed: false } : s
+        deleted.includes(s.name) ? { ...s, installed: false } : s
       ))
     } catch (error) {
       toast({ title: t("toast-batch-delete-failed", { error }), variant: "destructive" })
     } finally {
       setBatchDeleting(false)
-      setShowBatchDeleteDialog(false)
+      setBatchDeletePlan(null)
+    }
+  }
+
+  // 删除前预览将要移除的文件与软链接
+  const handlePreviewBatchDelete = async () => {
+    try {
+      setBatchDeletePlan(await PlanBatchDeleteSkills(Array.from(selectedSkills)))
+    } catch (error) {
+      toast({ title: t("toast-batch-delete-failed", { error }), variant: "destructive" })
     }
   }
 
@@ -374,16 +437,29 @@ const SkillsPage = () => {
     try {
       setBatchUpdating(true)
       let successCount = 0
+      const skipped: string[] = []
+      const failed: string[] = []
       for (const name of names) {
         try {
+          // 批量更新不覆盖本地修改（或无法判断是否修改），跳过后由用户单独处理
+          const changes = await GetSkillLocalChanges(name)
+          if (changes?.status === "modified" || changes?.status === "unverified") {
+            skipped.push(name)
+            continue
+          }
           await UpdateSkill(name)
           successCount++
         } catch (err) {
           console.error(`Failed to update ${name}:`, err)
+          failed.push(name)
         }
       }
       toast({ title: t("toast-batch-update-success", { count: successCount }), variant: "success" })
-      setUpdatableSkills(new Set())
+      if (skipped.length > 0) {
+        toast({ title: t("toast-batch-update-skipped-local", { count: skipped.length }), variant: "default" })
+      }
+      // 跳过与失败的 skill 仍保留在可更新列表中，便于单独处理
+      setUpdatableSkills(new Set([...skipped, ...failed]))
       await loadLocalSkills()
     } catch (error) {
       toast({ title: t("toast-batch-update-failed", { error }), variant: "destructive" })
@@ -506,7 +582,7 @@ const SkillsPage = () => {
               <Settings02Icon size={14} className="mr-1.5" />
               {t("batch-config-links")}
             </Button>
-            <Button size="sm" variant="destructive" onClick={() => setShowBatchDeleteDialog(true)} disabled={selectedSkills.size === 0}>
+            <Button size="sm" variant="destructive" onClick={handlePreviewBatchDelete} disabled={selectedSkills.size === 0}>
               <Delete02Icon size={14} className="mr-1.5" />
               {t("batch-delete")}
             </Button>
@@ -638,29 +714,38 @@ const SkillsPage = () => {
       </AlertDialog>
 
       {/* Batch delete dialog */}
-      <AlertDialog open={showBatchDeleteDialog} onOpenChange={setShowBatchDeleteDialog}>
-        <AlertDialogContent>
-          <AlertDialogHeader>
-            <AlertDialogTitle>{t("confirm-batch-delete")}</AlertDialogTitle>
-            <AlertDialogDescription>
-              {t("confirm-batch-delete-desc", { count: selectedSkills.size })}
-              <ul className="mt-3 ml-4 space-y-1 list-disc text-foreground/80">
-                {Array.from(selectedSkills).map(name => (
-                  <li key={name} className="font-mono text-xs">{name}</li>
-                ))}
-              </ul>
-              <br />
-              <span className="font-semibold text-destructive">{t("batch-delete-warn")}</span>
-            </AlertDialogDescription>
-          </AlertDialogHeader>
-          <AlertDialogFooter>
-            <AlertDialogCancel disabled={batchDeleting}>{t("cancel")}</AlertDialogCancel>
-            <AlertDialogAction onClick={handleBatchDelete} disabled={batchDeleting} className="bg-destructive text-destructive-foreground hover:bg-destructive/90">
-              {batchDeleting ? t("deleting") : t("batch-delete")}
-            </AlertDialogAction>
-          </AlertDialogFooter>
-        </AlertDialogContent>
-      </AlertDialog>
+      <ChangePlanDialog
+        open={batchDeletePlan !== null}
+        onOpenChange={(open) => { if (!open) setBatchDeletePlan(null) }}
+        title={t("confirm-batch-delete")}
+        description={`${t("confirm-batch-delete-desc", { count: selectedSkills.size })} ${t("batch-delete-warn")}`}
+        plan={batchDeletePlan}
+        confirmLabel={batchDeleting ? t("deleting") : t("batch-delete")}
+        destructive
+        busy={batchDeleting}
+        onConfirm={handleBatchDelete}
+      />
+
+      <BatchResultDialog
+        open={!!batchResult}
+        onOpenChange={(open) => !open && setBatchResult(null)}
+        title={t("batch-delete-result")}
+        result={batchResult}
+      />
+
+      <LocalChangesDialog
+        open={localChanges !== null}
+        onOpenChange={(open) => { if (!open) setLocalChanges(null) }}
+        changes={localChanges}
+        onChoose={handleLocalChangesChoice}
+      />
+
+      <ScanReportDialog
+        open={blockedInstall !== null}
+        onOpenChange={(open) => { if (!open) setBlockedInstall(null) }}
+        report={blockedInstall?.report ?? null}
+        onConfirm={handleInstallAnyway}
+      />
 
       {/* Config agent link dialog (single) */}
       <ConfigAgentLinkDialog
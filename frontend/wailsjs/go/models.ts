@@ -20,6 +20,60 @@ export namespace services {
 	        this.timestamp = source["timestamp"];
 	    }
 	}
+	export class ActivityLogPage {
+	    logs: ActivityLog[];
+	    total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ActivityLogPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.logs = this.convertValues(source["logs"], ActivityLog);
+	        this.total = source["total"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ActivityLogQuery {
+	    offset: number;
+	    limit: number;
+	    action: string;
+	    skillName: string;
+	    since: string;
+	    until: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ActivityLogQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.offset = source["offset"];
+	        this.limit = source["limit"];
+	        this.action = source["action"];
+	        this.skillName = source["skillName"];
+	        this.since = source["since"];
+	        this.until = source["until"];
+	    }
+	}
 	export class AgentInfo {
 	    name: string;
 	    globalPaths: string[];
@@ -127,6 +181,15 @@ export namespace services {
 	    showPath: boolean;
 	    compactMode: boolean;
 	    terminal?: string;
+	    maxSkillVersions?: number;
+	    cloneCacheTTLDays?: number;
+	    cloneCacheMaxSizeMB?: number;
+	    offlineMode?: boolean;
+	    blockSaveOnLintErrors?: boolean;
+	    storageBackend?: string;
+	    secretBackend?: string;
+	    scanBlockThreshold?: string;
+	    atomicBatch?: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new AppSettings(source);
@@ -142,6 +205,15 @@ export namespace services {
 	        this.showPath = source["showPath"];
 	        this.compactMode = source["compactMode"];
 	        this.terminal = source["terminal"];
+	        this.maxSkillVersions = source["maxSkillVersions"];
+	        this.cloneCacheTTLDays = source["cloneCacheTTLDays"];
+	        this.cloneCacheMaxSizeMB = source["cloneCacheMaxSizeMB"];
+	        this.offlineMode = source["offlineMode"];
+	        this.blockSaveOnLintErrors = source["blockSaveOnLintErrors"];
+	        this.storageBackend = source["storageBackend"];
+	        this.secretBackend = source["secretBackend"];
+	        this.scanBlockThreshold = source["scanBlockThreshold"];
+	        this.atomicBatch = source["atomicBatch"];
 	    }
 	}
 	export class AutoUpdateConfig {
@@ -260,6 +332,74 @@ export namespace services {
 	        this.description = source["description"];
 	    }
 	}
+	export class BatchItemResult {
+	    name: string;
+	    status: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchItemResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.status = source["status"];
+	        this.error = source["error"];
+	    }
+	}
+	export class BatchOptions {
+	    atomic: boolean;
+	    concurrency?: number;
+	    allowRisk?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.atomic = source["atomic"];
+	        this.concurrency = source["concurrency"];
+	        this.allowRisk = source["allowRisk"];
+	    }
+	}
+	export class BatchResult {
+	    items: BatchItemResult[];
+	    succeeded: number;
+	    failed: number;
+	    rolledBack: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], BatchItemResult);
+	        this.succeeded = source["succeeded"];
+	        this.failed = source["failed"];
+	        this.rolledBack = source["rolledBack"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class BrokenLink {
 	    agentName: string;
 	    skillName: string;
@@ -280,6 +420,196 @@ export namespace services {
 	        this.error = source["error"];
 	    }
 	}
+	export class LockChange {
+	    action: string;
+	    skill: string;
+	    source: string;
+	    oldRef?: string;
+	    newRef?: string;
+	    oldCommit?: string;
+	    newCommit?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LockChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.action = source["action"];
+	        this.skill = source["skill"];
+	        this.source = source["source"];
+	        this.oldRef = source["oldRef"];
+	        this.newRef = source["newRef"];
+	        this.oldCommit = source["oldCommit"];
+	        this.newCommit = source["newCommit"];
+	    }
+	}
+	export class LinkChange {
+	    action: string;
+	    agent: string;
+	    skill: string;
+	    path: string;
+	    target?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LinkChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.action = source["action"];
+	        this.agent = source["agent"];
+	        this.skill = source["skill"];
+	        this.path = source["path"];
+	        this.target = source["target"];
+	    }
+	}
+	export class FileChange {
+	    action: string;
+	    path: string;
+	    skill: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.action = source["action"];
+	        this.path = source["path"];
+	        this.skill = source["skill"];
+	    }
+	}
+	export class ChangePlan {
+	    operation: string;
+	    files: FileChange[];
+	    links: LinkChange[];
+	    lock: LockChange[];
+	    customAgents?: string[];
+	    warnings: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ChangePlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.operation = source["operation"];
+	        this.files = this.convertValues(source["files"], FileChange);
+	        this.links = this.convertValues(source["links"], LinkChange);
+	        this.lock = this.convertValues(source["lock"], LockChange);
+	        this.customAgents = source["customAgents"];
+	        this.warnings = source["warnings"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CloneCacheEntry {
+	    url: string;
+	    size: number;
+	    lastFetched: time.Time;
+	    lastUsed: time.Time;
+	    mirrored: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CloneCacheEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.size = source["size"];
+	        this.lastFetched = this.convertValues(source["lastFetched"], time.Time);
+	        this.lastUsed = this.convertValues(source["lastUsed"], time.Time);
+	        this.mirrored = source["mirrored"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CloneCacheInfo {
+	    dir: string;
+	    entries: CloneCacheEntry[];
+	    totalBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CloneCacheInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dir = source["dir"];
+	        this.entries = this.convertValues(source["entries"], CloneCacheEntry);
+	        this.totalBytes = source["totalBytes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CloneCachePruneResult {
+	    removed: string[];
+	    freedBytes: number;
+	    remaining: number;
+	    remainingBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CloneCachePruneResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.removed = source["removed"];
+	        this.freedBytes = source["freedBytes"];
+	        this.remaining = source["remaining"];
+	        this.remainingBytes = source["remainingBytes"];
+	    }
+	}
 	export class SkillCompareInfo {
 	    name: string;
 	    desc: string;
@@ -609,49 +939,108 @@ export namespace services {
 		}
 	}
 	
-	export class EditorInfo {
-	    id: string;
-	    name: string;
-	    icon: string;
-	    iconBase64: string;
+	export class DiffLine {
+	    type: string;
+	    content: string;
+	    oldLine: number;
+	    newLine: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new EditorInfo(source);
+	        return new DiffLine(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.icon = source["icon"];
-	        this.iconBase64 = source["iconBase64"];
+	        this.type = source["type"];
+	        this.content = source["content"];
+	        this.oldLine = source["oldLine"];
+	        this.newLine = source["newLine"];
 	    }
 	}
-	export class EnhancedRecommendation {
-	    skillName: string;
-	    fullName: string;
-	    description: string;
-	    reason: string;
-	    score: number;
-	    type: string;
-	    tags: string[];
-	    language: string;
-	    framework: string;
-	    installs: number;
-	    rating: number;
-	    createdAt: time.Time;
+	export class DiffHunk {
+	    oldStart: number;
+	    oldLines: number;
+	    newStart: number;
+	    newLines: number;
+	    header: string;
+	    lines: DiffLine[];
 	
 	    static createFrom(source: any = {}) {
-	        return new EnhancedRecommendation(source);
+	        return new DiffHunk(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.skillName = source["skillName"];
-	        this.fullName = source["fullName"];
-	        this.description = source["description"];
-	        this.reason = source["reason"];
-	        this.score = source["score"];
+	        this.oldStart = source["oldStart"];
+	        this.oldLines = source["oldLines"];
+	        this.newStart = source["newStart"];
+	        this.newLines = source["newLines"];
+	        this.header = source["header"];
+	        this.lines = this.convertValues(source["lines"], DiffLine);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class EditorInfo {
+	    id: string;
+	    name: string;
+	    icon: string;
+	    iconBase64: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EditorInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.icon = source["icon"];
+	        this.iconBase64 = source["iconBase64"];
+	    }
+	}
+	export class EnhancedRecommendation {
+	    skillName: string;
+	    fullName: string;
+	    description: string;
+	    reason: string;
+	    score: number;
+	    type: string;
+	    tags: string[];
+	    language: string;
+	    framework: string;
+	    installs: number;
+	    rating: number;
+	    createdAt: time.Time;
+	
+	    static createFrom(source: any = {}) {
+	        return new EnhancedRecommendation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.skillName = source["skillName"];
+	        this.fullName = source["fullName"];
+	        this.description = source["description"];
+	        this.reason = source["reason"];
+	        this.score = source["score"];
 	        this.type = source["type"];
 	        this.tags = source["tags"];
 	        this.language = source["language"];
@@ -850,6 +1239,47 @@ export namespace services {
 		}
 	}
 	
+	
+	export class FileDiff {
+	    path: string;
+	    status: string;
+	    binary: boolean;
+	    additions: number;
+	    deletions: number;
+	    hunks: DiffHunk[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FileDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.status = source["status"];
+	        this.binary = source["binary"];
+	        this.additions = source["additions"];
+	        this.deletions = source["deletions"];
+	        this.hunks = this.convertValues(source["hunks"], DiffHunk);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class GitHubRepoSkill {
 	    name: string;
 	    fullName: string;
@@ -866,6 +1296,32 @@ export namespace services {
 	        this.desc = source["desc"];
 	    }
 	}
+	export class SkillVerifyResult {
+	    name: string;
+	    status: string;
+	    expectedHash: string;
+	    actualHash: string;
+	    commitSha: string;
+	    modifiedFiles: string[];
+	    missingFiles: string[];
+	    extraFiles: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SkillVerifyResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.status = source["status"];
+	        this.expectedHash = source["expectedHash"];
+	        this.actualHash = source["actualHash"];
+	        this.commitSha = source["commitSha"];
+	        this.modifiedFiles = source["modifiedFiles"];
+	        this.missingFiles = source["missingFiles"];
+	        this.extraFiles = source["extraFiles"];
+	    }
+	}
 	export class UnknownFile {
 	    agentName: string;
 	    fileName: string;
@@ -888,6 +1344,7 @@ export namespace services {
 	    unknownFiles: UnknownFile[];
 	    totalLinks: number;
 	    healthyLinks: number;
+	    modifiedSkills: SkillVerifyResult[];
 	
 	    static createFrom(source: any = {}) {
 	        return new HealthCheckResult(source);
@@ -900,6 +1357,7 @@ export namespace services {
 	        this.unknownFiles = this.convertValues(source["unknownFiles"], UnknownFile);
 	        this.totalLinks = source["totalLinks"];
 	        this.healthyLinks = source["healthyLinks"];
+	        this.modifiedSkills = this.convertValues(source["modifiedSkills"], SkillVerifyResult);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -920,10 +1378,31 @@ export namespace services {
 		    return a;
 		}
 	}
+	export class HostCredential {
+	    host: string;
+	    username?: string;
+	    token?: string;
+	    sshKeyPath?: string;
+	    addedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HostCredential(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.host = source["host"];
+	        this.username = source["username"];
+	        this.token = source["token"];
+	        this.sshKeyPath = source["sshKeyPath"];
+	        this.addedAt = source["addedAt"];
+	    }
+	}
 	export class ImportResult {
 	    installedCount: number;
 	    skippedCount: number;
 	    failedCount: number;
+	    policyViolations?: string[];
 	
 	    static createFrom(source: any = {}) {
 	        return new ImportResult(source);
@@ -934,6 +1413,7 @@ export namespace services {
 	        this.installedCount = source["installedCount"];
 	        this.skippedCount = source["skippedCount"];
 	        this.failedCount = source["failedCount"];
+	        this.policyViolations = source["policyViolations"];
 	    }
 	}
 	export class IndexedSkill {
@@ -984,6 +1464,18 @@ export namespace services {
 		    return a;
 		}
 	}
+	export class InstallOptions {
+	    allowRisk: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new InstallOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.allowRisk = source["allowRisk"];
+	    }
+	}
 	export class InstallPlan {
 	    skillName: string;
 	    requiredDeps: string[];
@@ -1008,40 +1500,43 @@ export namespace services {
 	        this.canInstall = source["canInstall"];
 	    }
 	}
-	export class Match {
-	    field: string;
-	    text: string;
-	    highlight: string;
-	    position: number;
+	
+	export class LintDiagnostic {
+	    severity: string;
+	    rule: string;
+	    message: string;
+	    file: string;
+	    line: number;
+	    column: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new Match(source);
+	        return new LintDiagnostic(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.field = source["field"];
-	        this.text = source["text"];
-	        this.highlight = source["highlight"];
-	        this.position = source["position"];
+	        this.severity = source["severity"];
+	        this.rule = source["rule"];
+	        this.message = source["message"];
+	        this.file = source["file"];
+	        this.line = source["line"];
+	        this.column = source["column"];
 	    }
 	}
-	export class PerformanceDataPoint {
-	    timestamp: time.Time;
-	    responseTime: number;
-	    memoryUsage: number;
-	    cpuUsage: number;
+	export class LintResult {
+	    diagnostics: LintDiagnostic[];
+	    errors: number;
+	    warnings: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new PerformanceDataPoint(source);
+	        return new LintResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.timestamp = this.convertValues(source["timestamp"], time.Time);
-	        this.responseTime = source["responseTime"];
-	        this.memoryUsage = source["memoryUsage"];
-	        this.cpuUsage = source["cpuUsage"];
+	        this.diagnostics = this.convertValues(source["diagnostics"], LintDiagnostic);
+	        this.errors = source["errors"];
+	        this.warnings = source["warnings"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -1062,92 +1557,281 @@ export namespace services {
 		    return a;
 		}
 	}
-	export class PerformanceMetric {
-	    skillName: string;
-	    usageCount: number;
-	    totalExecTime: number;
-	    avgExecTime: number;
-	    minExecTime: number;
-	    maxExecTime: number;
-	    errorCount: number;
-	    errorRate: number;
-	    lastUsed: time.Time;
-	    firstUsed: time.Time;
-	    agentUsage: Record<string, number>;
-	    hourlyUsage: Record<number, number>;
-	    dailyUsage: Record<string, number>;
+	
+	export class ManifestDependency {
+	    name: string;
+	    version: string;
+	    optional: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new PerformanceMetric(source);
+	        return new ManifestDependency(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.skillName = source["skillName"];
-	        this.usageCount = source["usageCount"];
-	        this.totalExecTime = source["totalExecTime"];
-	        this.avgExecTime = source["avgExecTime"];
-	        this.minExecTime = source["minExecTime"];
-	        this.maxExecTime = source["maxExecTime"];
-	        this.errorCount = source["errorCount"];
-	        this.errorRate = source["errorRate"];
-	        this.lastUsed = this.convertValues(source["lastUsed"], time.Time);
-	        this.firstUsed = this.convertValues(source["firstUsed"], time.Time);
-	        this.agentUsage = source["agentUsage"];
-	        this.hourlyUsage = source["hourlyUsage"];
-	        this.dailyUsage = source["dailyUsage"];
+	        this.name = source["name"];
+	        this.version = source["version"];
+	        this.optional = source["optional"];
 	    }
+	}
+	export class Match {
+	    field: string;
+	    text: string;
+	    highlight: string;
+	    position: number;
 	
-		convertValues(a: any, classs: any, asMap: boolean = false): any {
-		    if (!a) {
-		        return a;
-		    }
-		    if (a.slice && a.map) {
-		        return (a as any[]).map(elem => this.convertValues(elem, classs));
-		    } else if ("object" === typeof a) {
-		        if (asMap) {
-		            for (const key of Object.keys(a)) {
-		                a[key] = new classs(a[key]);
-		            }
-		            return a;
-		        }
-		        return new classs(a);
-		    }
-		    return a;
-		}
+	    static createFrom(source: any = {}) {
+	        return new Match(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.text = source["text"];
+	        this.highlight = source["highlight"];
+	        this.position = source["position"];
+	    }
 	}
-	export class Profile {
+	export class MetadataImportResult {
+	    tags: number;
+	    ratings: number;
+	    activityLogs: number;
+	    metrics: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MetadataImportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tags = source["tags"];
+	        this.ratings = source["ratings"];
+	        this.activityLogs = source["activityLogs"];
+	        this.metrics = source["metrics"];
+	    }
+	}
+	export class MetadataStoreInfo {
+	    backend: string;
+	    path: string;
+	    importedAt?: string;
+	    tags: number;
+	    ratings: number;
+	    activityLogs: number;
+	    metrics: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MetadataStoreInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.backend = source["backend"];
+	        this.path = source["path"];
+	        this.importedAt = source["importedAt"];
+	        this.tags = source["tags"];
+	        this.ratings = source["ratings"];
+	        this.activityLogs = source["activityLogs"];
+	        this.metrics = source["metrics"];
+	    }
+	}
+	export class MirrorSkill {
 	    name: string;
+	    fullName: string;
 	    description: string;
-	    agentSkills: Record<string, Array<string>>;
-	    createdAt: string;
-	    updatedAt: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new Profile(source);
+	        return new MirrorSkill(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.name = source["name"];
+	        this.fullName = source["fullName"];
 	        this.description = source["description"];
-	        this.agentSkills = source["agentSkills"];
-	        this.createdAt = source["createdAt"];
-	        this.updatedAt = source["updatedAt"];
 	    }
 	}
-	export class ProfilesConfig {
-	    profiles: Profile[];
-	    active: string;
+	export class MirrorSource {
+	    source: string;
+	    url: string;
+	    commitSha: string;
+	    syncedAt: string;
+	    skills: MirrorSkill[];
 	
 	    static createFrom(source: any = {}) {
-	        return new ProfilesConfig(source);
+	        return new MirrorSource(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.profiles = this.convertValues(source["profiles"], Profile);
-	        this.active = source["active"];
+	        this.source = source["source"];
+	        this.url = source["url"];
+	        this.commitSha = source["commitSha"];
+	        this.syncedAt = source["syncedAt"];
+	        this.skills = this.convertValues(source["skills"], MirrorSkill);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MirrorSyncResult {
+	    source: string;
+	    url: string;
+	    ok: boolean;
+	    error?: string;
+	    commitSha?: string;
+	    skills: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MirrorSyncResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.url = source["url"];
+	        this.ok = source["ok"];
+	        this.error = source["error"];
+	        this.commitSha = source["commitSha"];
+	        this.skills = source["skills"];
+	    }
+	}
+	export class PerformanceDataPoint {
+	    timestamp: time.Time;
+	    responseTime: number;
+	    memoryUsage: number;
+	    cpuUsage: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PerformanceDataPoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timestamp = this.convertValues(source["timestamp"], time.Time);
+	        this.responseTime = source["responseTime"];
+	        this.memoryUsage = source["memoryUsage"];
+	        this.cpuUsage = source["cpuUsage"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PerformanceMetric {
+	    skillName: string;
+	    usageCount: number;
+	    totalExecTime: number;
+	    avgExecTime: number;
+	    minExecTime: number;
+	    maxExecTime: number;
+	    errorCount: number;
+	    errorRate: number;
+	    lastUsed: time.Time;
+	    firstUsed: time.Time;
+	    agentUsage: Record<string, number>;
+	    hourlyUsage: Record<number, number>;
+	    dailyUsage: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new PerformanceMetric(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.skillName = source["skillName"];
+	        this.usageCount = source["usageCount"];
+	        this.totalExecTime = source["totalExecTime"];
+	        this.avgExecTime = source["avgExecTime"];
+	        this.minExecTime = source["minExecTime"];
+	        this.maxExecTime = source["maxExecTime"];
+	        this.errorCount = source["errorCount"];
+	        this.errorRate = source["errorRate"];
+	        this.lastUsed = this.convertValues(source["lastUsed"], time.Time);
+	        this.firstUsed = this.convertValues(source["firstUsed"], time.Time);
+	        this.agentUsage = source["agentUsage"];
+	        this.hourlyUsage = source["hourlyUsage"];
+	        this.dailyUsage = source["dailyUsage"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Profile {
+	    name: string;
+	    description: string;
+	    agentSkills: Record<string, Array<string>>;
+	    createdAt: string;
+	    updatedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Profile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.agentSkills = source["agentSkills"];
+	        this.createdAt = source["createdAt"];
+	        this.updatedAt = source["updatedAt"];
+	    }
+	}
+	export class ProfilesConfig {
+	    profiles: Profile[];
+	    active: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProfilesConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.profiles = this.convertValues(source["profiles"], Profile);
+	        this.active = source["active"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -1336,16 +2020,39 @@ export namespace services {
 	        this.reason = source["reason"];
 	    }
 	}
+	export class RegistryConfig {
+	    name: string;
+	    type: string;
+	    url: string;
+	    enabled: boolean;
+	    addedAt?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RegistryConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.url = source["url"];
+	        this.enabled = source["enabled"];
+	        this.addedAt = source["addedAt"];
+	    }
+	}
 	export class RemoteSkill {
 	    fullName: string;
 	    owner: string;
 	    repo: string;
 	    name: string;
 	    url: string;
+	    source: string;
 	    description: string;
 	    installed: boolean;
 	    installs: number;
 	    supportedAgents: string[];
+	    offline: boolean;
+	    registry: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new RemoteSkill(source);
@@ -1358,10 +2065,13 @@ export namespace services {
 	        this.repo = source["repo"];
 	        this.name = source["name"];
 	        this.url = source["url"];
+	        this.source = source["source"];
 	        this.description = source["description"];
 	        this.installed = source["installed"];
 	        this.installs = source["installs"];
 	        this.supportedAgents = source["supportedAgents"];
+	        this.offline = source["offline"];
+	        this.registry = source["registry"];
 	    }
 	}
 	export class RestoreOptions {
@@ -1388,6 +2098,28 @@ export namespace services {
 	        this.selectedItems = source["selectedItems"];
 	    }
 	}
+	export class ScanFinding {
+	    severity: string;
+	    rule: string;
+	    message: string;
+	    file: string;
+	    line: number;
+	    snippet?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanFinding(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.severity = source["severity"];
+	        this.rule = source["rule"];
+	        this.message = source["message"];
+	        this.file = source["file"];
+	        this.line = source["line"];
+	        this.snippet = source["snippet"];
+	    }
+	}
 	export class SearchFacets {
 	    languages: Record<string, number>;
 	    frameworks: Record<string, number>;
@@ -1569,6 +2301,42 @@ export namespace services {
 	        this.description = source["description"];
 	    }
 	}
+	export class SecretExportOptions {
+	    mode: string;
+	    passphrase: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SecretExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.passphrase = source["passphrase"];
+	    }
+	}
+	export class SecretStoreInfo {
+	    backend: string;
+	    configured: string;
+	    availableBackends: string[];
+	    vaultPath: string;
+	    passphraseProtected: boolean;
+	    locked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SecretStoreInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.backend = source["backend"];
+	        this.configured = source["configured"];
+	        this.availableBackends = source["availableBackends"];
+	        this.vaultPath = source["vaultPath"];
+	        this.passphraseProtected = source["passphraseProtected"];
+	        this.locked = source["locked"];
+	    }
+	}
 	export class SkillCollection {
 	    name: string;
 	    description: string;
@@ -1595,6 +2363,7 @@ export namespace services {
 	    optional: string[];
 	    version: string;
 	    description: string;
+	    constraints?: Record<string, string>;
 	
 	    static createFrom(source: any = {}) {
 	        return new SkillDependency(source);
@@ -1608,7 +2377,86 @@ export namespace services {
 	        this.optional = source["optional"];
 	        this.version = source["version"];
 	        this.description = source["description"];
+	        this.constraints = source["constraints"];
+	    }
+	}
+	export class SkillSignature {
+	    status: string;
+	    format?: string;
+	    keyId?: string;
+	    signer?: string;
+	    treeHash?: string;
+	    error?: string;
+	    verifiedAt?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SkillSignature(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.format = source["format"];
+	        this.keyId = source["keyId"];
+	        this.signer = source["signer"];
+	        this.treeHash = source["treeHash"];
+	        this.error = source["error"];
+	        this.verifiedAt = source["verifiedAt"];
+	    }
+	}
+	export class SkillManifest {
+	    name: string;
+	    description: string;
+	    version: string;
+	    license: string;
+	    language: string;
+	    framework: string;
+	    tags: string[];
+	    allowedTools: string[];
+	    dependencies: ManifestDependency[];
+	    conflicts: string[];
+	    compatibleAgents: string[];
+	    metadata: Record<string, any>;
+	    extra: Record<string, any>;
+	
+	    static createFrom(source: any = {}) {
+	        return new SkillManifest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.version = source["version"];
+	        this.license = source["license"];
+	        this.language = source["language"];
+	        this.framework = source["framework"];
+	        this.tags = source["tags"];
+	        this.allowedTools = source["allowedTools"];
+	        this.dependencies = this.convertValues(source["dependencies"], ManifestDependency);
+	        this.conflicts = source["conflicts"];
+	        this.compatibleAgents = source["compatibleAgents"];
+	        this.metadata = source["metadata"];
+	        this.extra = source["extra"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class SkillDetail {
 	    name: string;
@@ -1621,6 +2469,12 @@ export namespace services {
 	    content: string;
 	    installedAt: string;
 	    updatedAt: string;
+	    ref: string;
+	    commitSha: string;
+	    canRollback: boolean;
+	    manifest?: SkillManifest;
+	    manifestError: string;
+	    signature?: SkillSignature;
 	
 	    static createFrom(source: any = {}) {
 	        return new SkillDetail(source);
@@ -1638,13 +2492,40 @@ export namespace services {
 	        this.content = source["content"];
 	        this.installedAt = source["installedAt"];
 	        this.updatedAt = source["updatedAt"];
+	        this.ref = source["ref"];
+	        this.commitSha = source["commitSha"];
+	        this.canRollback = source["canRollback"];
+	        this.manifest = this.convertValues(source["manifest"], SkillManifest);
+	        this.manifestError = source["manifestError"];
+	        this.signature = this.convertValues(source["signature"], SkillSignature);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class SkillDiff {
 	    skillName: string;
 	    localContent: string;
 	    remoteContent: string;
 	    hasChanges: boolean;
+	    files: FileDiff[];
+	    additions: number;
+	    deletions: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new SkillDiff(source);
@@ -1656,7 +2537,28 @@ export namespace services {
 	        this.localContent = source["localContent"];
 	        this.remoteContent = source["remoteContent"];
 	        this.hasChanges = source["hasChanges"];
+	        this.files = this.convertValues(source["files"], FileDiff);
+	        this.additions = source["additions"];
+	        this.deletions = source["deletions"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class SkillFile {
 	    name: string;
@@ -1676,6 +2578,7 @@ export namespace services {
 	        this.content = source["content"];
 	    }
 	}
+	
 	export class SkillRating {
 	    skillName: string;
 	    rating: number;
@@ -1694,6 +2597,47 @@ export namespace services {
 	        this.updatedAt = source["updatedAt"];
 	    }
 	}
+	export class SkillScanReport {
+	    skill: string;
+	    risk: string;
+	    findings: ScanFinding[];
+	    filesScanned: number;
+	    threshold: string;
+	    blocked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SkillScanReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.skill = source["skill"];
+	        this.risk = source["risk"];
+	        this.findings = this.convertValues(source["findings"], ScanFinding);
+	        this.filesScanned = source["filesScanned"];
+	        this.threshold = source["threshold"];
+	        this.blocked = source["blocked"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
 	
 	export class SkillTemplate {
 	    name: string;
@@ -1721,6 +2665,10 @@ export namespace services {
 	    hasUpdate: boolean;
 	    currentSHA: string;
 	    latestSHA: string;
+	    ref: string;
+	    localModified: boolean;
+	    offline: boolean;
+	    error?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new SkillUpdateInfo(source);
@@ -1733,8 +2681,56 @@ export namespace services {
 	        this.hasUpdate = source["hasUpdate"];
 	        this.currentSHA = source["currentSHA"];
 	        this.latestSHA = source["latestSHA"];
+	        this.ref = source["ref"];
+	        this.localModified = source["localModified"];
+	        this.offline = source["offline"];
+	        this.error = source["error"];
 	    }
 	}
+	export class SkillUpdateResult {
+	    name: string;
+	    status: string;
+	    localModified: boolean;
+	    mergedFiles: string[];
+	    conflictFiles: string[];
+	    keptFiles: string[];
+	    overwrittenFiles: string[];
+	    lintDiagnostics: LintDiagnostic[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SkillUpdateResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.status = source["status"];
+	        this.localModified = source["localModified"];
+	        this.mergedFiles = source["mergedFiles"];
+	        this.conflictFiles = source["conflictFiles"];
+	        this.keptFiles = source["keptFiles"];
+	        this.overwrittenFiles = source["overwrittenFiles"];
+	        this.lintDiagnostics = this.convertValues(source["lintDiagnostics"], LintDiagnostic);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class SkillUsageStat {
 	    skillName: string;
 	    usageCount: number;
@@ -1755,6 +2751,31 @@ export namespace services {
 	        this.errorRate = source["errorRate"];
 	    }
 	}
+	
+	export class SkillVersion {
+	    id: string;
+	    name: string;
+	    reason: string;
+	    createdAt: string;
+	    treeHash: string;
+	    commitSha: string;
+	    fileCount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SkillVersion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.reason = source["reason"];
+	        this.createdAt = source["createdAt"];
+	        this.treeHash = source["treeHash"];
+	        this.commitSha = source["commitSha"];
+	        this.fileCount = source["fileCount"];
+	    }
+	}
 	export class Skills {
 	    name: string;
 	    desc: string;
@@ -1763,6 +2784,7 @@ export namespace services {
 	    framework: string;
 	    agents: string[];
 	    source: string;
+	    manifest?: SkillManifest;
 	
 	    static createFrom(source: any = {}) {
 	        return new Skills(source);
@@ -1777,7 +2799,26 @@ export namespace services {
 	        this.framework = source["framework"];
 	        this.agents = source["agents"];
 	        this.source = source["source"];
+	        this.manifest = this.convertValues(source["manifest"], SkillManifest);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class SystemMetric {
 	    timestamp: time.Time;
@@ -1859,7 +2900,137 @@ export namespace services {
 	        this.available = source["available"];
 	    }
 	}
+	export class TreeDiff {
+	    files: FileDiff[];
+	    additions: number;
+	    deletions: number;
+	    hasChanges: boolean;
 	
+	    static createFrom(source: any = {}) {
+	        return new TreeDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = this.convertValues(source["files"], FileDiff);
+	        this.additions = source["additions"];
+	        this.deletions = source["deletions"];
+	        this.hasChanges = source["hasChanges"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TrustPolicy {
+	    allowedOwners?: string[];
+	    allowedHosts?: string[];
+	    allowLocal?: boolean;
+	    blockedSkills?: string[];
+	    requirePin?: boolean;
+	    requireSignature?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TrustPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.allowedOwners = source["allowedOwners"];
+	        this.allowedHosts = source["allowedHosts"];
+	        this.allowLocal = source["allowLocal"];
+	        this.blockedSkills = source["blockedSkills"];
+	        this.requirePin = source["requirePin"];
+	        this.requireSignature = source["requireSignature"];
+	    }
+	}
+	export class TrustPolicyInfo {
+	    path: string;
+	    exists: boolean;
+	    policy: TrustPolicy;
+	
+	    static createFrom(source: any = {}) {
+	        return new TrustPolicyInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.exists = source["exists"];
+	        this.policy = this.convertValues(source["policy"], TrustPolicy);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TrustedKey {
+	    name: string;
+	    format: string;
+	    publicKey: string;
+	    keyId: string;
+	    addedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TrustedKey(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.format = source["format"];
+	        this.publicKey = source["publicKey"];
+	        this.keyId = source["keyId"];
+	        this.addedAt = source["addedAt"];
+	    }
+	}
+	
+	export class UpdateOptions {
+	    float: boolean;
+	    force: boolean;
+	    merge: boolean;
+	    allowRisk: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.float = source["float"];
+	        this.force = source["force"];
+	        this.merge = source["merge"];
+	        this.allowRisk = source["allowRisk"];
+	    }
+	}
 	export class UsageReport {
 	    period: string;
 	    startDate: time.Time;
@@ -3,6 +3,8 @@
 import {services} from '../models';
 import {context} from '../models';
 
+export function CompareBackupSkills(arg1:string):Promise<services.TreeDiff>;
+
 export function CreateBackup(arg1:string,arg2:string,arg3:Array<string>):Promise<services.BackupInfo>;
 
 export function DeleteBackup(arg1:string):Promise<void>;
@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CompareBackupSkills(arg1) {
+  return window['go']['services']['BackupService']['CompareBackupSkills'](arg1);
+}
+
 export function CreateBackup(arg1, arg2, arg3) {
   return window['go']['services']['BackupService']['CreateBackup'](arg1, arg2, arg3);
 }
@@ -9,6 +9,8 @@ export function DeleteProfile(arg1:string):Promise<void>;
 
 export function GetProfiles():Promise<services.ProfilesConfig>;
 
+export function PlanApplyProfile(arg1:string):Promise<services.ChangePlan>;
+
 export function SaveCurrentAsProfile(arg1:string,arg2:string):Promise<void>;
 
 export function Startup(arg1:context.Context):Promise<void>;
@@ -14,6 +14,10 @@ export function GetProfiles() {
   return window['go']['services']['ProfileService']['GetProfiles']();
 }
 
+export function PlanApplyProfile(arg1) {
+  return window['go']['services']['ProfileService']['PlanApplyProfile'](arg1);
+}
+
 export function SaveCurrentAsProfile(arg1, arg2) {
   return window['go']['services']['ProfileService']['SaveCurrentAsProfile'](arg1, arg2);
 }
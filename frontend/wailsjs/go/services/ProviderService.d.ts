@@ -13,6 +13,8 @@ export function DetectActiveProviders():Promise<Record<string, string>>;
 
 export function ExportProviders():Promise<string>;
 
+export function ExportProvidersWithOptions(arg1:services.SecretExportOptions):Promise<string>;
+
 export function GetAllProviders():Promise<services.ProvidersData>;
 
 export function GetAvailableTerminals():Promise<Array<services.TerminalInfo>>;
@@ -21,6 +23,8 @@ export function GetCodeBuddyActiveModel():Promise<string>;
 
 export function ImportProviders(arg1:string):Promise<number>;
 
+export function ImportProvidersWithPassphrase(arg1:string,arg2:string):Promise<number>;
+
 export function OpenTerminalWithCLI(arg1:string):Promise<void>;
 
 export function Startup(arg1:context.Context):Promise<void>;
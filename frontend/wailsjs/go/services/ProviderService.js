@@ -22,6 +22,10 @@ export function ExportProviders() {
   return window['go']['services']['ProviderService']['ExportProviders']();
 }
 
+export function ExportProvidersWithOptions(arg1) {
+  return window['go']['services']['ProviderService']['ExportProvidersWithOptions'](arg1);
+}
+
 export function GetAllProviders() {
   return window['go']['services']['ProviderService']['GetAllProviders']();
 }
@@ -38,6 +42,10 @@ export function ImportProviders(arg1) {
   return window['go']['services']['ProviderService']['ImportProviders'](arg1);
 }
 
+export function ImportProvidersWithPassphrase(arg1, arg2) {
+  return window['go']['services']['ProviderService']['ImportProvidersWithPassphrase'](arg1, arg2);
+}
+
 export function OpenTerminalWithCLI(arg1) {
   return window['go']['services']['ProviderService']['OpenTerminalWithCLI'](arg1);
 }
@@ -3,18 +3,30 @@
 import {services} from '../models';
 import {context} from '../models';
 
+export function AcceptSkillContent(arg1:string):Promise<services.SkillVerifyResult>;
+
 export function AddActivityLog(arg1:string,arg2:string,arg3:string):Promise<void>;
 
 export function AddCustomSource(arg1:string,arg2:string,arg3:string):Promise<void>;
 
+export function AddRegistry(arg1:string,arg2:string):Promise<void>;
+
+export function AddTrustedKey(arg1:string,arg2:string):Promise<void>;
+
 export function BatchDeleteSkills(arg1:Array<string>):Promise<number>;
 
+export function BatchDeleteSkillsWithOptions(arg1:Array<string>,arg2:services.BatchOptions):Promise<services.BatchResult>;
+
 export function BatchInstallFromRepo(arg1:Array<string>,arg2:Array<string>):Promise<number>;
 
+export function BatchInstallFromRepoWithOptions(arg1:Array<string>,arg2:Array<string>,arg3:services.BatchOptions):Promise<services.BatchResult>;
+
 export function BatchUpdateSkillAgentLinks(arg1:Array<string>,arg2:Array<string>):Promise<number>;
 
 export function CheckSkillUpdates():Promise<Array<services.SkillUpdateInfo>>;
 
+export function CheckTrustPolicy(arg1:string):Promise<void>;
+
 export function ClearActivityLogs():Promise<void>;
 
 export function CloneProjectConfig(arg1:string,arg2:string):Promise<number>;
@@ -31,6 +43,8 @@ export function DeleteSkill(arg1:string):Promise<void>;
 
 export function DetectProjectType(arg1:string):Promise<services.ProjectTypeInfo>;
 
+export function DiffSkillVersion(arg1:string,arg2:string):Promise<services.TreeDiff>;
+
 export function ExportConfig():Promise<services.ExportedConfig>;
 
 export function ExportConfigToFile():Promise<string>;
@@ -49,6 +63,8 @@ export function GetAutoUpdateConfig():Promise<services.AutoUpdateConfig>;
 
 export function GetAvailableEditors():Promise<Array<services.EditorInfo>>;
 
+export function GetCloneCacheInfo():Promise<services.CloneCacheInfo>;
+
 export function GetCollections():Promise<Array<services.SkillCollection>>;
 
 export function GetCustomSources():Promise<Array<services.CustomSource>>;
@@ -57,12 +73,24 @@ export function GetDashboardStats():Promise<services.DashboardStats>;
 
 export function GetFavorites():Promise<Array<string>>;
 
+export function GetHostCredentials():Promise<Array<services.HostCredential>>;
+
+export function GetMetadataStoreInfo():Promise<services.MetadataStoreInfo>;
+
+export function GetMirrors():Promise<Array<services.MirrorSource>>;
+
 export function GetProjectSkillAgentLinks(arg1:string,arg2:string):Promise<Array<string>>;
 
 export function GetProjectSkills(arg1:string):Promise<Array<services.ProjectSkill>>;
 
 export function GetRecommendations():Promise<Array<services.RecommendedSkill>>;
 
+export function GetRegistries():Promise<Array<services.RegistryConfig>>;
+
+export function GetRegistrySkill(arg1:string):Promise<services.RemoteSkill>;
+
+export function GetSecretStoreInfo():Promise<services.SecretStoreInfo>;
+
 export function GetSettings():Promise<services.AppSettings>;
 
 export function GetSkillAgentLinks(arg1:string):Promise<Array<string>>;
@@ -73,52 +101,130 @@ export function GetSkillDiff(arg1:string):Promise<services.SkillDiff>;
 
 export function GetSkillFiles(arg1:string):Promise<Array<services.SkillFile>>;
 
+export function GetSkillLocalChanges(arg1:string):Promise<services.SkillVerifyResult>;
+
 export function GetSkillTags(arg1:string):Promise<Array<string>>;
 
 export function GetSkillTemplates():Promise<Array<services.SkillTemplate>>;
 
+export function GetSkillVersionContent(arg1:string,arg2:string):Promise<string>;
+
+export function GetSkillsByTag(arg1:string):Promise<Array<string>>;
+
+export function GetTrustPolicy():Promise<services.TrustPolicyInfo>;
+
+export function GetTrustedKeys():Promise<Array<services.TrustedKey>>;
+
+export function HasRollback(arg1:string):Promise<boolean>;
+
 export function HealthCheck():Promise<services.HealthCheckResult>;
 
 export function ImportConfig(arg1:string):Promise<services.ImportResult>;
 
+export function ImportMetadataFromJSON():Promise<services.MetadataImportResult>;
+
 export function InstallCollection(arg1:string,arg2:Array<string>):Promise<number>;
 
+export function InstallCollectionWithOptions(arg1:string,arg2:Array<string>,arg3:services.BatchOptions):Promise<services.BatchResult>;
+
 export function InstallRemoteSkill(arg1:string,arg2:Array<string>):Promise<void>;
 
 export function InstallRemoteSkillToProject(arg1:string,arg2:string,arg3:Array<string>):Promise<void>;
 
+export function InstallRemoteSkillToProjectWithOptions(arg1:string,arg2:string,arg3:Array<string>,arg4:services.InstallOptions):Promise<void>;
+
+export function InstallRemoteSkillWithOptions(arg1:string,arg2:Array<string>,arg3:services.InstallOptions):Promise<void>;
+
 export function InstallSkillToProject(arg1:string,arg2:string,arg3:Array<string>):Promise<void>;
 
+export function IsOfflineMode():Promise<boolean>;
+
+export function LintSkill(arg1:string):Promise<services.LintResult>;
+
+export function LintSkillContent(arg1:string):Promise<services.LintResult>;
+
+export function ListSkillVersions(arg1:string):Promise<Array<services.SkillVersion>>;
+
+export function MirrorSources(arg1:Array<string>):Promise<Array<services.MirrorSyncResult>>;
+
+export function MoveRegistry(arg1:string,arg2:number):Promise<void>;
+
 export function OpenSkillInEditor(arg1:string,arg2:string):Promise<void>;
 
 export function OpenSkillInSystemEditor(arg1:string):Promise<void>;
 
+export function PlanBatchDeleteSkills(arg1:Array<string>):Promise<services.ChangePlan>;
+
+export function PlanBatchUpdateSkillAgentLinks(arg1:Array<string>,arg2:Array<string>):Promise<services.ChangePlan>;
+
+export function PlanCloneProjectConfig(arg1:string,arg2:string):Promise<services.ChangePlan>;
+
+export function PlanImportConfig(arg1:string):Promise<services.ChangePlan>;
+
+export function PlanInstallRemoteSkill(arg1:string,arg2:Array<string>,arg3:services.InstallOptions):Promise<services.ChangePlan>;
+
+export function PlanUpdateSkill(arg1:string,arg2:services.UpdateOptions):Promise<services.ChangePlan>;
+
 export function PreviewRemoteSkill(arg1:string):Promise<string>;
 
+export function PruneCloneCache(arg1:boolean):Promise<services.CloneCachePruneResult>;
+
+export function QueryActivityLogs(arg1:services.ActivityLogQuery):Promise<services.ActivityLogPage>;
+
 export function RemoveCustomSource(arg1:string):Promise<void>;
 
+export function RemoveHostCredential(arg1:string):Promise<void>;
+
+export function RemoveMirror(arg1:string):Promise<void>;
+
+export function RemoveRegistry(arg1:string):Promise<void>;
+
 export function RemoveSkillFromProject(arg1:string,arg2:string):Promise<void>;
 
+export function RemoveTrustedKey(arg1:string):Promise<void>;
+
 export function RepairBrokenLinks():Promise<number>;
 
+export function RestoreSkillVersion(arg1:string,arg2:string):Promise<void>;
+
+export function RollbackSkill(arg1:string):Promise<void>;
+
 export function RunAutoUpdate():Promise<number>;
 
 export function SaveSettings(arg1:string):Promise<void>;
 
 export function SaveSkillContent(arg1:string,arg2:string):Promise<void>;
 
+export function SaveTrustPolicy(arg1:services.TrustPolicy):Promise<void>;
+
 export function ScanGitHubRepo(arg1:string):Promise<Array<services.GitHubRepoSkill>>;
 
+export function ScanRemoteSkill(arg1:string):Promise<services.SkillScanReport>;
+
+export function ScanSkill(arg1:string):Promise<services.SkillScanReport>;
+
 export function SearchCustomSource(arg1:string):Promise<Array<services.RemoteSkill>>;
 
 export function SetAutoUpdateConfig(arg1:boolean,arg2:number):Promise<void>;
 
+export function SetHostCredential(arg1:services.HostCredential):Promise<void>;
+
+export function SetRegistryEnabled(arg1:string,arg2:boolean):Promise<void>;
+
+export function SetSecretVaultPassphrase(arg1:string,arg2:string):Promise<void>;
+
 export function SetSkillTags(arg1:string,arg2:Array<string>):Promise<void>;
 
+export function SignSkillWithSSHKey(arg1:string,arg2:string):Promise<string>;
+
+export function SkillTreeHash(arg1:string):Promise<string>;
+
 export function Startup(arg1:context.Context):Promise<void>;
 
 export function ToggleFavorite(arg1:string):Promise<boolean>;
 
+export function UnlockSecretVault(arg1:string):Promise<void>;
+
 export function UpdateCollection(arg1:string,arg2:string,arg3:Array<string>):Promise<void>;
 
 export function UpdateProjectSkillAgentLinks(arg1:string,arg2:string,arg3:Array<string>):Promise<void>;
@@ -126,3 +232,7 @@ export function UpdateProjectSkillAgentLinks(arg1:string,arg2:string,arg3:Array<
 export function UpdateSkill(arg1:string):Promise<void>;
 
 export function UpdateSkillAgentLinks(arg1:string,arg2:Array<string>):Promise<number>;
+
+export function UpdateSkillWithOptions(arg1:string,arg2:services.UpdateOptions):Promise<services.SkillUpdateResult>;
+
+export function VerifySkills():Promise<Array<services.SkillVerifyResult>>;
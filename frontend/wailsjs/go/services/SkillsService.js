@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AcceptSkillContent(arg1) {
+  return window['go']['services']['SkillsService']['AcceptSkillContent'](arg1);
+}
+
 export function AddActivityLog(arg1, arg2, arg3) {
   return window['go']['services']['SkillsService']['AddActivityLog'](arg1, arg2, arg3);
 }
@@ -10,14 +14,30 @@ export function AddCustomSource(arg1, arg2, arg3) {
   return window['go']['services']['SkillsService']['AddCustomSource'](arg1, arg2, arg3);
 }
 
+export function AddRegistry(arg1, arg2) {
+  return window['go']['services']['SkillsService']['AddRegistry'](arg1, arg2);
+}
+
+export function AddTrustedKey(arg1, arg2) {
+  return window['go']['services']['SkillsService']['AddTrustedKey'](arg1, arg2);
+}
+
 export function BatchDeleteSkills(arg1) {
   return window['go']['services']['SkillsService']['BatchDeleteSkills'](arg1);
 }
 
+export function BatchDeleteSkillsWithOptions(arg1, arg2) {
+  return window['go']['services']['SkillsService']['BatchDeleteSkillsWithOptions'](arg1, arg2);
+}
+
 export function BatchInstallFromRepo(arg1, arg2) {
   return window['go']['services']['SkillsService']['BatchInstallFromRepo'](arg1, arg2);
 }
 
+export function BatchInstallFromRepoWithOptions(arg1, arg2, arg3) {
+  return window['go']['services']['SkillsService']['BatchInstallFromRepoWithOptions'](arg1, arg2, arg3);
+}
+
 export function BatchUpdateSkillAgentLinks(arg1, arg2) {
   return window['go']['services']['SkillsService']['BatchUpdateSkillAgentLinks'](arg1, arg2);
 }
@@ -26,6 +46,10 @@ export function CheckSkillUpdates() {
   return window['go']['services']['SkillsService']['CheckSkillUpdates']();
 }
 
+export function CheckTrustPolicy(arg1) {
+  return window['go']['services']['SkillsService']['CheckTrustPolicy'](arg1);
+}
+
 export function ClearActivityLogs() {
   return window['go']['services']['SkillsService']['ClearActivityLogs']();
 }
@@ -58,6 +82,10 @@ export function DetectProjectType(arg1) {
   return window['go']['services']['SkillsService']['DetectProjectType'](arg1);
 }
 
+export function DiffSkillVersion(arg1, arg2) {
+  return window['go']['services']['SkillsService']['DiffSkillVersion'](arg1, arg2);
+}
+
 export function ExportConfig() {
   return window['go']['services']['SkillsService']['ExportConfig']();
 }
@@ -94,6 +122,10 @@ export function GetAvailableEditors() {
   return window['go']['services']['SkillsService']['GetAvailableEditors']();
 }
 
+export function GetCloneCacheInfo() {
+  return window['go']['services']['SkillsService']['GetCloneCacheInfo']();
+}
+
 export function GetCollections() {
   return window['go']['services']['SkillsService']['GetCollections']();
 }
@@ -110,6 +142,18 @@ export function GetFavorites() {
   return window['go']['services']['SkillsService']['GetFavorites']();
 }
 
+export function GetHostCredentials() {
+  return window['go']['services']['SkillsService']['GetHostCredentials']();
+}
+
+export function GetMetadataStoreInfo() {
+  return window['go']['services']['SkillsService']['GetMetadataStoreInfo']();
+}
+
+export function GetMirrors() {
+  return window['go']['services']['SkillsService']['GetMirrors']();
+}
+
 export function GetProjectSkillAgentLinks(arg1, arg2) {
   return window['go']['services']['SkillsService']['GetProjectSkillAgentLinks'](arg1, arg2);
 }
@@ -122,6 +166,18 @@ export function GetRecommendations() {
   return window['go']['services']['SkillsService']['GetRecommendations']();
 }
 
+export function GetRegistries() {
+  return window['go']['services']['SkillsService']['GetRegistries']();
+}
+
+export function GetRegistrySkill(arg1) {
+  return window['go']['services']['SkillsService']['GetRegistrySkill'](arg1);
+}
+
+export function GetSecretStoreInfo() {
+  return window['go']['services']['SkillsService']['GetSecretStoreInfo']();
+}
+
 export function GetSettings() {
   return window['go']['services']['SkillsService']['GetSettings']();
 }
@@ -142,6 +198,10 @@ export function GetSkillFiles(arg1) {
   return window['go']['services']['SkillsService']['GetSkillFiles'](arg1);
 }
 
+export function GetSkillLocalChanges(arg1) {
+  return window['go']['services']['SkillsService']['GetSkillLocalChanges'](arg1);
+}
+
 export function GetSkillTags(arg1) {
   return window['go']['services']['SkillsService']['GetSkillTags'](arg1);
 }
@@ -150,6 +210,26 @@ export function GetSkillTemplates() {
   return window['go']['services']['SkillsService']['GetSkillTemplates']();
 }
 
+export function GetSkillVersionContent(arg1, arg2) {
+  return window['go']['services']['SkillsService']['GetSkillVersionContent'](arg1, arg2);
+}
+
+export function GetSkillsByTag(arg1) {
+  return window['go']['services']['SkillsService']['GetSkillsByTag'](arg1);
+}
+
+export function GetTrustPolicy() {
+  return window['go']['services']['SkillsService']['GetTrustPolicy']();
+}
+
+export function GetTrustedKeys() {
+  return window['go']['services']['SkillsService']['GetTrustedKeys']();
+}
+
+export function HasRollback(arg1) {
+  return window['go']['services']['SkillsService']['HasRollback'](arg1);
+}
+
 export function HealthCheck() {
   return window['go']['services']['SkillsService']['HealthCheck']();
 }
@@ -158,10 +238,18 @@ export function ImportConfig(arg1) {
   return window['go']['services']['SkillsService']['ImportConfig'](arg1);
 }
 
+export function ImportMetadataFromJSON() {
+  return window['go']['services']['SkillsService']['ImportMetadataFromJSON']();
+}
+
 export function InstallCollection(arg1, arg2) {
   return window['go']['services']['SkillsService']['InstallCollection'](arg1, arg2);
 }
 
+export function InstallCollectionWithOptions(arg1, arg2, arg3) {
+  return window['go']['services']['SkillsService']['InstallCollectionWithOptions'](arg1, arg2, arg3);
+}
+
 export function InstallRemoteSkill(arg1, arg2) {
   return window['go']['services']['SkillsService']['InstallRemoteSkill'](arg1, arg2);
 }
@@ -170,10 +258,42 @@ export function InstallRemoteSkillToProject(arg1, arg2, arg3) {
   return window['go']['services']['SkillsService']['InstallRemoteSkillToProject'](arg1, arg2, arg3);
 }
 
+export function InstallRemoteSkillToProjectWithOptions(arg1, arg2, arg3, arg4) {
+  return window['go']['services']['SkillsService']['InstallRemoteSkillToProjectWithOptions'](arg1, arg2, arg3, arg4);
+}
+
+export function InstallRemoteSkillWithOptions(arg1, arg2, arg3) {
+  return window['go']['services']['SkillsService']['InstallRemoteSkillWithOptions'](arg1, arg2, arg3);
+}
+
 export function InstallSkillToProject(arg1, arg2, arg3) {
   return window['go']['services']['SkillsService']['InstallSkillToProject'](arg1, arg2, arg3);
 }
 
+export function IsOfflineMode() {
+  return window['go']['services']['SkillsService']['IsOfflineMode']();
+}
+
+export function LintSkill(arg1) {
+  return window['go']['services']['SkillsService']['LintSkill'](arg1);
+}
+
+export function LintSkillContent(arg1) {
+  return window['go']['services']['SkillsService']['LintSkillContent'](arg1);
+}
+
+export function ListSkillVersions(arg1) {
+  return window['go']['services']['SkillsService']['ListSkillVersions'](arg1);
+}
+
+export function MirrorSources(arg1) {
+  return window['go']['services']['SkillsService']['MirrorSources'](arg1);
+}
+
+export function MoveRegistry(arg1, arg2) {
+  return window['go']['services']['SkillsService']['MoveRegistry'](arg1, arg2);
+}
+
 export function OpenSkillInEditor(arg1, arg2) {
   return window['go']['services']['SkillsService']['OpenSkillInEditor'](arg1, arg2);
 }
@@ -182,22 +302,78 @@ export function OpenSkillInSystemEditor(arg1) {
   return window['go']['services']['SkillsService']['OpenSkillInSystemEditor'](arg1);
 }
 
+export function PlanBatchDeleteSkills(arg1) {
+  return window['go']['services']['SkillsService']['PlanBatchDeleteSkills'](arg1);
+}
+
+export function PlanBatchUpdateSkillAgentLinks(arg1, arg2) {
+  return window['go']['services']['SkillsService']['PlanBatchUpdateSkillAgentLinks'](arg1, arg2);
+}
+
+export function PlanCloneProjectConfig(arg1, arg2) {
+  return window['go']['services']['SkillsService']['PlanCloneProjectConfig'](arg1, arg2);
+}
+
+export function PlanImportConfig(arg1) {
+  return window['go']['services']['SkillsService']['PlanImportConfig'](arg1);
+}
+
+export function PlanInstallRemoteSkill(arg1, arg2, arg3) {
+  return window['go']['services']['SkillsService']['PlanInstallRemoteSkill'](arg1, arg2, arg3);
+}
+
+export function PlanUpdateSkill(arg1, arg2) {
+  return window['go']['services']['SkillsService']['PlanUpdateSkill'](arg1, arg2);
+}
+
 export function PreviewRemoteSkill(arg1) {
   return window['go']['services']['SkillsService']['PreviewRemoteSkill'](arg1);
 }
 
+export function PruneCloneCache(arg1) {
+  return window['go']['services']['SkillsService']['PruneCloneCache'](arg1);
+}
+
+export function QueryActivityLogs(arg1) {
+  return window['go']['services']['SkillsService']['QueryActivityLogs'](arg1);
+}
+
 export function RemoveCustomSource(arg1) {
   return window['go']['services']['SkillsService']['RemoveCustomSource'](arg1);
 }
 
+export function RemoveHostCredential(arg1) {
+  return window['go']['services']['SkillsService']['RemoveHostCredential'](arg1);
+}
+
+export function RemoveMirror(arg1) {
+  return window['go']['services']['SkillsService']['RemoveMirror'](arg1);
+}
+
+export function RemoveRegistry(arg1) {
+  return window['go']['services']['SkillsService']['RemoveRegistry'](arg1);
+}
+
 export function RemoveSkillFromProject(arg1, arg2) {
   return window['go']['services']['SkillsService']['RemoveSkillFromProject'](arg1, arg2);
 }
 
+export function RemoveTrustedKey(arg1) {
+  return window['go']['services']['SkillsService']['RemoveTrustedKey'](arg1);
+}
+
 export function RepairBrokenLinks() {
   return window['go']['services']['SkillsService']['RepairBrokenLinks']();
 }
 
+export function RestoreSkillVersion(arg1, arg2) {
+  return window['go']['services']['SkillsService']['RestoreSkillVersion'](arg1, arg2);
+}
+
+export function RollbackSkill(arg1) {
+  return window['go']['services']['SkillsService']['RollbackSkill'](arg1);
+}
+
 export function RunAutoUpdate() {
   return window['go']['services']['SkillsService']['RunAutoUpdate']();
 }
@@ -210,10 +386,22 @@ export function SaveSkillContent(arg1, arg2) {
   return window['go']['services']['SkillsService']['SaveSkillContent'](arg1, arg2);
 }
 
+export function SaveTrustPolicy(arg1) {
+  return window['go']['services']['SkillsService']['SaveTrustPolicy'](arg1);
+}
+
 export function ScanGitHubRepo(arg1) {
   return window['go']['services']['SkillsService']['ScanGitHubRepo'](arg1);
 }
 
+export function ScanRemoteSkill(arg1) {
+  return window['go']['services']['SkillsService']['ScanRemoteSkill'](arg1);
+}
+
+export function ScanSkill(arg1) {
+  return window['go']['services']['SkillsService']['ScanSkill'](arg1);
+}
+
 export function SearchCustomSource(arg1) {
   return window['go']['services']['SkillsService']['SearchCustomSource'](arg1);
 }
@@ -222,10 +410,30 @@ export function SetAutoUpdateConfig(arg1, arg2) {
   return window['go']['services']['SkillsService']['SetAutoUpdateConfig'](arg1, arg2);
 }
 
+export function SetHostCredential(arg1) {
+  return window['go']['services']['SkillsService']['SetHostCredential'](arg1);
+}
+
+export function SetRegistryEnabled(arg1, arg2) {
+  return window['go']['services']['SkillsService']['SetRegistryEnabled'](arg1, arg2);
+}
+
+export function SetSecretVaultPassphrase(arg1, arg2) {
+  return window['go']['services']['SkillsService']['SetSecretVaultPassphrase'](arg1, arg2);
+}
+
 export function SetSkillTags(arg1, arg2) {
   return window['go']['services']['SkillsService']['SetSkillTags'](arg1, arg2);
 }
 
+export function SignSkillWithSSHKey(arg1, arg2) {
+  return window['go']['services']['SkillsService']['SignSkillWithSSHKey'](arg1, arg2);
+}
+
+export function SkillTreeHash(arg1) {
+  return window['go']['services']['SkillsService']['SkillTreeHash'](arg1);
+}
+
 export function Startup(arg1) {
   return window['go']['services']['SkillsService']['Startup'](arg1);
 }
@@ -234,6 +442,10 @@ export function ToggleFavorite(arg1) {
   return window['go']['services']['SkillsService']['ToggleFavorite'](arg1);
 }
 
+export function UnlockSecretVault(arg1) {
+  return window['go']['services']['SkillsService']['UnlockSecretVault'](arg1);
+}
+
 export function UpdateCollection(arg1, arg2, arg3) {
   return window['go']['services']['SkillsService']['UpdateCollection'](arg1, arg2, arg3);
 }
@@ -249,3 +461,11 @@ export function UpdateSkill(arg1) {
 export function UpdateSkillAgentLinks(arg1, arg2) {
   return window['go']['services']['SkillsService']['UpdateSkillAgentLinks'](arg1, arg2);
 }
+
+export function UpdateSkillWithOptions(arg1, arg2) {
+  return window['go']['services']['SkillsService']['UpdateSkillWithOptions'](arg1, arg2);
+}
+
+export function VerifySkills() {
+  return window['go']['services']['SkillsService']['VerifySkills']();
+}
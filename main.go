@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"embed"
+	"os"
 	"agent-hub/backend"
+	"agent-hub/backend/cli"
 	"agent-hub/backend/services"
 
 	"github.com/wailsapp/wails/v2"
@@ -16,27 +18,39 @@ import (
 var assets embed.FS
 
 func main() {
+	// 命令行模式：agent-hub <command> ... 直接执行并退出，不启动 GUI
+	if cli.IsCommand(os.Args[1:]) {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	// 文件系统根目录（home / 中央 skills / 配置目录），可通过 AGENT_HUB_* 环境变量覆盖
+	env, err := services.LoadEnvironment("", "", "")
+	if err != nil {
+		println("Error:", err.Error())
+		os.Exit(1)
+	}
+
 	// Create an instance of the app structure
 	app := backend.NewApp()
-	folderService := services.NewFolderService()
-	skillsService := services.NewSkillsService()
-	agentService := services.NewAgentService()
+	folderService := services.NewFolderService(env)
+	skillsService := services.NewSkillsService(env)
+	agentService := services.NewAgentService(env)
 	envService := services.NewEnvService()
 	
 	// Create new enhanced services
-	templateService := services.NewTemplateService()
-	recommendationService := services.NewRecommendationService(skillsService)
-	dependencyService := services.NewDependencyService(skillsService)
-	monitoringService := services.NewMonitoringService()
-	backupService := services.NewBackupService()
-	searchService := services.NewSearchService(skillsService)
-	profileService := services.NewProfileService(skillsService)
-	ratingService := services.NewRatingService()
-	providerService := services.NewProviderService()
+	templateService := services.NewTemplateService(env)
+	recommendationService := services.NewRecommendationService(env, skillsService)
+	dependencyService := services.NewDependencyService(env, skillsService)
+	monitoringService := services.NewMonitoringService(env)
+	backupService := services.NewBackupService(env)
+	searchService := services.NewSearchService(env, skillsService)
+	profileService := services.NewProfileService(env, skillsService)
+	ratingService := services.NewRatingService(env)
+	providerService := services.NewProviderService(env)
 	trayService := services.NewTrayService(providerService)
 
 	// Create application with options
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:             "Agent Hub",
 		Width:             1024,
 		Height:            768,